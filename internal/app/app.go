@@ -8,12 +8,15 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net"
 	"net/http"
 	"net/http/pprof"
 	"path"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
@@ -33,10 +36,17 @@ import (
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/demo"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/demodata"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/docker"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/eventlog"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/exporter"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/fakesmi"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/mps"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/notify"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvmlnative"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/state"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/xidcatalog"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/xidlog"
 )
 
 const appName = "nvidia_gpu_exporter"
@@ -168,6 +178,133 @@ func Run(ctx context.Context, args []string, opts Options) error {
 				"backend). Opt-in because it changes the label set of the "+
 				"per-process series.").
 			Default("false").Bool()
+		collectComputeAppsTypes = app.Flag("collect.compute-apps-types",
+			"Also list graphics and MPS client processes in the per-process metrics, "+
+				"with a type label (compute, graphics, mps) telling them apart (requires "+
+				"--collect.compute-apps and the exec or nvml backend; the exec backend "+
+				"reads them from `nvidia-smi -q -x -d PIDS`). Opt-in because it changes the "+
+				"label set of the per-process series.").
+			Default("false").Bool()
+		collectComputeAppsUtilization = app.Flag("collect.compute-apps-utilization",
+			"Also export per-process SM, memory, encoder and decoder utilization, "+
+				"averaged over the driver samples taken between collections (requires "+
+				"--collect.compute-apps and --collect.backend=nvml). The first collection "+
+				"after startup only opens the window and reports none.").
+			Default("false").Bool()
+		collectComputeAppsDocker = app.Flag("collect.compute-apps-docker",
+			"Also export which Docker container runs each process (container name, image and "+
+				"Compose project), resolved through the Docker Engine API (requires "+
+				"--collect.compute-apps and the exec or nvml backend). Needs the host PID "+
+				"namespace and read access to the daemon socket.").
+			Default("false").Bool()
+		collectDockerSocket = app.Flag("collect.docker-socket",
+			"Path to the Docker daemon's unix socket, for --collect.compute-apps-docker. "+
+				"The daemon's default, "+docker.DefaultSocket+", is used when unset.").
+			Default("").String()
+		collectComputeAppsMPS = app.Flag("collect.compute-apps-mps",
+			"Also export the MPS sharing state: the running MPS control daemon and servers, "+
+				"and each MPS client's active thread percentage and pinned memory limits, read "+
+				"from the processes' environments (requires --collect.compute-apps, plus "+
+				"--collect.compute-apps-types with the exec or nvml backend; the demo backend "+
+				"simulates its configured topology).").
+			Default("false").Bool()
+		collectAccounting = app.Flag("collect.accounting",
+			"Also export per-GPU counters of completed processes, their GPU-seconds and "+
+				"their largest peak memory, read from the driver's accounting buffers, so "+
+				"jobs that start and finish between scrapes are counted too (requires "+
+				"accounting mode, `nvidia-smi -am 1`, and the exec or nvml backend). Only "+
+				"processes completing after the exporter started are counted.").
+			Default("false").Bool()
+		collectVideoSessions = app.Flag("collect.video-sessions",
+			"Also export the active NVENC encoder and NvFBC capture sessions per GPU: "+
+				"session counts plus per-session average FPS and latency (requires "+
+				"--collect.backend=nvml; the demo backend serves the families regardless). "+
+				"Opt-in because the per-session series churn with every new session.").
+			Default("false").Bool()
+		collectNVLink = app.Flag("collect.nvlink",
+			"Also export the NVLink links per GPU: link state, version and far end, plus "+
+				"per-link data throughput and CRC, replay and recovery error counters (requires "+
+				"--collect.backend=nvml; the demo backend serves the families regardless).").
+			Default("false").Bool()
+		collectGPM = app.Flag("collect.gpm",
+			"Also export whole-GPU profiling metrics from the driver's GPU performance monitoring: "+
+				"SM activity and occupancy, tensor, DRAM and FP64/FP32/FP16 pipe activity, and "+
+				"NVLink and PCIe bandwidth (requires --collect.backend=nvml and a Hopper or newer "+
+				"GPU outside MIG mode; the demo backend serves the families regardless). Computed "+
+				"over the window between two collections, so the first collection has none.").
+			Default("false").Bool()
+		collectDriverSamples = app.Flag("collect.driver-samples",
+			"Also export the power draw, GPU and memory utilization and clocks the driver samples "+
+				"between collections, as min, max and average per collection window, plus a native "+
+				"histogram of power draw, so short spikes a single reading misses still show "+
+				"(requires --collect.backend=nvml; the demo backend serves the families regardless). "+
+				"The first collection that sees a GPU only opens its window.").
+			Default("false").Bool()
+		collectCapabilities = app.Flag("collect.capabilities",
+			"Also export each GPU's static capabilities for capacity planning: architecture, brand, "+
+				"board part number and maximum PCIe generation and width as info labels, plus its "+
+				"core count, memory bus width, maximum clocks and per-performance-state clock ranges "+
+				"(requires --collect.backend=nvml; the demo backend serves the families regardless). "+
+				"A GPU is read once per NVML initialization, not on every collection.").
+			Default("false").Bool()
+		collectTemperatureThresholds = app.Flag("collect.temperature-thresholds",
+			"Also export each GPU's temperature thresholds: shutdown, slowdown, maximum operating "+
+				"GPU and memory temperatures, and with the nvml backend the acoustic target "+
+				"temperature bounds. The exec backend reads them from `nvidia-smi -q -d TEMPERATURE` "+
+				"on every collection, where datacenter GPUs report them relative to T.Limit; the "+
+				"demo backend serves the families regardless.").
+			Default("false").Bool()
+		collectPCIeErrors = app.Flag("collect.pcie-errors",
+			"Also export each GPU's PCIe replay and replay rollover counters, and with the nvml "+
+				"backend its correctable, uncorrectable and fatal AER error counts. The exec backend "+
+				"reads the replay counters from the full `nvidia-smi -q -x` report on every collection, "+
+				"one run shared with --collect.compute-apps-types; the demo backend serves the families "+
+				"regardless.").
+			Default("false").Bool()
+		collectFans = app.Flag("collect.fans",
+			"Also export the speed, target speed and control policy of each fan of GPUs with fans of "+
+				"their own, beside the board-level fan.speed (requires --collect.backend=nvml: "+
+				"nvidia-smi reports one speed per board; the demo backend serves the families "+
+				"regardless).").
+			Default("false").Bool()
+		collectConfComputing = app.Flag("collect.confidential-computing",
+			"Also export the system's confidential computing capabilities, mode, ready state and key "+
+				"rotation threshold, and each GPU's protected and unprotected memory sizes (requires "+
+				"--collect.backend=nvml; the demo backend serves the families regardless).").
+			Default("false").Bool()
+		collectVGPU = app.Flag("collect.vgpu",
+			"Also export the vGPU instances running on hypervisor GPUs: their type, VM, framebuffer "+
+				"usage, encoder sessions and engine utilization, labeled by the host GPU's uuid. GPUs "+
+				"hosting no vGPUs export nothing (requires --collect.backend=nvml; the demo backend "+
+				"serves the families regardless).").
+			Default("false").Bool()
+		collectSuperchip = app.Flag("collect.superchip",
+			"Also export the C2C links joining superchip GPUs such as GH200 and GB200 to their Grace "+
+				"CPU, with each link's state, bandwidth and error counters, the whole module's energy "+
+				"and the NUMA node the GPU's memory is onlined as. GPUs without C2C links export "+
+				"nothing (requires --collect.backend=nvml; the demo backend serves the families "+
+				"regardless).").
+			Default("false").Bool()
+		collectTopology = app.Flag("collect.topology",
+			"Also export how each pair of GPUs connects, over NVLink or through which PCIe or host "+
+				"hop as in `nvidia-smi topo -m`, and the NUMA node and CPUs each GPU is local to. "+
+				"Read again only when the set of GPUs changes; the exec backend runs "+
+				"`nvidia-smi topo -m` for it, and the demo backend serves the families regardless.").
+			Default("false").Bool()
+		collectXIDCatalog = app.Flag("collect.xid-catalog",
+			"Path to a YAML file overriding the built-in XID catalog exported as nvidia_smi_xid_info: "+
+				"each entry replaces the built-in one with the same xid, and codes the built-in "+
+				"catalog lacks are added. Entries carry xid, description, severity (info, warning "+
+				"or critical), action and reset-required.").
+			Default("").String()
+		collectXIDLog = app.Flag("collect.xid-log",
+			"Count XID errors for the exec backend from the kernel log at the given path: "+
+				xidlog.DefaultPath+", or a file a journal export appends to "+
+				"(`journalctl -k -f -o short-iso >> FILE`). The driver's `NVRM: Xid` lines are "+
+				"parsed into the nvml backend's XID families, attributed to GPUs by PCI bus id; "+
+				"only the lines written after startup are counted. Reading "+xidlog.DefaultPath+
+				" needs CAP_SYSLOG where kernel.dmesg_restrict is set.").
+			Default("").String()
 		collectPcieThroughput = app.Flag("collect.pcie-throughput",
 			"Also export the PCIe TX/RX throughput per GPU (requires --collect.backend=nvml; "+
 				"the demo backend serves the family regardless). "+
@@ -175,6 +312,41 @@ func Run(ctx context.Context, args []string, opts Options) error {
 				"roughly 40ms per GPU to every collection cycle (~320ms on an 8-GPU node); "+
 				"pairing it with --collect.interval keeps scrapes unaffected.").
 			Default("false").Bool()
+		stateDir = app.Flag("state.dir",
+			"Directory to persist the cumulative counters in across restarts: the XID and driver "+
+				"event counts with their last-seen times, the demo backend's integrated GPU and "+
+				"module energy and the collection failure count. They are checkpointed atomically "+
+				"every 30s and at shutdown, and restored at startup only under the same backend, "+
+				"driver version and boot; a checkpoint of another driver generation is discarded. "+
+				"Unset, the counters start from zero on every start.").
+			Default("").String()
+		notifyWebhookURLs = app.Flag("notify.webhook-url",
+			"URL to POST a notification to on every new driver event and GPU recovery action "+
+				"change, within a second of it; repeat the flag for several webhooks. Deliveries are "+
+				"retried with backoff on network errors, 5xx and 429 answers, then dropped with a "+
+				"warning. Unset, no notifications are sent.").
+			Strings()
+		notifyTemplate = app.Flag("notify.template",
+			"Path to a Go text/template rendering the notification body from its fields "+
+				"(Kind, Time, Host, UUID, Type, XID, Description, Severity, Action, ResetRequired, "+
+				"PCIBusID, Message, From, To), with a json function quoting a value. The JSON "+
+				"encoding of the notification is posted when unset.").
+			Default("").String()
+		notifyEventTypes = app.Flag("notify.event-types",
+			"Comma-separated driver event types to notify, out of xid, ecc_single_bit, "+
+				"ecc_double_bit, clock_change, power_source_change and mig_config_change.").
+			Default(strings.Join(notify.DefaultEventTypes, ",")).String()
+		notifyXIDs = app.Flag("notify.xids",
+			"Comma-separated XID codes to notify (e.g. 79,94,95); every XID is notified when unset.").
+			Default("").String()
+		notifyDedupWindow = app.Flag("notify.dedup-window",
+			"Suppress a notification identical to one sent within this window: the same GPU, "+
+				"event type and XID, or the same recovery action. 0 disables the deduplication.").
+			Default("1m").Duration()
+		notifyRateLimit = app.Flag("notify.rate-limit",
+			"Maximum notifications per minute across all GPUs, bursting up to as many; the "+
+				"excess is dropped with a warning. 0 disables the limit.").
+			Default("30").Int()
 		shutdownOnErr = app.Flag("shutdown-on-error",
 			"Shut down the exporter if there is a fatal collection error "+
 				"(a failing nvidia-smi run, or a lost GPU/driver in nvml mode). "+
@@ -216,6 +388,22 @@ func Run(ctx context.Context, args []string, opts Options) error {
 		pcieThroughput:   *collectPcieThroughput,
 		computeApps:      *collectComputeApps,
 		computeAppsMIG:   *collectComputeAppsMIG,
+		computeAppsTypes: *collectComputeAppsTypes,
+		computeAppsUtil:  *collectComputeAppsUtilization,
+		docker:           *collectComputeAppsDocker,
+		dockerSocket:     *collectDockerSocket,
+		computeAppsMPS:   *collectComputeAppsMPS,
+		accounting:       *collectAccounting,
+		videoSessions:    *collectVideoSessions,
+		nvlink:           *collectNVLink,
+		gpm:              *collectGPM,
+		driverSamples:    *collectDriverSamples,
+		capabilities:     *collectCapabilities,
+		fans:             *collectFans,
+		confComputing:    *collectConfComputing,
+		vgpu:             *collectVGPU,
+		superchip:        *collectSuperchip,
+		xidLog:           *collectXIDLog,
 		demoConfig:       *demoConfig,
 	}
 
@@ -223,6 +411,23 @@ func Run(ctx context.Context, args []string, opts Options) error {
 		return err
 	}
 
+	xidCatalog, err := loadXIDCatalog(*collectXIDCatalog)
+	if err != nil {
+		return err
+	}
+
+	notifyCfg, err := buildNotifyConfig(notifyFlagSet{
+		urls:        *notifyWebhookURLs,
+		template:    *notifyTemplate,
+		eventTypes:  *notifyEventTypes,
+		xids:        *notifyXIDs,
+		dedupWindow: *notifyDedupWindow,
+		rateLimit:   *notifyRateLimit,
+	})
+	if err != nil {
+		return err
+	}
+
 	ctx, serverCancel := context.WithCancelCause(ctx)
 	defer serverCancel(nil)
 
@@ -242,14 +447,36 @@ func Run(ctx context.Context, args []string, opts Options) error {
 		timeout:          *collectTimeout,
 		computeApps:      *collectComputeApps,
 		computeAppsMIG:   *collectComputeAppsMIG,
+		computeAppsTypes: *collectComputeAppsTypes,
+		computeAppsUtil:  *collectComputeAppsUtilization,
+		docker:           *collectComputeAppsDocker,
+		dockerSocket:     *collectDockerSocket,
+		computeAppsMPS:   *collectComputeAppsMPS,
+		accounting:       *collectAccounting,
+		videoSessions:    *collectVideoSessions,
+		nvlink:           *collectNVLink,
+		gpm:              *collectGPM,
+		driverSamples:    *collectDriverSamples,
+		capabilities:     *collectCapabilities,
+		thresholds:       *collectTemperatureThresholds,
+		pcieErrors:       *collectPCIeErrors,
+		fans:             *collectFans,
+		confComputing:    *collectConfComputing,
+		vgpu:             *collectVGPU,
+		superchip:        *collectSuperchip,
+		topology:         *collectTopology,
 		pcieThroughput:   *collectPcieThroughput,
+		xidLog:           *collectXIDLog,
 		demoConfig:       *demoConfig,
+		xidCatalog:       xidCatalog,
+		stateDir:         *stateDir,
+		notify:           notifyCfg,
 		onFatal:          onFatal,
 	}
 
 	registry := prometheus.NewRegistry()
 
-	exp, err := setupExporter(ctx, eg, collectCfg, registry, logger)
+	exp, events, err := setupExporter(ctx, eg, collectCfg, registry, logger)
 	if err != nil {
 		return err
 	}
@@ -259,6 +486,7 @@ func Run(ctx context.Context, args []string, opts Options) error {
 		enablePprof:   *enablePprof,
 		maxRequests:   *maxRequests,
 		timeoutOffset: *timeoutOffset,
+		events:        events,
 	}, registry, exp, logger)
 	if err != nil {
 		return err
@@ -338,6 +566,22 @@ type backendFlagSet struct {
 	pcieThroughput   bool
 	computeApps      bool
 	computeAppsMIG   bool
+	computeAppsTypes bool
+	computeAppsUtil  bool
+	docker           bool
+	dockerSocket     string
+	computeAppsMPS   bool
+	accounting       bool
+	videoSessions    bool
+	nvlink           bool
+	gpm              bool
+	driverSamples    bool
+	capabilities     bool
+	fans             bool
+	confComputing    bool
+	vgpu             bool
+	superchip        bool
+	xidLog           string
 	demoConfig       string
 }
 
@@ -368,6 +612,105 @@ func validateBackendFlags(flags backendFlagSet) error {
 		return errors.New("--collect.compute-apps-mig requires --collect.compute-apps")
 	}
 
+	if flags.computeAppsTypes && flags.backend == backendDemo {
+		// the demo's fake has no XML report to answer the typed query
+		return errors.New("--collect.compute-apps-types requires --collect.backend=exec or nvml")
+	}
+
+	if flags.computeAppsTypes && !flags.computeApps {
+		return errors.New("--collect.compute-apps-types requires --collect.compute-apps")
+	}
+
+	if flags.computeAppsUtil && flags.backend != backendNVML {
+		// the utilization samples only exist in the driver library
+		return errors.New("--collect.compute-apps-utilization requires --collect.backend=nvml")
+	}
+
+	if flags.computeAppsUtil && !flags.computeApps {
+		return errors.New("--collect.compute-apps-utilization requires --collect.compute-apps")
+	}
+
+	if flags.docker && flags.backend == backendDemo {
+		// the demo's processes are fictional, their pids name nothing
+		return errors.New("--collect.compute-apps-docker requires --collect.backend=exec or nvml")
+	}
+
+	if flags.docker && !flags.computeApps {
+		return errors.New("--collect.compute-apps-docker requires --collect.compute-apps")
+	}
+
+	if flags.dockerSocket != "" && !flags.docker {
+		return errors.New("--collect.docker-socket requires --collect.compute-apps-docker")
+	}
+
+	if flags.computeAppsMPS && !flags.computeApps {
+		return errors.New("--collect.compute-apps-mps requires --collect.compute-apps")
+	}
+
+	if flags.computeAppsMPS && flags.backend != backendDemo && !flags.computeAppsTypes {
+		// only the typed process lists tell MPS clients apart (the demo
+		// synthesizes its clients instead)
+		return errors.New("--collect.compute-apps-mps requires --collect.compute-apps-types " +
+			"with the exec or nvml backend")
+	}
+
+	if flags.videoSessions && flags.backend == backendExec {
+		// the per-session readings only exist in the driver library (the
+		// demo backend serves the families regardless)
+		return errors.New("--collect.video-sessions requires --collect.backend=nvml")
+	}
+
+	if flags.nvlink && flags.backend == backendExec {
+		// nvidia-smi's query interface has no per-link fields
+		return errors.New("--collect.nvlink requires --collect.backend=nvml")
+	}
+
+	if flags.gpm && flags.backend == backendExec {
+		// GPM samples are only reachable through the driver library
+		return errors.New("--collect.gpm requires --collect.backend=nvml")
+	}
+
+	if flags.driverSamples && flags.backend == backendExec {
+		// nvidia-smi prints no sample buffers
+		return errors.New("--collect.driver-samples requires --collect.backend=nvml")
+	}
+
+	if flags.capabilities && flags.backend == backendExec {
+		// read through the driver library, cached per initialization
+		return errors.New("--collect.capabilities requires --collect.backend=nvml")
+	}
+
+	if flags.fans && flags.backend == backendExec {
+		// nvidia-smi prints one fan speed per board, in text and XML alike
+		return errors.New("--collect.fans requires --collect.backend=nvml")
+	}
+
+	if flags.confComputing && flags.backend == backendExec {
+		// the system state has no query field, only the library getters
+		return errors.New("--collect.confidential-computing requires --collect.backend=nvml")
+	}
+
+	if flags.vgpu && flags.backend == backendExec {
+		// nvidia-smi reports vGPUs only in its separate vgpu subcommand
+		return errors.New("--collect.vgpu requires --collect.backend=nvml")
+	}
+
+	if flags.superchip && flags.backend == backendExec {
+		// the C2C readings are field values, which no query field maps
+		return errors.New("--collect.superchip requires --collect.backend=nvml")
+	}
+
+	if flags.accounting && flags.backend == backendDemo {
+		// the demo's fake records no accounting buffers to answer with
+		return errors.New("--collect.accounting requires --collect.backend=exec or nvml")
+	}
+
+	if flags.xidLog != "" && flags.backend != backendExec {
+		// the nvml backend watches the driver events directly, and the
+		// demo backend synthesizes its XIDs
+		return errors.New("--collect.xid-log requires --collect.backend=exec")
+	}
+
 	if flags.demoConfig != "" && flags.backend != backendDemo {
 		return errors.New("--demo-config requires --collect.backend=demo")
 	}
@@ -410,7 +753,7 @@ func validateWebFlags(metricsPath string, maxRequests int, timeoutOffset time.Du
 // trailing slash reserves exactly that path. The pprof subtree is reserved
 // even in runs that do not enable pprof, so enabling it later cannot turn a
 // working configuration into a startup failure.
-var reservedPaths = []string{"/-/healthy", "/-/ready", "/debug/pprof/"}
+var reservedPaths = []string{"/-/healthy", "/-/ready", eventlog.Path, "/debug/pprof/"}
 
 // validateMetricsPath rejects telemetry path values that would collide with
 // the exporter's own routes or make the route registration panic at startup.
@@ -483,27 +826,187 @@ type collectConfig struct {
 	timeout          time.Duration
 	computeApps      bool
 	computeAppsMIG   bool
+	computeAppsTypes bool
+	computeAppsUtil  bool
+	docker           bool
+	dockerSocket     string
+	computeAppsMPS   bool
+	accounting       bool
+	videoSessions    bool
+	nvlink           bool
+	gpm              bool
+	driverSamples    bool
+	capabilities     bool
+	thresholds       bool
+	pcieErrors       bool
+	fans             bool
+	confComputing    bool
+	vgpu             bool
+	superchip        bool
+	topology         bool
 	pcieThroughput   bool
+	xidLog           string
 	demoConfig       string
+	xidCatalog       *xidcatalog.Catalog
+	stateDir         string
+	notify           notify.Config
 	onFatal          func(error)
 }
 
+// loadXIDCatalog returns the built-in XID catalog, overridden by the file at
+// path when one is given.
+func loadXIDCatalog(path string) (*xidcatalog.Catalog, error) {
+	if path == "" {
+		return xidcatalog.Builtin(), nil
+	}
+
+	catalog, err := xidcatalog.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load --collect.xid-catalog: %w", err)
+	}
+
+	return catalog, nil
+}
+
+// notifyFlagSet carries the --notify.* flags.
+type notifyFlagSet struct {
+	urls        []string
+	template    string
+	eventTypes  string
+	xids        string
+	dedupWindow time.Duration
+	rateLimit   int
+}
+
+// buildNotifyConfig validates the --notify.* flags and loads the template.
+// Setting any of them without a webhook URL is an error rather than a
+// silently idle notifier.
+func buildNotifyConfig(flags notifyFlagSet) (notify.Config, error) {
+	if len(flags.urls) == 0 {
+		if flags.template != "" || flags.xids != "" {
+			return notify.Config{}, errors.New("--notify.template and --notify.xids require --notify.webhook-url")
+		}
+
+		return notify.Config{}, nil
+	}
+
+	for _, target := range flags.urls {
+		if err := notify.ValidateURL(target); err != nil {
+			return notify.Config{}, fmt.Errorf("--notify.webhook-url: %w", err)
+		}
+	}
+
+	if flags.dedupWindow < 0 {
+		return notify.Config{}, fmt.Errorf("notify.dedup-window must not be negative, got %s", flags.dedupWindow)
+	}
+
+	if flags.rateLimit < 0 {
+		return notify.Config{}, fmt.Errorf("notify.rate-limit must not be negative, got %d", flags.rateLimit)
+	}
+
+	eventTypes, err := notify.ParseEventTypes(flags.eventTypes)
+	if err != nil {
+		return notify.Config{}, fmt.Errorf("invalid --notify.event-types: %w", err)
+	}
+
+	xids, err := notify.ParseXIDs(flags.xids)
+	if err != nil {
+		return notify.Config{}, fmt.Errorf("invalid --notify.xids: %w", err)
+	}
+
+	cfg := notify.Config{
+		URLs:        flags.urls,
+		EventTypes:  eventTypes,
+		XIDs:        xids,
+		DedupWindow: flags.dedupWindow,
+		RateLimit:   flags.rateLimit,
+	}
+
+	if flags.template != "" {
+		if cfg.Template, err = notify.LoadTemplate(flags.template); err != nil {
+			return notify.Config{}, fmt.Errorf("failed to load --notify.template: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
+
 // setupExporter resolves the query fields, builds the collection source
 // (adding the background collector to the errgroup when an interval is set),
 // and builds the exporter. The exporter itself is returned instead of
 // registered: the metrics handler collects it under each scrape's own
 // context, so it lives in a per-scrape registry there. The given registry
-// gets the collectors whose output is scrape-independent.
+// gets the collectors whose output is scrape-independent. The backend's
+// driver event log is returned beside it, nil when the backend has none.
+//
+//nolint:ireturn // the exec backend without --collect.xid-log has no event log, a nil interface is the point
 func setupExporter(
 	ctx context.Context,
 	eg *errgroup.Group,
 	cfg collectConfig,
 	registry *prometheus.Registry,
 	logger *slog.Logger,
-) (*exporter.GPUExporter, error) {
-	resolved, query, xids, exitCodeMetric, err := setupBackend(ctx, eg, cfg, logger)
+) (*exporter.GPUExporter, eventlog.Source, error) {
+	backend, err := setupBackend(ctx, eg, cfg, logger)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	query := backend.query
+
+	// the counters alone: the state directory carries them over, the event
+	// log is served from the backend itself
+	var events state.EventSource
+	if backend.events != nil {
+		events = backend.events
+	}
+
+	if cfg.docker {
+		socket := cfg.dockerSocket
+		if socket == "" {
+			socket = docker.DefaultSocket
+		}
+
+		resolver := docker.NewResolver(socket, docker.DefaultProcRoot, logger)
+		query = withContainerAttribution(query, resolver, logger)
+	}
+
+	if cfg.computeAppsMPS && cfg.backend != backendDemo {
+		query = withMPSSharing(query, logger)
+	}
+
+	if len(cfg.notify.URLs) > 0 {
+		// fed the backend's own event log: a restored counter is no new event
+		var recent eventlog.Source
+		if backend.events != nil {
+			recent = backend.events
+		}
+
+		notifier := notify.New(cfg.notify, recent, cfg.xidCatalog, logger)
+		query = notifier.WatchQueryFunc(query)
+
+		eg.Go(func() error { return notifier.Run(ctx) })
+	}
+
+	var store *state.Store
+
+	if cfg.stateDir != "" {
+		generation := state.CurrentGeneration(cfg.backend, backend.driverVersion)
+
+		store, err = state.Open(cfg.stateDir, generation, logger)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to set up --state.dir: %w", err)
+		}
+
+		// the demo backend integrates its energy counters itself; the nvml
+		// backend's are the driver's own and survive a restart anyway
+		if cfg.backend == backendDemo {
+			query = store.WrapQueryFunc(query)
+		}
+
+		if events != nil {
+			events = store.Events(events)
+		}
 	}
 
 	var src collect.Source
@@ -519,34 +1022,94 @@ func setupExporter(
 		src = collect.NewLive(query, cfg.timeout, cfg.onFatal, logger)
 	}
 
+	if store != nil {
+		src = store.Source(src)
+
+		eg.Go(func() error { return store.Run(ctx) })
+	}
+
 	extrasCapable := cfg.backend == backendNVML || cfg.backend == backendDemo
 
 	features := exporter.Features{
 		ComputeApps:         cfg.computeApps,
 		ComputeAppMIGLabels: cfg.computeAppsMIG,
+		ComputeAppTypes:     cfg.computeAppsTypes,
+		// validated to the nvml backend at startup
+		ComputeAppUtilization: cfg.computeAppsUtil,
+		// validated to the exec and nvml backends at startup
+		ComputeAppContainers: cfg.docker,
+		ComputeAppMPS:        cfg.computeAppsMPS,
+		Accounting:           cfg.accounting,
 		// the extras families exist in the nvml backend and its demo twin;
 		// the demo serves the PCIe family unconditionally
 		PCIeThroughput: cfg.pcieThroughput || cfg.backend == backendDemo,
-		Energy:         extrasCapable,
-		MIG:            extrasCapable,
-		XIDEvents:      extrasCapable,
+		VideoSessions:  cfg.videoSessions || cfg.backend == backendDemo,
+		NVLink:         cfg.nvlink || cfg.backend == backendDemo,
+		GPM:            cfg.gpm || cfg.backend == backendDemo,
+		DriverSamples:  cfg.driverSamples || cfg.backend == backendDemo,
+		Capabilities:   cfg.capabilities || cfg.backend == backendDemo,
+		// the exec backend reads the thresholds too
+		TemperatureThresholds: cfg.thresholds || cfg.backend == backendDemo,
+		PCIeErrors:            cfg.pcieErrors || cfg.backend == backendDemo,
+		Fans:                  cfg.fans || cfg.backend == backendDemo,
+		ConfidentialComputing: cfg.confComputing || cfg.backend == backendDemo,
+		VGPU:                  cfg.vgpu || cfg.backend == backendDemo,
+		Superchip:             cfg.superchip || cfg.backend == backendDemo,
+		Topology:              cfg.topology || cfg.backend == backendDemo,
+		Energy:                extrasCapable,
+		MIG:                   extrasCapable,
+		XIDEvents:             extrasCapable || cfg.xidLog != "",
 	}
 
-	exp := exporter.New(ctx, exporter.DefaultPrefix, resolved, src, features, xids, exitCodeMetric, logger)
+	exp := exporter.New(ctx, exporter.DefaultPrefix, backend.resolved, src, features, events, events,
+		backend.exitCodeMetric, logger)
 
 	// the go and process collectors keep the exposed families identical to
 	// what the default registry used to serve
-	for _, collector := range []prometheus.Collector{
+	collectorSet := []prometheus.Collector{
 		clientversion.NewCollector(appName),
 		collectors.NewGoCollector(),
 		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
-	} {
+	}
+
+	// the catalog only annotates the XID counters, so it is served with them
+	if features.XIDEvents {
+		collectorSet = append(collectorSet, exporter.NewXIDCatalogCollector(exporter.DefaultPrefix, cfg.xidCatalog))
+	}
+
+	for _, collector := range collectorSet {
 		if err = registry.Register(collector); err != nil {
-			return nil, fmt.Errorf("failed to register collector: %w", err)
+			return nil, nil, fmt.Errorf("failed to register collector: %w", err)
 		}
 	}
 
-	return exp, nil
+	if backend.events == nil {
+		return exp, nil, nil
+	}
+
+	return exp, backend.events, nil
+}
+
+// eventSource is what the nvml backend and its demo twin serve beside the
+// collections: the counters and the recent event log of the XID watcher.
+// The exec backend serves it from the kernel log when --collect.xid-log is
+// set.
+type eventSource interface {
+	exporter.XIDSource
+	exporter.DriverEventSource
+	eventlog.Source
+}
+
+// backendSetup is what a backend flavor hands the exporter: the resolved
+// fields, the collection function, the event source (nil for the exec
+// backend without --collect.xid-log), the collection status metric, and the
+// driver version the persisted state is keyed by.
+type backendSetup struct {
+	resolved       nvidiasmi.ResolvedFields
+	query          collect.QueryFunc
+	events         eventSource
+	exitCodeMetric exporter.ExitCodeMetric
+	driverVersion  string
 }
 
 // setupBackend resolves the query fields and builds the collection function
@@ -554,14 +1117,12 @@ func setupExporter(
 // nvidia-smi; the nvml backend resolves against its compiled catalog and
 // reports collection status as an NVML return code under its own metric
 // name.
-//
-//nolint:ireturn // the exec backend has no XID source, a nil interface is the point
 func setupBackend(
 	ctx context.Context,
 	eg *errgroup.Group,
 	cfg collectConfig,
 	logger *slog.Logger,
-) (nvidiasmi.ResolvedFields, collect.QueryFunc, exporter.XIDSource, exporter.ExitCodeMetric, error) {
+) (backendSetup, error) {
 	if cfg.backend == backendNVML {
 		return setupNVMLBackend(ctx, eg, cfg, logger)
 	}
@@ -580,45 +1141,73 @@ func setupBackend(
 		logger,
 	)
 	if err != nil {
-		return nvidiasmi.ResolvedFields{}, nil, nil, exporter.ExitCodeMetric{},
-			fmt.Errorf("failed to resolve query fields: %w", err)
+		return backendSetup{}, fmt.Errorf("failed to resolve query fields: %w", err)
 	}
 
 	// the CUDA version is not a query field and is effectively constant for
 	// the process lifetime (it changes with the driver, which requires the
 	// GPUs to be idle), so it is read once at startup, never per scrape
-	cudaVersion := nvidiasmi.QueryCudaVersion(
+	versions := nvidiasmi.QueryVersions(
 		ctx, cfg.nvidiaSmiCommand, cfg.timeout, nvidiasmi.DefaultRunFunc, logger)
 
-	query := buildQueryFunc(cfg, resolved, cudaVersion, nvidiasmi.DefaultRunFunc, logger)
+	setup := backendSetup{
+		resolved:       resolved,
+		query:          buildQueryFunc(cfg, resolved, versions.CUDA, nvidiasmi.DefaultRunFunc, logger),
+		exitCodeMetric: exporter.ExecExitCodeMetric,
+		driverVersion:  versions.Driver,
+	}
+
+	if cfg.xidLog == "" {
+		return setup, nil
+	}
 
-	return resolved, query, nil, exporter.ExecExitCodeMetric, nil
+	xids, err := xidlog.Open(cfg.xidLog, cfg.xidCatalog, logger)
+	if err != nil {
+		return backendSetup{}, fmt.Errorf("failed to set up --collect.xid-log: %w", err)
+	}
+
+	eg.Go(func() error { return xids.Run(ctx) })
+
+	setup.query = withXIDBusIDs(setup.query, xids)
+	setup.events = xids
+
+	return setup, nil
+}
+
+// withXIDBusIDs feeds every collected table to the kernel log XID source,
+// which names GPUs by PCI bus id alone.
+func withXIDBusIDs(query collect.QueryFunc, xids *xidlog.Source) collect.QueryFunc {
+	return func(queryCtx context.Context) (collect.Reading, int, error) {
+		reading, exitCode, err := query(queryCtx)
+		if err == nil && reading.Table != nil {
+			xids.ObserveTable(reading.Table)
+		}
+
+		return reading, exitCode, err
+	}
 }
 
 // setupNVMLBackend wires the nvml flavor: field resolution against the
 // compiled catalog, driver shutdown tied to the application lifetime, and the
 // XID watcher running beside the collection cycles.
-//
-//nolint:ireturn // the backend implements the XID source interface
 func setupNVMLBackend(
 	ctx context.Context,
 	eg *errgroup.Group,
 	cfg collectConfig,
 	logger *slog.Logger,
-) (nvidiasmi.ResolvedFields, collect.QueryFunc, exporter.XIDSource, exporter.ExitCodeMetric, error) {
+) (backendSetup, error) {
 	backend, err := nvmlnative.New(logger)
 	if err != nil {
-		return nvidiasmi.ResolvedFields{}, nil, nil, exporter.ExitCodeMetric{},
-			fmt.Errorf("failed to set up the nvml backend: %w", err)
+		return backendSetup{}, fmt.Errorf("failed to set up the nvml backend: %w", err)
 	}
 
-	resolved, err := nvmlnative.Resolve(
-		cfg.qFieldsRaw, cfg.qFieldsExclude, backend.DriverVersion(), logger)
+	driverVersion := backend.DriverVersion()
+
+	resolved, err := nvmlnative.Resolve(cfg.qFieldsRaw, cfg.qFieldsExclude, driverVersion, logger)
 	if err != nil {
 		backend.Close()
 
-		return nvidiasmi.ResolvedFields{}, nil, nil, exporter.ExitCodeMetric{},
-			fmt.Errorf("failed to resolve query fields: %w", err)
+		return backendSetup{}, fmt.Errorf("failed to resolve query fields: %w", err)
 	}
 
 	// tie NVML shutdown to the application lifetime, best-effort: a
@@ -632,37 +1221,55 @@ func setupNVMLBackend(
 		return nil
 	})
 
-	superviseXIDWatcher(ctx, eg, backend, logger)
+	superviseXIDWatcher(ctx, eg, backend, cfg.xidCatalog, logger)
 
 	opts := nvmlnative.CollectOptions{
-		ComputeApps:    cfg.computeApps,
-		PCIeThroughput: cfg.pcieThroughput,
-		Energy:         true,
-		MIG:            true,
+		ComputeApps:           cfg.computeApps,
+		ProcessTypes:          cfg.computeAppsTypes,
+		PCIeThroughput:        cfg.pcieThroughput,
+		Energy:                true,
+		MIG:                   true,
+		ProcessUtilization:    cfg.computeAppsUtil,
+		Accounting:            cfg.accounting,
+		VideoSessions:         cfg.videoSessions,
+		NVLink:                cfg.nvlink,
+		GPM:                   cfg.gpm,
+		TemperatureThresholds: cfg.thresholds,
+		PCIeErrors:            cfg.pcieErrors,
+		Fans:                  cfg.fans,
+		DriverSamples:         cfg.driverSamples,
+		Capabilities:          cfg.capabilities,
+		Topology:              cfg.topology,
+		ConfidentialComputing: cfg.confComputing,
+		VGPU:                  cfg.vgpu,
+		Superchip:             cfg.superchip,
 	}
 
-	return resolved, backend.QueryFunc(resolved, opts), backend, exporter.NVMLReturnCodeMetric, nil
+	return backendSetup{
+		resolved:       resolved,
+		query:          backend.QueryFunc(resolved, opts),
+		events:         backend,
+		exitCodeMetric: exporter.NVMLReturnCodeMetric,
+		driverVersion:  driverVersion,
+	}, nil
 }
 
 // setupDemoBackend wires the demo flavor: the exec pipeline running against
 // the in-process fake, wrapped so every cycle works from one immutable
 // configuration snapshot and carries the synthesized extras families. The
 // served surface mimics the nvml flavor.
-//
-//nolint:ireturn // the exec backend has no XID source, a nil interface is the point
 func setupDemoBackend(
 	ctx context.Context,
 	cfg collectConfig,
 	logger *slog.Logger,
-) (nvidiasmi.ResolvedFields, collect.QueryFunc, exporter.XIDSource, exporter.ExitCodeMetric, error) {
+) (backendSetup, error) {
 	logger.Warn("demo mode: serving synthetic data, not a real GPU")
 
 	source := fakesmi.CaptureSource{FS: demodata.FS, Default: demodata.Default}
 
 	backend, err := demo.New(source, cfg.demoConfig, logger)
 	if err != nil {
-		return nvidiasmi.ResolvedFields{}, nil, nil, exporter.ExitCodeMetric{},
-			fmt.Errorf("failed to set up the demo backend: %w", err)
+		return backendSetup{}, fmt.Errorf("failed to set up the demo backend: %w", err)
 	}
 
 	runFunc := backend.RunFunc()
@@ -670,18 +1277,26 @@ func setupDemoBackend(
 	resolved, err := nvidiasmi.ResolveFields(
 		ctx, demoCommand, cfg.qFieldsRaw, cfg.qFieldsExclude, cfg.timeout, runFunc, logger)
 	if err != nil {
-		return nvidiasmi.ResolvedFields{}, nil, nil, exporter.ExitCodeMetric{},
-			fmt.Errorf("failed to resolve query fields: %w", err)
+		return backendSetup{}, fmt.Errorf("failed to resolve query fields: %w", err)
 	}
 
-	cudaVersion := nvidiasmi.QueryCudaVersion(ctx, demoCommand, cfg.timeout, runFunc, logger)
+	versions := nvidiasmi.QueryVersions(ctx, demoCommand, cfg.timeout, runFunc, logger)
 
 	demoCfg := cfg
 	demoCfg.nvidiaSmiCommand = demoCommand
-
-	query := backend.WrapQueryFunc(buildQueryFunc(demoCfg, resolved, cudaVersion, runFunc, logger))
-
-	return resolved, query, backend, exporter.NVMLReturnCodeMetric, nil
+	// the fake's -q and topo output is a fixed capture; the demo
+	// synthesizes the families read from them
+	demoCfg.thresholds = false
+	demoCfg.pcieErrors = false
+	demoCfg.topology = false
+
+	return backendSetup{
+		resolved:       resolved,
+		query:          backend.WrapQueryFunc(buildQueryFunc(demoCfg, resolved, versions.CUDA, runFunc, logger)),
+		events:         backend,
+		exitCodeMetric: exporter.NVMLReturnCodeMetric,
+		driverVersion:  versions.Driver,
+	}, nil
 }
 
 // superviseXIDWatcher runs the XID watcher beside the collection cycles for
@@ -693,6 +1308,7 @@ func superviseXIDWatcher(
 	ctx context.Context,
 	eg *errgroup.Group,
 	backend *nvmlnative.Backend,
+	catalog *xidcatalog.Catalog,
 	logger *slog.Logger,
 ) {
 	eg.Go(func() error {
@@ -701,7 +1317,7 @@ func superviseXIDWatcher(
 		go func() {
 			defer close(done)
 
-			_ = backend.RunXIDWatcher(ctx)
+			_ = backend.RunXIDWatcher(ctx, catalog)
 		}()
 
 		select {
@@ -731,6 +1347,11 @@ func buildQueryFunc(
 	runFunc nvidiasmi.RunFunc,
 	logger *slog.Logger,
 ) collect.QueryFunc {
+	var (
+		accounting collect.AccountingTracker
+		topology   topologyCache
+	)
+
 	return func(queryCtx context.Context) (collect.Reading, int, error) {
 		table, exitCode, err := nvidiasmi.Query(
 			queryCtx, cfg.nvidiaSmiCommand, resolved.Query, runFunc)
@@ -738,14 +1359,34 @@ func buildQueryFunc(
 			return collect.Reading{}, exitCode, fmt.Errorf("failed to query gpus: %w", err)
 		}
 
+		var report fullReport
+
 		reading := collect.Reading{Table: table}
 		reading.Extras.CUDAVersion = cudaVersion
 
 		if cfg.computeApps {
 			reading.AppsAttempted = true
 
-			apps, appsErr := nvidiasmi.QueryComputeApps(
-				queryCtx, cfg.nvidiaSmiCommand, runFunc, logger)
+			var (
+				apps    []nvidiasmi.ComputeApp
+				appsErr error
+			)
+
+			switch {
+			case cfg.computeAppsTypes && cfg.pcieErrors:
+				// the PCIe error counters read the full report anyway, and
+				// it carries the process lists too
+				var output string
+				if output, appsErr = report.get(queryCtx, cfg, runFunc); appsErr == nil {
+					apps, appsErr = nvidiasmi.ParseProcessesXML(output, busIDUUIDs(table), logger)
+				}
+			case cfg.computeAppsTypes:
+				apps, appsErr = nvidiasmi.QueryProcesses(
+					queryCtx, cfg.nvidiaSmiCommand, runFunc, busIDUUIDs(table), logger)
+			default:
+				apps, appsErr = nvidiasmi.QueryComputeApps(queryCtx, cfg.nvidiaSmiCommand, runFunc, logger)
+			}
+
 			if appsErr != nil {
 				reading.AppsErr = appsErr
 			} else {
@@ -754,21 +1395,351 @@ func buildQueryFunc(
 			}
 		}
 
+		if cfg.accounting {
+			reading.Extras.Accounting = queryAccounting(queryCtx, cfg, table, &accounting, runFunc, logger)
+		}
+
+		if cfg.thresholds {
+			reading.Extras.TemperatureThresholds = queryTemperatureThresholds(queryCtx, cfg, table, runFunc, logger)
+		}
+
+		if cfg.pcieErrors {
+			reading.Extras.PCIeErrors = queryPCIeErrors(queryCtx, cfg, table, &report, runFunc, logger)
+		}
+
+		if cfg.topology {
+			reading.Extras.Topology = topology.query(queryCtx, cfg, table, runFunc, logger)
+		}
+
 		return reading, exitCode, nil
 	}
 }
 
-// serveMuxConfig carries the web flags into the mux construction.
+// queryAccounting reads the accounting buffers and folds them into the
+// tracker. The family fails softly like the per-process query: a failed read
+// leaves it empty for the cycle and the tracker untouched.
+func queryAccounting(
+	ctx context.Context,
+	cfg collectConfig,
+	table *nvidiasmi.Table,
+	tracker *collect.AccountingTracker,
+	runFunc nvidiasmi.RunFunc,
+	logger *slog.Logger,
+) []collect.AccountingCounter {
+	apps, err := nvidiasmi.QueryAccountedApps(ctx, cfg.nvidiaSmiCommand, runFunc, logger)
+	if err != nil {
+		logger.Warn("failed to collect the accounted processes", "err", err)
+
+		return nil
+	}
+
+	// nvidia-smi prints no row for an empty buffer, so every GPU keeping
+	// one counts as read
+	return tracker.Observe(accountingUUIDs(table), apps)
+}
+
+// accountingUUIDs lists the normalized uuids of the table's GPUs with
+// accounting mode enabled. A disabled GPU has no buffer to count, and the
+// nvml backend leaves it out the same way.
+func accountingUUIDs(table *nvidiasmi.Table) []string {
+	uuids := make([]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		mode := strings.TrimSpace(row.QFieldToCells[nvidiasmi.AccountingModeQField].RawValue)
+		if !strings.EqualFold(mode, "Enabled") {
+			continue
+		}
+
+		uuids = append(uuids, nvidiasmi.NormalizeUUID(row.QFieldToCells[nvidiasmi.UUIDQField].RawValue))
+	}
+
+	return uuids
+}
+
+// queryTemperatureThresholds reads the thresholds and attributes them to the
+// table's GPUs by PCI bus id, the only identity the -q output carries. The
+// family fails softly like the per-process query.
+func queryTemperatureThresholds(
+	ctx context.Context,
+	cfg collectConfig,
+	table *nvidiasmi.Table,
+	runFunc nvidiasmi.RunFunc,
+	logger *slog.Logger,
+) []collect.TemperatureThreshold {
+	thresholds, err := nvidiasmi.QueryTemperatureThresholds(ctx, cfg.nvidiaSmiCommand, runFunc)
+	if err != nil {
+		logger.Warn("failed to collect the temperature thresholds", "err", err)
+
+		return nil
+	}
+
+	uuids := busIDUUIDs(table)
+	result := make([]collect.TemperatureThreshold, 0, len(thresholds))
+
+	for _, threshold := range thresholds {
+		uuid, ok := uuids[threshold.BusID]
+		if !ok {
+			// a GPU that appeared between the two queries
+			continue
+		}
+
+		result = append(result, collect.TemperatureThreshold{
+			UUID:    uuid,
+			Kind:    threshold.Kind,
+			Celsius: threshold.Celsius,
+			TLimit:  threshold.TLimit,
+		})
+	}
+
+	return result
+}
+
+// queryPCIeErrors reads the replay counters from the collection's full report
+// and attributes them to the table's GPUs by PCI bus id. The family fails
+// softly like the per-process query.
+func queryPCIeErrors(
+	ctx context.Context,
+	cfg collectConfig,
+	table *nvidiasmi.Table,
+	report *fullReport,
+	runFunc nvidiasmi.RunFunc,
+	logger *slog.Logger,
+) []collect.PCIeErrorCounters {
+	output, err := report.get(ctx, cfg, runFunc)
+
+	var counters []nvidiasmi.PCIeErrors
+	if err == nil {
+		counters, err = nvidiasmi.ParsePCIeErrors(output)
+	}
+
+	if err != nil {
+		logger.Warn("failed to collect the PCIe error counters", "err", err)
+
+		return nil
+	}
+
+	uuids := busIDUUIDs(table)
+	result := make([]collect.PCIeErrorCounters, 0, len(counters))
+
+	for _, entry := range counters {
+		uuid, ok := uuids[entry.BusID]
+		if !ok {
+			// a GPU that appeared between the two queries
+			continue
+		}
+
+		result = append(result, collect.PCIeErrorCounters{
+			UUID:            uuid,
+			Replays:         entry.Replays,
+			ReplayRollovers: entry.ReplayRollovers,
+		})
+	}
+
+	return result
+}
+
+// fullReport runs the full nvidia-smi -q -x at most once per collection, for
+// the families that read it: the PCIe replay counters, and with them the
+// typed process lists.
+type fullReport struct {
+	read   bool
+	output string
+	err    error
+}
+
+// get returns the collection's report, running nvidia-smi on the first call.
+func (r *fullReport) get(ctx context.Context, cfg collectConfig, runFunc nvidiasmi.RunFunc) (string, error) {
+	if !r.read {
+		r.output, r.err = nvidiasmi.QueryReport(ctx, cfg.nvidiaSmiCommand, runFunc)
+		r.read = true
+	}
+
+	return r.output, r.err
+}
+
+// busIDUUIDs maps the table's upper-cased PCI bus ids to the normalized GPU
+// uuids, for the -q readings that identify a GPU by bus id only.
+func busIDUUIDs(table *nvidiasmi.Table) map[string]string {
+	uuids := make(map[string]string, len(table.Rows))
+	for _, row := range table.Rows {
+		busID := strings.ToUpper(strings.TrimSpace(row.QFieldToCells[nvidiasmi.PCIBusIDQField].RawValue))
+		uuids[busID] = nvidiasmi.NormalizeUUID(row.QFieldToCells[nvidiasmi.UUIDQField].RawValue)
+	}
+
+	return uuids
+}
+
+// topologyCache holds the exec backend's GPU topology, read again only when
+// the device set changes. A fresh collection may overlap an abandoned one, so
+// the cached state is guarded; the topo -m run itself is not.
+type topologyCache struct {
+	mu sync.Mutex
+	// key names the device set the topology was read for: the table's
+	// indexes and uuids in index order.
+	key      string
+	topology collect.Topology
+}
+
+// query returns the topology of the table's GPUs, running nvidia-smi topo -m
+// when the device set changed since the last successful read. The matrix
+// names GPUs by index, attributed here through the table's index column. The
+// family fails softly like the per-process query: a failed read leaves it
+// empty for the cycle and is retried on the next.
+func (c *topologyCache) query(
+	ctx context.Context,
+	cfg collectConfig,
+	table *nvidiasmi.Table,
+	runFunc nvidiasmi.RunFunc,
+	logger *slog.Logger,
+) collect.Topology {
+	uuids := indexUUIDs(table)
+
+	indexes := slices.Sorted(maps.Keys(uuids))
+	entries := make([]string, 0, len(indexes))
+
+	for _, index := range indexes {
+		entries = append(entries, strconv.Itoa(index)+"="+uuids[index])
+	}
+
+	key := strings.Join(entries, ",")
+
+	c.mu.Lock()
+	cached, topology := key == c.key, c.topology
+	c.mu.Unlock()
+
+	if cached {
+		return topology
+	}
+
+	matrix, err := nvidiasmi.QueryTopology(ctx, cfg.nvidiaSmiCommand, runFunc)
+	if err != nil {
+		logger.Warn("failed to collect the GPU topology", "err", err)
+
+		return collect.Topology{}
+	}
+
+	topology = collect.Topology{}
+
+	for _, link := range matrix.Links {
+		uuid, ok := uuids[link.GPU]
+		peer, peerOK := uuids[link.Peer]
+
+		if !ok || !peerOK {
+			// a GPU that appeared between the two queries
+			continue
+		}
+
+		topology.Links = append(topology.Links, collect.TopologyLink{
+			UUID:     uuid,
+			PeerUUID: peer,
+			Link:     link.Link,
+		})
+	}
+
+	for _, affinity := range matrix.Affinity {
+		uuid, ok := uuids[affinity.GPU]
+		if !ok {
+			continue
+		}
+
+		topology.NUMA = append(topology.NUMA, collect.NUMAAffinity{
+			UUID:        uuid,
+			Node:        affinity.NUMANode,
+			CPUAffinity: affinity.CPUAffinity,
+		})
+	}
+
+	c.mu.Lock()
+	c.key = key
+	c.topology = topology
+	c.mu.Unlock()
+
+	return topology
+}
+
+// indexUUIDs maps the table's GPU indexes to the normalized GPU uuids, for
+// the readings that identify a GPU by index only. A row without a usable
+// index is left out.
+func indexUUIDs(table *nvidiasmi.Table) map[int]string {
+	uuids := make(map[int]string, len(table.Rows))
+	for _, row := range table.Rows {
+		index, err := strconv.Atoi(strings.TrimSpace(row.QFieldToCells[nvidiasmi.IndexQField].RawValue))
+		if err != nil {
+			continue
+		}
+
+		uuids[index] = nvidiasmi.NormalizeUUID(row.QFieldToCells[nvidiasmi.UUIDQField].RawValue)
+	}
+
+	return uuids
+}
+
+// tableUUIDs lists the table's GPU uuids, normalized, in row order (which is
+// the GPU index order).
+func tableUUIDs(table *nvidiasmi.Table) []string {
+	uuids := make([]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		uuids = append(uuids, nvidiasmi.NormalizeUUID(row.QFieldToCells[nvidiasmi.UUIDQField].RawValue))
+	}
+
+	return uuids
+}
+
+// withContainerAttribution extends a collection cycle with the Docker
+// container of each listed process. It sits outside the backends because the
+// lookup is the same for all of them: a pid, then its cgroup, then the
+// daemon. The family fails softly like the per-process query it extends.
+func withContainerAttribution(
+	query collect.QueryFunc,
+	resolver *docker.Resolver,
+	logger *slog.Logger,
+) collect.QueryFunc {
+	return func(queryCtx context.Context) (collect.Reading, int, error) {
+		reading, exitCode, err := query(queryCtx)
+		if err != nil || !reading.AppsSuccess {
+			return reading, exitCode, err
+		}
+
+		containers, attributeErr := resolver.Attribute(queryCtx, reading.Apps)
+		if attributeErr != nil {
+			logger.Warn("failed to attribute processes to docker containers", "err", attributeErr)
+		}
+
+		reading.Extras.Containers = containers
+
+		return reading, exitCode, nil
+	}
+}
+
+// withMPSSharing extends a collection cycle with the MPS sharing state of
+// the listed processes. Like the container attribution it is the same for
+// every backend: the state lives in the processes, not in the driver.
+func withMPSSharing(query collect.QueryFunc, logger *slog.Logger) collect.QueryFunc {
+	return func(queryCtx context.Context) (collect.Reading, int, error) {
+		reading, exitCode, err := query(queryCtx)
+		if err != nil || !reading.AppsSuccess {
+			return reading, exitCode, err
+		}
+
+		reading.Extras.MPS = mps.Inspect(mps.DefaultProcRoot, tableUUIDs(reading.Table), reading.Apps, logger)
+
+		return reading, exitCode, nil
+	}
+}
+
+// serveMuxConfig carries the web flags and the driver event log into the
+// mux construction.
 type serveMuxConfig struct {
 	metricsPath   string
 	enablePprof   bool
 	maxRequests   int
 	timeoutOffset time.Duration
+	// events is nil when the backend watches no driver events.
+	events eventlog.Source
 }
 
 // newServeMux builds the HTTP mux: the landing page on exactly the root path
-// (anything unknown is a 404), the metrics endpoint, the health endpoints and
-// optionally pprof.
+// (anything unknown is a 404), the metrics endpoint, the health endpoints,
+// the driver event log when the backend keeps one, and optionally pprof.
 func newServeMux(
 	cfg serveMuxConfig,
 	registry *prometheus.Registry,
@@ -777,14 +1748,17 @@ func newServeMux(
 ) (*http.ServeMux, error) {
 	mux := http.NewServeMux()
 
+	links := []web.LandingLinks{{Address: cfg.metricsPath, Text: "Metrics"}}
+	if cfg.events != nil {
+		links = append(links, web.LandingLinks{Address: eventlog.Path, Text: "Driver events"})
+	}
+
 	landingPage, err := web.NewLandingPage(web.LandingConfig{
 		Name:        "Nvidia GPU Exporter",
 		Description: "Prometheus exporter for Nvidia GPUs, using nvidia-smi.",
 		Version:     version.Info(),
-		Links: []web.LandingLinks{
-			{Address: cfg.metricsPath, Text: "Metrics"},
-		},
-		Profiling: strconv.FormatBool(cfg.enablePprof),
+		Links:       links,
+		Profiling:   strconv.FormatBool(cfg.enablePprof),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the landing page: %w", err)
@@ -799,6 +1773,10 @@ func newServeMux(
 	mux.HandleFunc("GET /-/healthy", healthHandler("Healthy"))
 	mux.HandleFunc("GET /-/ready", healthHandler("Ready"))
 
+	if cfg.events != nil {
+		mux.Handle("GET "+eventlog.Path, eventlog.Handler(cfg.events))
+	}
+
 	if cfg.enablePprof {
 		logger.Info("pprof endpoints enabled")
 		registerPprof(mux)
@@ -1,13 +1,22 @@
 package app
 
 import (
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os/exec"
+	"slices"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
 )
 
 func TestValidateMetricsPath(t *testing.T) {
@@ -44,6 +53,8 @@ func TestValidateMetricsPath(t *testing.T) {
 		{path: "/-/ready", wantErr: true},
 		{path: "/debug/pprof", wantErr: true},
 		{path: "/debug/pprof/heap", wantErr: true},
+		{path: "/api/events", wantErr: true},
+		{path: "/api/metrics", wantErr: false},
 	}
 
 	for _, tt := range tests {
@@ -190,6 +201,233 @@ func TestValidateBackendFlags(t *testing.T) {
 			},
 			wantErr: "--collect.compute-apps-mig requires --collect.compute-apps",
 		},
+		{
+			name: "exec accepts compute apps types",
+			flags: backendFlagSet{
+				backend: backendExec, nvidiaSmiCommand: "nvidia-smi",
+				computeApps: true, computeAppsTypes: true,
+			},
+		},
+		{
+			name: "demo rejects compute apps types",
+			flags: backendFlagSet{
+				backend: backendDemo, nvidiaSmiCommand: "nvidia-smi",
+				computeApps: true, computeAppsTypes: true,
+			},
+			wantErr: "--collect.compute-apps-types requires --collect.backend=exec or nvml",
+		},
+		{
+			name: "compute apps types requires compute apps",
+			flags: backendFlagSet{
+				backend: backendNVML, nvidiaSmiCommand: "nvidia-smi", computeAppsTypes: true,
+			},
+			wantErr: "--collect.compute-apps-types requires --collect.compute-apps",
+		},
+		{
+			name: "nvml accepts compute apps utilization",
+			flags: backendFlagSet{
+				backend: backendNVML, nvidiaSmiCommand: "nvidia-smi",
+				computeApps: true, computeAppsUtil: true,
+			},
+		},
+		{
+			name: "demo rejects compute apps utilization",
+			flags: backendFlagSet{
+				backend: backendDemo, nvidiaSmiCommand: "nvidia-smi",
+				computeApps: true, computeAppsUtil: true,
+			},
+			wantErr: "--collect.compute-apps-utilization requires --collect.backend=nvml",
+		},
+		{
+			name: "compute apps utilization requires compute apps",
+			flags: backendFlagSet{
+				backend: backendNVML, nvidiaSmiCommand: "nvidia-smi", computeAppsUtil: true,
+			},
+			wantErr: "--collect.compute-apps-utilization requires --collect.compute-apps",
+		},
+		{
+			name:  "exec accepts accounting",
+			flags: backendFlagSet{backend: backendExec, nvidiaSmiCommand: "nvidia-smi", accounting: true},
+		},
+		{
+			name:  "nvml accepts accounting",
+			flags: backendFlagSet{backend: backendNVML, nvidiaSmiCommand: "nvidia-smi", accounting: true},
+		},
+		{
+			name:    "demo rejects accounting",
+			flags:   backendFlagSet{backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", accounting: true},
+			wantErr: "--collect.accounting requires --collect.backend=exec or nvml",
+		},
+		{
+			name:  "exec accepts an xid log",
+			flags: backendFlagSet{backend: backendExec, nvidiaSmiCommand: "nvidia-smi", xidLog: "/dev/kmsg"},
+		},
+		{
+			name:    "nvml rejects an xid log",
+			flags:   backendFlagSet{backend: backendNVML, nvidiaSmiCommand: "nvidia-smi", xidLog: "/dev/kmsg"},
+			wantErr: "--collect.xid-log requires --collect.backend=exec",
+		},
+		{
+			name:    "demo rejects an xid log",
+			flags:   backendFlagSet{backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", xidLog: "/dev/kmsg"},
+			wantErr: "--collect.xid-log requires --collect.backend=exec",
+		},
+		{
+			name:    "exec rejects video sessions",
+			flags:   backendFlagSet{backend: backendExec, nvidiaSmiCommand: "nvidia-smi", videoSessions: true},
+			wantErr: "--collect.video-sessions requires --collect.backend=nvml",
+		},
+		{
+			name:  "nvml accepts video sessions",
+			flags: backendFlagSet{backend: backendNVML, nvidiaSmiCommand: "nvidia-smi", videoSessions: true},
+		},
+		{
+			name:  "demo accepts video sessions as a no-op",
+			flags: backendFlagSet{backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", videoSessions: true},
+		},
+		{
+			name:    "exec rejects nvlink",
+			flags:   backendFlagSet{backend: backendExec, nvidiaSmiCommand: "nvidia-smi", nvlink: true},
+			wantErr: "--collect.nvlink requires --collect.backend=nvml",
+		},
+		{
+			name:  "nvml accepts nvlink",
+			flags: backendFlagSet{backend: backendNVML, nvidiaSmiCommand: "nvidia-smi", nvlink: true},
+		},
+		{
+			name:  "demo accepts nvlink as a no-op",
+			flags: backendFlagSet{backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", nvlink: true},
+		},
+		{
+			name:    "exec rejects gpm",
+			flags:   backendFlagSet{backend: backendExec, nvidiaSmiCommand: "nvidia-smi", gpm: true},
+			wantErr: "--collect.gpm requires --collect.backend=nvml",
+		},
+		{
+			name:  "nvml accepts gpm",
+			flags: backendFlagSet{backend: backendNVML, nvidiaSmiCommand: "nvidia-smi", gpm: true},
+		},
+		{
+			name:  "demo accepts gpm as a no-op",
+			flags: backendFlagSet{backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", gpm: true},
+		},
+		{
+			name:    "exec rejects driver samples",
+			flags:   backendFlagSet{backend: backendExec, nvidiaSmiCommand: "nvidia-smi", driverSamples: true},
+			wantErr: "--collect.driver-samples requires --collect.backend=nvml",
+		},
+		{
+			name:  "nvml accepts driver samples",
+			flags: backendFlagSet{backend: backendNVML, nvidiaSmiCommand: "nvidia-smi", driverSamples: true},
+		},
+		{
+			name:  "demo accepts driver samples as a no-op",
+			flags: backendFlagSet{backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", driverSamples: true},
+		},
+		{
+			name:    "exec rejects capabilities",
+			flags:   backendFlagSet{backend: backendExec, nvidiaSmiCommand: "nvidia-smi", capabilities: true},
+			wantErr: "--collect.capabilities requires --collect.backend=nvml",
+		},
+		{
+			name:  "nvml accepts capabilities",
+			flags: backendFlagSet{backend: backendNVML, nvidiaSmiCommand: "nvidia-smi", capabilities: true},
+		},
+		{
+			name:  "demo accepts capabilities as a no-op",
+			flags: backendFlagSet{backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", capabilities: true},
+		},
+		{
+			name:    "exec rejects fans",
+			flags:   backendFlagSet{backend: backendExec, nvidiaSmiCommand: "nvidia-smi", fans: true},
+			wantErr: "--collect.fans requires --collect.backend=nvml",
+		},
+		{
+			name:  "demo accepts fans as a no-op",
+			flags: backendFlagSet{backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", fans: true},
+		},
+		{
+			name:    "exec rejects confidential computing",
+			flags:   backendFlagSet{backend: backendExec, nvidiaSmiCommand: "nvidia-smi", confComputing: true},
+			wantErr: "--collect.confidential-computing requires --collect.backend=nvml",
+		},
+		{
+			name:  "demo accepts confidential computing as a no-op",
+			flags: backendFlagSet{backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", confComputing: true},
+		},
+		{
+			name:    "exec rejects vGPU",
+			flags:   backendFlagSet{backend: backendExec, nvidiaSmiCommand: "nvidia-smi", vgpu: true},
+			wantErr: "--collect.vgpu requires --collect.backend=nvml",
+		},
+		{
+			name:  "demo accepts vGPU as a no-op",
+			flags: backendFlagSet{backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", vgpu: true},
+		},
+		{
+			name:    "exec rejects superchip",
+			flags:   backendFlagSet{backend: backendExec, nvidiaSmiCommand: "nvidia-smi", superchip: true},
+			wantErr: "--collect.superchip requires --collect.backend=nvml",
+		},
+		{
+			name:  "demo accepts superchip as a no-op",
+			flags: backendFlagSet{backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", superchip: true},
+		},
+		{
+			name: "exec accepts docker attribution with a custom socket",
+			flags: backendFlagSet{
+				backend: backendExec, nvidiaSmiCommand: "nvidia-smi",
+				computeApps: true, docker: true, dockerSocket: "/run/user/1000/docker.sock",
+			},
+		},
+		{
+			name: "demo rejects docker attribution",
+			flags: backendFlagSet{
+				backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", computeApps: true, docker: true,
+			},
+			wantErr: "--collect.compute-apps-docker requires --collect.backend=exec or nvml",
+		},
+		{
+			name: "docker attribution requires compute apps",
+			flags: backendFlagSet{
+				backend: backendNVML, nvidiaSmiCommand: "nvidia-smi", docker: true,
+			},
+			wantErr: "--collect.compute-apps-docker requires --collect.compute-apps",
+		},
+		{
+			name: "nvml accepts mps with process types",
+			flags: backendFlagSet{
+				backend: backendNVML, nvidiaSmiCommand: "nvidia-smi",
+				computeApps: true, computeAppsTypes: true, computeAppsMPS: true,
+			},
+		},
+		{
+			name: "exec rejects mps without process types",
+			flags: backendFlagSet{
+				backend: backendExec, nvidiaSmiCommand: "nvidia-smi", computeApps: true, computeAppsMPS: true,
+			},
+			wantErr: "--collect.compute-apps-mps requires --collect.compute-apps-types",
+		},
+		{
+			name: "demo accepts mps without process types",
+			flags: backendFlagSet{
+				backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", computeApps: true, computeAppsMPS: true,
+			},
+		},
+		{
+			name: "mps requires compute apps",
+			flags: backendFlagSet{
+				backend: backendDemo, nvidiaSmiCommand: "nvidia-smi", computeAppsMPS: true,
+			},
+			wantErr: "--collect.compute-apps-mps requires --collect.compute-apps",
+		},
+		{
+			name: "docker socket requires docker attribution",
+			flags: backendFlagSet{
+				backend: backendExec, nvidiaSmiCommand: "nvidia-smi", dockerSocket: "/tmp/docker.sock",
+			},
+			wantErr: "--collect.docker-socket requires --collect.compute-apps-docker",
+		},
 	}
 
 	for _, testCase := range tests {
@@ -207,3 +445,178 @@ func TestValidateBackendFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildNotifyConfig(t *testing.T) {
+	t.Parallel()
+
+	defaults := notifyFlagSet{eventTypes: "xid,ecc_double_bit", dedupWindow: time.Minute, rateLimit: 30}
+
+	tests := []struct {
+		name    string
+		modify  func(flags *notifyFlagSet)
+		wantErr string
+	}{
+		{name: "disabled by default", modify: func(*notifyFlagSet) {}},
+		{
+			name: "webhook with filters",
+			modify: func(flags *notifyFlagSet) {
+				flags.urls, flags.xids = []string{"https://hooks.example.com/x"}, "79"
+			},
+		},
+		{
+			name:    "filters require a webhook",
+			modify:  func(flags *notifyFlagSet) { flags.xids = "79" },
+			wantErr: "require --notify.webhook-url",
+		},
+		{
+			name:    "webhook must be http",
+			modify:  func(flags *notifyFlagSet) { flags.urls = []string{"hooks.example.com/x"} },
+			wantErr: "invalid webhook URL",
+		},
+		{
+			name: "unknown event type",
+			modify: func(flags *notifyFlagSet) {
+				flags.urls, flags.eventTypes = []string{"https://hooks.example.com/x"}, "xid,bus_error"
+			},
+			wantErr: "invalid --notify.event-types",
+		},
+		{
+			name: "negative rate limit",
+			modify: func(flags *notifyFlagSet) {
+				flags.urls, flags.rateLimit = []string{"https://hooks.example.com/x"}, -1
+			},
+			wantErr: "notify.rate-limit must not be negative",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			flags := defaults
+			testCase.modify(&flags)
+
+			_, err := buildNotifyConfig(flags)
+			if testCase.wantErr == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.ErrorContains(t, err, testCase.wantErr)
+		})
+	}
+}
+
+func TestAccountingUUIDs(t *testing.T) {
+	t.Parallel()
+
+	table := &nvidiasmi.Table{}
+	for uuid, mode := range map[string]string{"GPU-aaa": "Enabled", "GPU-bbb": "Disabled", "GPU-ccc": "[N/A]"} {
+		table.Rows = append(table.Rows, nvidiasmi.Row{QFieldToCells: map[nvidiasmi.QField]nvidiasmi.Cell{
+			nvidiasmi.UUIDQField:           {QField: nvidiasmi.UUIDQField, RawValue: uuid},
+			nvidiasmi.AccountingModeQField: {QField: nvidiasmi.AccountingModeQField, RawValue: mode},
+		}})
+	}
+
+	assert.Equal(t, []string{"aaa"}, accountingUUIDs(table), "a GPU keeping no buffer is not counted as read")
+}
+
+// topologyTable is a table of the given uuids, indexed in order.
+func topologyTable(uuids ...string) *nvidiasmi.Table {
+	table := &nvidiasmi.Table{}
+	for index, uuid := range uuids {
+		table.Rows = append(table.Rows, nvidiasmi.Row{QFieldToCells: map[nvidiasmi.QField]nvidiasmi.Cell{
+			nvidiasmi.UUIDQField:  {QField: nvidiasmi.UUIDQField, RawValue: uuid},
+			nvidiasmi.IndexQField: {QField: nvidiasmi.IndexQField, RawValue: strconv.Itoa(index)},
+		}})
+	}
+
+	return table
+}
+
+// topologyRunFunc answers topo -m with two GPUs on NUMA nodes 0 and 1.
+func topologyRunFunc(cmd *exec.Cmd) error {
+	_, err := io.WriteString(cmd.Stdout, "\tGPU0\tGPU1\tCPU Affinity\tNUMA Affinity\n"+
+		"GPU0\t X \tNV4\t0-7\t0\nGPU1\tNV4\t X \t8-15\t1\n")
+
+	return err
+}
+
+func TestTopologyCacheAttributesByIndex(t *testing.T) {
+	t.Parallel()
+
+	table := topologyTable("GPU-aaa", "GPU-bbb")
+	slices.Reverse(table.Rows)
+
+	var cache topologyCache
+
+	cfg := collectConfig{nvidiaSmiCommand: "nvidia-smi"}
+
+	topology := cache.query(t.Context(), cfg, table, topologyRunFunc, slog.New(slog.DiscardHandler))
+	assert.ElementsMatch(t, []collect.NUMAAffinity{
+		{UUID: "aaa", Node: 0, CPUAffinity: "0-7"},
+		{UUID: "bbb", Node: 1, CPUAffinity: "8-15"},
+	}, topology.NUMA, "the row order does not name the GPUs")
+}
+
+func TestTopologyCacheConcurrentQueries(t *testing.T) {
+	t.Parallel()
+
+	table := topologyTable("GPU-aaa", "GPU-bbb")
+	runFunc := topologyRunFunc
+
+	var (
+		cache topologyCache
+		wg    sync.WaitGroup
+	)
+
+	cfg := collectConfig{nvidiaSmiCommand: "nvidia-smi"}
+	logger := slog.New(slog.DiscardHandler)
+
+	// a fresh collection may overlap an abandoned one; run under -race
+	for range 8 {
+		wg.Go(func() {
+			topology := cache.query(t.Context(), cfg, table, runFunc, logger)
+			assert.Len(t, topology.Links, 2)
+		})
+	}
+
+	wg.Wait()
+
+	assert.Len(t, cache.query(t.Context(), cfg, table, runFunc, logger).NUMA, 2, "the cached read serves")
+}
+
+func TestBuildQueryFuncSharesTheFullReport(t *testing.T) {
+	t.Parallel()
+
+	const report = `<nvidia_smi_log><gpu id="00000000:19:00.0">` +
+		"<pci><replay_counter>9</replay_counter><replay_rollover_counter>0</replay_rollover_counter></pci>" +
+		"<processes><process_info><pid>42</pid><type>C</type><process_name>python</process_name>" +
+		"<used_memory>3 MiB</used_memory></process_info></processes></gpu></nvidia_smi_log>"
+
+	reports := 0
+	runFunc := func(cmd *exec.Cmd) error {
+		output := "uuid, pci.bus_id\nGPU-aaa, 00000000:19:00.0\n"
+		if slices.Equal(cmd.Args[1:], []string{"-q", "-x"}) {
+			reports++
+			output = report
+		}
+
+		_, err := io.WriteString(cmd.Stdout, output)
+
+		return err
+	}
+
+	cfg := collectConfig{nvidiaSmiCommand: "nvidia-smi", computeApps: true, computeAppsTypes: true, pcieErrors: true}
+	resolved := nvidiasmi.ResolvedFields{Query: []nvidiasmi.QField{nvidiasmi.UUIDQField, nvidiasmi.PCIBusIDQField}}
+
+	reading, _, err := buildQueryFunc(cfg, resolved, "", runFunc, slog.New(slog.DiscardHandler))(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, reports, "one report serves both families")
+	require.Len(t, reading.Apps, 1)
+	assert.Equal(t, "aaa", reading.Apps[0].GPUUUID)
+	require.Len(t, reading.Extras.PCIeErrors, 1)
+	assert.Equal(t, "aaa", reading.Extras.PCIeErrors[0].UUID)
+}
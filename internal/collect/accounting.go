@@ -0,0 +1,112 @@
+package collect
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+// AccountingCounter is one GPU's cumulative totals over the processes that
+// completed on it while the exporter was watching its accounting buffer.
+type AccountingCounter struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	// Completed counts the completed processes.
+	Completed uint64
+	// GPUSeconds sums how long the completed processes held their context.
+	GPUSeconds float64
+	// PeakMemoryBytes is the largest peak memory use of any of them, 0 until
+	// one reports it.
+	PeakMemoryBytes uint64
+}
+
+// accountingKey identifies one buffer entry. nvidia-smi exposes no process
+// start time, so a completed entry is told apart from a later process that
+// reused its pid by its duration, which is fixed once the process exits.
+type accountingKey struct {
+	pid      string
+	duration time.Duration
+}
+
+// accountingGPU is the tracker's state for one GPU.
+type accountingGPU struct {
+	seen   map[accountingKey]bool
+	totals AccountingCounter
+}
+
+// AccountingTracker folds successive reads of the driver's accounting buffers
+// into cumulative per-GPU counters. A buffer is a circular list that still
+// holds every entry counted on earlier cycles, so each completed entry is
+// counted once, on the first cycle it is seen completed. The first read of a
+// GPU only records what its buffer already holds: those processes completed
+// before the exporter was watching, and counting them would make the counters
+// jump at every exporter restart. The zero value is ready to use; it is safe
+// for concurrent use.
+type AccountingTracker struct {
+	mu   sync.Mutex
+	gpus map[string]*accountingGPU
+}
+
+// Observe folds one read of the accounting buffers and returns the totals of
+// the GPUs in gpus, in the order given. gpus lists the GPUs whose buffer was
+// read in full on this cycle (an empty buffer included); entries of any other
+// GPU are ignored, and the state of GPUs missing from a read is kept, so a
+// transiently unreadable GPU neither loses its totals nor recounts its buffer
+// on return.
+func (t *AccountingTracker) Observe(gpus []string, apps []nvidiasmi.AccountedApp) []AccountingCounter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.gpus == nil {
+		t.gpus = map[string]*accountingGPU{}
+	}
+
+	completed := map[string]map[accountingKey]nvidiasmi.AccountedApp{}
+
+	for _, app := range apps {
+		if app.Running || !slices.Contains(gpus, app.GPUUUID) {
+			continue
+		}
+
+		if completed[app.GPUUUID] == nil {
+			completed[app.GPUUUID] = map[accountingKey]nvidiasmi.AccountedApp{}
+		}
+
+		completed[app.GPUUUID][accountingKey{pid: app.PID, duration: app.Duration}] = app
+	}
+
+	counters := make([]AccountingCounter, 0, len(gpus))
+
+	for _, uuid := range gpus {
+		entries := completed[uuid]
+
+		state, known := t.gpus[uuid]
+		if !known {
+			state = &accountingGPU{totals: AccountingCounter{UUID: uuid}}
+			t.gpus[uuid] = state
+		}
+
+		for key, app := range entries {
+			if !known || state.seen[key] {
+				continue
+			}
+
+			state.totals.Completed++
+			state.totals.GPUSeconds += app.Duration.Seconds()
+			state.totals.PeakMemoryBytes = max(state.totals.PeakMemoryBytes, app.MaxMemoryBytes)
+		}
+
+		// entries the buffer has overwritten can never come back, so the seen
+		// set is replaced rather than grown
+		state.seen = make(map[accountingKey]bool, len(entries))
+		for key := range entries {
+			state.seen[key] = true
+		}
+
+		counters = append(counters, state.totals)
+	}
+
+	return counters
+}
@@ -0,0 +1,75 @@
+package collect_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+func accounted(uuid, pid string, seconds int, memory uint64) nvidiasmi.AccountedApp {
+	return nvidiasmi.AccountedApp{
+		GPUUUID:        uuid,
+		PID:            pid,
+		MaxMemoryBytes: memory,
+		Duration:       time.Duration(seconds) * time.Second,
+		Running:        seconds == 0,
+	}
+}
+
+func TestAccountingTrackerCountsEachCompletedProcessOnce(t *testing.T) {
+	t.Parallel()
+
+	var tracker collect.AccountingTracker
+
+	gpus := []string{"gpu-a", "gpu-b"}
+
+	// the first read only records history from before the exporter started
+	first := tracker.Observe(gpus, []nvidiasmi.AccountedApp{
+		accounted("gpu-a", "100", 30, 1<<30),
+		accounted("gpu-a", "101", 0, 1<<20),
+	})
+	assert.Equal(t, []collect.AccountingCounter{{UUID: "gpu-a"}, {UUID: "gpu-b"}}, first)
+
+	second := tracker.Observe(gpus, []nvidiasmi.AccountedApp{
+		accounted("gpu-a", "100", 30, 1<<30),
+		accounted("gpu-a", "101", 12, 2<<20), // the running one finished
+		accounted("gpu-a", "102", 3, 4<<20),  // started and finished between reads
+		accounted("gpu-b", "200", 0, 8<<20),
+	})
+	assert.Equal(t, []collect.AccountingCounter{
+		{UUID: "gpu-a", Completed: 2, GPUSeconds: 15, PeakMemoryBytes: 4 << 20},
+		{UUID: "gpu-b"},
+	}, second)
+
+	// a reused pid with a different duration is a new process; entries the
+	// buffer dropped are forgotten without affecting the totals
+	third := tracker.Observe(gpus, []nvidiasmi.AccountedApp{
+		accounted("gpu-a", "102", 3, 4<<20),
+		accounted("gpu-a", "101", 5, 1<<20),
+	})
+	assert.Equal(t, collect.AccountingCounter{
+		UUID: "gpu-a", Completed: 3, GPUSeconds: 20, PeakMemoryBytes: 4 << 20,
+	}, third[0])
+}
+
+func TestAccountingTrackerKeepsStateOfUnreadGPUs(t *testing.T) {
+	t.Parallel()
+
+	var tracker collect.AccountingTracker
+
+	buffer := []nvidiasmi.AccountedApp{accounted("gpu-a", "100", 30, 0)}
+
+	tracker.Observe([]string{"gpu-a"}, nil)
+	tracker.Observe([]string{"gpu-a"}, buffer)
+
+	// gpu-a could not be read: it is absent, and its entries are ignored
+	assert.Empty(t, tracker.Observe(nil, buffer))
+
+	// on return its buffer is not counted again
+	assert.Equal(t, []collect.AccountingCounter{{UUID: "gpu-a", Completed: 1, GPUSeconds: 30}},
+		tracker.Observe([]string{"gpu-a"}, buffer))
+}
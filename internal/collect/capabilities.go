@@ -0,0 +1,57 @@
+package collect
+
+// The clock domains of the capability clocks, the clock label values of
+// GPUCapabilities.MaxClocks and SupportedClocks.
+const (
+	CapabilityClockGraphics = "graphics"
+	CapabilityClockSM       = "sm"
+	CapabilityClockMemory   = "memory"
+	CapabilityClockVideo    = "video"
+)
+
+// GPUCapabilities is one GPU's static description, for capacity planning.
+// None of it changes while the driver stays loaded. Strings the driver
+// cannot report are empty and numbers it cannot report nil or zero, as
+// noted per field.
+type GPUCapabilities struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	// Architecture and Brand are spelled the way nvidia-smi -q prints its
+	// Product Architecture and Product Brand lines, e.g. "Hopper" and
+	// "NVIDIA".
+	Architecture    string
+	Brand           string
+	BoardPartNumber string
+	// PCIeMaxGeneration and PCIeMaxWidth are the most the GPU supports,
+	// whatever the slot negotiated; zero when unknown.
+	PCIeMaxGeneration int
+	PCIeMaxWidth      int
+	// Cores is the GPU's core count and MemoryBusWidthBits its memory bus
+	// width; nil when unknown.
+	Cores              *float64
+	MemoryBusWidthBits *float64
+	// MaxClocks holds the maximum clock of each domain the GPU reports.
+	MaxClocks []CapabilityClock
+	// SupportedClocks holds the clock range of each domain per performance
+	// state, the GPU's supported clock table.
+	SupportedClocks []SupportedClockRange
+}
+
+// CapabilityClock is one clock domain's maximum clock.
+type CapabilityClock struct {
+	// Clock is one of the CapabilityClock* constants.
+	Clock string
+	Hz    float64
+}
+
+// SupportedClockRange is the range one clock domain runs at in one
+// performance state.
+type SupportedClockRange struct {
+	// PState is the performance state as nvidia-smi spells it, "P0" to
+	// "P15".
+	PState string
+	// Clock is one of the CapabilityClock* constants.
+	Clock string
+	MinHz float64
+	MaxHz float64
+}
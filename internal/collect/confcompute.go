@@ -0,0 +1,58 @@
+package collect
+
+// The confidential computing modes, the mode label values of the
+// confidential computing families.
+const (
+	// CCModeOff is a system running without confidential computing.
+	CCModeOff = "off"
+	// CCModeOn is a system running with confidential computing enforced.
+	CCModeOn = "on"
+	// CCModeDevtools is confidential computing with the developer tools
+	// mode on: the workload is isolated but debuggable, so not attestable
+	// as production.
+	CCModeDevtools = "devtools"
+)
+
+// ConfidentialComputing is the system's confidential computing state. The
+// driver reports the mode and the ready state for the whole system, not per
+// GPU; the per-GPU entries repeat the mode so it joins with the per-GPU
+// series.
+type ConfidentialComputing struct {
+	// CPUCapability is the CPU's confidential computing technology (none,
+	// amd_sev, amd_sev_snp, amd_snp_vtom or intel_tdx), and GPUCapability
+	// whether the GPUs support it (capable or not_capable). Both are empty
+	// when unknown.
+	CPUCapability string
+	GPUCapability string
+	// Mode is one of the CCMode constants, and Environment the driver's
+	// environment (prod, sim or unavailable). Both are empty when unknown.
+	Mode        string
+	Environment string
+	// MultiGPUMode is how GPUs exchange protected data (none,
+	// protected_pcie or nvle), empty when unknown.
+	MultiGPUMode string
+	// Ready is 1 while the GPUs accept confidential client requests, the
+	// switch flipped once attestation succeeded, and 0 before; nil when
+	// unknown.
+	Ready *float64
+	// KeyRotationAttackerAdvantage is the attacker advantage, in log2 of
+	// the encrypted data volume, past which the driver rotates the keys;
+	// nil when unknown.
+	KeyRotationAttackerAdvantage *float64
+	// GPUs holds the per-GPU readings.
+	GPUs []ConfidentialComputingGPU
+}
+
+// ConfidentialComputingGPU is one GPU's confidential computing readings.
+type ConfidentialComputingGPU struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	// Mode is the system's mode, one of the CCMode constants, empty when
+	// unknown.
+	Mode string
+	// ProtectedMemoryBytes and UnprotectedMemoryBytes split the GPU's
+	// framebuffer into the protected region and the rest; nil when the GPU
+	// cannot report them.
+	ProtectedMemoryBytes   *float64
+	UnprotectedMemoryBytes *float64
+}
@@ -19,10 +19,81 @@ type Extras struct {
 	// Energy holds per-GPU cumulative energy counters. The nvml and demo
 	// backends fill it; devices that cannot report it are absent.
 	Energy []EnergyCounter
+	// TemperatureThresholds holds the per-GPU temperature thresholds. The
+	// exec and nvml backends fill it under --collect.temperature-thresholds;
+	// the demo backend always fills it.
+	TemperatureThresholds []TemperatureThreshold
+	// PCIeErrors holds the per-GPU PCIe replay and AER error counters. The
+	// exec and nvml backends fill it under --collect.pcie-errors; the demo
+	// backend always fills it.
+	PCIeErrors []PCIeErrorCounters
+	// Fans holds the per-fan readings of GPUs with their own fans. The nvml
+	// backend fills it under --collect.fans; the demo backend synthesizes
+	// its configured fans.
+	Fans []Fan
+	// DriverSamples summarizes the driver's buffered power, utilization and
+	// clock samples per GPU and kind, over the window since the previous
+	// collection. The nvml backend fills it under --collect.driver-samples;
+	// the demo backend always fills it.
+	DriverSamples []DriverSampleWindow
+	// PowerHistograms holds per-GPU cumulative distributions of the same
+	// power samples, filled alongside DriverSamples.
+	PowerHistograms []NativeHistogram
+	// Capabilities holds each GPU's static capability description. The nvml
+	// backend fills it under --collect.capabilities, reading a GPU once per
+	// NVML generation; the demo backend always fills it.
+	Capabilities []GPUCapabilities
+	// Topology describes the GPU interconnect and NUMA affinity. The exec
+	// and nvml backends fill it under --collect.topology, reading it again
+	// only when the device set changes; the demo backend always fills it.
+	Topology Topology
+	// ConfidentialComputing describes the system's confidential computing
+	// state and the GPUs' protected memory. The nvml backend fills it under
+	// --collect.confidential-computing; the demo backend always fills it.
+	ConfidentialComputing ConfidentialComputing
+	// VGPUs holds the active vGPU instances of hypervisor GPUs. The nvml
+	// backend fills it under --collect.vgpu; the demo backend synthesizes its
+	// configured instances.
+	VGPUs []VGPUHost
+	// Superchips holds the C2C link, module energy and memory placement
+	// readings of superchip GPUs. The nvml backend fills it under
+	// --collect.superchip; the demo backend synthesizes its configured
+	// superchips.
+	Superchips []Superchip
 	// MIG holds per-MIG-instance readings. The nvml backend fills it for
 	// GPUs with MIG mode enabled; the demo backend synthesizes its
 	// configured topology.
 	MIG []MIGInstance
+	// MIGProfiles holds the GPU instance capacity of each MIG profile on
+	// GPUs in MIG mode, filled beside MIG.
+	MIGProfiles []MIGProfileCapacity
+	// GPM holds the whole-GPU profiling readings of GPUs outside MIG mode.
+	// The nvml backend fills it under --collect.gpm for GPUs with GPM
+	// support (Hopper and later); the demo backend always fills it.
+	GPM []GPMProfile
+	// ProcessUtilization holds per-process engine utilization. The nvml
+	// backend fills it under --collect.compute-apps-utilization.
+	ProcessUtilization []ProcessUtilization
+	// Accounting holds per-GPU totals of completed processes, folded by an
+	// AccountingTracker. The exec and nvml backends fill it under
+	// --collect.accounting.
+	Accounting []AccountingCounter
+	// VideoSessions holds per-GPU active encoder and NvFBC sessions. The
+	// nvml backend fills it under --collect.video-sessions; the demo
+	// backend always fills it.
+	VideoSessions []VideoSessions
+	// NVLink holds the per-link state and counters of GPUs with NVLink. The
+	// nvml backend fills it under --collect.nvlink; the demo backend
+	// synthesizes its configured links.
+	NVLink []NVLink
+	// Containers attributes per-process entries to the Docker containers
+	// running them. Filled beside the exec and nvml backends under
+	// --collect.compute-apps-docker; processes outside Docker are absent.
+	Containers []ProcessContainer
+	// MPS describes the MPS daemons and the limits of their clients. Filled
+	// beside the exec and nvml backends under --collect.compute-apps-mps;
+	// the demo backend synthesizes its configured topology.
+	MPS MPSSharing
 }
 
 // PCIeThroughput is one GPU's sampled PCIe throughput.
@@ -44,6 +115,61 @@ type EnergyCounter struct {
 	Joules float64
 }
 
+// TemperatureThreshold is one temperature threshold of a GPU.
+type TemperatureThreshold struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	// Kind is one of the nvidiasmi.TemperatureThreshold* constants.
+	Kind    string
+	Celsius float64
+	// TLimit marks a threshold relative to the GPU's T.Limit temperature,
+	// as datacenter GPUs report them through nvidia-smi, rather than an
+	// absolute one.
+	TLimit bool
+}
+
+// PCIeErrorCounters is one GPU's PCIe link error counters. They count since
+// the driver was loaded; a nil counter is one the GPU or backend cannot
+// report.
+type PCIeErrorCounters struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	// Replays counts the link-level retransmissions, and ReplayRollovers how
+	// many times the replay sequence number wrapped around.
+	Replays         *float64
+	ReplayRollovers *float64
+	// CorrectableErrors, UncorrectableErrors and FatalErrors are the
+	// Advanced Error Reporting counts by severity; UncorrectableErrors holds
+	// the non-fatal ones.
+	CorrectableErrors   *float64
+	UncorrectableErrors *float64
+	FatalErrors         *float64
+}
+
+// The fan control policies, the policy label values of the fan control
+// policy family.
+const (
+	// FanPolicyAuto is the driver's temperature-driven control.
+	FanPolicyAuto = "auto"
+	// FanPolicyManual is a speed set by hand (nvidia-settings, nvml).
+	FanPolicyManual = "manual"
+)
+
+// Fan is one fan of a GPU.
+type Fan struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	// Fan is the fan index on the GPU.
+	Fan string
+	// SpeedRatio is the speed the driver reports for the fan, as a share of
+	// its maximum, and TargetSpeedRatio the speed its controller asks for;
+	// nil when the fan cannot report it.
+	SpeedRatio       *float64
+	TargetSpeedRatio *float64
+	// Policy is one of the FanPolicy constants, empty when unknown.
+	Policy string
+}
+
 // XIDCounter is one (GPU, XID code) pair's cumulative error-event count.
 // XID state deliberately does NOT ride Extras: it is owned by a long-lived
 // watcher and read at scrape time, so the counters stay visible during the
@@ -54,13 +180,39 @@ type XIDCounter struct {
 	UUID string
 	// XID is the numeric XID error code.
 	XID uint64
-	// Count is the number of events observed since the exporter started.
+	// Count is the number of events observed since the exporter started,
+	// or since the driver generation began when the state is persisted.
 	Count uint64
 	// LastSeen is when the most recent event was received by the exporter
 	// (NVML events carry no timestamp of their own).
 	LastSeen time.Time
 }
 
+// The driver event types, the type label values of the driver event
+// counters.
+const (
+	DriverEventXID               = "xid"
+	DriverEventECCSingleBit      = "ecc_single_bit"
+	DriverEventECCDoubleBit      = "ecc_double_bit"
+	DriverEventClockChange       = "clock_change"
+	DriverEventPowerSourceChange = "power_source_change"
+	DriverEventMIGConfigChange   = "mig_config_change"
+)
+
+// DriverEventCounter is one (GPU, event type) pair's cumulative count of
+// driver events. Like XIDCounter it is owned by the event watcher, not
+// Extras; XID events count here too, beside their per-code counters.
+type DriverEventCounter struct {
+	// UUID is the GPU uuid, normalized like every uuid label, cached at
+	// event registration time.
+	UUID string
+	// Type is one of the DriverEvent* constants.
+	Type string
+	// Count is the number of events observed since the exporter started,
+	// or since the driver generation began when the state is persisted.
+	Count uint64
+}
+
 // MIGInstance is one MIG device: a compute instance inside a GPU instance of
 // a MIG-partitioned GPU.
 type MIGInstance struct {
@@ -111,3 +263,194 @@ type MIGUtilization struct {
 	PCIeTXBytesPerSecond *float64
 	PCIeRXBytesPerSecond *float64
 }
+
+// MIGProfileCapacity is how many GPU instances of one MIG profile a GPU in
+// MIG mode can host, and where.
+type MIGProfileCapacity struct {
+	// ParentUUID is the GPU uuid, normalized like every uuid label.
+	ParentUUID string
+	// Profile is the GPU instance profile named the way nvidia-smi names
+	// it, for example "1g.10gb" or "1g.10gb+me", so it matches
+	// MIGInstance.Profile.
+	Profile string
+	// Total is how many instances of the profile the empty GPU can host.
+	Total int
+	// Remaining is how many more it can host beside the existing GPU
+	// instances, nil when the driver cannot report it.
+	Remaining *int
+	// Placements lists the memory slice ranges an instance of the profile
+	// can occupy, whether or not they are free.
+	Placements []MIGPlacement
+}
+
+// MIGPlacement is one memory slice range a GPU instance can be created at.
+type MIGPlacement struct {
+	Start int
+	Size  int
+}
+
+// GPMProfile is one whole GPU's profiling readings over the window between
+// the two most recent collections, from the driver's GPU performance
+// monitoring. A GPU is absent on the first cycle it is seen; each value is nil
+// when that particular metric could not be read.
+type GPMProfile struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	// SMActivityRatio, SMOccupancyRatio, TensorActivityRatio and the FP*
+	// pipe ratios are fractions of the window, 0 to 1. DRAMActivityRatio is
+	// the fraction of the peak memory bandwidth used.
+	SMActivityRatio     *float64
+	SMOccupancyRatio    *float64
+	TensorActivityRatio *float64
+	DRAMActivityRatio   *float64
+	FP64ActivityRatio   *float64
+	FP32ActivityRatio   *float64
+	FP16ActivityRatio   *float64
+	// The traffic rates are the GPU's total over all of its NVLink links and
+	// over PCIe.
+	NVLinkTXBytesPerSecond *float64
+	NVLinkRXBytesPerSecond *float64
+	PCIeTXBytesPerSecond   *float64
+	PCIeRXBytesPerSecond   *float64
+}
+
+// ProcessUtilization is one process's share of a GPU's engines, averaged over
+// the driver samples taken between the two most recent collections. A process
+// that was not sampled in that window (idle, or gone) is absent.
+type ProcessUtilization struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	// PID and ProcessName identify the process the same way the per-process
+	// query does, so the series join with compute_app_info.
+	PID         string
+	ProcessName string
+	// SMRatio, MemoryRatio, EncoderRatio and DecoderRatio are fractions of
+	// the sampling period, 0 to 1. MemoryRatio is memory-controller
+	// activity, not memory occupancy.
+	SMRatio      float64
+	MemoryRatio  float64
+	EncoderRatio float64
+	DecoderRatio float64
+}
+
+// The video session kinds.
+const (
+	VideoSessionEncoder = "encoder"
+	VideoSessionFBC     = "fbc"
+)
+
+// VideoSessions is one GPU's active sessions of one kind. An entry with no
+// sessions means the GPU was read and had none; a GPU or kind that could not
+// be read has no entry.
+type VideoSessions struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	// Type is one of the VideoSession kinds.
+	Type     string
+	Sessions []VideoSession
+}
+
+// VideoSession is one active encoder or NvFBC capture session.
+type VideoSession struct {
+	// ID is the driver's session id, unique per GPU. It is carried as a
+	// string because it is only ever a label.
+	ID  string
+	PID string
+	// Codec is the encoder codec ("h264", "hevc", "av1" or "unknown"),
+	// empty for NvFBC sessions, which capture rather than encode.
+	Codec string
+	// Resolution is the session's current resolution, for example
+	// "1920x1080".
+	Resolution string
+	// AverageFPS and AverageLatencySeconds are the driver's moving averages
+	// for the session.
+	AverageFPS            float64
+	AverageLatencySeconds float64
+}
+
+// NVLink device types a link's remote end can be.
+const (
+	NVLinkRemoteGPU     = "gpu"
+	NVLinkRemoteSwitch  = "switch"
+	NVLinkRemoteIBMNPU  = "ibmnpu"
+	NVLinkRemoteUnknown = "unknown"
+)
+
+// NVLink is one NVLink link of a GPU. A link that is down carries only its
+// state: the driver reports neither its peer nor its counters. Every other
+// reading is nil or empty when the driver cannot report it.
+type NVLink struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	// Link is the link index on the GPU.
+	Link   string
+	Active bool
+	// Version is the NVLink version the link runs, 0 when unknown.
+	Version uint32
+	// RemoteBusID is the PCI bus id of the device at the link's far end, in
+	// the form gpu_info's pci_bus_id label carries.
+	RemoteBusID string
+	// RemoteDeviceType is one of the NVLinkRemote kinds, empty when unknown.
+	RemoteDeviceType string
+	// TXBytes and RXBytes count the data payload sent and received over the
+	// link, protocol overhead excluded.
+	TXBytes *float64
+	RXBytes *float64
+	// CRCFlitErrors, CRCDataErrors, ReplayErrors and RecoveryErrors are the
+	// link's data-link error counters. They count since the driver was
+	// loaded.
+	CRCFlitErrors  *float64
+	CRCDataErrors  *float64
+	ReplayErrors   *float64
+	RecoveryErrors *float64
+}
+
+// ProcessContainer ties one process on one GPU to the Docker container
+// running it.
+type ProcessContainer struct {
+	// UUID and PID identify the process the same way the per-process query
+	// does, so the series join with compute_app_info.
+	UUID string
+	PID  string
+	// ContainerID is the full 64-character container id.
+	ContainerID string
+	// Name is the container name, without the API's leading slash.
+	Name string
+	// Image is the image reference the container was created from.
+	Image string
+	// ComposeProject is the Docker Compose project, empty outside Compose.
+	ComposeProject string
+}
+
+// MPSSharing is the MPS (Multi-Process Service) state of one collection: the
+// daemons sharing the GPUs and the limits their clients run under.
+type MPSSharing struct {
+	// ControlDaemonPIDs lists the running MPS control daemons, normally one
+	// per node (one per user in multi-user setups).
+	ControlDaemonPIDs []string
+	// Servers lists the MPS server on each GPU it holds a context on.
+	Servers []MPSServer
+	// Clients lists the MPS clients with the limits they run under.
+	Clients []MPSClient
+}
+
+// MPSServer is one MPS server process on one GPU.
+type MPSServer struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	PID  string
+}
+
+// MPSClient is one MPS client process on one GPU.
+type MPSClient struct {
+	// UUID and PID identify the process the same way the per-process query
+	// does, so the series join with compute_app_info.
+	UUID string
+	PID  string
+	// ActiveThreadRatio is the fraction of the GPU's threads the client may
+	// use, 0 to 1, nil when no limit applies.
+	ActiveThreadRatio *float64
+	// PinnedMemoryLimitBytes is the device memory the client may pin on this
+	// GPU, nil when no limit applies.
+	PinnedMemoryLimitBytes *uint64
+}
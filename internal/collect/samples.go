@@ -0,0 +1,123 @@
+package collect
+
+import (
+	"maps"
+	"math"
+	"sort"
+	"time"
+)
+
+// The kinds of buffered driver samples, the kind label values of
+// DriverSampleWindow.
+const (
+	DriverSamplePower             = "power"
+	DriverSampleGPUUtilization    = "gpu_utilization"
+	DriverSampleMemoryUtilization = "memory_utilization"
+	DriverSampleGraphicsClock     = "graphics_clock"
+	DriverSampleMemoryClock       = "memory_clock"
+)
+
+// DriverSampleWindow summarizes one GPU's buffered driver samples of one kind,
+// taken between the previous collection and this one.
+type DriverSampleWindow struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	// Kind is one of the DriverSample* constants.
+	Kind string
+	// Min, Max and Avg are in base units: watts, a 0-1 ratio, or hertz.
+	Min float64
+	Max float64
+	Avg float64
+	// Samples is how many driver samples the window held, at least one.
+	Samples int
+}
+
+// NewDriverSampleWindow summarizes a non-empty window of sample values.
+func NewDriverSampleWindow(uuid, kind string, values []float64) DriverSampleWindow {
+	window := DriverSampleWindow{
+		UUID:    uuid,
+		Kind:    kind,
+		Min:     math.Inf(1),
+		Max:     math.Inf(-1),
+		Samples: len(values),
+	}
+
+	var sum float64
+
+	for _, value := range values {
+		window.Min = min(window.Min, value)
+		window.Max = max(window.Max, value)
+		sum += value
+	}
+
+	window.Avg = sum / float64(len(values))
+
+	return window
+}
+
+// NativeHistogramSchema is the resolution of every NativeHistogram: each
+// bucket is 2^(1/8) times as wide as the previous one, about 9%.
+const NativeHistogramSchema = 3
+
+// nativeHistogramBounds are the upper bounds of the buckets within one power
+// of two, as fractions in [0.5, 1) the way math.Frexp reports them.
+//
+//nolint:gochecknoglobals // lookup table
+var nativeHistogramBounds = func() []float64 {
+	const perOctave = 1 << NativeHistogramSchema
+
+	bounds := make([]float64, perOctave)
+	for i := range bounds {
+		bounds[i] = math.Exp2(float64(i)/perOctave - 1)
+	}
+
+	return bounds
+}()
+
+// NativeHistogram is one GPU's cumulative distribution of sample values,
+// bucketed the way Prometheus native histograms are, so it renders without
+// predefined bucket boundaries. Values at or below zero count in the zero
+// bucket. The zero value is ready to use; it is not safe for concurrent use.
+type NativeHistogram struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID      string
+	Count     uint64
+	Sum       float64
+	ZeroCount uint64
+	// Buckets maps a bucket index under NativeHistogramSchema to its
+	// observation count.
+	Buckets map[int]int64
+	// Created is when the first observation was made.
+	Created time.Time
+}
+
+// Observe adds one value.
+func (h *NativeHistogram) Observe(value float64, now time.Time) {
+	if h.Created.IsZero() {
+		h.Created = now
+	}
+
+	h.Count++
+	h.Sum += value
+
+	if value <= 0 {
+		h.ZeroCount++
+
+		return
+	}
+
+	if h.Buckets == nil {
+		h.Buckets = map[int]int64{}
+	}
+
+	frac, exp := math.Frexp(value)
+	h.Buckets[sort.SearchFloat64s(nativeHistogramBounds, frac)+(exp-1)*len(nativeHistogramBounds)]++
+}
+
+// Clone returns a copy sharing no state, for handing to a snapshot.
+func (h *NativeHistogram) Clone() NativeHistogram {
+	clone := *h
+	clone.Buckets = maps.Clone(h.Buckets)
+
+	return clone
+}
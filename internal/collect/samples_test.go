@@ -0,0 +1,88 @@
+package collect_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+func TestNewDriverSampleWindow(t *testing.T) {
+	t.Parallel()
+
+	window := collect.NewDriverSampleWindow("gpu-a", collect.DriverSamplePower, []float64{250, 411.5, 180})
+
+	assert.Equal(t, collect.DriverSampleWindow{
+		UUID: "gpu-a", Kind: collect.DriverSamplePower,
+		Min: 180, Max: 411.5, Avg: 280.5, Samples: 3,
+	}, window)
+}
+
+func TestNativeHistogramMatchesTheClientLibrary(t *testing.T) {
+	t.Parallel()
+
+	reference := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:                         "reference",
+		NativeHistogramBucketFactor:  1.1, // picks schema 3
+		NativeHistogramZeroThreshold: prometheus.NativeHistogramZeroThresholdZero,
+	})
+
+	var histogram collect.NativeHistogram
+
+	now := time.Now()
+
+	for _, value := range []float64{0, 0.75, 1, 1.5, 61.2, 64, 250, 251, 410.5, 700, 700} {
+		reference.Observe(value)
+		histogram.Observe(value, now)
+	}
+
+	var metric dto.Metric
+	require.NoError(t, reference.Write(&metric))
+
+	want := map[int]int64{}
+	index, count := 0, int64(0)
+
+	deltas := metric.GetHistogram().GetPositiveDelta()
+
+	// spans are gaps from the previous span's end; counts are deltas
+	for _, span := range metric.GetHistogram().GetPositiveSpan() {
+		index += int(span.GetOffset())
+
+		for range span.GetLength() {
+			count += deltas[0]
+			deltas = deltas[1:]
+
+			if count != 0 {
+				want[index] = count
+			}
+
+			index++
+		}
+	}
+
+	assert.Equal(t, int32(collect.NativeHistogramSchema), metric.GetHistogram().GetSchema())
+	assert.Equal(t, want, histogram.Buckets)
+	assert.Equal(t, metric.GetHistogram().GetZeroCount(), histogram.ZeroCount)
+	assert.Equal(t, metric.GetHistogram().GetSampleCount(), histogram.Count)
+	assert.InDelta(t, metric.GetHistogram().GetSampleSum(), histogram.Sum, 1e-9)
+	assert.Equal(t, now, histogram.Created)
+}
+
+func TestNativeHistogramCloneIsIndependent(t *testing.T) {
+	t.Parallel()
+
+	var histogram collect.NativeHistogram
+
+	histogram.Observe(100, time.Now())
+
+	clone := histogram.Clone()
+	histogram.Observe(100, time.Now())
+
+	assert.Equal(t, uint64(1), clone.Count)
+	assert.Equal(t, map[int]int64{54: 1}, clone.Buckets)
+}
@@ -0,0 +1,41 @@
+package collect
+
+// Superchip is one superchip GPU's readings: a GPU joined to its Grace CPU
+// over the C2C (chip-to-chip) interconnect, as on GH200 and GB200. A GPU
+// without C2C links has no entry.
+type Superchip struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	// Links is the number of C2C links.
+	Links *float64
+	// C2CLinks holds the per-link readings.
+	C2CLinks []C2CLink
+	// ModuleEnergyJoules is the whole module's energy consumption, GPU,
+	// Grace CPU and memory together, since the driver was last loaded; nil
+	// when unknown.
+	ModuleEnergyJoules *float64
+	// MemoryNUMANode is the NUMA node the GPU's memory is onlined as,
+	// beside the Grace CPU's own, so the host's per-node memory readings
+	// tell the two memories apart; nil when unknown.
+	MemoryNUMANode *float64
+}
+
+// C2CLink is one C2C link's readings. A reading the link does not report is
+// nil.
+type C2CLink struct {
+	// Link is the link index, as a label value.
+	Link string
+	// Up is 1 while the link is active and 0 while inactive.
+	Up *float64
+	// MaxBandwidthBytesPerSecond is the link's speed, reported for active
+	// links only.
+	MaxBandwidthBytesPerSecond *float64
+	// CRCErrors, ReplayErrors and BackToBackReplayErrors are the link's
+	// error counters.
+	CRCErrors              *float64
+	ReplayErrors           *float64
+	BackToBackReplayErrors *float64
+	// LowPower is 1 while the link is in its low power state and 0 at full
+	// power.
+	LowPower *float64
+}
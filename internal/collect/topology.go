@@ -0,0 +1,31 @@
+package collect
+
+// Topology is how the GPUs connect to each other and to the host. It only
+// changes with the device set, so backends read it once per device set.
+type Topology struct {
+	// Links holds one entry per ordered pair of distinct GPUs, so each GPU
+	// lists every peer it can reach.
+	Links []TopologyLink
+	// NUMA holds the GPUs local to a single NUMA node.
+	NUMA []NUMAAffinity
+}
+
+// TopologyLink is the path between two GPUs.
+type TopologyLink struct {
+	// UUID and PeerUUID are the GPU uuids, normalized like every uuid label.
+	UUID     string
+	PeerUUID string
+	// Link is one of the nvidiasmi.TopologyLink* constants: NVLink, or the
+	// closest PCIe or host hop the path crosses.
+	Link string
+}
+
+// NUMAAffinity is the NUMA node one GPU is local to.
+type NUMAAffinity struct {
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string
+	Node int
+	// CPUAffinity is the node's CPU list in nvidia-smi's notation, for
+	// example "0-23,48-71", empty when unknown.
+	CPUAffinity string
+}
@@ -0,0 +1,40 @@
+package collect
+
+// VGPUHost is one hypervisor GPU's active vGPU instances. An entry with no
+// instances means the GPU hosts vGPUs and none is running; a GPU that is no
+// vGPU host (or could not be read) has no entry.
+type VGPUHost struct {
+	// UUID is the physical GPU uuid, normalized like every uuid label.
+	UUID string
+	// Instances holds the active vGPU instances.
+	Instances []VGPUInstance
+}
+
+// VGPUInstance is one active vGPU instance, a slice of its host GPU handed to
+// a virtual machine.
+type VGPUInstance struct {
+	// UUID is the vGPU uuid, normalized like the GPU uuids. It is what the
+	// guest sees as its own GPU uuid.
+	UUID string
+	// VMID identifies the virtual machine running the vGPU: its domain id or
+	// uuid, whichever the hypervisor reports. Empty when unknown.
+	VMID string
+	// Type is the vGPU type name (for example "NVIDIA H100-40C") and Class
+	// its class (for example "Compute"). Both are empty when unknown.
+	Type  string
+	Class string
+	// FramebufferUsedBytes is the framebuffer the guest has in use, nil when
+	// unknown.
+	FramebufferUsedBytes *float64
+	// EncoderSessions is the number of active encoder sessions, nil when
+	// unknown.
+	EncoderSessions *float64
+	// SMRatio, MemoryRatio, EncoderRatio and DecoderRatio are the vGPU's
+	// engine utilization, fractions of the sampling period averaged over the
+	// driver samples taken between the two most recent collections; nil
+	// when the vGPU was not sampled in that window.
+	SMRatio      *float64
+	MemoryRatio  *float64
+	EncoderRatio *float64
+	DecoderRatio *float64
+}
@@ -15,6 +15,8 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
 )
 
 // extrasConfig is the demo-specific `extras:` block of the shared fake-smi
@@ -30,6 +32,30 @@ type extrasConfig struct {
 	// MIG lists per-GPU MIG topologies, keyed by the simulated GPU's list
 	// index (the same keying as the fake's per-GPU overrides).
 	MIG []migGPUConfig `yaml:"mig"`
+	// VideoSessions lists per-GPU encoder and NvFBC session loads, keyed the
+	// same way; GPUs without an entry report no sessions.
+	VideoSessions []videoSessionsConfig `yaml:"video-sessions"` //nolint:tagliatelle // kebab-case config keys
+	// MPS lists per-GPU MPS topologies, keyed the same way: an MPS server
+	// and its clients, all joining the per-process readings.
+	MPS []mpsGPUConfig `yaml:"mps"`
+	// NVLink lists per-GPU NVLink links, keyed the same way; GPUs without
+	// an entry have no NVLink.
+	NVLink []nvlinkGPUConfig `yaml:"nvlink"`
+	// PCIeErrors lists per-GPU PCIe error rates, keyed the same way; GPUs
+	// without an entry report healthy links whose counters stay at zero.
+	PCIeErrors []pcieErrorsConfig `yaml:"pcie-errors"` //nolint:tagliatelle // kebab-case config keys
+	// Fans lists per-GPU fans, keyed the same way; GPUs without an entry
+	// are cooled by the enclosure and have none, like the captured H200.
+	Fans []fansConfig `yaml:"fans"`
+	// ConfidentialComputing sets the system's confidential computing state,
+	// shared by every GPU; without it the system runs with the mode off.
+	ConfidentialComputing *ccConfig `yaml:"confidential-computing"` //nolint:tagliatelle // kebab-case config keys
+	// VGPU lists the hypervisor GPUs and their running vGPU instances,
+	// keyed the same way; GPUs without an entry host no vGPUs.
+	VGPU []vgpuConfig `yaml:"vgpu"`
+	// Superchip lists the superchip GPUs and their C2C links, keyed the
+	// same way; GPUs without an entry are PCIe cards and have none.
+	Superchip []superchipConfig `yaml:"superchip"`
 	// EnergyFallbackPowerWatts integrates the energy counter when the GPU
 	// query does not include the power field (an explicit field selection
 	// may exclude it; the counter must not depend on the public schema).
@@ -87,6 +113,105 @@ type migInstanceConfig struct {
 	Busy bool `yaml:"busy"`
 }
 
+// videoSessionsConfig is one simulated GPU's video session load.
+type videoSessionsConfig struct {
+	GPU int `yaml:"gpu"`
+	// Encoder and FBC are the numbers of active sessions of each kind.
+	Encoder int `yaml:"encoder"`
+	FBC     int `yaml:"fbc"`
+}
+
+// mpsGPUConfig is one simulated GPU's MPS topology.
+type mpsGPUConfig struct {
+	GPU     int               `yaml:"gpu"`
+	Clients []mpsClientConfig `yaml:"clients"`
+}
+
+// mpsClientConfig is one MPS client's limits; zero means no limit.
+type mpsClientConfig struct {
+	ActiveThreadPercentage float64 `yaml:"active-thread-percentage"`  //nolint:tagliatelle // kebab-case config keys
+	PinnedMemoryLimitBytes uint64  `yaml:"pinned-memory-limit-bytes"` //nolint:tagliatelle // kebab-case config keys
+}
+
+// nvlinkGPUConfig is one simulated GPU's NVLink links.
+type nvlinkGPUConfig struct {
+	GPU int `yaml:"gpu"`
+	// Links is the number of links the GPU has.
+	Links int `yaml:"links"`
+	// Version is the NVLink version the links run; defaults to 4.
+	Version uint32 `yaml:"version"`
+	// Remote is the device type at the links' far end: switch (the
+	// default) or gpu.
+	Remote string `yaml:"remote"`
+	// Down lists the indexes of the links that are down.
+	Down []int `yaml:"down"`
+}
+
+// pcieErrorsConfig is one simulated GPU's PCIe error rates: how much each
+// counter grows per collection.
+type pcieErrorsConfig struct {
+	GPU             int    `yaml:"gpu"`
+	Replays         uint64 `yaml:"replays"`
+	ReplayRollovers uint64 `yaml:"replay-rollovers"` //nolint:tagliatelle // kebab-case config keys
+	Correctable     uint64 `yaml:"correctable"`
+	Uncorrectable   uint64 `yaml:"uncorrectable"`
+	Fatal           uint64 `yaml:"fatal"`
+}
+
+// fansConfig is one simulated GPU's fans.
+type fansConfig struct {
+	GPU int `yaml:"gpu"`
+	// Count is the number of fans the GPU has.
+	Count int `yaml:"count"`
+	// Speed is the speed every fan is asked for, in percent.
+	Speed float64 `yaml:"speed"`
+	// Policy is how the fans are controlled: auto (the default) or manual.
+	Policy string `yaml:"policy"`
+	// Stalled lists the indexes of the fans that stopped: they report no
+	// speed while their target stays.
+	Stalled []int `yaml:"stalled"`
+}
+
+// ccConfig is the simulated system's confidential computing state.
+type ccConfig struct {
+	// Mode is the confidential computing mode: off (the default), on or
+	// devtools. GPUs outside off carve out a protected memory region.
+	Mode string `yaml:"mode"`
+	// Ready marks the GPUs as accepting confidential client requests, the
+	// state attestation sets.
+	Ready bool `yaml:"ready"`
+}
+
+// vgpuConfig is one simulated hypervisor GPU's vGPU instances.
+type vgpuConfig struct {
+	GPU int `yaml:"gpu"`
+	// Count is the number of running instances; 0 is a host with none
+	// running.
+	Count int `yaml:"count"`
+	// Type is the vGPU type every instance runs, defaultVGPUType by default.
+	Type string `yaml:"type"`
+}
+
+// superchipConfig is one simulated superchip GPU's C2C links.
+type superchipConfig struct {
+	GPU int `yaml:"gpu"`
+	// Links is the number of C2C links joining the GPU to its Grace CPU; a
+	// GH200 has 10.
+	Links int `yaml:"links"`
+	// Down lists the indexes of the links that are down.
+	Down []int `yaml:"down"`
+}
+
+// defaultVGPUType is the vGPU type of the simulated instances: a quarter of
+// an H200, compute class.
+const defaultVGPUType = "NVIDIA H200-35C"
+
+// maxNVLinks is the most links a GPU can have, the driver's NVLINK_MAX_LINKS.
+const maxNVLinks = 36
+
+// defaultNVLinkVersion is the version of the simulated links (Hopper's).
+const defaultNVLinkVersion = 4
+
 // defaultXIDCodes is the pool ongoing events draw from: the codes commonly
 // seen in the wild (application faults, ECC, thermal, bus errors).
 //
@@ -152,6 +277,38 @@ func (c *extrasConfig) validate() error {
 		return err
 	}
 
+	if err := c.validateVideoSessions(); err != nil {
+		return err
+	}
+
+	if err := c.validateMPS(); err != nil {
+		return err
+	}
+
+	if err := c.validateNVLink(); err != nil {
+		return err
+	}
+
+	if err := c.validatePCIeErrors(); err != nil {
+		return err
+	}
+
+	if err := c.validateFans(); err != nil {
+		return err
+	}
+
+	if err := c.validateConfComputing(); err != nil {
+		return err
+	}
+
+	if err := c.validateVGPU(); err != nil {
+		return err
+	}
+
+	if err := c.validateSuperchip(); err != nil {
+		return err
+	}
+
 	seenGPU := map[int]bool{}
 
 	for _, gpu := range c.MIG {
@@ -195,6 +352,214 @@ func (c *extrasConfig) validateXIDs() error {
 	return nil
 }
 
+// validateVideoSessions checks the video session loads.
+func (c *extrasConfig) validateVideoSessions() error {
+	seenGPU := map[int]bool{}
+
+	for _, gpu := range c.VideoSessions {
+		if gpu.GPU < 0 {
+			return fmt.Errorf("video-sessions entry has a negative gpu index %d", gpu.GPU)
+		}
+
+		if seenGPU[gpu.GPU] {
+			return fmt.Errorf("duplicate video-sessions entry for gpu %d", gpu.GPU)
+		}
+
+		seenGPU[gpu.GPU] = true
+
+		if gpu.Encoder < 0 || gpu.FBC < 0 {
+			return fmt.Errorf("video-sessions entry for gpu %d has a negative session count", gpu.GPU)
+		}
+	}
+
+	return nil
+}
+
+// validateMPS checks the MPS topologies.
+func (c *extrasConfig) validateMPS() error {
+	seenGPU := map[int]bool{}
+
+	for _, gpu := range c.MPS {
+		if gpu.GPU < 0 {
+			return fmt.Errorf("mps entry has a negative gpu index %d", gpu.GPU)
+		}
+
+		if seenGPU[gpu.GPU] {
+			return fmt.Errorf("duplicate mps entry for gpu %d", gpu.GPU)
+		}
+
+		seenGPU[gpu.GPU] = true
+
+		for _, client := range gpu.Clients {
+			if !isFinite(client.ActiveThreadPercentage) ||
+				client.ActiveThreadPercentage < 0 || client.ActiveThreadPercentage > 100 {
+				return fmt.Errorf("mps client on gpu %d: active-thread-percentage must be within 0 and 100",
+					gpu.GPU)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateNVLink checks the NVLink links.
+func (c *extrasConfig) validateNVLink() error {
+	seenGPU := map[int]bool{}
+
+	for _, gpu := range c.NVLink {
+		if gpu.GPU < 0 {
+			return fmt.Errorf("nvlink entry has a negative gpu index %d", gpu.GPU)
+		}
+
+		if seenGPU[gpu.GPU] {
+			return fmt.Errorf("duplicate nvlink entry for gpu %d", gpu.GPU)
+		}
+
+		seenGPU[gpu.GPU] = true
+
+		if gpu.Links < 1 || gpu.Links > maxNVLinks {
+			return fmt.Errorf("nvlink entry for gpu %d must have between 1 and %d links", gpu.GPU, maxNVLinks)
+		}
+
+		if gpu.Remote != "" && gpu.Remote != collect.NVLinkRemoteSwitch && gpu.Remote != collect.NVLinkRemoteGPU {
+			return fmt.Errorf("nvlink entry for gpu %d: remote must be %q or %q, got %q",
+				gpu.GPU, collect.NVLinkRemoteSwitch, collect.NVLinkRemoteGPU, gpu.Remote)
+		}
+
+		for _, link := range gpu.Down {
+			if link < 0 || link >= gpu.Links {
+				return fmt.Errorf("nvlink entry for gpu %d: down link %d is out of range", gpu.GPU, link)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validatePCIeErrors checks the PCIe error rates.
+func (c *extrasConfig) validatePCIeErrors() error {
+	seenGPU := map[int]bool{}
+
+	for _, gpu := range c.PCIeErrors {
+		if gpu.GPU < 0 {
+			return fmt.Errorf("pcie-errors entry has a negative gpu index %d", gpu.GPU)
+		}
+
+		if seenGPU[gpu.GPU] {
+			return fmt.Errorf("duplicate pcie-errors entry for gpu %d", gpu.GPU)
+		}
+
+		seenGPU[gpu.GPU] = true
+	}
+
+	return nil
+}
+
+// validateFans checks the fans.
+func (c *extrasConfig) validateFans() error {
+	seenGPU := map[int]bool{}
+
+	for _, gpu := range c.Fans {
+		if gpu.GPU < 0 {
+			return fmt.Errorf("fans entry has a negative gpu index %d", gpu.GPU)
+		}
+
+		if seenGPU[gpu.GPU] {
+			return fmt.Errorf("duplicate fans entry for gpu %d", gpu.GPU)
+		}
+
+		seenGPU[gpu.GPU] = true
+
+		if gpu.Count < 1 {
+			return fmt.Errorf("fans entry for gpu %d must have at least one fan", gpu.GPU)
+		}
+
+		if !isFinite(gpu.Speed) || gpu.Speed < 0 || gpu.Speed > 100 {
+			return fmt.Errorf("fans entry for gpu %d: speed must be within 0 and 100", gpu.GPU)
+		}
+
+		if gpu.Policy != "" && gpu.Policy != collect.FanPolicyAuto && gpu.Policy != collect.FanPolicyManual {
+			return fmt.Errorf("fans entry for gpu %d: policy must be %q or %q, got %q",
+				gpu.GPU, collect.FanPolicyAuto, collect.FanPolicyManual, gpu.Policy)
+		}
+
+		for _, fan := range gpu.Stalled {
+			if fan < 0 || fan >= gpu.Count {
+				return fmt.Errorf("fans entry for gpu %d: stalled fan %d is out of range", gpu.GPU, fan)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateConfComputing checks the confidential computing state.
+func (c *extrasConfig) validateConfComputing() error {
+	if c.ConfidentialComputing == nil {
+		return nil
+	}
+
+	switch mode := c.ConfidentialComputing.Mode; mode {
+	case "", collect.CCModeOff, collect.CCModeOn, collect.CCModeDevtools:
+		return nil
+	default:
+		return fmt.Errorf("confidential-computing mode must be %q, %q or %q, got %q",
+			collect.CCModeOff, collect.CCModeOn, collect.CCModeDevtools, mode)
+	}
+}
+
+// validateVGPU checks the vGPU hosts.
+func (c *extrasConfig) validateVGPU() error {
+	seenGPU := map[int]bool{}
+
+	for _, gpu := range c.VGPU {
+		if gpu.GPU < 0 {
+			return fmt.Errorf("vgpu entry has a negative gpu index %d", gpu.GPU)
+		}
+
+		if seenGPU[gpu.GPU] {
+			return fmt.Errorf("duplicate vgpu entry for gpu %d", gpu.GPU)
+		}
+
+		seenGPU[gpu.GPU] = true
+
+		if gpu.Count < 0 {
+			return fmt.Errorf("vgpu entry for gpu %d has a negative count %d", gpu.GPU, gpu.Count)
+		}
+	}
+
+	return nil
+}
+
+// validateSuperchip checks the superchip GPUs' C2C links.
+func (c *extrasConfig) validateSuperchip() error {
+	seenGPU := map[int]bool{}
+
+	for _, gpu := range c.Superchip {
+		if gpu.GPU < 0 {
+			return fmt.Errorf("superchip entry has a negative gpu index %d", gpu.GPU)
+		}
+
+		if seenGPU[gpu.GPU] {
+			return fmt.Errorf("duplicate superchip entry for gpu %d", gpu.GPU)
+		}
+
+		seenGPU[gpu.GPU] = true
+
+		if gpu.Links < 1 {
+			return fmt.Errorf("superchip entry for gpu %d must have at least 1 link", gpu.GPU)
+		}
+
+		for _, link := range gpu.Down {
+			if link < 0 || link >= gpu.Links {
+				return fmt.Errorf("superchip entry for gpu %d: down link %d is out of range", gpu.GPU, link)
+			}
+		}
+	}
+
+	return nil
+}
+
 // isFinite reports whether the value is a usable number.
 func isFinite(v float64) bool {
 	return !math.IsNaN(v) && !math.IsInf(v, 0)
@@ -261,6 +626,37 @@ func (c *extrasConfig) applyDefaults() {
 		c.EnergyFallbackPowerWatts = defaultEnergyFallbackPowerWatts
 	}
 
+	for i := range c.NVLink {
+		gpu := &c.NVLink[i]
+		if gpu.Version == 0 {
+			gpu.Version = defaultNVLinkVersion
+		}
+
+		if gpu.Remote == "" {
+			gpu.Remote = collect.NVLinkRemoteSwitch
+		}
+	}
+
+	for i := range c.Fans {
+		if c.Fans[i].Policy == "" {
+			c.Fans[i].Policy = collect.FanPolicyAuto
+		}
+	}
+
+	if c.ConfidentialComputing == nil {
+		c.ConfidentialComputing = &ccConfig{}
+	}
+
+	if c.ConfidentialComputing.Mode == "" {
+		c.ConfidentialComputing.Mode = collect.CCModeOff
+	}
+
+	for i := range c.VGPU {
+		if c.VGPU[i].Type == "" {
+			c.VGPU[i].Type = defaultVGPUType
+		}
+	}
+
 	for gpuIdx := range c.MIG {
 		for i := range c.MIG[gpuIdx].Instances {
 			instance := &c.MIG[gpuIdx].Instances[i]
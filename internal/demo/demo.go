@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/eventlog"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/fakesmi"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
 )
@@ -66,6 +67,10 @@ type Backend struct {
 
 	energy map[string]*energyState
 	xids   map[string]map[uint64]*xidStat
+	// recent logs the synthesized driver events in order. Unlike the
+	// counters it survives a config edit: it is history, not scenario
+	// state.
+	recent eventlog.Ring
 	// xidsSeeded records that the initial events were applied (they resolve
 	// GPU indexes against the first served table).
 	xidsSeeded bool
@@ -74,6 +79,17 @@ type Backend struct {
 	// real backend's GPM sampling, utilization needs a sample pair, so the
 	// first cycle that sees a GPU instance serves no utilization for it.
 	seenGIs map[string]bool
+	// nvlink holds the running link counters, keyed by uuid and link.
+	nvlink map[string]*nvlinkState
+	// pcieErrors holds the running PCIe error counters, keyed by uuid.
+	pcieErrors map[string]*pcieErrorState
+	// seenGPM tracks the GPUs previous cycles profiled, for the same sample
+	// pair rule as seenGIs.
+	seenGPM map[string]bool
+	// powerHistograms accumulates each GPU's synthesized power samples. Like
+	// the real backend's sample windows, the first cycle that sees a GPU
+	// only creates its entry.
+	powerHistograms map[string]*collect.NativeHistogram
 }
 
 // energyState is one GPU's energy integration state.
@@ -83,6 +99,22 @@ type energyState struct {
 	lastAt    time.Time
 }
 
+// nvlinkState is one link's running counters.
+type nvlinkState struct {
+	txBytes, rxBytes                      float64
+	crcFlit, crcData, replays, recoveries float64
+}
+
+// pcieErrorState is one GPU's running PCIe error counters.
+type pcieErrorState struct {
+	replays, rollovers                float64
+	correctable, uncorrectable, fatal float64
+}
+
+// demoDoubleBitECCXID is the XID the driver raises for a double-bit ECC
+// error.
+const demoDoubleBitECCXID = 48
+
 // xidStat is one (GPU, XID code) pair's running state.
 type xidStat struct {
 	count uint64
@@ -188,6 +220,48 @@ func (b *Backend) XIDCounts() []collect.XIDCounter {
 	return counters
 }
 
+// RecentEvents serves the latest synthesized driver events, oldest first,
+// like the real watcher's log.
+func (b *Backend) RecentEvents(filter eventlog.Filter) []eventlog.Event {
+	return b.recent.RecentEvents(filter)
+}
+
+// DriverEventCounts serves the driver events behind the synthetic XIDs: each
+// XID is an xid event, and XID 48 (a double-bit ECC error) comes with the
+// ecc_double_bit event the real driver delivers beside it.
+func (b *Backend) DriverEventCounts() []collect.DriverEventCounter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var counters []collect.DriverEventCounter
+
+	for uuid, perGPU := range b.xids {
+		var xids, doubleBit uint64
+
+		for xid, stat := range perGPU {
+			xids += stat.count
+
+			if xid == demoDoubleBitECCXID {
+				doubleBit += stat.count
+			}
+		}
+
+		counters = append(counters, collect.DriverEventCounter{
+			UUID: uuid, Type: collect.DriverEventXID, Count: xids,
+		})
+
+		if doubleBit > 0 {
+			counters = append(counters, collect.DriverEventCounter{
+				UUID: uuid, Type: collect.DriverEventECCDoubleBit, Count: doubleBit,
+			})
+		}
+	}
+
+	sortDriverEventCounters(counters)
+
+	return counters
+}
+
 // preflight proves the configuration actually serves a GPU table: the capture
 // must exist, hold the requested state, and the identity/override resolution
 // must go through. Without it, a bad capture name would pass the YAML
@@ -213,6 +287,10 @@ func (b *Backend) resetState(snap snapshot) {
 	b.xidsSeeded = false
 	b.nextXIDAt = time.Time{}
 	b.seenGIs = map[string]bool{}
+	b.nvlink = map[string]*nvlinkState{}
+	b.pcieErrors = map[string]*pcieErrorState{}
+	b.seenGPM = map[string]bool{}
+	b.powerHistograms = map[string]*collect.NativeHistogram{}
 }
 
 // loadSnapshot reads and validates one immutable configuration, reconciling
@@ -272,6 +350,55 @@ func reconcile(cfg *fakesmi.Config, extras *extrasConfig) error {
 		}
 	}
 
+	for _, gpu := range extras.VideoSessions {
+		if gpu.GPU >= cfg.GPUCount() {
+			return fmt.Errorf("video-sessions entry: gpu index %d is out of range: the config simulates %d GPU(s)",
+				gpu.GPU, cfg.GPUCount())
+		}
+	}
+
+	for _, gpu := range extras.MPS {
+		if gpu.GPU >= cfg.GPUCount() {
+			return fmt.Errorf("mps entry: gpu index %d is out of range: the config simulates %d GPU(s)",
+				gpu.GPU, cfg.GPUCount())
+		}
+	}
+
+	for _, gpu := range extras.NVLink {
+		if gpu.GPU >= cfg.GPUCount() {
+			return fmt.Errorf("nvlink entry: gpu index %d is out of range: the config simulates %d GPU(s)",
+				gpu.GPU, cfg.GPUCount())
+		}
+	}
+
+	for _, gpu := range extras.PCIeErrors {
+		if gpu.GPU >= cfg.GPUCount() {
+			return fmt.Errorf("pcie-errors entry: gpu index %d is out of range: the config simulates %d GPU(s)",
+				gpu.GPU, cfg.GPUCount())
+		}
+	}
+
+	for _, gpu := range extras.Fans {
+		if gpu.GPU >= cfg.GPUCount() {
+			return fmt.Errorf("fans entry: gpu index %d is out of range: the config simulates %d GPU(s)",
+				gpu.GPU, cfg.GPUCount())
+		}
+	}
+
+	for _, gpu := range extras.VGPU {
+		if gpu.GPU >= cfg.GPUCount() {
+			return fmt.Errorf("vgpu entry: gpu index %d is out of range: the config simulates %d GPU(s)",
+				gpu.GPU, cfg.GPUCount())
+		}
+	}
+
+	for _, gpu := range extras.Superchip {
+		if gpu.GPU >= cfg.GPUCount() {
+			return fmt.Errorf("superchip entry: gpu index %d is out of range: the config simulates %d GPU(s)",
+				gpu.GPU, cfg.GPUCount())
+		}
+	}
+
 	return nil
 }
 
@@ -319,8 +446,21 @@ func (b *Backend) overlay(reading *collect.Reading) {
 	b.synthEnergy(uuids, power, snap.extras, now, reading)
 	b.synthPCIe(uuids, snap.extras, reading)
 	b.synthMIG(uuids, snap.extras, reading)
+	b.synthMPS(uuids, snap.extras, reading)
 	attributeApps(uuids, snap.extras, reading)
 	b.tickXIDs(uuids, snap.extras, now)
+	b.synthVideoSessions(uuids, snap.extras, reading)
+	b.synthNVLink(uuids, snap.extras, reading)
+	b.synthGPM(uuids, snap.extras, reading)
+	synthTemperatureThresholds(uuids, reading)
+	b.synthPCIeErrors(uuids, snap.extras, reading)
+	synthFans(uuids, snap.extras, reading)
+	synthConfComputing(uuids, snap.extras, reading)
+	synthVGPUs(uuids, snap.extras, reading)
+	synthSuperchips(uuids, snap.extras, reading)
+	synthCapabilities(uuids, reading)
+	synthTopology(uuids, snap.extras, reading)
+	b.synthDriverSamples(uuids, power, snap.extras, now, reading)
 }
 
 // privatePower fills the power draws the public query left out, from the
@@ -19,6 +19,7 @@ import (
 
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/demodata"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/eventlog"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/fakesmi"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
 )
@@ -124,6 +125,46 @@ func TestParseExtrasValidation(t *testing.T) {
 			doc:     "extras:\n  xids:\n    interval: often\n",
 			wantErr: "invalid xids interval",
 		},
+		{
+			name:    "duplicate video sessions gpu",
+			doc:     "extras:\n  video-sessions:\n    - {gpu: 0, encoder: 1}\n    - {gpu: 0, fbc: 1}\n",
+			wantErr: "duplicate video-sessions entry for gpu 0",
+		},
+		{
+			name:    "negative video session count",
+			doc:     "extras:\n  video-sessions:\n    - {gpu: 0, encoder: -1}\n",
+			wantErr: "negative session count",
+		},
+		{
+			name:    "duplicate mps gpu",
+			doc:     "extras:\n  mps:\n    - {gpu: 0}\n    - {gpu: 0}\n",
+			wantErr: "duplicate mps entry for gpu 0",
+		},
+		{
+			name:    "mps thread percentage out of range",
+			doc:     "extras:\n  mps:\n    - {gpu: 0, clients: [{active-thread-percentage: 150}]}\n",
+			wantErr: "active-thread-percentage must be within 0 and 100",
+		},
+		{
+			name:    "duplicate nvlink gpu",
+			doc:     "extras:\n  nvlink:\n    - {gpu: 0, links: 4}\n    - {gpu: 0, links: 2}\n",
+			wantErr: "duplicate nvlink entry for gpu 0",
+		},
+		{
+			name:    "nvlink without links",
+			doc:     "extras:\n  nvlink:\n    - {gpu: 0}\n",
+			wantErr: "must have between 1 and 36 links",
+		},
+		{
+			name:    "nvlink unknown remote",
+			doc:     "extras:\n  nvlink:\n    - {gpu: 0, links: 4, remote: ibmnpu}\n",
+			wantErr: `remote must be "switch" or "gpu"`,
+		},
+		{
+			name:    "nvlink down link out of range",
+			doc:     "extras:\n  nvlink:\n    - {gpu: 0, links: 4, down: [4]}\n",
+			wantErr: "down link 4 is out of range",
+		},
 	} {
 		t.Run(testCase.name, func(t *testing.T) {
 			t.Parallel()
@@ -270,6 +311,433 @@ func TestSynthMIGShape(t *testing.T) {
 	assert.Less(t, *third.Utilization.SMActivityRatio, 0.1)
 }
 
+func TestSynthVideoSessions(t *testing.T) {
+	t.Parallel()
+
+	seed := int64(1)
+	backend := &Backend{rng: newDemoRand(&seed)}
+	extras, err := extrasFrom(t, "extras:\n  video-sessions:\n    - {gpu: 1, encoder: 4, fbc: 1}\n")
+	require.NoError(t, err)
+
+	var reading collect.Reading
+
+	backend.synthVideoSessions([]string{"u0", "u1"}, extras, &reading)
+
+	// both kinds for every GPU, empty where nothing is configured
+	require.Len(t, reading.Extras.VideoSessions, 4)
+	assert.Empty(t, reading.Extras.VideoSessions[0].Sessions)
+	assert.Empty(t, reading.Extras.VideoSessions[1].Sessions)
+
+	encoder, fbc := reading.Extras.VideoSessions[2], reading.Extras.VideoSessions[3]
+	assert.Equal(t, collect.VideoSessionEncoder, encoder.Type)
+	assert.Equal(t, collect.VideoSessionFBC, fbc.Type)
+	require.Len(t, encoder.Sessions, 4)
+	require.Len(t, fbc.Sessions, 1)
+
+	// the shapes cycle and the identities are distinct across both kinds
+	assert.Equal(t, "h264", encoder.Sessions[3].Codec)
+	assert.Empty(t, fbc.Sessions[0].Codec)
+
+	ids := map[string]bool{}
+	for _, session := range append(encoder.Sessions, fbc.Sessions...) {
+		ids[session.ID] = true
+
+		assert.Equal(t, session.AverageFPS, float64(int(session.AverageFPS)), "whole frames, like the driver")
+		assert.Positive(t, session.AverageLatencySeconds)
+	}
+
+	assert.Len(t, ids, 5)
+}
+
+func TestSynthMPS(t *testing.T) {
+	t.Parallel()
+
+	seed := int64(1)
+	backend := &Backend{rng: newDemoRand(&seed)}
+	extras, err := extrasFrom(t, "extras:\n  mps:\n    - gpu: 1\n      clients:\n"+
+		"        - {active-thread-percentage: 50, pinned-memory-limit-bytes: 1073741824}\n"+
+		"        - {}\n")
+	require.NoError(t, err)
+
+	reading := collect.Reading{
+		AppsSuccess: true,
+		Apps:        []nvidiasmi.ComputeApp{{GPUUUID: "u0", PID: "10291"}},
+	}
+
+	backend.synthMPS([]string{"u0", "u1"}, extras, &reading)
+
+	sharing := reading.Extras.MPS
+	assert.Equal(t, []string{"1190"}, sharing.ControlDaemonPIDs)
+	assert.Equal(t, []collect.MPSServer{{UUID: "u1", PID: "1201"}}, sharing.Servers)
+	require.Len(t, sharing.Clients, 2)
+	assert.InDelta(t, 0.5, *sharing.Clients[0].ActiveThreadRatio, 1e-9)
+	assert.Equal(t, uint64(1<<30), *sharing.Clients[0].PinnedMemoryLimitBytes)
+	assert.Nil(t, sharing.Clients[1].ActiveThreadRatio, "an unlimited client has no limits")
+	assert.Nil(t, sharing.Clients[1].PinnedMemoryLimitBytes)
+
+	// the server and the clients join the process list, the limited client
+	// within its pinned limit
+	require.Len(t, reading.Apps, 4)
+	assert.Equal(t, "nvidia-cuda-mps-server", reading.Apps[1].ProcessName)
+	assert.Equal(t, sharing.Clients[0].PID, reading.Apps[2].PID)
+	assert.Equal(t, "1024 MiB", reading.Apps[2].UsedMemory)
+
+	// without a process list only the sharing families are synthesized
+	var bare collect.Reading
+
+	backend.synthMPS([]string{"u0", "u1"}, extras, &bare)
+	assert.Empty(t, bare.Apps)
+	assert.Len(t, bare.Extras.MPS.Clients, 2)
+}
+
+func TestSynthNVLink(t *testing.T) {
+	t.Parallel()
+
+	seed := int64(1)
+	backend := &Backend{rng: newDemoRand(&seed), nvlink: map[string]*nvlinkState{}}
+	extras, err := extrasFrom(t, "extras:\n  nvlink:\n    - {gpu: 1, links: 3, remote: gpu, down: [2]}\n")
+	require.NoError(t, err)
+
+	var first collect.Reading
+
+	backend.synthNVLink([]string{"u0", "u1"}, extras, &first)
+
+	links := first.Extras.NVLink
+	require.Len(t, links, 3, "only the configured GPU has links")
+	assert.Equal(t, "u1", links[0].UUID)
+	assert.True(t, links[0].Active)
+	assert.Equal(t, uint32(4), links[0].Version, "the version defaults to 4")
+	assert.Equal(t, collect.NVLinkRemoteGPU, links[0].RemoteDeviceType)
+	assert.Equal(t, "00000000:41:00.0", links[1].RemoteBusID)
+	require.NotNil(t, links[0].TXBytes)
+	assert.Equal(t, collect.NVLink{UUID: "u1", Link: "2"}, links[2], "a down link reports only its state")
+
+	// the counters only grow
+	var second collect.Reading
+
+	backend.synthNVLink([]string{"u0", "u1"}, extras, &second)
+	assert.Greater(t, *second.Extras.NVLink[0].TXBytes, *links[0].TXBytes)
+	assert.GreaterOrEqual(t, *second.Extras.NVLink[0].CRCFlitErrors, *links[0].CRCFlitErrors)
+}
+
+func TestSynthGPM(t *testing.T) {
+	t.Parallel()
+
+	seed := int64(1)
+	backend := &Backend{rng: newDemoRand(&seed), seenGPM: map[string]bool{}}
+	extras, err := extrasFrom(t, "extras:\n"+
+		"  mig:\n    - {gpu: 0, instances: [{gi: 1, profile: 1g.18gb}]}\n"+
+		"  nvlink:\n    - {gpu: 1, links: 2}\n")
+	require.NoError(t, err)
+
+	uuids := []string{"u0", "u1", "u2"}
+
+	var first collect.Reading
+
+	backend.synthGPM(uuids, extras, &first)
+	assert.Empty(t, first.Extras.GPM, "the first cycle has no sample pair yet")
+
+	var second collect.Reading
+
+	backend.synthGPM(uuids, extras, &second)
+
+	profiles := second.Extras.GPM
+	require.Len(t, profiles, 2, "the MIG GPU is profiled per GPU instance instead")
+	assert.Equal(t, "u1", profiles[0].UUID)
+	require.NotNil(t, profiles[0].SMActivityRatio)
+	assert.InDelta(t, 0.75, *profiles[0].SMActivityRatio, 0.2)
+	assert.Positive(t, *profiles[0].NVLinkTXBytesPerSecond)
+	assert.Equal(t, "u2", profiles[1].UUID)
+	assert.Zero(t, *profiles[1].NVLinkTXBytesPerSecond, "a GPU without links moves no NVLink traffic")
+}
+
+func TestSynthTemperatureThresholds(t *testing.T) {
+	t.Parallel()
+
+	var reading collect.Reading
+
+	synthTemperatureThresholds([]string{"u0", "u1"}, &reading)
+
+	thresholds := reading.Extras.TemperatureThresholds
+	require.Len(t, thresholds, 2*len(demoTemperatureThresholds))
+	assert.Equal(t, "u1", thresholds[len(demoTemperatureThresholds)].UUID)
+
+	for _, threshold := range thresholds {
+		assert.False(t, threshold.TLimit, "the demo mirrors the nvml backend's absolute thresholds")
+	}
+}
+
+func TestSynthPCIeErrors(t *testing.T) {
+	t.Parallel()
+
+	backend := &Backend{pcieErrors: map[string]*pcieErrorState{}}
+	extras, err := extrasFrom(t, "extras:\n  pcie-errors:\n    - {gpu: 1, replays: 5, correctable: 2, fatal: 1}\n")
+	require.NoError(t, err)
+
+	var reading collect.Reading
+
+	for range 3 {
+		reading = collect.Reading{}
+		backend.synthPCIeErrors([]string{"u0", "u1"}, extras, &reading)
+	}
+
+	counters := reading.Extras.PCIeErrors
+	require.Len(t, counters, 2, "every GPU reports its counters")
+	assert.Equal(t, "u0", counters[0].UUID)
+	assert.Zero(t, *counters[0].Replays, "a GPU without an entry has a healthy link")
+	assert.Equal(t, "u1", counters[1].UUID)
+	assert.InDelta(t, 15, *counters[1].Replays, 0)
+	assert.InDelta(t, 6, *counters[1].CorrectableErrors, 0)
+	assert.Zero(t, *counters[1].UncorrectableErrors)
+	assert.InDelta(t, 3, *counters[1].FatalErrors, 0)
+
+	_, err = extrasFrom(t, "extras:\n  pcie-errors:\n    - {gpu: 0}\n    - {gpu: 0, replays: 1}\n")
+	require.ErrorContains(t, err, "duplicate pcie-errors entry")
+}
+
+func TestSynthFans(t *testing.T) {
+	t.Parallel()
+
+	extras, err := extrasFrom(t, "extras:\n  fans:\n    - {gpu: 1, count: 3, speed: 60, stalled: [2]}\n")
+	require.NoError(t, err)
+
+	var reading collect.Reading
+
+	synthFans([]string{"u0", "u1"}, extras, &reading)
+
+	fans := reading.Extras.Fans
+	require.Len(t, fans, 3, "only the configured GPU has fans")
+	assert.Equal(t, "u1", fans[0].UUID)
+	assert.Equal(t, collect.FanPolicyAuto, fans[0].Policy, "the policy defaults to auto")
+	assert.InDelta(t, 0.6, *fans[0].SpeedRatio, 1e-9)
+	assert.Equal(t, "2", fans[2].Fan)
+	assert.Zero(t, *fans[2].SpeedRatio, "a stalled fan reports no speed")
+	assert.InDelta(t, 0.6, *fans[2].TargetSpeedRatio, 1e-9, "while keeping its target")
+
+	_, err = extrasFrom(t, "extras:\n  fans:\n    - {gpu: 0, count: 2, stalled: [2]}\n")
+	require.ErrorContains(t, err, "stalled fan 2 is out of range")
+}
+
+func TestSynthConfComputing(t *testing.T) {
+	t.Parallel()
+
+	extras, err := extrasFrom(t, "extras: {}\n")
+	require.NoError(t, err)
+
+	var off collect.Reading
+
+	synthConfComputing([]string{"u0", "u1"}, extras, &off)
+
+	state := off.Extras.ConfidentialComputing
+	assert.Equal(t, collect.CCModeOff, state.Mode, "the mode defaults to off")
+	assert.Zero(t, *state.Ready)
+	require.Len(t, state.GPUs, 2)
+	assert.Nil(t, state.GPUs[0].ProtectedMemoryBytes, "a GPU outside confidential computing has no protected region")
+
+	extras, err = extrasFrom(t, "extras:\n  confidential-computing: {mode: on, ready: true}\n")
+	require.NoError(t, err)
+
+	var on collect.Reading
+
+	synthConfComputing([]string{"u0", "u1"}, extras, &on)
+
+	state = on.Extras.ConfidentialComputing
+	assert.Equal(t, collect.CCModeOn, state.Mode)
+	assert.InDelta(t, 1, *state.Ready, 0)
+	assert.Equal(t, collect.CCModeOn, state.GPUs[1].Mode)
+	assert.InDelta(t, demoProtectedMemoryBytes, *state.GPUs[1].ProtectedMemoryBytes, 0)
+
+	_, err = extrasFrom(t, "extras:\n  confidential-computing: {mode: enforced}\n")
+	require.ErrorContains(t, err, "confidential-computing mode must be")
+}
+
+func TestSynthVGPUs(t *testing.T) {
+	t.Parallel()
+
+	extras, err := extrasFrom(t, "extras:\n  vgpu:\n    - {gpu: 0, count: 0}\n    - {gpu: 1, count: 2}\n")
+	require.NoError(t, err)
+
+	var reading collect.Reading
+
+	synthVGPUs([]string{"u0", "u1"}, extras, &reading)
+
+	hosts := reading.Extras.VGPUs
+	require.Len(t, hosts, 2)
+	assert.Empty(t, hosts[0].Instances, "a host with nothing running still reports")
+	require.Len(t, hosts[1].Instances, 2)
+
+	first, second := hosts[1].Instances[0], hosts[1].Instances[1]
+	assert.Equal(t, defaultVGPUType, first.Type, "the type defaults")
+	assert.NotEqual(t, first.UUID, second.UUID)
+	assert.NotEqual(t, first.VMID, second.VMID)
+	assert.Equal(t, vgpuUUID("u1", 0), first.UUID, "the uuids are stable across cycles")
+	assert.InDelta(t, 2*demoVGPUFramebufferBytes, *second.FramebufferUsedBytes, 0)
+
+	_, err = extrasFrom(t, "extras:\n  vgpu:\n    - {gpu: 0, count: -1}\n")
+	require.ErrorContains(t, err, "negative count")
+
+	_, err = extrasFrom(t, "extras:\n  vgpu:\n    - {gpu: 0}\n    - {gpu: 0}\n")
+	require.ErrorContains(t, err, "duplicate vgpu entry")
+}
+
+func TestSynthSuperchips(t *testing.T) {
+	t.Parallel()
+
+	extras, err := extrasFrom(t, "extras:\n  superchip:\n    - {gpu: 1, links: 3, down: [2]}\n")
+	require.NoError(t, err)
+
+	reading := collect.Reading{Extras: collect.Extras{Energy: []collect.EnergyCounter{
+		{UUID: "u0", Joules: 100},
+		{UUID: "u1", Joules: 200},
+	}}}
+
+	synthSuperchips([]string{"u0", "u1"}, extras, &reading)
+
+	superchips := reading.Extras.Superchips
+	require.Len(t, superchips, 1, "a GPU without an entry is a PCIe card")
+
+	superchip := superchips[0]
+	assert.Equal(t, "u1", superchip.UUID)
+	assert.InDelta(t, 3, *superchip.Links, 0)
+	assert.InDelta(t, 200*demoModuleEnergyFactor, *superchip.ModuleEnergyJoules, 1e-9,
+		"the module energy follows the GPU's counter")
+	assert.InDelta(t, 3, *superchip.MemoryNUMANode, 0, "the GPU memory nodes follow the CPU nodes")
+
+	require.Len(t, superchip.C2CLinks, 3)
+	assert.InDelta(t, 1, *superchip.C2CLinks[0].Up, 0)
+	assert.InDelta(t, demoC2CLinkBandwidthBytesPerSecond, *superchip.C2CLinks[0].MaxBandwidthBytesPerSecond, 0)
+
+	down := superchip.C2CLinks[2]
+	assert.InDelta(t, 0, *down.Up, 0)
+	assert.InDelta(t, 1, *down.LowPower, 0)
+	assert.Nil(t, down.MaxBandwidthBytesPerSecond, "an inactive link reports no bandwidth")
+
+	_, err = extrasFrom(t, "extras:\n  superchip:\n    - {gpu: 0}\n")
+	require.ErrorContains(t, err, "at least 1 link")
+
+	_, err = extrasFrom(t, "extras:\n  superchip:\n    - {gpu: 0, links: 2, down: [2]}\n")
+	require.ErrorContains(t, err, "out of range")
+
+	_, err = extrasFrom(t, "extras:\n  superchip:\n    - {gpu: 0, links: 1}\n    - {gpu: 0, links: 1}\n")
+	require.ErrorContains(t, err, "duplicate superchip entry")
+}
+
+func TestSynthDriverSamples(t *testing.T) {
+	t.Parallel()
+
+	seed := int64(1)
+	backend := &Backend{rng: newDemoRand(&seed), powerHistograms: map[string]*collect.NativeHistogram{}}
+	extras, err := extrasFrom(t, "extras: {}\n")
+	require.NoError(t, err)
+
+	uuids := []string{"u0", "u1"}
+	power := map[string]float64{"u0": 200}
+	now := time.Now()
+
+	var first collect.Reading
+
+	backend.synthDriverSamples(uuids, power, extras, now, &first)
+	assert.Empty(t, first.Extras.DriverSamples, "the first cycle only opens the windows")
+
+	var second collect.Reading
+
+	backend.synthDriverSamples(uuids, power, extras, now, &second)
+	require.Len(t, second.Extras.DriverSamples, 10)
+
+	window := second.Extras.DriverSamples[0]
+	assert.Equal(t, collect.DriverSamplePower, window.Kind)
+	assert.Greater(t, window.Max, 260.0, "the window carries a spike")
+	assert.GreaterOrEqual(t, window.Min, 170.0)
+	assert.GreaterOrEqual(t, second.Extras.DriverSamples[5].Min, extras.EnergyFallbackPowerWatts*0.85,
+		"a GPU without a table power draws around the fallback")
+	require.Len(t, second.Extras.PowerHistograms, 2)
+	assert.Equal(t, uint64(demoSamplesPerWindow), second.Extras.PowerHistograms[0].Count)
+
+	var third collect.Reading
+
+	backend.synthDriverSamples(uuids[:1], power, extras, now, &third)
+	assert.Equal(t, uint64(2*demoSamplesPerWindow), third.Extras.PowerHistograms[0].Count)
+	assert.NotContains(t, backend.powerHistograms, "u1", "a departed GPU starts over")
+}
+
+func TestSynthCapabilities(t *testing.T) {
+	t.Parallel()
+
+	var reading collect.Reading
+
+	synthCapabilities([]string{"u0", "u1"}, &reading)
+
+	capabilities := reading.Extras.Capabilities
+	require.Len(t, capabilities, 2)
+	assert.Equal(t, "u1", capabilities[1].UUID)
+	assert.NotSame(t, capabilities[0].Cores, capabilities[1].Cores, "every GPU owns its values")
+	require.NotNil(t, capabilities[0].MemoryBusWidthBits)
+	assert.NotEmpty(t, capabilities[0].SupportedClocks)
+}
+
+func TestSynthTopology(t *testing.T) {
+	t.Parallel()
+
+	extras, err := extrasFrom(t, "extras:\n  nvlink:\n    - {gpu: 0, links: 2}\n    - {gpu: 2, links: 2}\n"+
+		"    - {gpu: 3, links: 1, down: [0]}\n")
+	require.NoError(t, err)
+
+	var reading collect.Reading
+
+	synthTopology([]string{"u0", "u1", "u2", "u3"}, extras, &reading)
+
+	links := map[string]string{}
+	for _, link := range reading.Extras.Topology.Links {
+		links[link.UUID+"/"+link.PeerUUID] = link.Link
+	}
+
+	assert.Len(t, links, 12, "every ordered pair of distinct GPUs has a link")
+	assert.Equal(t, nvidiasmi.TopologyLinkNVLink, links["u0/u2"])
+	assert.Equal(t, nvidiasmi.TopologyLinkNVLink, links["u2/u0"])
+	assert.Equal(t, nvidiasmi.TopologyLinkNode, links["u0/u1"], "GPU 1 has no NVLink")
+	assert.Equal(t, nvidiasmi.TopologyLinkSystem, links["u0/u3"], "GPU 3's only link is down")
+
+	assert.Equal(t, []collect.NUMAAffinity{
+		{UUID: "u0", Node: 0, CPUAffinity: "0-23"},
+		{UUID: "u1", Node: 0, CPUAffinity: "0-23"},
+		{UUID: "u2", Node: 1, CPUAffinity: "24-47"},
+		{UUID: "u3", Node: 1, CPUAffinity: "24-47"},
+	}, reading.Extras.Topology.NUMA)
+}
+
+func TestSynthMIGCapacity(t *testing.T) {
+	t.Parallel()
+
+	extras, err := extrasFrom(t, "extras:\n  mig:\n"+
+		"    - {gpu: 0, instances: [{gi: 2, profile: 3g.71gb}, {gi: 7, profile: 1g.18gb}]}\n"+
+		"    - {gpu: 1, instances: [{gi: 1, profile: 5g.90gb}]}\n")
+	require.NoError(t, err)
+
+	var reading collect.Reading
+
+	synthMIGCapacity("u0", extras.MIG[0], &reading)
+
+	remaining := map[string]int{}
+
+	for _, capacity := range reading.Extras.MIGProfiles {
+		require.NotNil(t, capacity.Remaining)
+		assert.Len(t, capacity.Placements, len(demoMIGProfiles[len(remaining)].starts))
+
+		remaining[capacity.Profile] = *capacity.Remaining
+	}
+
+	// the 3g instance takes slices 0-3 and the 1g one slice 4
+	assert.Equal(t, map[string]int{
+		"1g.18gb": 2, "1g.18gb+me": 1, "1g.35gb": 1,
+		"2g.35gb": 0, "3g.71gb": 0, "4g.71gb": 0, "7g.141gb": 0,
+	}, remaining)
+
+	var unknown collect.Reading
+
+	synthMIGCapacity("u1", extras.MIG[1], &unknown)
+	assert.Empty(t, unknown.Extras.MIGProfiles, "a profile outside the table cannot be placed")
+}
+
 func TestSynthMIGDepartedInstanceStartsOver(t *testing.T) {
 	t.Parallel()
 
@@ -322,6 +790,37 @@ func TestTickXIDsSeedsInitialOnce(t *testing.T) {
 	assert.Equal(t, uint64(2), counters[0].Count)
 }
 
+func TestDriverEventCountsFollowXIDs(t *testing.T) {
+	t.Parallel()
+
+	seed := int64(1)
+	backend := &Backend{rng: newDemoRand(&seed), xids: map[string]map[uint64]*xidStat{}}
+	extras, err := extrasFrom(t, `extras:
+  xids:
+    initial:
+      - {gpu: 0, xid: 79, count: 2}
+      - {gpu: 0, xid: 48, count: 1}
+      - {gpu: 1, xid: 13, count: 3}
+`)
+	require.NoError(t, err)
+
+	backend.tickXIDs([]string{"u0", "u1"}, extras, time.Unix(1000, 0))
+
+	assert.Equal(t, []collect.DriverEventCounter{
+		{UUID: "u0", Type: collect.DriverEventECCDoubleBit, Count: 1},
+		{UUID: "u0", Type: collect.DriverEventXID, Count: 3},
+		{UUID: "u1", Type: collect.DriverEventXID, Count: 3},
+	}, backend.DriverEventCounts())
+
+	// the log holds every synthesized event, each double-bit ECC XID
+	// followed by its ECC event
+	events := backend.RecentEvents(eventlog.Filter{UUIDs: []string{"u0"}})
+	require.Len(t, events, 4)
+	assert.Equal(t, uint64(48), events[2].XID)
+	assert.Equal(t, collect.DriverEventECCDoubleBit, events[3].Type)
+	assert.Len(t, backend.RecentEvents(eventlog.Filter{XIDs: []uint64{13}}), 3)
+}
+
 func TestTickXIDsCadenceAndCatchUpBound(t *testing.T) {
 	t.Parallel()
 
@@ -491,8 +990,11 @@ func TestReconcileRejectsOutOfRangeIndexes(t *testing.T) {
 	t.Parallel()
 
 	for name, doc := range map[string]string{
-		"mig gpu": "gpus: 2\nextras:\n  mig:\n    - {gpu: 5, instances: [{gi: 1, profile: 1g.18gb}]}\n",
-		"xid gpu": "extras:\n  xids:\n    initial: [{gpu: 3, xid: 79, count: 1}]\n",
+		"mig gpu":            "gpus: 2\nextras:\n  mig:\n    - {gpu: 5, instances: [{gi: 1, profile: 1g.18gb}]}\n",
+		"xid gpu":            "extras:\n  xids:\n    initial: [{gpu: 3, xid: 79, count: 1}]\n",
+		"video sessions gpu": "extras:\n  video-sessions:\n    - {gpu: 3, encoder: 1}\n",
+		"mps gpu":            "extras:\n  mps:\n    - {gpu: 2, clients: [{}]}\n",
+		"nvlink gpu":         "extras:\n  nvlink:\n    - {gpu: 2, links: 4}\n",
 	} {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
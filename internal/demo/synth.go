@@ -3,12 +3,16 @@ package demo
 import (
 	"crypto/sha256"
 	"fmt"
+	"math"
 	"math/rand"
+	"slices"
 	"sort"
 	"strconv"
 	"time"
 
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/eventlog"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
 )
 
 // demoRand is the seeded source behind every synthesized draw. One source,
@@ -113,6 +117,8 @@ func (b *Backend) synthMIG(uuids []string, extras *extrasConfig, reading *collec
 
 		parent := uuids[gpu.GPU]
 
+		synthMIGCapacity(parent, gpu, reading)
+
 		for _, instance := range gpu.Instances {
 			memory := b.migMemory(instance)
 
@@ -148,6 +154,79 @@ func (b *Backend) synthMIG(uuids []string, extras *extrasConfig, reading *collec
 	}
 }
 
+// demoMIGProfile is one GPU instance profile of the simulated H200: how many
+// instances the empty GPU hosts, and the memory slice ranges they can take.
+type demoMIGProfile struct {
+	name   string
+	total  int
+	starts []int
+	size   int
+}
+
+// demoMIGProfiles is the H200's GPU instance profile table, in the driver's
+// profile id order.
+//
+//nolint:gochecknoglobals // lookup table
+var demoMIGProfiles = []demoMIGProfile{
+	{name: "1g.18gb", total: 7, starts: []int{0, 1, 2, 3, 4, 5, 6}, size: 1},
+	{name: "2g.35gb", total: 3, starts: []int{0, 2, 4}, size: 2},
+	{name: "3g.71gb", total: 2, starts: []int{0, 4}, size: 4},
+	{name: "4g.71gb", total: 1, starts: []int{0}, size: 4},
+	{name: "7g.141gb", total: 1, starts: []int{0}, size: 8},
+	{name: "1g.18gb+me", total: 1, starts: []int{0, 1, 2, 3, 4, 5, 6}, size: 1},
+	{name: "1g.35gb", total: 4, starts: []int{0, 2, 4, 6}, size: 2},
+}
+
+// synthMIGCapacity reports the H200 profile table's capacity beside the
+// configured instances, placed in config order at the first free range of
+// their profile. A topology the table
+// cannot place (an unknown profile, or more instances than fit) reports no
+// capacity rather than counts a real GPU could never show.
+func synthMIGCapacity(parent string, gpu migGPUConfig, reading *collect.Reading) {
+	var used [8]bool
+
+	existing := map[string]int{}
+
+	for _, instance := range gpu.Instances {
+		index := slices.IndexFunc(demoMIGProfiles, func(p demoMIGProfile) bool { return p.name == instance.Profile })
+		if index < 0 {
+			return
+		}
+
+		profile := demoMIGProfiles[index]
+
+		start := slices.IndexFunc(profile.starts, func(start int) bool {
+			return !slices.Contains(used[start:start+profile.size], true)
+		})
+		if start < 0 || existing[profile.name] == profile.total {
+			return
+		}
+
+		for slice := range profile.size {
+			used[profile.starts[start]+slice] = true
+		}
+
+		existing[profile.name]++
+	}
+
+	for _, profile := range demoMIGProfiles {
+		capacity := collect.MIGProfileCapacity{ParentUUID: parent, Profile: profile.name, Total: profile.total}
+
+		free := 0
+
+		for _, start := range profile.starts {
+			capacity.Placements = append(capacity.Placements, collect.MIGPlacement{Start: start, Size: profile.size})
+
+			if !slices.Contains(used[start:start+profile.size], true) {
+				free++
+			}
+		}
+
+		capacity.Remaining = new(min(free, profile.total-existing[profile.name]))
+		reading.Extras.MIGProfiles = append(reading.Extras.MIGProfiles, capacity)
+	}
+}
+
 // migMemory synthesizes one GPU instance's memory, upholding the
 // used + free + reserved = total invariant.
 func (b *Backend) migMemory(instance migInstanceConfig) *collect.MIGMemory {
@@ -255,8 +334,17 @@ func (b *Backend) jitteredInterval(mean time.Duration) time.Duration {
 	return time.Duration(float64(mean) * (0.5 + b.rng.src.Float64()))
 }
 
-// bumpXID folds events into the accumulator.
+// bumpXID folds events into the accumulator and logs them, each XID 48
+// followed by its double-bit ECC event like the driver delivers it.
 func (b *Backend) bumpXID(uuid string, xid, count uint64, at time.Time) {
+	for range min(count, eventlog.Capacity) {
+		b.recent.Add(eventlog.Event{Time: at, UUID: uuid, Type: collect.DriverEventXID, XID: xid, Data: xid})
+
+		if xid == demoDoubleBitECCXID {
+			b.recent.Add(eventlog.Event{Time: at, UUID: uuid, Type: collect.DriverEventECCDoubleBit})
+		}
+	}
+
 	perGPU := b.xids[uuid]
 	if perGPU == nil {
 		perGPU = map[uint64]*xidStat{}
@@ -273,6 +361,665 @@ func (b *Backend) bumpXID(uuid string, xid, count uint64, at time.Time) {
 	stat.last = at
 }
 
+// demoEncoderShapes are the codec and resolution pairs the synthesized
+// encoder sessions cycle through.
+//
+//nolint:gochecknoglobals // static default table
+var demoEncoderShapes = []struct{ codec, resolution string }{
+	{codec: "h264", resolution: "1920x1080"},
+	{codec: "hevc", resolution: "3840x2160"},
+	{codec: "av1", resolution: "2560x1440"},
+}
+
+// demoSessionPIDBase keeps the synthesized session pids clear of the fake's
+// compute processes.
+const demoSessionPIDBase = 30000
+
+// synthVideoSessions builds the configured session load. Every served GPU
+// reports both kinds, empty unless configured, like a real GPU with NVENC
+// and NvFBC. Session ids and pids are stable across cycles, so the series
+// persist like long-running transcodes; frame rate and latency jitter
+// around typical values, whole numbers as the driver reports them.
+func (b *Backend) synthVideoSessions(uuids []string, extras *extrasConfig, reading *collect.Reading) {
+	loads := map[int]videoSessionsConfig{}
+	for _, gpu := range extras.VideoSessions {
+		loads[gpu.GPU] = gpu
+	}
+
+	session := func(gpu, idx int, codec, resolution string, fps, latencyUs rangeCfg) collect.VideoSession {
+		return collect.VideoSession{
+			ID:                    strconv.Itoa(idx + 1),
+			PID:                   strconv.Itoa(demoSessionPIDBase + gpu*100 + idx),
+			Codec:                 codec,
+			Resolution:            resolution,
+			AverageFPS:            math.Round(b.rng.draw(fps)),
+			AverageLatencySeconds: math.Round(b.rng.draw(latencyUs)) / 1e6,
+		}
+	}
+
+	for gpu, uuid := range uuids {
+		load := loads[gpu]
+
+		encoder := make([]collect.VideoSession, 0, load.Encoder)
+		for idx := range load.Encoder {
+			shape := demoEncoderShapes[idx%len(demoEncoderShapes)]
+			encoder = append(encoder, session(gpu, idx, shape.codec, shape.resolution,
+				rangeCfg{Min: 55, Max: 60}, rangeCfg{Min: 2000, Max: 8000}))
+		}
+
+		fbc := make([]collect.VideoSession, 0, load.FBC)
+		for idx := range load.FBC {
+			fbc = append(fbc, session(gpu, load.Encoder+idx, "", "1920x1080",
+				rangeCfg{Min: 30, Max: 60}, rangeCfg{Min: 10000, Max: 20000}))
+		}
+
+		reading.Extras.VideoSessions = append(reading.Extras.VideoSessions,
+			collect.VideoSessions{UUID: uuid, Type: collect.VideoSessionEncoder, Sessions: encoder},
+			collect.VideoSessions{UUID: uuid, Type: collect.VideoSessionFBC, Sessions: fbc})
+	}
+}
+
+// The synthesized MPS pids, clear of the fake's process pids and of the
+// video session ones: one control daemon, one server per GPU, and the
+// clients numbered per GPU.
+const (
+	demoMPSControlPID    = 1190
+	demoMPSServerPIDBase = 1200
+	demoMPSClientPIDBase = 40000
+)
+
+// synthMPS builds the configured MPS topologies: one control daemon for the
+// node, a server on every GPU with a topology, and its clients with their
+// limits. When the cycle listed processes, the server and the clients join
+// that list (the server holds a context of its own, like on real hardware),
+// so the sharing series join with the per-process ones. Pids and limits are
+// stable across cycles; only the clients' memory use jitters, within their
+// pinned limit.
+func (b *Backend) synthMPS(uuids []string, extras *extrasConfig, reading *collect.Reading) {
+	if len(extras.MPS) == 0 {
+		return
+	}
+
+	sharing := collect.MPSSharing{ControlDaemonPIDs: []string{strconv.Itoa(demoMPSControlPID)}}
+
+	for _, gpu := range extras.MPS {
+		if gpu.GPU >= len(uuids) {
+			continue
+		}
+
+		uuid := uuids[gpu.GPU]
+		serverPID := strconv.Itoa(demoMPSServerPIDBase + gpu.GPU)
+
+		sharing.Servers = append(sharing.Servers, collect.MPSServer{UUID: uuid, PID: serverPID})
+
+		if reading.AppsSuccess {
+			reading.Apps = append(reading.Apps, nvidiasmi.ComputeApp{
+				GPUUUID: uuid, PID: serverPID, ProcessName: "nvidia-cuda-mps-server", UsedMemory: "30 MiB",
+			})
+		}
+
+		for idx, cfg := range gpu.Clients {
+			client := collect.MPSClient{UUID: uuid, PID: strconv.Itoa(demoMPSClientPIDBase + gpu.GPU*100 + idx)}
+
+			if cfg.ActiveThreadPercentage > 0 {
+				ratio := cfg.ActiveThreadPercentage / 100
+				client.ActiveThreadRatio = &ratio
+			}
+
+			usedMiB := math.Round(b.rng.draw(rangeCfg{Min: 1024, Max: 4096}))
+
+			if cfg.PinnedMemoryLimitBytes > 0 {
+				limit := cfg.PinnedMemoryLimitBytes
+				client.PinnedMemoryLimitBytes = &limit
+				usedMiB = math.Min(usedMiB, math.Floor(float64(limit)/nvidiasmi.UsedMemoryMultiplier))
+			}
+
+			sharing.Clients = append(sharing.Clients, client)
+
+			if reading.AppsSuccess {
+				reading.Apps = append(reading.Apps, nvidiasmi.ComputeApp{
+					GPUUUID: uuid, PID: client.PID, ProcessName: "python3",
+					UsedMemory: strconv.FormatFloat(usedMiB, 'f', 0, 64) + " MiB",
+				})
+			}
+		}
+	}
+
+	reading.Extras.MPS = sharing
+}
+
+// Synthesized NVLink remote bus ids: NVSwitch ports on one bus range, peer
+// GPUs on another.
+const (
+	demoNVLinkSwitchBus = 0xc0
+	demoNVLinkGPUBus    = 0x40
+)
+
+// synthNVLink builds the configured links. Down links report only their
+// state, like on real hardware; active ones carry a stable far end and
+// counters that grow every cycle: payload in the gigabytes, CRC flit errors
+// occasionally, the rarer error kinds almost never.
+func (b *Backend) synthNVLink(uuids []string, extras *extrasConfig, reading *collect.Reading) {
+	live := map[string]bool{}
+
+	counter := func(v float64) *float64 {
+		return &v
+	}
+
+	for _, gpu := range extras.NVLink {
+		if gpu.GPU >= len(uuids) {
+			continue
+		}
+
+		uuid := uuids[gpu.GPU]
+
+		bus := demoNVLinkSwitchBus
+		if gpu.Remote == collect.NVLinkRemoteGPU {
+			bus = demoNVLinkGPUBus
+		}
+
+		for link := range gpu.Links {
+			entry := collect.NVLink{UUID: uuid, Link: strconv.Itoa(link), Active: !slices.Contains(gpu.Down, link)}
+			if !entry.Active {
+				reading.Extras.NVLink = append(reading.Extras.NVLink, entry)
+
+				continue
+			}
+
+			key := uuid + "/" + entry.Link
+			live[key] = true
+
+			state := b.nvlink[key]
+			if state == nil {
+				state = &nvlinkState{}
+				b.nvlink[key] = state
+			}
+
+			state.txBytes += math.Round(b.rng.draw(rangeCfg{Min: 1e9, Max: 8e9}))
+			state.rxBytes += math.Round(b.rng.draw(rangeCfg{Min: 1e9, Max: 8e9}))
+			state.crcFlit += math.Floor(b.rng.draw(rangeCfg{Min: 0, Max: 2}))
+			state.crcData += math.Floor(b.rng.draw(rangeCfg{Min: 0, Max: 1.1}))
+			state.replays += math.Floor(b.rng.draw(rangeCfg{Min: 0, Max: 1.05}))
+			state.recoveries += math.Floor(b.rng.draw(rangeCfg{Min: 0, Max: 1.01}))
+
+			entry.Version = gpu.Version
+			entry.RemoteBusID = fmt.Sprintf("00000000:%02X:00.0", bus+link)
+			entry.RemoteDeviceType = gpu.Remote
+			entry.TXBytes = counter(state.txBytes)
+			entry.RXBytes = counter(state.rxBytes)
+			entry.CRCFlitErrors = counter(state.crcFlit)
+			entry.CRCDataErrors = counter(state.crcData)
+			entry.ReplayErrors = counter(state.replays)
+			entry.RecoveryErrors = counter(state.recoveries)
+
+			reading.Extras.NVLink = append(reading.Extras.NVLink, entry)
+		}
+	}
+
+	// a link that left the config, or went down, starts over
+	for key := range b.nvlink {
+		if !live[key] {
+			delete(b.nvlink, key)
+		}
+	}
+}
+
+// synthGPM builds the whole-GPU profiling readings of every GPU outside the
+// configured MIG topology, like the real backend, which samples GPUs in MIG
+// mode per GPU instance instead. The first cycle that sees a GPU serves
+// nothing for it (the sample pair rule); NVLink traffic is zero on GPUs
+// without configured links.
+func (b *Backend) synthGPM(uuids []string, extras *extrasConfig, reading *collect.Reading) {
+	skipped := map[int]bool{}
+	for _, gpu := range extras.MIG {
+		skipped[gpu.GPU] = true
+	}
+
+	nvlinked := map[int]bool{}
+	for _, gpu := range extras.NVLink {
+		nvlinked[gpu.GPU] = true
+	}
+
+	value := func(rng rangeCfg) *float64 {
+		v := b.rng.draw(rng)
+
+		return &v
+	}
+
+	live := map[string]bool{}
+
+	for index, uuid := range uuids {
+		if skipped[index] {
+			continue
+		}
+
+		live[uuid] = true
+
+		if !b.seenGPM[uuid] {
+			b.seenGPM[uuid] = true
+
+			continue
+		}
+
+		nvlinkRate := rangeCfg{}
+		if nvlinked[index] {
+			nvlinkRate = rangeCfg{Min: 5e9, Max: 4e10}
+		}
+
+		reading.Extras.GPM = append(reading.Extras.GPM, collect.GPMProfile{
+			UUID:                   uuid,
+			SMActivityRatio:        value(rangeCfg{Min: 0.55, Max: 0.95}),
+			SMOccupancyRatio:       value(rangeCfg{Min: 0.25, Max: 0.6}),
+			TensorActivityRatio:    value(rangeCfg{Min: 0.2, Max: 0.5}),
+			DRAMActivityRatio:      value(rangeCfg{Min: 0.3, Max: 0.7}),
+			FP64ActivityRatio:      value(rangeCfg{Min: 0, Max: 0.02}),
+			FP32ActivityRatio:      value(rangeCfg{Min: 0.1, Max: 0.3}),
+			FP16ActivityRatio:      value(rangeCfg{Min: 0.05, Max: 0.2}),
+			NVLinkTXBytesPerSecond: value(nvlinkRate),
+			NVLinkRXBytesPerSecond: value(nvlinkRate),
+			PCIeTXBytesPerSecond:   value(extras.PCIe.TXBytesPerSecond),
+			PCIeRXBytesPerSecond:   value(extras.PCIe.RXBytesPerSecond),
+		})
+	}
+
+	// a GPU that left the table, or went into MIG mode, starts over
+	for uuid := range b.seenGPM {
+		if !live[uuid] {
+			delete(b.seenGPM, uuid)
+		}
+	}
+}
+
+// synthPCIeErrors grows every GPU's PCIe error counters by its configured
+// rates, deterministically and without drawing from the random source. GPUs
+// without an entry keep healthy links: their counters are reported, at zero.
+func (b *Backend) synthPCIeErrors(uuids []string, extras *extrasConfig, reading *collect.Reading) {
+	rates := make(map[int]pcieErrorsConfig, len(extras.PCIeErrors))
+	for _, gpu := range extras.PCIeErrors {
+		rates[gpu.GPU] = gpu
+	}
+
+	counter := func(v float64) *float64 {
+		return &v
+	}
+
+	for idx, uuid := range uuids {
+		state := b.pcieErrors[uuid]
+		if state == nil {
+			state = &pcieErrorState{}
+			b.pcieErrors[uuid] = state
+		}
+
+		rate := rates[idx]
+		state.replays += float64(rate.Replays)
+		state.rollovers += float64(rate.ReplayRollovers)
+		state.correctable += float64(rate.Correctable)
+		state.uncorrectable += float64(rate.Uncorrectable)
+		state.fatal += float64(rate.Fatal)
+
+		reading.Extras.PCIeErrors = append(reading.Extras.PCIeErrors, collect.PCIeErrorCounters{
+			UUID:                uuid,
+			Replays:             counter(state.replays),
+			ReplayRollovers:     counter(state.rollovers),
+			CorrectableErrors:   counter(state.correctable),
+			UncorrectableErrors: counter(state.uncorrectable),
+			FatalErrors:         counter(state.fatal),
+		})
+	}
+}
+
+// synthFans builds the configured fans. Every fan is asked for the same
+// speed; a stalled one reports none while keeping its target, the way a
+// failing fan shows on real hardware.
+func synthFans(uuids []string, extras *extrasConfig, reading *collect.Reading) {
+	ratio := func(v float64) *float64 {
+		return &v
+	}
+
+	for _, gpu := range extras.Fans {
+		if gpu.GPU >= len(uuids) {
+			continue
+		}
+
+		for fan := range gpu.Count {
+			speed := gpu.Speed
+			if slices.Contains(gpu.Stalled, fan) {
+				speed = 0
+			}
+
+			reading.Extras.Fans = append(reading.Extras.Fans, collect.Fan{
+				UUID:             uuids[gpu.GPU],
+				Fan:              strconv.Itoa(fan),
+				SpeedRatio:       ratio(speed / 100),
+				TargetSpeedRatio: ratio(gpu.Speed / 100),
+				Policy:           gpu.Policy,
+			})
+		}
+	}
+}
+
+// The demo's confidential computing readings: an H200's framebuffer split
+// the driver's way, nearly all of it protected, and a key rotation threshold
+// within the driver's accepted range.
+const (
+	demoProtectedMemoryBytes         = 140 << 30
+	demoUnprotectedMemoryBytes       = 256 << 20
+	demoKeyRotationAttackerAdvantage = 60
+)
+
+// synthConfComputing builds the configured confidential computing state on
+// an AMD SEV-SNP host with capable GPUs. Only GPUs outside the off mode
+// report a memory split, the way a GPU without confidential computing has no
+// protected region to carve out.
+func synthConfComputing(uuids []string, extras *extrasConfig, reading *collect.Reading) {
+	value := func(v float64) *float64 {
+		return &v
+	}
+
+	cfg := extras.ConfidentialComputing
+
+	ready := 0.0
+	if cfg.Ready {
+		ready = 1
+	}
+
+	state := collect.ConfidentialComputing{
+		CPUCapability:                "amd_sev_snp",
+		GPUCapability:                "capable",
+		Mode:                         cfg.Mode,
+		Environment:                  "prod",
+		MultiGPUMode:                 "none",
+		Ready:                        &ready,
+		KeyRotationAttackerAdvantage: value(demoKeyRotationAttackerAdvantage),
+	}
+
+	for _, uuid := range uuids {
+		gpu := collect.ConfidentialComputingGPU{UUID: uuid, Mode: cfg.Mode}
+		if cfg.Mode != collect.CCModeOff {
+			gpu.ProtectedMemoryBytes = value(demoProtectedMemoryBytes)
+			gpu.UnprotectedMemoryBytes = value(demoUnprotectedMemoryBytes)
+		}
+
+		state.GPUs = append(state.GPUs, gpu)
+	}
+
+	reading.Extras.ConfidentialComputing = state
+}
+
+// demoVGPUFramebufferBytes is the framebuffer the first demo vGPU's guest
+// has in use; every further instance uses another such slice more.
+const demoVGPUFramebufferBytes = 6 << 30
+
+// synthVGPUs builds the configured vGPU instances, each in a VM of its own.
+// Their load is fixed per instance rather than drawn, staggered so the
+// instances tell apart on a dashboard.
+func synthVGPUs(uuids []string, extras *extrasConfig, reading *collect.Reading) {
+	value := func(v float64) *float64 {
+		return &v
+	}
+
+	for _, gpu := range extras.VGPU {
+		if gpu.GPU >= len(uuids) {
+			continue
+		}
+
+		host := collect.VGPUHost{UUID: uuids[gpu.GPU], Instances: []collect.VGPUInstance{}}
+
+		for idx := range gpu.Count {
+			load := float64(idx%4+1) / 10
+
+			host.Instances = append(host.Instances, collect.VGPUInstance{
+				UUID:                 vgpuUUID(host.UUID, idx),
+				VMID:                 fmt.Sprintf("demo-vm-%d-%d", gpu.GPU, idx),
+				Type:                 gpu.Type,
+				Class:                "Compute",
+				FramebufferUsedBytes: value(float64(idx+1) * demoVGPUFramebufferBytes),
+				EncoderSessions:      value(0),
+				SMRatio:              value(2 * load),
+				MemoryRatio:          value(load),
+				EncoderRatio:         value(0),
+				DecoderRatio:         value(0),
+			})
+		}
+
+		reading.Extras.VGPUs = append(reading.Extras.VGPUs, host)
+	}
+}
+
+// demoC2CLinkBandwidthBytesPerSecond is the speed of every active demo C2C
+// link, a GH200's.
+const demoC2CLinkBandwidthBytesPerSecond = 44.712e9
+
+// demoModuleEnergyFactor scales a GPU's energy counter into its module's:
+// the Grace CPU and its memory draw on top of the GPU.
+const demoModuleEnergyFactor = 1.4
+
+// synthSuperchips builds the configured superchips. The module energy
+// follows the GPU's own counter, so it grows with the configured power
+// draw, and the C2C links stay error free. The GPU memory NUMA nodes follow
+// one CPU node per GPU, as on a multi-superchip node.
+func synthSuperchips(uuids []string, extras *extrasConfig, reading *collect.Reading) {
+	value := func(v float64) *float64 {
+		return &v
+	}
+
+	energy := map[string]float64{}
+	for _, counter := range reading.Extras.Energy {
+		energy[counter.UUID] = counter.Joules
+	}
+
+	for _, gpu := range extras.Superchip {
+		if gpu.GPU >= len(uuids) {
+			continue
+		}
+
+		uuid := uuids[gpu.GPU]
+		superchip := collect.Superchip{
+			UUID:               uuid,
+			Links:              value(float64(gpu.Links)),
+			ModuleEnergyJoules: value(energy[uuid] * demoModuleEnergyFactor),
+			MemoryNUMANode:     value(float64(len(uuids) + gpu.GPU)),
+		}
+
+		for link := range gpu.Links {
+			entry := collect.C2CLink{
+				Link:                   strconv.Itoa(link),
+				Up:                     value(1),
+				CRCErrors:              value(0),
+				ReplayErrors:           value(0),
+				BackToBackReplayErrors: value(0),
+				LowPower:               value(0),
+			}
+
+			if slices.Contains(gpu.Down, link) {
+				// the driver reports no bandwidth for an inactive link
+				entry.Up = value(0)
+				entry.LowPower = value(1)
+			} else {
+				entry.MaxBandwidthBytesPerSecond = value(demoC2CLinkBandwidthBytesPerSecond)
+			}
+
+			superchip.C2CLinks = append(superchip.C2CLinks, entry)
+		}
+
+		reading.Extras.Superchips = append(reading.Extras.Superchips, superchip)
+	}
+}
+
+// demoTemperatureThresholds are the thresholds every demo GPU reports, in the
+// real backend's order: a datacenter GPU's driver limits plus the acoustic
+// target range of a workstation board, so the whole family is populated.
+//
+//nolint:gochecknoglobals // lookup table
+var demoTemperatureThresholds = []struct {
+	kind    string
+	celsius float64
+}{
+	{nvidiasmi.TemperatureThresholdShutdown, 92},
+	{nvidiasmi.TemperatureThresholdSlowdown, 89},
+	{nvidiasmi.TemperatureThresholdMemoryMax, 95},
+	{nvidiasmi.TemperatureThresholdGPUMax, 87},
+	{nvidiasmi.TemperatureThresholdAcousticMin, 60},
+	{nvidiasmi.TemperatureThresholdAcousticCurrent, 83},
+	{nvidiasmi.TemperatureThresholdAcousticMax, 90},
+}
+
+// synthTemperatureThresholds reports the fixed thresholds for every GPU;
+// like the real ones, they do not change between cycles.
+func synthTemperatureThresholds(uuids []string, reading *collect.Reading) {
+	for _, uuid := range uuids {
+		for _, threshold := range demoTemperatureThresholds {
+			reading.Extras.TemperatureThresholds = append(reading.Extras.TemperatureThresholds,
+				collect.TemperatureThreshold{UUID: uuid, Kind: threshold.kind, Celsius: threshold.celsius})
+		}
+	}
+}
+
+// demoSamplesPerWindow is how many samples each synthesized sample buffer
+// holds per cycle.
+const demoSamplesPerWindow = 10
+
+// synthDriverSamples draws each GPU's sample windows: power around the
+// table's draw with one spike above it, so the max stands out from the
+// average the way a real load's short peaks do, and utilization and clocks
+// from fixed ranges. The power samples accumulate into the GPU's histogram.
+func (b *Backend) synthDriverSamples(
+	uuids []string,
+	power map[string]float64,
+	extras *extrasConfig,
+	now time.Time,
+	reading *collect.Reading,
+) {
+	live := map[string]bool{}
+
+	draws := func(rng rangeCfg) []float64 {
+		values := make([]float64, demoSamplesPerWindow)
+		for i := range values {
+			values[i] = b.rng.draw(rng)
+		}
+
+		return values
+	}
+
+	for _, uuid := range uuids {
+		live[uuid] = true
+
+		histogram := b.powerHistograms[uuid]
+		if histogram == nil {
+			b.powerHistograms[uuid] = &collect.NativeHistogram{UUID: uuid}
+
+			continue
+		}
+
+		watts, ok := power[uuid]
+		if !ok {
+			watts = extras.EnergyFallbackPowerWatts
+		}
+
+		powerSamples := draws(rangeCfg{Min: watts * 0.85, Max: watts * 1.1})
+		powerSamples[len(powerSamples)-1] = b.rng.draw(rangeCfg{Min: watts * 1.3, Max: watts * 1.6})
+
+		for _, value := range powerSamples {
+			histogram.Observe(value, now)
+		}
+
+		reading.Extras.DriverSamples = append(reading.Extras.DriverSamples,
+			collect.NewDriverSampleWindow(uuid, collect.DriverSamplePower, powerSamples),
+			collect.NewDriverSampleWindow(uuid, collect.DriverSampleGPUUtilization,
+				draws(rangeCfg{Min: 0.4, Max: 0.95})),
+			collect.NewDriverSampleWindow(uuid, collect.DriverSampleMemoryUtilization,
+				draws(rangeCfg{Min: 0.2, Max: 0.6})),
+			collect.NewDriverSampleWindow(uuid, collect.DriverSampleGraphicsClock,
+				draws(rangeCfg{Min: 1.6e9, Max: 1.98e9})),
+			collect.NewDriverSampleWindow(uuid, collect.DriverSampleMemoryClock,
+				draws(rangeCfg{Min: 2.6e9, Max: 2.62e9})),
+		)
+		reading.Extras.PowerHistograms = append(reading.Extras.PowerHistograms, histogram.Clone())
+	}
+
+	// a GPU that left the table starts over
+	for uuid := range b.powerHistograms {
+		if !live[uuid] {
+			delete(b.powerHistograms, uuid)
+		}
+	}
+}
+
+// synthCapabilities reports a fixed capability description for every GPU,
+// the H200 of the default configuration's capture. Like the real backend's
+// cached descriptions, it never changes between cycles.
+func synthCapabilities(uuids []string, reading *collect.Reading) {
+	for _, uuid := range uuids {
+		cores := 16896.0
+		busWidth := 6144.0
+
+		reading.Extras.Capabilities = append(reading.Extras.Capabilities, collect.GPUCapabilities{
+			UUID:               uuid,
+			Architecture:       "Hopper",
+			Brand:              "NVIDIA",
+			BoardPartNumber:    "695-2G520-0280-001",
+			PCIeMaxGeneration:  5,
+			PCIeMaxWidth:       16,
+			Cores:              &cores,
+			MemoryBusWidthBits: &busWidth,
+			MaxClocks: []collect.CapabilityClock{
+				{Clock: collect.CapabilityClockGraphics, Hz: 1980e6},
+				{Clock: collect.CapabilityClockSM, Hz: 1980e6},
+				{Clock: collect.CapabilityClockMemory, Hz: 3201e6},
+				{Clock: collect.CapabilityClockVideo, Hz: 1545e6},
+			},
+			SupportedClocks: []collect.SupportedClockRange{
+				{PState: "P0", Clock: collect.CapabilityClockGraphics, MinHz: 345e6, MaxHz: 1980e6},
+				{PState: "P0", Clock: collect.CapabilityClockMemory, MinHz: 3201e6, MaxHz: 3201e6},
+			},
+		})
+	}
+}
+
+// demoCPUsPerNode is the CPU count of each simulated NUMA node, the
+// 24-thread node of the default configuration's capture.
+const demoCPUsPerNode = 24
+
+// synthTopology builds a two-node box: the first half of the GPUs is local to
+// NUMA node 0, the rest to node 1. GPUs with a configured active NVLink link
+// connect over NVLink, like GPUs behind one NVSwitch; other pairs cross the
+// host bridges of their node, or the SMP interconnect between nodes.
+func synthTopology(uuids []string, extras *extrasConfig, reading *collect.Reading) {
+	nvlinked := map[int]bool{}
+
+	for _, gpu := range extras.NVLink {
+		if gpu.Links > len(gpu.Down) {
+			nvlinked[gpu.GPU] = true
+		}
+	}
+
+	node := func(gpu int) int { return gpu * 2 / len(uuids) }
+
+	for i, uuid := range uuids {
+		reading.Extras.Topology.NUMA = append(reading.Extras.Topology.NUMA, collect.NUMAAffinity{
+			UUID:        uuid,
+			Node:        node(i),
+			CPUAffinity: fmt.Sprintf("%d-%d", node(i)*demoCPUsPerNode, (node(i)+1)*demoCPUsPerNode-1),
+		})
+
+		for j, peer := range uuids {
+			link := nvidiasmi.TopologyLinkSystem
+
+			switch {
+			case i == j:
+				continue
+			case nvlinked[i] && nvlinked[j]:
+				link = nvidiasmi.TopologyLinkNVLink
+			case node(i) == node(j):
+				link = nvidiasmi.TopologyLinkNode
+			}
+
+			reading.Extras.Topology.Links = append(reading.Extras.Topology.Links,
+				collect.TopologyLink{UUID: uuid, PeerUUID: peer, Link: link})
+		}
+	}
+}
+
 // migUUID derives a stable MIG device uuid from the identity tuple, like the
 // real driver's deterministic placement-derived uuids.
 func migUUID(parent string, gi, ci int, profile string) string {
@@ -281,6 +1028,14 @@ func migUUID(parent string, gi, ci int, profile string) string {
 	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
 }
 
+// vgpuUUID derives a stable vGPU uuid from the host GPU and the instance
+// index, the way migUUID derives the MIG ones.
+func vgpuUUID(host string, idx int) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s/vgpu/%d", host, idx))
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
 // ciProfile spells the compute-instance-qualified profile the way nvidia-smi
 // names sliced instances ("1c.3g.71gb") when a GPU instance hosts several
 // compute instances.
@@ -303,6 +1058,17 @@ func sortXIDCounters(counters []collect.XIDCounter) {
 	})
 }
 
+// sortDriverEventCounters orders the scrape output deterministically.
+func sortDriverEventCounters(counters []collect.DriverEventCounter) {
+	sort.Slice(counters, func(i, j int) bool {
+		if counters[i].UUID != counters[j].UUID {
+			return counters[i].UUID < counters[j].UUID
+		}
+
+		return counters[i].Type < counters[j].Type
+	})
+}
+
 // attributeApps assigns per-process readings to the configured MIG topology,
 // the way the real nvml backend attributes processes to their compute
 // instance. The mapping is stable: a process keeps its instance across
@@ -0,0 +1,55 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultProcRoot is where the process information lives. Outside the
+// exporter's own PID namespace the host's processes are invisible, which is
+// why per-process metrics in a container need the host PID namespace anyway.
+const DefaultProcRoot = "/proc"
+
+// containerIDPattern matches a container id as one cgroup path segment.
+// Docker names the cgroup after the full 64-character id under every layout
+// it uses: "/docker/<id>" (cgroupfs driver), "/system.slice/docker-<id>.scope"
+// (systemd driver), and those same paths behind "/.." prefixes when read from
+// inside another cgroup namespace.
+var containerIDPattern = regexp.MustCompile(`^(?:[a-z-]+-)?([0-9a-f]{64})(?:\.scope)?$`)
+
+// ContainerID returns the id of the container running the process, read from
+// its cgroup membership under procRoot, or "" when the process runs outside
+// any container. The id is only a candidate: other container runtimes name
+// their cgroups the same way, so whether Docker owns it is the daemon's call.
+func ContainerID(procRoot, pid string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(procRoot, pid, "cgroup"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the cgroup membership of pid %s: %w", pid, err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path; the path may contain
+		// colons itself, the first two never do
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		// the innermost match wins: a container nested in another is
+		// attributed to the one actually running the process
+		segments := strings.Split(fields[2], "/")
+		for i := len(segments) - 1; i >= 0; i-- {
+			if match := containerIDPattern.FindStringSubmatch(segments[i]); match != nil {
+				return match[1], nil
+			}
+		}
+	}
+
+	return "", nil
+}
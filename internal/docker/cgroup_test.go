@@ -0,0 +1,84 @@
+package docker_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/docker"
+)
+
+// writeCgroup fakes /proc/<pid>/cgroup under procRoot.
+func writeCgroup(t *testing.T, procRoot, pid, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(procRoot, pid), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(procRoot, pid, "cgroup"), []byte(content), 0o600))
+}
+
+func TestContainerID(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "cgroup v2 with the systemd driver",
+			content: "0::/system.slice/docker-" + trainerID + ".scope\n",
+			want:    trainerID,
+		},
+		{
+			name: "cgroup v1 with the cgroupfs driver",
+			content: "12:memory:/docker/" + trainerID + "\n" +
+				"11:cpu,cpuacct:/docker/" + trainerID + "\n" +
+				"1:name=systemd:/docker/" + trainerID + "\n",
+			want: trainerID,
+		},
+		{
+			name:    "read from inside another cgroup namespace",
+			content: "0::/../../system.slice/docker-" + trainerID + ".scope\n",
+			want:    trainerID,
+		},
+		{
+			name:    "nested container",
+			content: "0::/docker/" + notebookID + "/docker/" + trainerID + "\n",
+			want:    trainerID,
+		},
+		{
+			name:    "containerd under kubernetes",
+			content: "0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-" + trainerID + ".scope\n",
+			want:    trainerID,
+		},
+		{
+			name:    "host process",
+			content: "0::/user.slice/user-1000.slice/session-3.scope\n",
+		},
+		{
+			name:    "id-shaped but truncated",
+			content: "0::/docker/" + trainerID[:63] + "\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			procRoot := t.TempDir()
+			writeCgroup(t, procRoot, "4242", tc.content)
+
+			containerID, err := docker.ContainerID(procRoot, "4242")
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, containerID)
+		})
+	}
+}
+
+func TestContainerIDGoneProcess(t *testing.T) {
+	t.Parallel()
+
+	_, err := docker.ContainerID(t.TempDir(), "4242")
+	require.Error(t, err)
+}
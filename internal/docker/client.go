@@ -0,0 +1,123 @@
+// Package docker attributes GPU processes to the Docker containers running
+// them: a process's cgroup membership names its container, and the Docker
+// Engine API, reached over its unix socket, names the container.
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultSocket is where the Docker daemon listens by default.
+const DefaultSocket = "/var/run/docker.sock"
+
+// ComposeProjectLabel is the container label Docker Compose records the
+// project name under.
+const ComposeProjectLabel = "com.docker.compose.project"
+
+const (
+	// maxInspectBytes bounds the inspect response read. The response carries
+	// the whole container configuration (environment, mounts, labels), which
+	// stays far below this in practice.
+	maxInspectBytes = 4 << 20
+	// maxErrorBytes bounds the error body kept for the error message.
+	maxErrorBytes = 512
+)
+
+// ErrNotFound reports that the daemon does not know the container: it exited
+// meanwhile, or it is not Docker's (a containerd or CRI-O container shares
+// the cgroup id shape).
+var ErrNotFound = errors.New("container not found")
+
+// Container is the identity of one container, as the daemon reports it.
+type Container struct {
+	ID string
+	// Name is the container name without the leading slash the API prints.
+	Name string
+	// Image is the image reference the container was created from, as the
+	// user gave it (for example "pytorch/pytorch:latest").
+	Image string
+	// ComposeProject is the Docker Compose project name, empty for a
+	// container Compose did not create.
+	ComposeProject string
+}
+
+// Client talks to the Docker Engine API over a unix socket. It is safe for
+// concurrent use.
+type Client struct {
+	http *http.Client
+}
+
+// NewClient builds a client for the daemon listening on socketPath. Nothing is
+// dialed until the first request.
+func NewClient(socketPath string) *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}
+
+	return &Client{http: &http.Client{Transport: transport}}
+}
+
+// inspectResponse is the part of the inspect response the client reads. The
+// fields match the API's PascalCase keys by name.
+type inspectResponse struct {
+	ID     string `json:"Id"` //nolint:tagliatelle // the Docker API's key
+	Name   string
+	Config struct {
+		Image  string
+		Labels map[string]string
+	}
+}
+
+// Inspect looks up one container by id. The request is unversioned, so the
+// daemon answers in its own API version: the fields read here are stable
+// across all of them.
+func (c *Client) Inspect(ctx context.Context, id string) (Container, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"http://docker/containers/"+url.PathEscape(id)+"/json", nil)
+	if err != nil {
+		return Container{}, fmt.Errorf("failed to build the inspect request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Container{}, fmt.Errorf("failed to reach the docker daemon: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return Container{}, ErrNotFound
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+
+		return Container{}, fmt.Errorf("docker daemon answered %s: %s",
+			resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var inspected inspectResponse
+
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxInspectBytes)).Decode(&inspected); err != nil {
+		return Container{}, fmt.Errorf("failed to decode the inspect response: %w", err)
+	}
+
+	return Container{
+		ID:             inspected.ID,
+		Name:           strings.TrimPrefix(inspected.Name, "/"),
+		Image:          inspected.Config.Image,
+		ComposeProject: inspected.Config.Labels[ComposeProjectLabel],
+	}, nil
+}
@@ -0,0 +1,115 @@
+package docker_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/docker"
+)
+
+const (
+	trainerID  = "4f1c0a6b9e2d7c3f8a5b1e0d6c9f2a7b3e8d1c4f0a6b9e2d7c3f8a5b1e0d6c9f"
+	notebookID = "9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b"
+)
+
+// fakeDaemon serves inspect responses keyed by container id on a unix socket
+// and counts the requests it answered.
+type fakeDaemon struct {
+	socket   string
+	requests atomic.Int64
+}
+
+func newFakeDaemon(t *testing.T, containers map[string]string) *fakeDaemon {
+	t.Helper()
+
+	daemon := &fakeDaemon{socket: filepath.Join(t.TempDir(), "docker.sock")}
+
+	listener, err := (&net.ListenConfig{}).Listen(t.Context(), "unix", daemon.socket)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		daemon.requests.Add(1)
+
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/containers/"), "/json")
+
+		body, ok := containers[id]
+		if !ok {
+			http.Error(w, `{"message":"No such container: `+id+`"}`, http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(body))
+	}))
+
+	_ = server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return daemon
+}
+
+const trainerJSON = `{
+	"Id": "` + trainerID + `",
+	"Name": "/trainer-1",
+	"State": {"Running": true},
+	"Config": {
+		"Image": "pytorch/pytorch:2.4.0-cuda12.1-cudnn9-runtime",
+		"Labels": {"com.docker.compose.project": "ml", "com.docker.compose.service": "trainer"}
+	}
+}`
+
+func TestClientInspect(t *testing.T) {
+	t.Parallel()
+
+	daemon := newFakeDaemon(t, map[string]string{
+		trainerID:  trainerJSON,
+		notebookID: `{"Id": "` + notebookID + `", "Name": "/notebook", "Config": {"Image": "jupyter/base"}}`,
+	})
+	client := docker.NewClient(daemon.socket)
+
+	container, err := client.Inspect(t.Context(), trainerID)
+	require.NoError(t, err)
+	assert.Equal(t, docker.Container{
+		ID:             trainerID,
+		Name:           "trainer-1",
+		Image:          "pytorch/pytorch:2.4.0-cuda12.1-cudnn9-runtime",
+		ComposeProject: "ml",
+	}, container)
+
+	container, err = client.Inspect(t.Context(), notebookID)
+	require.NoError(t, err)
+	assert.Empty(t, container.ComposeProject, "a container outside Compose has no project")
+
+	_, err = client.Inspect(t.Context(), strings.Repeat("0", 64))
+	require.ErrorIs(t, err, docker.ErrNotFound)
+}
+
+func TestClientInspectFailures(t *testing.T) {
+	t.Parallel()
+
+	_, err := docker.NewClient(filepath.Join(t.TempDir(), "missing.sock")).Inspect(t.Context(), trainerID)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, docker.ErrNotFound)
+
+	daemon := newFakeDaemon(t, map[string]string{trainerID: "{not json"})
+
+	_, err = docker.NewClient(daemon.socket).Inspect(t.Context(), trainerID)
+	require.ErrorContains(t, err, "decode")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err = docker.NewClient(daemon.socket).Inspect(ctx, trainerID)
+	require.ErrorIs(t, err, context.Canceled)
+}
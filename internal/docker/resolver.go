@@ -0,0 +1,109 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+// Resolver attributes the processes of a collection cycle to containers. It
+// keeps nothing between cycles: container names change on rename and ids are
+// never reused, so a cross-cycle cache would only serve stale names.
+type Resolver struct {
+	client   *Client
+	procRoot string
+	logger   *slog.Logger
+}
+
+// NewResolver builds a resolver reading process cgroups under procRoot
+// (DefaultProcRoot outside tests) and asking the daemon on socketPath.
+func NewResolver(socketPath, procRoot string, logger *slog.Logger) *Resolver {
+	return &Resolver{client: NewClient(socketPath), procRoot: procRoot, logger: logger}
+}
+
+// Attribute resolves one cycle's processes. Each pid and each container is
+// looked up once per call, however many GPUs or context types list it.
+// Processes outside any container, in containers the daemon does not know,
+// or gone before their cgroup could be read are left out. An unreachable or
+// failing daemon aborts the whole pass with an error instead of repeating the
+// failure for every container.
+func (r *Resolver) Attribute(ctx context.Context, apps []nvidiasmi.ComputeApp) ([]collect.ProcessContainer, error) {
+	containerIDs := make(map[string]string, len(apps))
+	// nil marks a container the daemon does not know
+	containers := map[string]*Container{}
+	emitted := make(map[string]bool, len(apps))
+
+	var result []collect.ProcessContainer
+
+	for _, app := range apps {
+		// a process holding several kinds of context is listed once per
+		// kind, but is attributed once per GPU
+		key := app.GPUUUID + "/" + app.PID
+		if emitted[key] {
+			continue
+		}
+
+		emitted[key] = true
+
+		containerID, ok := containerIDs[app.PID]
+		if !ok {
+			containerID = r.containerID(app.PID)
+			containerIDs[app.PID] = containerID
+		}
+
+		if containerID == "" {
+			continue
+		}
+
+		container, ok := containers[containerID]
+		if !ok {
+			inspected, err := r.client.Inspect(ctx, containerID)
+
+			switch {
+			case err == nil:
+				container = &inspected
+			case errors.Is(err, ErrNotFound):
+				r.logger.Debug("process runs in a container docker does not know",
+					"pid", app.PID, "container_id", containerID)
+			default:
+				return nil, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+			}
+
+			containers[containerID] = container
+		}
+
+		if container == nil {
+			continue
+		}
+
+		result = append(result, collect.ProcessContainer{
+			UUID:           app.GPUUUID,
+			PID:            app.PID,
+			ContainerID:    containerID,
+			Name:           container.Name,
+			Image:          container.Image,
+			ComposeProject: container.ComposeProject,
+		})
+	}
+
+	return result, nil
+}
+
+// containerID reads the process's container id, "" when it has none or its
+// cgroup cannot be read. The read fails routinely (the process exited since
+// the per-process query, or the exporter does not share the host PID
+// namespace), so it is logged at debug level only.
+func (r *Resolver) containerID(pid string) string {
+	containerID, err := ContainerID(r.procRoot, pid)
+	if err != nil {
+		r.logger.Debug("failed to read the container of a process", "pid", pid, "err", err)
+
+		return ""
+	}
+
+	return containerID
+}
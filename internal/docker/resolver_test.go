@@ -0,0 +1,74 @@
+package docker_test
+
+import (
+	"testing"
+
+	"github.com/neilotoole/slogt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/docker"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+func TestResolverAttribute(t *testing.T) {
+	t.Parallel()
+
+	daemon := newFakeDaemon(t, map[string]string{trainerID: trainerJSON})
+
+	procRoot := t.TempDir()
+	writeCgroup(t, procRoot, "100", "0::/system.slice/docker-"+trainerID+".scope\n")
+	writeCgroup(t, procRoot, "101", "0::/system.slice/docker-"+trainerID+".scope\n")
+	writeCgroup(t, procRoot, "200", "0::/user.slice/user-1000.slice/session-3.scope\n")
+	writeCgroup(t, procRoot, "300", "0::/kubepods.slice/cri-containerd-"+notebookID+".scope\n")
+
+	resolver := docker.NewResolver(daemon.socket, procRoot, slogt.New(t))
+
+	apps := []nvidiasmi.ComputeApp{
+		{GPUUUID: "gpu-a", PID: "100", Type: nvidiasmi.ProcessTypeCompute},
+		{GPUUUID: "gpu-a", PID: "100", Type: nvidiasmi.ProcessTypeGraphics},
+		{GPUUUID: "gpu-b", PID: "100"},
+		{GPUUUID: "gpu-b", PID: "101"},
+		{GPUUUID: "gpu-a", PID: "200"}, // a host process
+		{GPUUUID: "gpu-a", PID: "300"}, // a container docker does not own
+		{GPUUUID: "gpu-a", PID: "400"}, // exited since the per-process query
+	}
+
+	containers, err := resolver.Attribute(t.Context(), apps)
+	require.NoError(t, err)
+
+	trainer := func(uuid, pid string) collect.ProcessContainer {
+		return collect.ProcessContainer{
+			UUID: uuid, PID: pid, ContainerID: trainerID, Name: "trainer-1",
+			Image: "pytorch/pytorch:2.4.0-cuda12.1-cudnn9-runtime", ComposeProject: "ml",
+		}
+	}
+
+	assert.Equal(t, []collect.ProcessContainer{
+		trainer("gpu-a", "100"), trainer("gpu-b", "100"), trainer("gpu-b", "101"),
+	}, containers)
+	assert.Equal(t, int64(2), daemon.requests.Load(), "one lookup per container per cycle")
+
+	// the cache does not outlive the cycle
+	_, err = resolver.Attribute(t.Context(), apps)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), daemon.requests.Load())
+}
+
+func TestResolverAttributeDaemonDown(t *testing.T) {
+	t.Parallel()
+
+	procRoot := t.TempDir()
+	writeCgroup(t, procRoot, "100", "0::/docker/"+trainerID+"\n")
+
+	resolver := docker.NewResolver(procRoot+"/missing.sock", procRoot, slogt.New(t))
+
+	_, err := resolver.Attribute(t.Context(), []nvidiasmi.ComputeApp{{GPUUUID: "gpu-a", PID: "100"}})
+	require.Error(t, err)
+
+	// no container process, no daemon round trip to fail
+	containers, err := resolver.Attribute(t.Context(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, containers)
+}
@@ -0,0 +1,198 @@
+// Package eventlog keeps the most recent driver events for triage. The
+// counters tell that an XID happened; the log tells in which order the
+// events arrived, when, on which GPU and with which event data, which is
+// what working out an incident takes. It is served as JSON at /api/events.
+package eventlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+// Path is the HTTP path the log is served at.
+const Path = "/api/events"
+
+// Capacity is how many events a Ring keeps: the oldest is dropped when a new
+// one arrives beyond it. It bounds the memory an event storm can take, while
+// holding far more than an incident's worth of XIDs.
+const Capacity = 1000
+
+// Event is one observed driver event.
+type Event struct {
+	// Seq numbers the events a Ring received, from 1; a gap between two
+	// served events means the ones between were dropped or filtered out.
+	Seq uint64 `json:"seq"`
+	// Time is when the event was received (driver events carry no timestamp
+	// of their own).
+	Time time.Time `json:"time"`
+	// UUID is the GPU uuid, normalized like every uuid label, or "unknown".
+	UUID string `json:"uuid"`
+	// Type is one of the collect.DriverEvent* type labels.
+	Type string `json:"type"`
+	// XID is the XID code of an xid event.
+	XID uint64 `json:"xid,omitempty"`
+	// Data is the event data the driver delivered with an NVML event.
+	Data uint64 `json:"data,omitempty"`
+	// PCIBusID is the bus id a kernel log line names the GPU by.
+	PCIBusID string `json:"pciBusId,omitempty"`
+	// Message is the kernel log line an event was parsed from.
+	Message string `json:"message,omitempty"`
+}
+
+// Source serves the recent events matching a filter, oldest first.
+type Source interface {
+	RecentEvents(filter Filter) []Event
+}
+
+// Ring is a bounded log of the most recent events. The zero value is ready
+// to use and safe for concurrent use.
+type Ring struct {
+	mu     sync.Mutex
+	events []Event
+	// next is where the next event goes once the ring is full.
+	next int
+	seq  uint64
+	// capacity overrides Capacity, for tests.
+	capacity int
+}
+
+// Add appends an event, numbering it and dropping the oldest one when the
+// ring is full.
+func (r *Ring) Add(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	capacity := r.capacity
+	if capacity <= 0 {
+		capacity = Capacity
+	}
+
+	r.seq++
+	event.Seq = r.seq
+
+	if len(r.events) < capacity {
+		r.events = append(r.events, event)
+
+		return
+	}
+
+	r.events[r.next] = event
+	r.next = (r.next + 1) % capacity
+}
+
+// RecentEvents returns the events matching the filter, oldest first.
+func (r *Ring) RecentEvents(filter Filter) []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := []Event{}
+
+	for i := range r.events {
+		event := r.events[(r.next+i)%len(r.events)]
+		if filter.Match(event) {
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+// Filter selects events. An empty field matches every event; the values of
+// one field are alternatives.
+type Filter struct {
+	// UUIDs are the normalized GPU uuids to match.
+	UUIDs []string
+	// XIDs are the XID codes to match; a filter on them matches xid events
+	// alone.
+	XIDs []uint64
+	// Since matches the events received at or after it.
+	Since time.Time
+}
+
+// Match reports whether the event passes the filter.
+func (f Filter) Match(event Event) bool {
+	if len(f.UUIDs) > 0 && !slices.Contains(f.UUIDs, event.UUID) {
+		return false
+	}
+
+	if len(f.XIDs) > 0 && (event.XID == 0 || !slices.Contains(f.XIDs, event.XID)) {
+		return false
+	}
+
+	return !event.Time.Before(f.Since)
+}
+
+// ParseFilter reads a filter from the query parameters: uuid and xid, each
+// repeatable, and since, as an RFC 3339 time or Unix seconds. A uuid may
+// carry the "GPU-" prefix nvidia-smi prints.
+func ParseFilter(query url.Values) (Filter, error) {
+	var filter Filter
+
+	for _, uuid := range query["uuid"] {
+		filter.UUIDs = append(filter.UUIDs, nvidiasmi.NormalizeUUID(uuid))
+	}
+
+	for _, raw := range query["xid"] {
+		xid, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return Filter{}, fmt.Errorf("invalid xid %q", raw)
+		}
+
+		filter.XIDs = append(filter.XIDs, xid)
+	}
+
+	if raw := query.Get("since"); raw != "" {
+		since, err := parseTime(raw)
+		if err != nil {
+			return Filter{}, err
+		}
+
+		filter.Since = since
+	}
+
+	return filter, nil
+}
+
+// parseTime reads an RFC 3339 time or Unix seconds, fractions allowed.
+func parseTime(raw string) (time.Time, error) {
+	if since, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return since, nil
+	}
+
+	seconds, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %q: want an RFC 3339 time or Unix seconds", raw)
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), nil
+}
+
+// response is the JSON document the handler serves.
+type response struct {
+	Events []Event `json:"events"`
+}
+
+// Handler serves the source's events matching the request's filter as JSON.
+// A malformed filter is a 400.
+func Handler(source Source) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		filter, err := ParseFilter(req.URL.Query())
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		_ = json.NewEncoder(writer).Encode(response{Events: source.RecentEvents(filter)})
+	})
+}
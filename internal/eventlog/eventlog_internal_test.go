@@ -0,0 +1,112 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingKeepsTheMostRecentEvents(t *testing.T) {
+	t.Parallel()
+
+	ring := Ring{capacity: 3}
+	assert.Equal(t, []Event{}, ring.RecentEvents(Filter{}), "an empty log serves an empty list, not null")
+
+	start := time.Unix(1700000000, 0)
+
+	for i := range 5 {
+		ring.Add(Event{Time: start.Add(time.Duration(i) * time.Second), UUID: "gpu", Type: "xid", XID: uint64(i + 1)})
+	}
+
+	events := ring.RecentEvents(Filter{})
+	require.Len(t, events, 3)
+
+	for i, event := range events {
+		assert.Equal(t, uint64(i+3), event.Seq, "oldest first, the first two dropped")
+		assert.Equal(t, uint64(i+3), event.XID)
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	at := time.Unix(1700000000, 0)
+	xid := Event{Time: at, UUID: "gpu-a", Type: "xid", XID: 79}
+	ecc := Event{Time: at, UUID: "gpu-a", Type: "ecc_double_bit"}
+
+	assert.True(t, Filter{}.Match(xid))
+	assert.True(t, Filter{UUIDs: []string{"gpu-b", "gpu-a"}}.Match(xid))
+	assert.False(t, Filter{UUIDs: []string{"gpu-b"}}.Match(xid))
+	assert.True(t, Filter{XIDs: []uint64{48, 79}}.Match(xid))
+	assert.False(t, Filter{XIDs: []uint64{48}}.Match(xid))
+	assert.False(t, Filter{XIDs: []uint64{79}}.Match(ecc), "an xid filter matches xid events alone")
+	assert.True(t, Filter{Since: at}.Match(xid), "since is inclusive")
+	assert.False(t, Filter{Since: at.Add(time.Nanosecond)}.Match(xid))
+}
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	filter, err := ParseFilter(url.Values{
+		"uuid":  {"GPU-aaaa", "bbbb"},
+		"xid":   {"79", "48"},
+		"since": {"2023-11-14T22:13:20Z"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa", "bbbb"}, filter.UUIDs)
+	assert.Equal(t, []uint64{79, 48}, filter.XIDs)
+	assert.True(t, filter.Since.Equal(time.Unix(1700000000, 0)))
+
+	filter, err = ParseFilter(url.Values{"since": {"1700000000.5"}})
+	require.NoError(t, err)
+	assert.True(t, filter.Since.Equal(time.Unix(1700000000, 500000000)))
+
+	for _, query := range []url.Values{
+		{"xid": {"fallen"}},
+		{"xid": {"-1"}},
+		{"since": {"yesterday"}},
+	} {
+		_, err = ParseFilter(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	var ring Ring
+
+	at := time.Unix(1700000000, 0).UTC()
+	ring.Add(Event{Time: at, UUID: "gpu-a", Type: "xid", XID: 79, Data: 79})
+	ring.Add(Event{Time: at, UUID: "gpu-b", Type: "xid", XID: 13, Data: 13})
+
+	handler := Handler(&ring)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, Path+"?uuid=GPU-gpu-b", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t,
+		`{"events":[{"seq":2,"time":"2023-11-14T22:13:20Z","uuid":"gpu-b","type":"xid","xid":13,"data":13}]}`,
+		recorder.Body.String())
+
+	var decoded struct {
+		Events []Event `json:"events"`
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, Path+"?xid=48", nil))
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded))
+	assert.Empty(t, decoded.Events)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, Path+"?since=soon", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
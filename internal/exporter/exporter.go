@@ -16,6 +16,7 @@ import (
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/util"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/xidcatalog"
 )
 
 const DefaultPrefix = "nvidia_smi"
@@ -51,6 +52,16 @@ var computeAppLabels = []string{uuidLabel, "pid", "process_name"}
 // (opt-in: adding labels changes the series identity of a shipped family).
 var computeAppMIGLabels = []string{uuidLabel, "pid", "process_name", "gpu_instance_id", "compute_instance_id"}
 
+// processTypeLabel is appended to the per-process label sets when the typed
+// process query is on (opt-in for the same reason as the MIG labels).
+const processTypeLabel = "type"
+
+// processTypes are the values of the type label, zero-filled per GPU on the
+// process count.
+var processTypes = []string{
+	nvidiasmi.ProcessTypeCompute, nvidiasmi.ProcessTypeGraphics, nvidiasmi.ProcessTypeMPS,
+}
+
 // invalidNameCharRuns matches runs of characters that are not legal in a
 // classic Prometheus metric name. Matching whole runs keeps a legal
 // underscore a driver put in a field name untouched: collapsing those would
@@ -87,15 +98,69 @@ type Features struct {
 	// ComputeAppMIGLabels adds the MIG attribution labels to the
 	// per-process metrics (nvml backend, --collect.compute-apps-mig).
 	ComputeAppMIGLabels bool
+	// ComputeAppTypes adds graphics and MPS client processes to the
+	// per-process metrics and a type label telling them apart
+	// (--collect.compute-apps-types).
+	ComputeAppTypes bool
+	// ComputeAppUtilization enables the per-process engine utilization
+	// gauges (nvml backend, --collect.compute-apps-utilization).
+	ComputeAppUtilization bool
+	// ComputeAppContainers enables the per-process Docker container info
+	// series (exec and nvml backends, --collect.compute-apps-docker).
+	ComputeAppContainers bool
+	// ComputeAppMPS enables the MPS sharing families
+	// (--collect.compute-apps-mps).
+	ComputeAppMPS bool
 	// PCIeThroughput enables the per-GPU PCIe throughput gauges (nvml
 	// backend, --collect.pcie-throughput).
 	PCIeThroughput bool
 	// Energy enables the per-GPU cumulative energy counter (nvml backend).
 	Energy bool
+	// TemperatureThresholds enables the per-GPU temperature threshold
+	// families (exec and nvml backends, --collect.temperature-thresholds).
+	TemperatureThresholds bool
+	// PCIeErrors enables the per-GPU PCIe replay and AER error counters
+	// (exec and nvml backends, --collect.pcie-errors).
+	PCIeErrors bool
+	// Fans enables the per-fan families (nvml backend, --collect.fans).
+	Fans bool
+	// Capabilities enables the per-GPU static capability families (nvml
+	// backend, --collect.capabilities).
+	Capabilities bool
+	// Topology enables the GPU interconnect and NUMA affinity families
+	// (exec and nvml backends, --collect.topology).
+	Topology bool
+	// ConfidentialComputing enables the confidential computing families
+	// (nvml backend, --collect.confidential-computing).
+	ConfidentialComputing bool
+	// VGPU enables the per-vGPU-instance families of hypervisor GPUs (nvml
+	// backend, --collect.vgpu).
+	VGPU bool
+	// Superchip enables the C2C link, module energy and memory placement
+	// families of superchip GPUs (nvml backend, --collect.superchip).
+	Superchip bool
 	// MIG enables the per-MIG-instance metric families (nvml backend).
 	MIG bool
-	// XIDEvents enables the XID error counter families (nvml backend). The
-	// values come from the XIDSource passed to New, not from the snapshot.
+	// Accounting enables the completed-process counters read from the
+	// driver's accounting buffers (exec and nvml backends,
+	// --collect.accounting).
+	Accounting bool
+	// VideoSessions enables the encoder and NvFBC session families (nvml
+	// backend, --collect.video-sessions).
+	VideoSessions bool
+	// NVLink enables the per-link NVLink families (nvml backend,
+	// --collect.nvlink).
+	NVLink bool
+	// GPM enables the whole-GPU profiling families (nvml backend,
+	// --collect.gpm).
+	GPM bool
+	// DriverSamples enables the sub-interval sample families (nvml backend,
+	// --collect.driver-samples).
+	DriverSamples bool
+	// XIDEvents enables the XID error counter and driver event families
+	// (nvml backend, or exec with --collect.xid-log). The values come from
+	// the XIDSource and DriverEventSource passed to New, not from the
+	// snapshot.
 	XIDEvents bool
 }
 
@@ -107,6 +172,14 @@ type XIDSource interface {
 	XIDCounts() []collect.XIDCounter
 }
 
+// DriverEventSource serves the cumulative driver event counts, read at
+// scrape time like the XIDSource and for the same reason: ECC and power
+// source events cluster around the incidents that fail collections. The
+// implementation must be safe for concurrent use.
+type DriverEventSource interface {
+	DriverEventCounts() []collect.DriverEventCounter
+}
+
 // GPUExporter renders the latest collection as Prometheus metrics. It is
 // agnostic to how the reading is produced: the source may collect inline on
 // each scrape or serve a cached result from background collection.
@@ -125,14 +198,33 @@ type GPUExporter struct {
 	appMemoryDesc         *prometheus.Desc
 	appCountDesc          *prometheus.Desc
 	appsSuccessDesc       *prometheus.Desc
+	appUtilDescs          *appUtilDescs
+	appContainerDesc      *prometheus.Desc
+	mpsDescs              *mpsDescs
 	pcieTxDesc            *prometheus.Desc
 	pcieRxDesc            *prometheus.Desc
 	energyDesc            *prometheus.Desc
+	thresholdDescs        *temperatureThresholdDescs
+	pcieErrorDescs        *pcieErrorDescs
+	fanDescs              *fanDescs
+	capabilityDescs       *capabilityDescs
+	topologyDescs         *topologyDescs
+	confComputeDescs      *confComputeDescs
+	vgpuDescs             *vgpuDescs
+	superchipDescs        *superchipDescs
 	migDescs              *migDescs
+	accountingDescs       *accountingDescs
+	videoSessionDescs     *videoSessionDescs
+	nvlinkDescs           *nvlinkDescs
+	gpmDescs              *gpmDescs
+	driverSampleDescs     *driverSampleDescs
 	appMIGLabels          bool
+	appTypes              bool
 	xids                  XIDSource
 	xidCountDesc          *prometheus.Desc
 	xidTimestampDesc      *prometheus.Desc
+	driverEvents          DriverEventSource
+	driverEventDesc       *prometheus.Desc
 	logger                *slog.Logger
 	ctx                   context.Context //nolint:containedctx
 }
@@ -151,6 +243,255 @@ type migDescs struct {
 	tensorActivity   *prometheus.Desc
 	pcieTx           *prometheus.Desc
 	pcieRx           *prometheus.Desc
+	profileMax       *prometheus.Desc
+	profileRemaining *prometheus.Desc
+	placement        *prometheus.Desc
+}
+
+// appUtilDescs bundles the per-process utilization descriptors, nil as a
+// whole when the feature is off.
+type appUtilDescs struct {
+	sm      *prometheus.Desc
+	memory  *prometheus.Desc
+	encoder *prometheus.Desc
+	decoder *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (a *appUtilDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{a.sm, a.memory, a.encoder, a.decoder}
+}
+
+// mpsDescs bundles the MPS sharing descriptors, nil as a whole when the
+// feature is off.
+type mpsDescs struct {
+	controlDaemon     *prometheus.Desc
+	server            *prometheus.Desc
+	clientThreads     *prometheus.Desc
+	clientPinnedLimit *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (m *mpsDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{m.controlDaemon, m.server, m.clientThreads, m.clientPinnedLimit}
+}
+
+// driverSampleDescs bundles the sub-interval sample descriptors, nil as a
+// whole when the feature is off.
+type driverSampleDescs struct {
+	// windows maps a collect.DriverSample* kind to its min/max/avg family.
+	windows      map[string]*prometheus.Desc
+	powerHistory *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe, in a fixed order.
+func (d *driverSampleDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		d.windows[collect.DriverSamplePower],
+		d.windows[collect.DriverSampleGPUUtilization],
+		d.windows[collect.DriverSampleMemoryUtilization],
+		d.windows[collect.DriverSampleGraphicsClock],
+		d.windows[collect.DriverSampleMemoryClock],
+		d.powerHistory,
+	}
+}
+
+// capabilityDescs bundles the static capability descriptors, nil as a whole
+// when the feature is off.
+type capabilityDescs struct {
+	info           *prometheus.Desc
+	cores          *prometheus.Desc
+	memoryBusWidth *prometheus.Desc
+	maxClock       *prometheus.Desc
+	supportedClock *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (c *capabilityDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{c.info, c.cores, c.memoryBusWidth, c.maxClock, c.supportedClock}
+}
+
+// topologyDescs bundles the GPU topology descriptors, nil as a whole when
+// the feature is off.
+type topologyDescs struct {
+	info     *prometheus.Desc
+	numaNode *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (t *topologyDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{t.info, t.numaNode}
+}
+
+// confComputeDescs bundles the confidential computing descriptors, nil as a
+// whole when the feature is off.
+type confComputeDescs struct {
+	capabilities    *prometheus.Desc
+	mode            *prometheus.Desc
+	multiGPUMode    *prometheus.Desc
+	ready           *prometheus.Desc
+	keyRotation     *prometheus.Desc
+	gpuMode         *prometheus.Desc
+	protectedMemory *prometheus.Desc
+	unprotectedMem  *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (c *confComputeDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.capabilities, c.mode, c.multiGPUMode, c.ready, c.keyRotation,
+		c.gpuMode, c.protectedMemory, c.unprotectedMem,
+	}
+}
+
+// vgpuDescs bundles the vGPU descriptors, nil as a whole when the feature is
+// off.
+type vgpuDescs struct {
+	active      *prometheus.Desc
+	info        *prometheus.Desc
+	framebuffer *prometheus.Desc
+	encoder     *prometheus.Desc
+	smUtil      *prometheus.Desc
+	memoryUtil  *prometheus.Desc
+	encoderUtil *prometheus.Desc
+	decoderUtil *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (v *vgpuDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		v.active, v.info, v.framebuffer, v.encoder, v.smUtil, v.memoryUtil, v.encoderUtil, v.decoderUtil,
+	}
+}
+
+// superchipDescs bundles the superchip descriptors, nil as a whole when the
+// feature is off.
+type superchipDescs struct {
+	links        *prometheus.Desc
+	linkUp       *prometheus.Desc
+	maxBandwidth *prometheus.Desc
+	linkErrors   *prometheus.Desc
+	lowPower     *prometheus.Desc
+	moduleEnergy *prometheus.Desc
+	numaNode     *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (s *superchipDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		s.links, s.linkUp, s.maxBandwidth, s.linkErrors, s.lowPower, s.moduleEnergy, s.numaNode,
+	}
+}
+
+// temperatureThresholdDescs bundles the temperature threshold descriptors,
+// nil as a whole when the feature is off.
+type temperatureThresholdDescs struct {
+	absolute *prometheus.Desc
+	tLimit   *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (t *temperatureThresholdDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{t.absolute, t.tLimit}
+}
+
+// pcieErrorDescs bundles the PCIe error counter descriptors, nil as a whole
+// when the feature is off.
+type pcieErrorDescs struct {
+	replays         *prometheus.Desc
+	replayRollovers *prometheus.Desc
+	aerErrors       *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (p *pcieErrorDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{p.replays, p.replayRollovers, p.aerErrors}
+}
+
+// fanDescs bundles the per-fan descriptors, nil as a whole when the feature
+// is off.
+type fanDescs struct {
+	speed  *prometheus.Desc
+	target *prometheus.Desc
+	policy *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (f *fanDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{f.speed, f.target, f.policy}
+}
+
+// accountingDescs bundles the completed-process descriptors, nil as a whole
+// when the feature is off.
+type accountingDescs struct {
+	completed  *prometheus.Desc
+	gpuSeconds *prometheus.Desc
+	peakMemory *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (a *accountingDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{a.completed, a.gpuSeconds, a.peakMemory}
+}
+
+// videoSessionDescs bundles the video session descriptors, nil as a whole
+// when the feature is off.
+type videoSessionDescs struct {
+	count   *prometheus.Desc
+	fps     *prometheus.Desc
+	latency *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (v *videoSessionDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{v.count, v.fps, v.latency}
+}
+
+// nvlinkDescs bundles the per-link NVLink descriptors, nil as a whole when
+// the feature is off.
+type nvlinkDescs struct {
+	state          *prometheus.Desc
+	version        *prometheus.Desc
+	remote         *prometheus.Desc
+	txBytes        *prometheus.Desc
+	rxBytes        *prometheus.Desc
+	crcFlitErrors  *prometheus.Desc
+	crcDataErrors  *prometheus.Desc
+	replayErrors   *prometheus.Desc
+	recoveryErrors *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (n *nvlinkDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		n.state, n.version, n.remote, n.txBytes, n.rxBytes,
+		n.crcFlitErrors, n.crcDataErrors, n.replayErrors, n.recoveryErrors,
+	}
+}
+
+// gpmDescs bundles the whole-GPU profiling descriptors, nil as a whole when
+// the feature is off.
+type gpmDescs struct {
+	smActivity     *prometheus.Desc
+	smOccupancy    *prometheus.Desc
+	tensorActivity *prometheus.Desc
+	dramActivity   *prometheus.Desc
+	fp64Activity   *prometheus.Desc
+	fp32Activity   *prometheus.Desc
+	fp16Activity   *prometheus.Desc
+	nvlinkTx       *prometheus.Desc
+	nvlinkRx       *prometheus.Desc
+	pcieTx         *prometheus.Desc
+	pcieRx         *prometheus.Desc
+}
+
+// all lists the bundled descriptors, for Describe.
+func (g *gpmDescs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		g.smActivity, g.smOccupancy, g.tensorActivity, g.dramActivity,
+		g.fp64Activity, g.fp32Activity, g.fp16Activity,
+		g.nvlinkTx, g.nvlinkRx, g.pcieTx, g.pcieRx,
+	}
 }
 
 // all lists the bundled descriptors, for Describe.
@@ -158,7 +499,7 @@ func (m *migDescs) all() []*prometheus.Desc {
 	return []*prometheus.Desc{
 		m.info, m.memTotal, m.memUsed, m.memFree, m.memReserved,
 		m.graphicsActivity, m.smActivity, m.smOccupancy, m.tensorActivity,
-		m.pcieTx, m.pcieRx,
+		m.pcieTx, m.pcieRx, m.profileMax, m.profileRemaining, m.placement,
 	}
 }
 
@@ -171,6 +512,7 @@ func New(
 	source collect.Source,
 	features Features,
 	xids XIDSource,
+	driverEvents DriverEventSource,
 	exitCodeMetric ExitCodeMetric,
 	logger *slog.Logger,
 ) *GPUExporter {
@@ -188,7 +530,7 @@ func New(
 	infoLabels = append(infoLabels, "cuda_version")
 
 	appInfoDesc, appMemoryDesc, appCountDesc, appsSuccessDesc := newComputeAppDescs(
-		prefix, features.ComputeApps, features.ComputeAppMIGLabels)
+		prefix, features.ComputeApps, features.ComputeAppMIGLabels, features.ComputeAppTypes)
 	pcieTxDesc, pcieRxDesc := newPCIeDescs(prefix, features.PCIeThroughput)
 
 	exp := &GPUExporter{
@@ -201,12 +543,30 @@ func New(
 		appMemoryDesc:         appMemoryDesc,
 		appCountDesc:          appCountDesc,
 		appsSuccessDesc:       appsSuccessDesc,
+		appUtilDescs:          newAppUtilDescs(prefix, features.ComputeAppUtilization),
+		appContainerDesc:      newAppContainerDesc(prefix, features.ComputeAppContainers),
+		mpsDescs:              newMPSDescs(prefix, features.ComputeAppMPS),
 		pcieTxDesc:            pcieTxDesc,
 		pcieRxDesc:            pcieRxDesc,
 		energyDesc:            newEnergyDesc(prefix, features.Energy),
+		thresholdDescs:        newTemperatureThresholdDescs(prefix, features.TemperatureThresholds),
+		pcieErrorDescs:        newPCIeErrorDescs(prefix, features.PCIeErrors),
+		fanDescs:              newFanDescs(prefix, features.Fans),
+		capabilityDescs:       newCapabilityDescs(prefix, features.Capabilities),
+		topologyDescs:         newTopologyDescs(prefix, features.Topology),
+		confComputeDescs:      newConfComputeDescs(prefix, features.ConfidentialComputing),
+		vgpuDescs:             newVGPUDescs(prefix, features.VGPU),
+		superchipDescs:        newSuperchipDescs(prefix, features.Superchip),
 		migDescs:              newMIGDescs(prefix, features.MIG),
+		accountingDescs:       newAccountingDescs(prefix, features.Accounting),
+		videoSessionDescs:     newVideoSessionDescs(prefix, features.VideoSessions),
+		nvlinkDescs:           newNVLinkDescs(prefix, features.NVLink),
+		gpmDescs:              newGPMDescs(prefix, features.GPM),
+		driverSampleDescs:     newDriverSampleDescs(prefix, features.DriverSamples),
 		appMIGLabels:          features.ComputeAppMIGLabels,
+		appTypes:              features.ComputeAppTypes,
 		xids:                  xids,
+		driverEvents:          driverEvents,
 		logger:                logger,
 		gpuInfoDesc: prometheus.NewDesc(
 			prometheus.BuildFQName(prefix, "", "gpu_info"),
@@ -222,8 +582,8 @@ func New(
 	return exp
 }
 
-// addXIDDescs builds the XID error counter descriptors, left nil when the
-// feature is disabled.
+// addXIDDescs builds the XID error counter and driver event descriptors,
+// left nil when the feature is disabled.
 func addXIDDescs(exp *GPUExporter, prefix string, enabled bool) {
 	if !enabled {
 		return
@@ -241,6 +601,13 @@ func addXIDDescs(exp *GPUExporter, prefix string, enabled bool) {
 			"(the driver events carry no timestamp of their own).",
 		[]string{uuidLabel, "xid"},
 		nil)
+	exp.driverEventDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(prefix, "", "driver_events_total"),
+		"Number of driver events of the type observed on the GPU since the exporter started: "+
+			"xid, ecc_single_bit, ecc_double_bit, clock_change, power_source_change or mig_config_change. "+
+			"A series appears when its first event arrives.",
+		[]string{uuidLabel, "type"},
+		nil)
 }
 
 // addHealthDescs builds the collection health descriptors.
@@ -278,6 +645,7 @@ func newComputeAppDescs(
 	prefix string,
 	enabled bool,
 	migLabels bool,
+	types bool,
 ) (*prometheus.Desc, *prometheus.Desc, *prometheus.Desc, *prometheus.Desc) {
 	if !enabled {
 		return nil, nil, nil, nil
@@ -288,6 +656,12 @@ func newComputeAppDescs(
 		labels = computeAppMIGLabels
 	}
 
+	countLabels := []string{uuidLabel}
+	if types {
+		labels = append(slices.Clip(labels), processTypeLabel)
+		countLabels = append(countLabels, processTypeLabel)
+	}
+
 	info := prometheus.NewDesc(
 		prometheus.BuildFQName(prefix, "", "compute_app_info"),
 		"A metric with a constant '1' value labeled by the identity of a process with a compute context on a GPU.",
@@ -301,7 +675,7 @@ func newComputeAppDescs(
 	count := prometheus.NewDesc(
 		prometheus.BuildFQName(prefix, "", "compute_apps"),
 		"Number of processes with a compute context on the GPU.",
-		[]string{uuidLabel},
+		countLabels,
 		nil)
 	success := prometheus.NewDesc(
 		prometheus.BuildFQName(prefix, "", "compute_apps_last_collect_success"),
@@ -312,179 +686,862 @@ func newComputeAppDescs(
 	return info, memory, count, success
 }
 
-// newPCIeDescs builds the PCIe throughput descriptors, nil when the feature
-// is disabled.
-func newPCIeDescs(prefix string, enabled bool) (*prometheus.Desc, *prometheus.Desc) {
+// newAppUtilDescs builds the per-process utilization descriptors, nil when
+// the feature is disabled. They carry the base per-process labels without the
+// MIG attribution: the driver samples processes per GPU, not per instance.
+func newAppUtilDescs(prefix string, enabled bool) *appUtilDescs {
 	if !enabled {
-		return nil, nil
+		return nil
 	}
 
-	tx := prometheus.NewDesc(
-		prometheus.BuildFQName(prefix, "", "pcie_throughput_tx_bytes_per_second"),
-		"PCIe traffic transmitted by the GPU, sampled by the driver over a dedicated 20ms window.",
-		[]string{uuidLabel},
-		nil)
-	rx := prometheus.NewDesc(
-		prometheus.BuildFQName(prefix, "", "pcie_throughput_rx_bytes_per_second"),
-		"PCIe traffic received by the GPU, sampled by the driver over a dedicated 20ms window.",
-		[]string{uuidLabel},
-		nil)
+	utilDesc := func(engine, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "compute_app_"+engine+"_utilization_ratio"),
+			help+" Averaged over the driver samples taken between the two most recent "+
+				"collections; absent for a process the driver did not sample in that window.",
+			computeAppLabels,
+			nil)
+	}
 
-	return tx, rx
+	return &appUtilDescs{
+		sm:      utilDesc("sm", "Fraction of time the process kept the GPU's SMs busy."),
+		memory:  utilDesc("memory", "Fraction of time the process kept the GPU's memory controller busy."),
+		encoder: utilDesc("encoder", "Fraction of time the process kept the GPU's video encoder busy."),
+		decoder: utilDesc("decoder", "Fraction of time the process kept the GPU's video decoder busy."),
+	}
 }
 
-// newEnergyDesc builds the energy counter descriptor, nil when the feature is
-// disabled.
-func newEnergyDesc(prefix string, enabled bool) *prometheus.Desc {
+// newAppContainerDesc builds the per-process container descriptor, nil when
+// the feature is disabled. It is a separate info series rather than extra
+// labels on compute_app_info, whose label set stays unchanged.
+func newAppContainerDesc(prefix string, enabled bool) *prometheus.Desc {
 	if !enabled {
 		return nil
 	}
 
 	return prometheus.NewDesc(
-		prometheus.BuildFQName(prefix, "", "energy_joules_total"),
-		"Total energy consumed by the GPU in joules since the driver was last loaded. "+
-			"Resets on a driver reload; absent on GPUs that cannot report it.",
-		[]string{uuidLabel},
+		prometheus.BuildFQName(prefix, "", "compute_app_container_info"),
+		"A metric with a constant '1' value labeled by the Docker container running a process on a GPU. "+
+			"Absent for processes outside Docker.",
+		[]string{uuidLabel, "pid", "container_id", "container_name", "image", "compose_project"},
 		nil)
 }
 
-// newMIGDescs builds the per-MIG-instance descriptors, nil when the feature
-// is disabled. Memory belongs to the MIG device (mig_uuid); utilization is
-// attributed per GPU instance, which may host several MIG devices.
-func newMIGDescs(prefix string, enabled bool) *migDescs {
+// newMPSDescs builds the MPS sharing descriptors, nil when the feature is
+// disabled. The client families carry (uuid, pid) only, to join with
+// compute_app_info.
+func newMPSDescs(prefix string, enabled bool) *mpsDescs {
 	if !enabled {
 		return nil
 	}
 
-	instanceLabels := []string{uuidLabel, "gpu_instance_id"}
+	clientLabels := []string{uuidLabel, "pid"}
 
-	memDesc := func(kind string) *prometheus.Desc {
-		return prometheus.NewDesc(
-			prometheus.BuildFQName(prefix, "", "mig_memory_"+kind+"_bytes"),
-			"Memory of the GPU instance ("+kind+"). The framebuffer belongs to the GPU "+
-				"instance and is shared by its compute instances (verified live: sibling "+
-				"compute instances report identical values).",
-			instanceLabels,
-			nil)
+	return &mpsDescs{
+		controlDaemon: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "mps_control_daemon_info"),
+			"A metric with a constant '1' value labeled by the pid of a running MPS control daemon.",
+			[]string{"pid"},
+			nil),
+		server: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "mps_server_info"),
+			"A metric with a constant '1' value labeled by the pid of the MPS server sharing the GPU.",
+			[]string{uuidLabel, "pid"},
+			nil),
+		clientThreads: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "mps_client_active_thread_ratio"),
+			"Fraction of the GPU's threads the MPS client may use (its active thread percentage). "+
+				"Absent when no limit applies.",
+			clientLabels,
+			nil),
+		clientPinnedLimit: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "mps_client_pinned_memory_limit_bytes"),
+			"Device memory the MPS client may pin on the GPU. Absent when no limit applies.",
+			clientLabels,
+			nil),
 	}
+}
 
-	activityDesc := func(name, help string) *prometheus.Desc {
-		return prometheus.NewDesc(
-			prometheus.BuildFQName(prefix, "", name),
-			help+" Computed over the window between the two most recent collections; "+
-				"absent on the first collection that sees the GPU instance.",
-			instanceLabels,
-			nil)
+// newAccountingDescs builds the completed-process descriptors, nil when the
+// feature is disabled. They count from the exporter's start: processes that
+// completed before it are not included.
+func newAccountingDescs(prefix string, enabled bool) *accountingDescs {
+	if !enabled {
+		return nil
 	}
 
-	return &migDescs{
-		info: prometheus.NewDesc(
-			prometheus.BuildFQName(prefix, "", "mig_info"),
-			"A metric with a constant '1' value labeled by the identity of a MIG device: "+
-				"the parent GPU's uuid, the MIG device's own uuid, its GPU instance and "+
-				"compute instance ids, and its profile.",
-			[]string{uuidLabel, "mig_uuid", "gpu_instance_id", "compute_instance_id", "profile"},
+	return &accountingDescs{
+		completed: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "accounted_apps_completed_total"),
+			"Number of processes that completed on the GPU, read from the driver's accounting "+
+				"buffer. Includes processes that started and finished between scrapes.",
+			[]string{uuidLabel},
+			nil),
+		gpuSeconds: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "accounted_apps_gpu_seconds_total"),
+			"Total time the completed processes held a context on the GPU, in seconds.",
+			[]string{uuidLabel},
+			nil),
+		peakMemory: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "accounted_apps_max_memory_used_bytes"),
+			"Largest peak memory use of any process that completed on the GPU, in bytes.",
+			[]string{uuidLabel},
 			nil),
-		memTotal:    memDesc("total"),
-		memUsed:     memDesc("used"),
-		memFree:     memDesc("free"),
-		memReserved: memDesc("reserved"),
-		graphicsActivity: activityDesc("mig_graphics_activity_ratio",
-			"Fraction of time the GPU instance's graphics/compute engines were active."),
-		smActivity: activityDesc("mig_sm_activity_ratio",
-			"Fraction of time the GPU instance's SMs were active."),
-		smOccupancy: activityDesc("mig_sm_occupancy_ratio",
-			"Fraction of the GPU instance's resident warp slots that were occupied."),
-		tensorActivity: activityDesc("mig_tensor_activity_ratio",
-			"Fraction of time the GPU instance's tensor pipes were active."),
-		pcieTx: activityDesc("mig_pcie_throughput_tx_bytes_per_second",
-			"PCIe traffic transmitted by the GPU instance."),
-		pcieRx: activityDesc("mig_pcie_throughput_rx_bytes_per_second",
-			"PCIe traffic received by the GPU instance."),
 	}
 }
 
-// WithContext returns a collector that renders the same metrics but bounds
-// its collection by the given context. The HTTP handler uses it to bound each
-// scrape's collection by the scrape's own lifetime. The copy shares all
-// state with the original; only the context differs, and everything shared is
-// immutable after construction or owns its own synchronization.
-func (e *GPUExporter) WithContext(ctx context.Context) *GPUExporter {
-	scoped := *e
-	scoped.ctx = ctx
-
-	return &scoped
-}
-
-// Describe describes all the metrics ever exported by the exporter. It
-// implements prometheus.Collector.
-func (e *GPUExporter) Describe(descCh chan<- *prometheus.Desc) {
-	for _, m := range e.qFieldToMetricInfoMap {
-		e.sendDesc(descCh, m.desc)
+// newVideoSessionDescs builds the video session descriptors, nil when the
+// feature is disabled. The per-session families carry the session id: one
+// process may hold several sessions of identical shape.
+func newVideoSessionDescs(prefix string, enabled bool) *videoSessionDescs {
+	if !enabled {
+		return nil
 	}
 
-	e.sendDesc(descCh, e.failedScrapesDesc)
-	e.sendDesc(descCh, e.exitCodeDesc)
-	e.sendDesc(descCh, e.collectSuccessDesc)
-	e.sendDesc(descCh, e.collectTimestampDesc)
-	e.sendDesc(descCh, e.collectDurationDesc)
-	e.sendDesc(descCh, e.gpuInfoDesc)
+	sessionLabels := []string{uuidLabel, "session_type", "session_id", "codec", "pid", "resolution"}
 
-	if e.appInfoDesc != nil {
-		e.sendDesc(descCh, e.appInfoDesc)
-		e.sendDesc(descCh, e.appMemoryDesc)
-		e.sendDesc(descCh, e.appCountDesc)
-		e.sendDesc(descCh, e.appsSuccessDesc)
+	return &videoSessionDescs{
+		count: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "video_sessions"),
+			"Number of active video sessions on the GPU, by session type (encoder or fbc). "+
+				"Absent for a session type the GPU cannot report.",
+			[]string{uuidLabel, "session_type"},
+			nil),
+		fps: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "video_session_average_fps"),
+			"Moving average frame rate of the video session, as reported by the driver.",
+			sessionLabels,
+			nil),
+		latency: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "video_session_average_latency_seconds"),
+			"Moving average latency of the video session, as reported by the driver.",
+			sessionLabels,
+			nil),
 	}
+}
 
-	if e.pcieTxDesc != nil {
-		e.sendDesc(descCh, e.pcieTxDesc)
-		e.sendDesc(descCh, e.pcieRxDesc)
+// newNVLinkDescs builds the per-link NVLink descriptors, nil when the feature
+// is disabled. The counters are the driver's own: they count since it was
+// loaded, not since the exporter started.
+func newNVLinkDescs(prefix string, enabled bool) *nvlinkDescs {
+	if !enabled {
+		return nil
 	}
 
-	if e.energyDesc != nil {
-		e.sendDesc(descCh, e.energyDesc)
-	}
+	linkLabels := []string{uuidLabel, "link"}
 
-	if e.migDescs != nil {
-		for _, desc := range e.migDescs.all() {
-			e.sendDesc(descCh, desc)
-		}
+	counter := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(prefix, "", name), help, linkLabels, nil)
 	}
 
-	if e.xidCountDesc != nil {
-		e.sendDesc(descCh, e.xidCountDesc)
-		e.sendDesc(descCh, e.xidTimestampDesc)
+	return &nvlinkDescs{
+		state: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "nvlink_state"),
+			"Whether the NVLink link is up (1) or down (0).",
+			linkLabels,
+			nil),
+		version: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "nvlink_version"),
+			"NVLink version the link runs. Absent while the link is down.",
+			linkLabels,
+			nil),
+		remote: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "nvlink_remote_info"),
+			"A metric with a constant '1' value labeled by the PCI bus id and the device type "+
+				"(gpu, switch, ibmnpu or unknown) of the NVLink link's far end.",
+			[]string{uuidLabel, "link", "remote_pci_bus_id", "remote_device_type"},
+			nil),
+		txBytes: counter("nvlink_tx_bytes_total",
+			"Data payload sent over the NVLink link, in bytes, protocol overhead excluded."),
+		rxBytes: counter("nvlink_rx_bytes_total",
+			"Data payload received over the NVLink link, in bytes, protocol overhead excluded."),
+		crcFlitErrors: counter("nvlink_crc_flit_errors_total",
+			"Flow control digits received over the NVLink link with a CRC error."),
+		crcDataErrors: counter("nvlink_crc_data_errors_total",
+			"Data packets received over the NVLink link with a CRC error."),
+		replayErrors: counter("nvlink_replay_errors_total",
+			"Transmissions the NVLink link had to replay."),
+		recoveryErrors: counter("nvlink_recovery_errors_total",
+			"Times the NVLink link went through error recovery."),
 	}
 }
 
-// Collect fetches the latest reading from the source and delivers it as
-// Prometheus metrics. It implements prometheus.Collector.
-func (e *GPUExporter) Collect(metricCh chan<- prometheus.Metric) {
-	snapshot := e.source.Latest(e.ctx)
-
-	e.renderHealth(metricCh, snapshot)
-
-	// the XID counters render before the no-data return below: a GPU
-	// throwing XIDs typically also fails collections, and that is exactly
-	// when these series must stay visible
-	e.renderXIDs(metricCh)
-
-	if snapshot.Table == nil {
-		return
+// newGPMDescs builds the whole-GPU profiling descriptors, nil when the
+// feature is disabled.
+func newGPMDescs(prefix string, enabled bool) *gpmDescs {
+	if !enabled {
+		return nil
 	}
 
-	for _, currentRow := range snapshot.Table.Rows {
-		e.renderRow(metricCh, currentRow, snapshot.Extras.CUDAVersion)
+	gpmDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", name),
+			help+" Computed over the window between the two most recent collections; "+
+				"absent on the first collection that sees the GPU and on GPUs in MIG mode.",
+			[]string{uuidLabel},
+			nil)
 	}
 
-	e.renderApps(metricCh, snapshot)
-	e.renderExtras(metricCh, snapshot)
+	return &gpmDescs{
+		smActivity: gpmDesc("gpm_sm_activity_ratio",
+			"Fraction of time the GPU's SMs were active."),
+		smOccupancy: gpmDesc("gpm_sm_occupancy_ratio",
+			"Fraction of the GPU's resident warp slots that were occupied."),
+		tensorActivity: gpmDesc("gpm_tensor_activity_ratio",
+			"Fraction of time the GPU's tensor pipes were active."),
+		dramActivity: gpmDesc("gpm_dram_activity_ratio",
+			"Fraction of the GPU's peak memory bandwidth that was used."),
+		fp64Activity: gpmDesc("gpm_fp64_activity_ratio",
+			"Fraction of time the GPU's FP64 pipes were active."),
+		fp32Activity: gpmDesc("gpm_fp32_activity_ratio",
+			"Fraction of time the GPU's FP32 pipes were active."),
+		fp16Activity: gpmDesc("gpm_fp16_activity_ratio",
+			"Fraction of time the GPU's FP16 pipes were active."),
+		nvlinkTx: gpmDesc("gpm_nvlink_throughput_tx_bytes_per_second",
+			"NVLink traffic transmitted by the GPU, over all of its links."),
+		nvlinkRx: gpmDesc("gpm_nvlink_throughput_rx_bytes_per_second",
+			"NVLink traffic received by the GPU, over all of its links."),
+		pcieTx: gpmDesc("gpm_pcie_throughput_tx_bytes_per_second",
+			"PCIe traffic transmitted by the GPU."),
+		pcieRx: gpmDesc("gpm_pcie_throughput_rx_bytes_per_second",
+			"PCIe traffic received by the GPU."),
+	}
 }
 
-// renderXIDs emits the XID error counters from the dedicated source. Unlike
-// every other family they do not come from the snapshot: the counts are
-// event-driven, cumulative in-process state served fresh on every scrape.
+// newCapabilityDescs builds the static capability descriptors, nil when the
+// feature is disabled.
+func newCapabilityDescs(prefix string, enabled bool) *capabilityDescs {
+	if !enabled {
+		return nil
+	}
+
+	return &capabilityDescs{
+		info: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_capabilities_info"),
+			"A metric with a constant '1' value labeled by the GPU's product architecture, brand, "+
+				"board part number and the PCIe generation and width it supports at most. A label "+
+				"the driver cannot report is empty.",
+			[]string{
+				uuidLabel, "architecture", "brand", "board_part_number",
+				"pcie_link_gen_max", "pcie_link_width_max",
+			},
+			nil),
+		cores: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_cores"),
+			"Number of cores of the GPU.",
+			[]string{uuidLabel},
+			nil),
+		memoryBusWidth: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_memory_bus_width_bits"),
+			"Width of the GPU's memory bus, in bits.",
+			[]string{uuidLabel},
+			nil),
+		maxClock: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_max_clock_hz"),
+			"Maximum clock of the clock domain the clock label names (graphics, sm, memory, video).",
+			[]string{uuidLabel, "clock"},
+			nil),
+		supportedClock: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_supported_clock_hz"),
+			"Bound (min or max) of the range the clock domain runs at in the performance state, "+
+				"the GPU's supported clock table. Reported for the graphics and memory clocks.",
+			[]string{uuidLabel, "pstate", "clock", "bound"},
+			nil),
+	}
+}
+
+// newTopologyDescs builds the GPU topology descriptors, nil when the feature
+// is disabled.
+func newTopologyDescs(prefix string, enabled bool) *topologyDescs {
+	if !enabled {
+		return nil
+	}
+
+	return &topologyDescs{
+		info: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_topology_info"),
+			"A metric with a constant '1' value per ordered pair of GPUs, labeled by how they connect, "+
+				"in nvidia-smi topo -m's terms: nvlink, or the closest PCIe or host hop the path crosses "+
+				"(pix, pxb, phb, node, sys).",
+			[]string{uuidLabel, "peer_uuid", "link"},
+			nil),
+		numaNode: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_numa_node"),
+			"NUMA node the GPU is local to, labeled by the node's CPU list. Absent when the GPU is "+
+				"local to no single node.",
+			[]string{uuidLabel, "cpu_affinity"},
+			nil),
+	}
+}
+
+// newDriverSampleDescs builds the sub-interval sample descriptors, nil when
+// the feature is disabled.
+func newDriverSampleDescs(prefix string, enabled bool) *driverSampleDescs {
+	if !enabled {
+		return nil
+	}
+
+	window := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", name),
+			help+" Summarized by the stat label (min, max, avg) over the samples the driver took "+
+				"between the two most recent collections; absent when it took none.",
+			[]string{uuidLabel, "stat"},
+			nil)
+	}
+
+	return &driverSampleDescs{
+		windows: map[string]*prometheus.Desc{
+			collect.DriverSamplePower: window("driver_samples_power_draw_watts",
+				"Power drawn by the GPU, in watts, from the driver's sample buffer."),
+			collect.DriverSampleGPUUtilization: window("driver_samples_utilization_gpu_ratio",
+				"GPU utilization from the driver's sample buffer."),
+			collect.DriverSampleMemoryUtilization: window("driver_samples_utilization_memory_ratio",
+				"Memory controller utilization from the driver's sample buffer."),
+			collect.DriverSampleGraphicsClock: window("driver_samples_clocks_graphics_hz",
+				"Graphics clock from the driver's sample buffer."),
+			collect.DriverSampleMemoryClock: window("driver_samples_clocks_memory_hz",
+				"Memory clock from the driver's sample buffer."),
+		},
+		powerHistory: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "driver_samples_power_draw_distribution_watts"),
+			"Distribution of every power sample the driver took since the exporter started "+
+				"watching the GPU, in watts. A native histogram: scrape it with native histograms "+
+				"enabled to get its buckets.",
+			[]string{uuidLabel},
+			nil),
+	}
+}
+
+// newPCIeDescs builds the PCIe throughput descriptors, nil when the feature
+// is disabled.
+func newPCIeDescs(prefix string, enabled bool) (*prometheus.Desc, *prometheus.Desc) {
+	if !enabled {
+		return nil, nil
+	}
+
+	tx := prometheus.NewDesc(
+		prometheus.BuildFQName(prefix, "", "pcie_throughput_tx_bytes_per_second"),
+		"PCIe traffic transmitted by the GPU, sampled by the driver over a dedicated 20ms window.",
+		[]string{uuidLabel},
+		nil)
+	rx := prometheus.NewDesc(
+		prometheus.BuildFQName(prefix, "", "pcie_throughput_rx_bytes_per_second"),
+		"PCIe traffic received by the GPU, sampled by the driver over a dedicated 20ms window.",
+		[]string{uuidLabel},
+		nil)
+
+	return tx, rx
+}
+
+// newEnergyDesc builds the energy counter descriptor, nil when the feature is
+// disabled.
+func newEnergyDesc(prefix string, enabled bool) *prometheus.Desc {
+	if !enabled {
+		return nil
+	}
+
+	return prometheus.NewDesc(
+		prometheus.BuildFQName(prefix, "", "energy_joules_total"),
+		"Total energy consumed by the GPU in joules since the driver was last loaded. "+
+			"Resets on a driver reload; absent on GPUs that cannot report it.",
+		[]string{uuidLabel},
+		nil)
+}
+
+// newTemperatureThresholdDescs builds the temperature threshold descriptors,
+// nil when the feature is disabled. A GPU reports each threshold in one of
+// the two forms, never both.
+func newTemperatureThresholdDescs(prefix string, enabled bool) *temperatureThresholdDescs {
+	if !enabled {
+		return nil
+	}
+
+	labels := []string{uuidLabel, "threshold"}
+
+	return &temperatureThresholdDescs{
+		absolute: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "temperature_threshold_celsius"),
+			"Temperature at which the GPU takes the action the threshold label names, in degrees "+
+				"celsius: shutdown, slowdown, gpu_max and memory_max for the maximum operating "+
+				"temperatures, and the acoustic_* bounds of the target temperature.",
+			labels,
+			nil),
+		tLimit: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "temperature_tlimit_threshold_celsius"),
+			"Temperature threshold relative to the GPU's T.Limit temperature, in degrees celsius, "+
+				"as nvidia-smi reports them on datacenter GPUs. Compare against "+
+				"temperature_gpu_tlimit; negative values lie past the limit.",
+			labels,
+			nil),
+	}
+}
+
+// newPCIeErrorDescs builds the PCIe error counter descriptors, nil when the
+// feature is disabled.
+func newPCIeErrorDescs(prefix string, enabled bool) *pcieErrorDescs {
+	if !enabled {
+		return nil
+	}
+
+	return &pcieErrorDescs{
+		replays: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "pcie_replays_total"),
+			"PCIe packets the GPU's link had to retransmit since the driver was loaded. A steadily "+
+				"growing count points at a marginal link: a loose riser, a bad slot or cable.",
+			[]string{uuidLabel},
+			nil),
+		replayRollovers: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "pcie_replay_rollovers_total"),
+			"Times the PCIe replay sequence number of the GPU's link rolled over since the driver "+
+				"was loaded.",
+			[]string{uuidLabel},
+			nil),
+		aerErrors: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "pcie_aer_errors_total"),
+			"PCIe Advanced Error Reporting errors on the GPU's link since the driver was loaded, by "+
+				"severity: correctable, uncorrectable (non-fatal) and fatal. The exec backend cannot read them.",
+			[]string{uuidLabel, "severity"},
+			nil),
+	}
+}
+
+// newConfComputeDescs builds the confidential computing descriptors, nil
+// when the feature is disabled. The system-wide families carry no uuid: the
+// driver reports them once for every GPU.
+func newConfComputeDescs(prefix string, enabled bool) *confComputeDescs {
+	if !enabled {
+		return nil
+	}
+
+	return &confComputeDescs{
+		capabilities: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "confidential_computing_capabilities_info"),
+			"A metric with a constant '1' value labeled by the platform's confidential computing support: "+
+				"cpu is the CPU's technology (none, amd_sev, amd_sev_snp, amd_snp_vtom, intel_tdx), gpus is "+
+				"capable or not_capable.",
+			[]string{"cpu", "gpus"},
+			nil),
+		mode: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "confidential_computing_mode_info"),
+			"A metric with a constant '1' value labeled by the system's confidential computing mode (on, off, "+
+				"devtools) and the driver's environment (prod, sim, unavailable).",
+			[]string{"mode", "environment"},
+			nil),
+		multiGPUMode: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "confidential_computing_multi_gpu_mode_info"),
+			"A metric with a constant '1' value labeled by how the GPUs exchange protected data: none, "+
+				"protected_pcie or nvle.",
+			[]string{"mode"},
+			nil),
+		ready: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "confidential_computing_gpus_ready"),
+			"Whether the GPUs accept confidential client requests (1) or still wait for attestation to "+
+				"set them ready (0).",
+			nil,
+			nil),
+		keyRotation: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "confidential_computing_key_rotation_attacker_advantage"),
+			"Attacker advantage past which the driver rotates the encryption keys, in log2 of the "+
+				"encrypted data volume.",
+			nil,
+			nil),
+		gpuMode: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_confidential_computing_mode_info"),
+			"A metric with a constant '1' value per GPU labeled by the confidential computing mode it runs "+
+				"in, the system's (on, off, devtools).",
+			[]string{uuidLabel, "mode"},
+			nil),
+		protectedMemory: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_confidential_computing_protected_memory_size_bytes"),
+			"Size of the GPU's protected memory region. Its usage is protected_memory_used_bytes.",
+			[]string{uuidLabel},
+			nil),
+		unprotectedMem: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_confidential_computing_unprotected_memory_size_bytes"),
+			"Size of the GPU's memory outside the protected region.",
+			[]string{uuidLabel},
+			nil),
+	}
+}
+
+// newVGPUDescs builds the vGPU descriptors, nil when the feature is
+// disabled. Every per-instance family carries the host GPU's uuid, so it
+// joins with the per-GPU series, and the vGPU's own as vgpu_uuid.
+func newVGPUDescs(prefix string, enabled bool) *vgpuDescs {
+	if !enabled {
+		return nil
+	}
+
+	instanceLabels := []string{uuidLabel, "vgpu_uuid"}
+	utilization := func(name, engine string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", name),
+			"Fraction of the sampling period the vGPU kept the GPU's "+engine+" busy, 0 to 1, averaged "+
+				"over the driver samples taken since the previous collection.",
+			instanceLabels,
+			nil)
+	}
+
+	return &vgpuDescs{
+		active: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "vgpu_active_instances"),
+			"Number of vGPU instances running on the GPU.",
+			[]string{uuidLabel},
+			nil),
+		info: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "vgpu_info"),
+			"A metric with a constant '1' value per active vGPU instance labeled by the virtual machine "+
+				"running it (vm_id), its vGPU type name (type) and the type's class.",
+			[]string{uuidLabel, "vgpu_uuid", "vm_id", "type", "class"},
+			nil),
+		framebuffer: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "vgpu_framebuffer_used_bytes"),
+			"Framebuffer the vGPU's guest has in use.",
+			instanceLabels,
+			nil),
+		encoder: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "vgpu_encoder_sessions"),
+			"Number of encoder sessions active on the vGPU.",
+			instanceLabels,
+			nil),
+		smUtil:      utilization("vgpu_sm_utilization_ratio", "streaming multiprocessors"),
+		memoryUtil:  utilization("vgpu_memory_utilization_ratio", "memory controller"),
+		encoderUtil: utilization("vgpu_encoder_utilization_ratio", "video encoder"),
+		decoderUtil: utilization("vgpu_decoder_utilization_ratio", "video decoder"),
+	}
+}
+
+// newSuperchipDescs builds the superchip descriptors, nil when the feature
+// is disabled. The per-link families carry the link index as link.
+func newSuperchipDescs(prefix string, enabled bool) *superchipDescs {
+	if !enabled {
+		return nil
+	}
+
+	linkLabels := []string{uuidLabel, "link"}
+
+	return &superchipDescs{
+		links: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "superchip_c2c_links"),
+			"Number of C2C links joining the GPU to its Grace CPU.",
+			[]string{uuidLabel},
+			nil),
+		linkUp: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "superchip_c2c_link_up"),
+			"Whether the C2C link is active (1) or not (0).",
+			linkLabels,
+			nil),
+		maxBandwidth: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "superchip_c2c_link_max_bandwidth_bytes_per_second"),
+			"Maximum bandwidth of the active C2C link.",
+			linkLabels,
+			nil),
+		linkErrors: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "superchip_c2c_link_errors_total"),
+			"C2C link errors by type: crc, replay or replay_back_to_back.",
+			[]string{uuidLabel, "link", "type"},
+			nil),
+		lowPower: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "superchip_c2c_link_low_power"),
+			"Whether the C2C link is in its low power state (1) or at full power (0).",
+			linkLabels,
+			nil),
+		moduleEnergy: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "superchip_module_energy_joules_total"),
+			"Energy consumed by the whole superchip module, GPU, Grace CPU and memory together, since the "+
+				"driver was last loaded.",
+			[]string{uuidLabel},
+			nil),
+		numaNode: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "superchip_memory_numa_node"),
+			"NUMA node the GPU's memory is onlined as, beside the Grace CPU's own; join with the host's "+
+				"per-node memory readings.",
+			[]string{uuidLabel},
+			nil),
+	}
+}
+
+// newFanDescs builds the per-fan descriptors, nil when the feature is
+// disabled. They carry a gpu_ prefix: fan_speed_ratio is the board-level
+// fan.speed query field's.
+func newFanDescs(prefix string, enabled bool) *fanDescs {
+	if !enabled {
+		return nil
+	}
+
+	labels := []string{uuidLabel, "fan"}
+
+	return &fanDescs{
+		speed: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_fan_speed_ratio"),
+			"Speed the driver reports for the fan, as a ratio of its maximum. Compare against "+
+				"gpu_fan_target_speed_ratio and the GPU's other fans.",
+			labels,
+			nil),
+		target: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_fan_target_speed_ratio"),
+			"Speed the fan controller asks the fan for, as a ratio of its maximum.",
+			labels,
+			nil),
+		policy: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "gpu_fan_control_policy_info"),
+			"How the fan is controlled, always 1: policy is auto for the driver's temperature-driven "+
+				"control, manual for a speed set by hand.",
+			[]string{uuidLabel, "fan", "policy"},
+			nil),
+	}
+}
+
+// newMIGDescs builds the per-MIG-instance descriptors, nil when the feature
+// is disabled. Memory belongs to the MIG device (mig_uuid); utilization is
+// attributed per GPU instance, which may host several MIG devices.
+func newMIGDescs(prefix string, enabled bool) *migDescs {
+	if !enabled {
+		return nil
+	}
+
+	instanceLabels := []string{uuidLabel, "gpu_instance_id"}
+
+	memDesc := func(kind string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "mig_memory_"+kind+"_bytes"),
+			"Memory of the GPU instance ("+kind+"). The framebuffer belongs to the GPU "+
+				"instance and is shared by its compute instances (verified live: sibling "+
+				"compute instances report identical values).",
+			instanceLabels,
+			nil)
+	}
+
+	activityDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", name),
+			help+" Computed over the window between the two most recent collections; "+
+				"absent on the first collection that sees the GPU instance.",
+			instanceLabels,
+			nil)
+	}
+
+	return &migDescs{
+		info: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "mig_info"),
+			"A metric with a constant '1' value labeled by the identity of a MIG device: "+
+				"the parent GPU's uuid, the MIG device's own uuid, its GPU instance and "+
+				"compute instance ids, and its profile.",
+			[]string{uuidLabel, "mig_uuid", "gpu_instance_id", "compute_instance_id", "profile"},
+			nil),
+		memTotal:    memDesc("total"),
+		memUsed:     memDesc("used"),
+		memFree:     memDesc("free"),
+		memReserved: memDesc("reserved"),
+		graphicsActivity: activityDesc("mig_graphics_activity_ratio",
+			"Fraction of time the GPU instance's graphics/compute engines were active."),
+		smActivity: activityDesc("mig_sm_activity_ratio",
+			"Fraction of time the GPU instance's SMs were active."),
+		smOccupancy: activityDesc("mig_sm_occupancy_ratio",
+			"Fraction of the GPU instance's resident warp slots that were occupied."),
+		tensorActivity: activityDesc("mig_tensor_activity_ratio",
+			"Fraction of time the GPU instance's tensor pipes were active."),
+		pcieTx: activityDesc("mig_pcie_throughput_tx_bytes_per_second",
+			"PCIe traffic transmitted by the GPU instance."),
+		pcieRx: activityDesc("mig_pcie_throughput_rx_bytes_per_second",
+			"PCIe traffic received by the GPU instance."),
+		profileMax: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "mig_profile_instances_max"),
+			"How many GPU instances of the MIG profile the GPU can host when it has none.",
+			[]string{uuidLabel, "profile"},
+			nil),
+		profileRemaining: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "mig_profile_instances_remaining"),
+			"How many more GPU instances of the MIG profile fit beside the GPU's existing ones.",
+			[]string{uuidLabel, "profile"},
+			nil),
+		placement: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "mig_profile_placement_info"),
+			"A metric with a constant '1' value labeled by a memory slice range a GPU instance "+
+				"of the MIG profile can be created at, whether or not the range is free.",
+			[]string{uuidLabel, "profile", "placement_start", "placement_size"},
+			nil),
+	}
+}
+
+// WithContext returns a collector that renders the same metrics but bounds
+// its collection by the given context. The HTTP handler uses it to bound each
+// scrape's collection by the scrape's own lifetime. The copy shares all
+// state with the original; only the context differs, and everything shared is
+// immutable after construction or owns its own synchronization.
+func (e *GPUExporter) WithContext(ctx context.Context) *GPUExporter {
+	scoped := *e
+	scoped.ctx = ctx
+
+	return &scoped
+}
+
+// Describe describes all the metrics ever exported by the exporter. It
+// implements prometheus.Collector.
+func (e *GPUExporter) Describe(descCh chan<- *prometheus.Desc) {
+	for _, m := range e.qFieldToMetricInfoMap {
+		e.sendDesc(descCh, m.desc)
+	}
+
+	e.sendDesc(descCh, e.failedScrapesDesc)
+	e.sendDesc(descCh, e.exitCodeDesc)
+	e.sendDesc(descCh, e.collectSuccessDesc)
+	e.sendDesc(descCh, e.collectTimestampDesc)
+	e.sendDesc(descCh, e.collectDurationDesc)
+	e.sendDesc(descCh, e.gpuInfoDesc)
+
+	if e.appInfoDesc != nil {
+		e.sendDesc(descCh, e.appInfoDesc)
+		e.sendDesc(descCh, e.appMemoryDesc)
+		e.sendDesc(descCh, e.appCountDesc)
+		e.sendDesc(descCh, e.appsSuccessDesc)
+	}
+
+	if e.appUtilDescs != nil {
+		for _, desc := range e.appUtilDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.appContainerDesc != nil {
+		e.sendDesc(descCh, e.appContainerDesc)
+	}
+
+	if e.mpsDescs != nil {
+		for _, desc := range e.mpsDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.pcieTxDesc != nil {
+		e.sendDesc(descCh, e.pcieTxDesc)
+		e.sendDesc(descCh, e.pcieRxDesc)
+	}
+
+	if e.energyDesc != nil {
+		e.sendDesc(descCh, e.energyDesc)
+	}
+
+	if e.thresholdDescs != nil {
+		for _, desc := range e.thresholdDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.pcieErrorDescs != nil {
+		for _, desc := range e.pcieErrorDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.fanDescs != nil {
+		for _, desc := range e.fanDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.capabilityDescs != nil {
+		for _, desc := range e.capabilityDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.topologyDescs != nil {
+		for _, desc := range e.topologyDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.confComputeDescs != nil {
+		for _, desc := range e.confComputeDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.vgpuDescs != nil {
+		for _, desc := range e.vgpuDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.superchipDescs != nil {
+		for _, desc := range e.superchipDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.migDescs != nil {
+		for _, desc := range e.migDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.accountingDescs != nil {
+		for _, desc := range e.accountingDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.videoSessionDescs != nil {
+		for _, desc := range e.videoSessionDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.nvlinkDescs != nil {
+		for _, desc := range e.nvlinkDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.gpmDescs != nil {
+		for _, desc := range e.gpmDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.driverSampleDescs != nil {
+		for _, desc := range e.driverSampleDescs.all() {
+			e.sendDesc(descCh, desc)
+		}
+	}
+
+	if e.xidCountDesc != nil {
+		e.sendDesc(descCh, e.xidCountDesc)
+		e.sendDesc(descCh, e.xidTimestampDesc)
+		e.sendDesc(descCh, e.driverEventDesc)
+	}
+}
+
+// Collect fetches the latest reading from the source and delivers it as
+// Prometheus metrics. It implements prometheus.Collector.
+func (e *GPUExporter) Collect(metricCh chan<- prometheus.Metric) {
+	snapshot := e.source.Latest(e.ctx)
+
+	e.renderHealth(metricCh, snapshot)
+
+	// the XID and event counters render before the no-data return below: a
+	// GPU throwing XIDs typically also fails collections, and that is
+	// exactly when these series must stay visible
+	e.renderXIDs(metricCh)
+	e.renderDriverEvents(metricCh)
+
+	if snapshot.Table == nil {
+		return
+	}
+
+	for _, currentRow := range snapshot.Table.Rows {
+		e.renderRow(metricCh, currentRow, snapshot.Extras.CUDAVersion)
+	}
+
+	e.renderApps(metricCh, snapshot)
+	e.renderExtras(metricCh, snapshot)
+}
+
+// renderXIDs emits the XID error counters from the dedicated source. Unlike
+// every other family they do not come from the snapshot: the counts are
+// event-driven, cumulative in-process state served fresh on every scrape.
 func (e *GPUExporter) renderXIDs(metricCh chan<- prometheus.Metric) {
 	if e.xidCountDesc == nil || e.xids == nil {
 		return
@@ -499,6 +1556,54 @@ func (e *GPUExporter) renderXIDs(metricCh chan<- prometheus.Metric) {
 	}
 }
 
+// renderDriverEvents emits the driver event counters from their dedicated
+// source, on the same terms as renderXIDs.
+func (e *GPUExporter) renderDriverEvents(metricCh chan<- prometheus.Metric) {
+	if e.driverEventDesc == nil || e.driverEvents == nil {
+		return
+	}
+
+	for _, counter := range e.driverEvents.DriverEventCounts() {
+		e.sendLabeledCounter(metricCh, e.driverEventDesc, float64(counter.Count), counter.UUID, counter.Type)
+	}
+}
+
+// XIDCatalogCollector serves the XID catalog as an info family, one series
+// per described code, for joining onto the XID counters by the xid label.
+// The catalog is static and backend-neutral, so the collector lives in the
+// shared registry rather than in the per-scrape exporter, on every backend:
+// XID counts gathered outside the exporter join just the same.
+type XIDCatalogCollector struct {
+	desc    *prometheus.Desc
+	entries []xidcatalog.Entry
+}
+
+// NewXIDCatalogCollector builds the collector serving the given catalog.
+func NewXIDCatalogCollector(prefix string, catalog *xidcatalog.Catalog) *XIDCatalogCollector {
+	return &XIDCatalogCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "xid_info"),
+			"A metric with a constant '1' value describing an XID code: what it means, its severity "+
+				"(info, warning or critical) and the recommended action.",
+			[]string{"xid", "description", "severity", "action"},
+			nil),
+		entries: catalog.Entries(),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *XIDCatalogCollector) Describe(descCh chan<- *prometheus.Desc) {
+	descCh <- c.desc
+}
+
+// Collect implements prometheus.Collector.
+func (c *XIDCatalogCollector) Collect(metricCh chan<- prometheus.Metric) {
+	for _, entry := range c.entries {
+		metricCh <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1,
+			strconv.FormatUint(entry.XID, 10), entry.Description, entry.Severity, entry.Action)
+	}
+}
+
 // sendLabeledCounter emits one constant counter with the given label values,
 // logging instead of failing if it cannot be built.
 func (e *GPUExporter) sendLabeledCounter(
@@ -537,6 +1642,128 @@ func (e *GPUExporter) renderExtras(metricCh chan<- prometheus.Metric, snapshot c
 		}
 	}
 
+	if e.thresholdDescs != nil {
+		for _, threshold := range snapshot.Extras.TemperatureThresholds {
+			desc := e.thresholdDescs.absolute
+			if threshold.TLimit {
+				desc = e.thresholdDescs.tLimit
+			}
+
+			e.sendLabeledGauge(metricCh, desc, threshold.Celsius, threshold.UUID, threshold.Kind)
+		}
+	}
+
+	if e.pcieErrorDescs != nil {
+		for _, counters := range snapshot.Extras.PCIeErrors {
+			e.renderPCIeErrors(metricCh, counters)
+		}
+	}
+
+	if e.fanDescs != nil {
+		for _, fan := range snapshot.Extras.Fans {
+			e.renderFan(metricCh, fan)
+		}
+	}
+
+	if e.capabilityDescs != nil {
+		for _, capabilities := range snapshot.Extras.Capabilities {
+			e.renderCapabilities(metricCh, capabilities)
+		}
+	}
+
+	if e.topologyDescs != nil {
+		for _, link := range snapshot.Extras.Topology.Links {
+			e.sendLabeledGauge(metricCh, e.topologyDescs.info, 1, link.UUID, link.PeerUUID, link.Link)
+		}
+
+		for _, affinity := range snapshot.Extras.Topology.NUMA {
+			e.sendLabeledGauge(metricCh, e.topologyDescs.numaNode, float64(affinity.Node),
+				affinity.UUID, affinity.CPUAffinity)
+		}
+	}
+
+	if e.confComputeDescs != nil {
+		e.renderConfComputing(metricCh, snapshot.Extras.ConfidentialComputing)
+	}
+
+	if e.vgpuDescs != nil {
+		for _, host := range snapshot.Extras.VGPUs {
+			e.renderVGPUHost(metricCh, host)
+		}
+	}
+
+	if e.superchipDescs != nil {
+		for _, superchip := range snapshot.Extras.Superchips {
+			e.renderSuperchip(metricCh, superchip)
+		}
+	}
+
+	if e.appUtilDescs != nil {
+		for _, util := range snapshot.Extras.ProcessUtilization {
+			labelValues := []string{util.UUID, util.PID, util.ProcessName}
+
+			e.sendLabeledGauge(metricCh, e.appUtilDescs.sm, util.SMRatio, labelValues...)
+			e.sendLabeledGauge(metricCh, e.appUtilDescs.memory, util.MemoryRatio, labelValues...)
+			e.sendLabeledGauge(metricCh, e.appUtilDescs.encoder, util.EncoderRatio, labelValues...)
+			e.sendLabeledGauge(metricCh, e.appUtilDescs.decoder, util.DecoderRatio, labelValues...)
+		}
+	}
+
+	if e.appContainerDesc != nil {
+		for _, container := range snapshot.Extras.Containers {
+			e.sendLabeledGauge(metricCh, e.appContainerDesc, 1, container.UUID, container.PID,
+				container.ContainerID, container.Name, container.Image, container.ComposeProject)
+		}
+	}
+
+	if e.mpsDescs != nil {
+		e.renderMPS(metricCh, snapshot.Extras.MPS)
+	}
+
+	if e.accountingDescs != nil {
+		for _, counter := range snapshot.Extras.Accounting {
+			e.sendConstWithUUID(metricCh, e.accountingDescs.completed, prometheus.CounterValue,
+				float64(counter.Completed), counter.UUID)
+			e.sendConstWithUUID(metricCh, e.accountingDescs.gpuSeconds, prometheus.CounterValue,
+				counter.GPUSeconds, counter.UUID)
+			e.sendConstWithUUID(metricCh, e.accountingDescs.peakMemory, prometheus.GaugeValue,
+				float64(counter.PeakMemoryBytes), counter.UUID)
+		}
+	}
+
+	if e.videoSessionDescs != nil {
+		for _, group := range snapshot.Extras.VideoSessions {
+			e.sendLabeledGauge(metricCh, e.videoSessionDescs.count, float64(len(group.Sessions)),
+				group.UUID, group.Type)
+
+			for _, session := range group.Sessions {
+				labelValues := []string{
+					group.UUID, group.Type, session.ID, session.Codec, session.PID, session.Resolution,
+				}
+
+				e.sendLabeledGauge(metricCh, e.videoSessionDescs.fps, session.AverageFPS, labelValues...)
+				e.sendLabeledGauge(metricCh, e.videoSessionDescs.latency,
+					session.AverageLatencySeconds, labelValues...)
+			}
+		}
+	}
+
+	if e.nvlinkDescs != nil {
+		for _, link := range snapshot.Extras.NVLink {
+			e.renderNVLink(metricCh, link)
+		}
+	}
+
+	if e.gpmDescs != nil {
+		for _, profile := range snapshot.Extras.GPM {
+			e.renderGPM(metricCh, profile)
+		}
+	}
+
+	if e.driverSampleDescs != nil {
+		e.renderDriverSamples(metricCh, snapshot.Extras)
+	}
+
 	if e.migDescs != nil {
 		// utilization is per GPU instance while the entries are per MIG
 		// device (compute instance): emit each GPU instance's series once
@@ -545,6 +1772,328 @@ func (e *GPUExporter) renderExtras(metricCh chan<- prometheus.Metric, snapshot c
 		for _, instance := range snapshot.Extras.MIG {
 			e.renderMIGInstance(metricCh, instance, emittedGIs)
 		}
+
+		for _, capacity := range snapshot.Extras.MIGProfiles {
+			e.renderMIGProfile(metricCh, capacity)
+		}
+	}
+}
+
+// renderMPS emits the MPS sharing families. A client limit that does not
+// apply has no series, rather than a zero that would read as "no threads".
+func (e *GPUExporter) renderMPS(metricCh chan<- prometheus.Metric, sharing collect.MPSSharing) {
+	for _, pid := range sharing.ControlDaemonPIDs {
+		e.sendLabeledGauge(metricCh, e.mpsDescs.controlDaemon, 1, pid)
+	}
+
+	for _, server := range sharing.Servers {
+		e.sendLabeledGauge(metricCh, e.mpsDescs.server, 1, server.UUID, server.PID)
+	}
+
+	for _, client := range sharing.Clients {
+		if client.ActiveThreadRatio != nil {
+			e.sendLabeledGauge(metricCh, e.mpsDescs.clientThreads, *client.ActiveThreadRatio,
+				client.UUID, client.PID)
+		}
+
+		if client.PinnedMemoryLimitBytes != nil {
+			e.sendLabeledGauge(metricCh, e.mpsDescs.clientPinnedLimit, float64(*client.PinnedMemoryLimitBytes),
+				client.UUID, client.PID)
+		}
+	}
+}
+
+// renderPCIeErrors emits one GPU's PCIe error counters; a counter the
+// backend could not read has no series.
+func (e *GPUExporter) renderPCIeErrors(metricCh chan<- prometheus.Metric, counters collect.PCIeErrorCounters) {
+	if counters.Replays != nil {
+		e.sendLabeledCounter(metricCh, e.pcieErrorDescs.replays, *counters.Replays, counters.UUID)
+	}
+
+	if counters.ReplayRollovers != nil {
+		e.sendLabeledCounter(metricCh, e.pcieErrorDescs.replayRollovers, *counters.ReplayRollovers, counters.UUID)
+	}
+
+	for _, entry := range []struct {
+		severity string
+		value    *float64
+	}{
+		{"correctable", counters.CorrectableErrors},
+		{"uncorrectable", counters.UncorrectableErrors},
+		{"fatal", counters.FatalErrors},
+	} {
+		if entry.value == nil {
+			continue
+		}
+
+		e.sendLabeledCounter(metricCh, e.pcieErrorDescs.aerErrors, *entry.value, counters.UUID, entry.severity)
+	}
+}
+
+// renderConfComputing emits the confidential computing series; a reading
+// the driver could not report has no series.
+func (e *GPUExporter) renderConfComputing(metricCh chan<- prometheus.Metric, state collect.ConfidentialComputing) {
+	descs := e.confComputeDescs
+
+	if state.CPUCapability != "" || state.GPUCapability != "" {
+		e.sendLabeledGauge(metricCh, descs.capabilities, 1, state.CPUCapability, state.GPUCapability)
+	}
+
+	if state.Mode != "" {
+		e.sendLabeledGauge(metricCh, descs.mode, 1, state.Mode, state.Environment)
+	}
+
+	if state.MultiGPUMode != "" {
+		e.sendLabeledGauge(metricCh, descs.multiGPUMode, 1, state.MultiGPUMode)
+	}
+
+	if state.Ready != nil {
+		e.sendLabeledGauge(metricCh, descs.ready, *state.Ready)
+	}
+
+	if state.KeyRotationAttackerAdvantage != nil {
+		e.sendLabeledGauge(metricCh, descs.keyRotation, *state.KeyRotationAttackerAdvantage)
+	}
+
+	for _, gpu := range state.GPUs {
+		if gpu.Mode != "" {
+			e.sendLabeledGauge(metricCh, descs.gpuMode, 1, gpu.UUID, gpu.Mode)
+		}
+
+		if gpu.ProtectedMemoryBytes != nil {
+			e.sendLabeledGauge(metricCh, descs.protectedMemory, *gpu.ProtectedMemoryBytes, gpu.UUID)
+		}
+
+		if gpu.UnprotectedMemoryBytes != nil {
+			e.sendLabeledGauge(metricCh, descs.unprotectedMem, *gpu.UnprotectedMemoryBytes, gpu.UUID)
+		}
+	}
+}
+
+// renderVGPUHost emits one hypervisor GPU's vGPU series; a reading the
+// driver could not report has no series.
+func (e *GPUExporter) renderVGPUHost(metricCh chan<- prometheus.Metric, host collect.VGPUHost) {
+	descs := e.vgpuDescs
+
+	e.sendLabeledGauge(metricCh, descs.active, float64(len(host.Instances)), host.UUID)
+
+	for _, instance := range host.Instances {
+		e.sendLabeledGauge(metricCh, descs.info, 1,
+			host.UUID, instance.UUID, instance.VMID, instance.Type, instance.Class)
+
+		gauges := []struct {
+			desc  *prometheus.Desc
+			value *float64
+		}{
+			{descs.framebuffer, instance.FramebufferUsedBytes},
+			{descs.encoder, instance.EncoderSessions},
+			{descs.smUtil, instance.SMRatio},
+			{descs.memoryUtil, instance.MemoryRatio},
+			{descs.encoderUtil, instance.EncoderRatio},
+			{descs.decoderUtil, instance.DecoderRatio},
+		}
+
+		for _, gauge := range gauges {
+			if gauge.value != nil {
+				e.sendLabeledGauge(metricCh, gauge.desc, *gauge.value, host.UUID, instance.UUID)
+			}
+		}
+	}
+}
+
+// renderSuperchip emits one superchip GPU's series; a reading the driver
+// could not report has no series.
+func (e *GPUExporter) renderSuperchip(metricCh chan<- prometheus.Metric, superchip collect.Superchip) {
+	descs := e.superchipDescs
+
+	if superchip.Links != nil {
+		e.sendLabeledGauge(metricCh, descs.links, *superchip.Links, superchip.UUID)
+	}
+
+	if superchip.ModuleEnergyJoules != nil {
+		e.sendLabeledCounter(metricCh, descs.moduleEnergy, *superchip.ModuleEnergyJoules, superchip.UUID)
+	}
+
+	if superchip.MemoryNUMANode != nil {
+		e.sendLabeledGauge(metricCh, descs.numaNode, *superchip.MemoryNUMANode, superchip.UUID)
+	}
+
+	for _, link := range superchip.C2CLinks {
+		gauges := []struct {
+			desc  *prometheus.Desc
+			value *float64
+		}{
+			{descs.linkUp, link.Up},
+			{descs.maxBandwidth, link.MaxBandwidthBytesPerSecond},
+			{descs.lowPower, link.LowPower},
+		}
+
+		for _, gauge := range gauges {
+			if gauge.value != nil {
+				e.sendLabeledGauge(metricCh, gauge.desc, *gauge.value, superchip.UUID, link.Link)
+			}
+		}
+
+		counters := []struct {
+			kind  string
+			value *float64
+		}{
+			{"crc", link.CRCErrors},
+			{"replay", link.ReplayErrors},
+			{"replay_back_to_back", link.BackToBackReplayErrors},
+		}
+
+		for _, counter := range counters {
+			if counter.value != nil {
+				e.sendLabeledCounter(metricCh, descs.linkErrors, *counter.value,
+					superchip.UUID, link.Link, counter.kind)
+			}
+		}
+	}
+}
+
+// renderFan emits one fan's series; a reading the driver could not report
+// has no series.
+func (e *GPUExporter) renderFan(metricCh chan<- prometheus.Metric, fan collect.Fan) {
+	if fan.SpeedRatio != nil {
+		e.sendLabeledGauge(metricCh, e.fanDescs.speed, *fan.SpeedRatio, fan.UUID, fan.Fan)
+	}
+
+	if fan.TargetSpeedRatio != nil {
+		e.sendLabeledGauge(metricCh, e.fanDescs.target, *fan.TargetSpeedRatio, fan.UUID, fan.Fan)
+	}
+
+	if fan.Policy != "" {
+		e.sendLabeledGauge(metricCh, e.fanDescs.policy, 1, fan.UUID, fan.Fan, fan.Policy)
+	}
+}
+
+// renderNVLink emits one link's series. A link that is down reports only
+// its state; a counter the driver could not read has no series.
+func (e *GPUExporter) renderNVLink(metricCh chan<- prometheus.Metric, link collect.NVLink) {
+	state := 0.0
+	if link.Active {
+		state = 1
+	}
+
+	e.sendLabeledGauge(metricCh, e.nvlinkDescs.state, state, link.UUID, link.Link)
+
+	if link.Version != 0 {
+		e.sendLabeledGauge(metricCh, e.nvlinkDescs.version, float64(link.Version), link.UUID, link.Link)
+	}
+
+	if link.RemoteBusID != "" || link.RemoteDeviceType != "" {
+		e.sendLabeledGauge(metricCh, e.nvlinkDescs.remote, 1,
+			link.UUID, link.Link, link.RemoteBusID, link.RemoteDeviceType)
+	}
+
+	for _, entry := range []struct {
+		desc  *prometheus.Desc
+		value *float64
+	}{
+		{e.nvlinkDescs.txBytes, link.TXBytes},
+		{e.nvlinkDescs.rxBytes, link.RXBytes},
+		{e.nvlinkDescs.crcFlitErrors, link.CRCFlitErrors},
+		{e.nvlinkDescs.crcDataErrors, link.CRCDataErrors},
+		{e.nvlinkDescs.replayErrors, link.ReplayErrors},
+		{e.nvlinkDescs.recoveryErrors, link.RecoveryErrors},
+	} {
+		if entry.value == nil {
+			continue
+		}
+
+		e.sendLabeledCounter(metricCh, entry.desc, *entry.value, link.UUID, link.Link)
+	}
+}
+
+// renderCapabilities emits one GPU's capability series. A number the driver
+// could not report has no series; an unknown PCIe limit is an empty label.
+func (e *GPUExporter) renderCapabilities(metricCh chan<- prometheus.Metric, capabilities collect.GPUCapabilities) {
+	pcieLabel := func(value int) string {
+		if value == 0 {
+			return ""
+		}
+
+		return strconv.Itoa(value)
+	}
+
+	e.sendLabeledGauge(metricCh, e.capabilityDescs.info, 1, capabilities.UUID,
+		capabilities.Architecture, capabilities.Brand, capabilities.BoardPartNumber,
+		pcieLabel(capabilities.PCIeMaxGeneration), pcieLabel(capabilities.PCIeMaxWidth))
+
+	if capabilities.Cores != nil {
+		e.sendLabeledGauge(metricCh, e.capabilityDescs.cores, *capabilities.Cores, capabilities.UUID)
+	}
+
+	if capabilities.MemoryBusWidthBits != nil {
+		e.sendLabeledGauge(metricCh, e.capabilityDescs.memoryBusWidth, *capabilities.MemoryBusWidthBits,
+			capabilities.UUID)
+	}
+
+	for _, clock := range capabilities.MaxClocks {
+		e.sendLabeledGauge(metricCh, e.capabilityDescs.maxClock, clock.Hz, capabilities.UUID, clock.Clock)
+	}
+
+	for _, clockRange := range capabilities.SupportedClocks {
+		e.sendLabeledGauge(metricCh, e.capabilityDescs.supportedClock, clockRange.MinHz,
+			capabilities.UUID, clockRange.PState, clockRange.Clock, "min")
+		e.sendLabeledGauge(metricCh, e.capabilityDescs.supportedClock, clockRange.MaxHz,
+			capabilities.UUID, clockRange.PState, clockRange.Clock, "max")
+	}
+}
+
+// renderDriverSamples emits the sample window summaries and the power
+// histograms. A window of a kind this exporter does not know is skipped.
+func (e *GPUExporter) renderDriverSamples(metricCh chan<- prometheus.Metric, extras collect.Extras) {
+	for _, window := range extras.DriverSamples {
+		desc := e.driverSampleDescs.windows[window.Kind]
+		if desc == nil {
+			continue
+		}
+
+		e.sendLabeledGauge(metricCh, desc, window.Min, window.UUID, "min")
+		e.sendLabeledGauge(metricCh, desc, window.Max, window.UUID, "max")
+		e.sendLabeledGauge(metricCh, desc, window.Avg, window.UUID, "avg")
+	}
+
+	for _, histogram := range extras.PowerHistograms {
+		metric, err := prometheus.NewConstNativeHistogram(e.driverSampleDescs.powerHistory,
+			histogram.Count, histogram.Sum, histogram.Buckets, nil, histogram.ZeroCount,
+			collect.NativeHistogramSchema, 0, histogram.Created, histogram.UUID)
+		if err != nil {
+			e.logger.Error("failed to create metric", "err", err, "uuid", histogram.UUID)
+
+			continue
+		}
+
+		e.sendMetric(metricCh, metric)
+	}
+}
+
+// renderGPM emits one GPU's profiling series; a metric the driver could not
+// compute has no series.
+func (e *GPUExporter) renderGPM(metricCh chan<- prometheus.Metric, profile collect.GPMProfile) {
+	for _, entry := range []struct {
+		desc  *prometheus.Desc
+		value *float64
+	}{
+		{e.gpmDescs.smActivity, profile.SMActivityRatio},
+		{e.gpmDescs.smOccupancy, profile.SMOccupancyRatio},
+		{e.gpmDescs.tensorActivity, profile.TensorActivityRatio},
+		{e.gpmDescs.dramActivity, profile.DRAMActivityRatio},
+		{e.gpmDescs.fp64Activity, profile.FP64ActivityRatio},
+		{e.gpmDescs.fp32Activity, profile.FP32ActivityRatio},
+		{e.gpmDescs.fp16Activity, profile.FP16ActivityRatio},
+		{e.gpmDescs.nvlinkTx, profile.NVLinkTXBytesPerSecond},
+		{e.gpmDescs.nvlinkRx, profile.NVLinkRXBytesPerSecond},
+		{e.gpmDescs.pcieTx, profile.PCIeTXBytesPerSecond},
+		{e.gpmDescs.pcieRx, profile.PCIeRXBytesPerSecond},
+	} {
+		if entry.value == nil {
+			continue
+		}
+
+		e.sendLabeledGauge(metricCh, entry.desc, *entry.value, profile.UUID)
 	}
 }
 
@@ -600,6 +2149,23 @@ func (e *GPUExporter) renderMIGInstance(
 	}
 }
 
+// renderMIGProfile emits one MIG profile's capacity and placement series. An
+// unknown remaining capacity has no series rather than a zero that would read
+// as a full GPU.
+func (e *GPUExporter) renderMIGProfile(metricCh chan<- prometheus.Metric, capacity collect.MIGProfileCapacity) {
+	e.sendLabeledGauge(metricCh, e.migDescs.profileMax, float64(capacity.Total), capacity.ParentUUID, capacity.Profile)
+
+	if capacity.Remaining != nil {
+		e.sendLabeledGauge(metricCh, e.migDescs.profileRemaining, float64(*capacity.Remaining),
+			capacity.ParentUUID, capacity.Profile)
+	}
+
+	for _, placement := range capacity.Placements {
+		e.sendLabeledGauge(metricCh, e.migDescs.placement, 1, capacity.ParentUUID, capacity.Profile,
+			strconv.Itoa(placement.Start), strconv.Itoa(placement.Size))
+	}
+}
+
 // sendLabeledGauge emits one constant gauge with the given label values,
 // logging instead of failing if it cannot be built.
 func (e *GPUExporter) sendLabeledGauge(
@@ -659,22 +2225,38 @@ func (e *GPUExporter) renderApps(metricCh chan<- prometheus.Metric, snapshot col
 	}
 
 	// zero-fill the per-GPU count from the GPU table, so an idle GPU reports
-	// an explicit 0 instead of a missing series
-	counts := make(map[string]float64, len(snapshot.Table.Rows))
+	// an explicit 0 instead of a missing series (per type, when typed)
+	countTypes := []string{""}
+	if e.appTypes {
+		countTypes = processTypes
+	}
+
+	type countKey struct{ uuid, procType string }
+
+	counts := make(map[countKey]float64, len(snapshot.Table.Rows)*len(countTypes))
+
 	for _, row := range snapshot.Table.Rows {
-		counts[nvidiasmi.NormalizeUUID(row.QFieldToCells[nvidiasmi.UUIDQField].RawValue)] = 0
+		uuid := nvidiasmi.NormalizeUUID(row.QFieldToCells[nvidiasmi.UUIDQField].RawValue)
+		for _, procType := range countTypes {
+			counts[countKey{uuid, procType}] = 0
+		}
 	}
 
 	for _, app := range snapshot.Apps {
-		counts[app.GPUUUID]++
+		counts[countKey{app.GPUUUID, app.Type}]++
 
 		e.renderApp(metricCh, app)
 	}
 
-	for uuid, count := range counts {
-		metric, err := prometheus.NewConstMetric(e.appCountDesc, prometheus.GaugeValue, count, uuid)
+	for key, count := range counts {
+		labelValues := []string{key.uuid}
+		if e.appTypes {
+			labelValues = append(labelValues, key.procType)
+		}
+
+		metric, err := prometheus.NewConstMetric(e.appCountDesc, prometheus.GaugeValue, count, labelValues...)
 		if err != nil {
-			e.logger.Error("failed to create compute apps count metric", "err", err, "uuid", uuid)
+			e.logger.Error("failed to create compute apps count metric", "err", err, "uuid", key.uuid)
 
 			continue
 		}
@@ -717,6 +2299,10 @@ func (e *GPUExporter) sendAppMetric(
 		labelValues = append(labelValues, app.GPUInstanceID, app.ComputeInstanceID)
 	}
 
+	if e.appTypes {
+		labelValues = append(labelValues, app.Type)
+	}
+
 	metric, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, value, labelValues...)
 	if err != nil {
 		e.logger.Error("failed to create per-process metric", "err", err, "pid", app.PID)
@@ -877,16 +2463,65 @@ var fixedMetricNames = []string{
 	// per-process
 	"compute_app_info", "compute_app_used_memory_bytes", "compute_apps",
 	"compute_apps_last_collect_success",
+	"compute_app_sm_utilization_ratio", "compute_app_memory_utilization_ratio",
+	"compute_app_encoder_utilization_ratio", "compute_app_decoder_utilization_ratio",
+	"compute_app_container_info",
+	// MPS sharing
+	"mps_control_daemon_info", "mps_server_info",
+	"mps_client_active_thread_ratio", "mps_client_pinned_memory_limit_bytes",
 	// nvml extras
 	"pcie_throughput_tx_bytes_per_second", "pcie_throughput_rx_bytes_per_second",
 	"energy_joules_total",
+	"temperature_threshold_celsius", "temperature_tlimit_threshold_celsius",
+	"pcie_replays_total", "pcie_replay_rollovers_total", "pcie_aer_errors_total",
+	"gpu_fan_speed_ratio", "gpu_fan_target_speed_ratio", "gpu_fan_control_policy_info",
+	// capabilities
+	"gpu_capabilities_info", "gpu_cores", "gpu_memory_bus_width_bits",
+	"gpu_max_clock_hz", "gpu_supported_clock_hz",
+	// topology
+	"gpu_topology_info", "gpu_numa_node",
+	// confidential computing
+	"confidential_computing_capabilities_info", "confidential_computing_mode_info",
+	"confidential_computing_multi_gpu_mode_info", "confidential_computing_gpus_ready",
+	"confidential_computing_key_rotation_attacker_advantage",
+	"gpu_confidential_computing_mode_info", "gpu_confidential_computing_protected_memory_size_bytes",
+	"gpu_confidential_computing_unprotected_memory_size_bytes",
+	// vGPU
+	"vgpu_active_instances", "vgpu_info", "vgpu_framebuffer_used_bytes", "vgpu_encoder_sessions",
+	"vgpu_sm_utilization_ratio", "vgpu_memory_utilization_ratio", "vgpu_encoder_utilization_ratio",
+	"vgpu_decoder_utilization_ratio",
+	// superchip
+	"superchip_c2c_links", "superchip_c2c_link_up", "superchip_c2c_link_max_bandwidth_bytes_per_second",
+	"superchip_c2c_link_errors_total", "superchip_c2c_link_low_power",
+	"superchip_module_energy_joules_total", "superchip_memory_numa_node",
 	"mig_info", "mig_memory_total_bytes", "mig_memory_used_bytes",
 	"mig_memory_free_bytes", "mig_memory_reserved_bytes",
 	"mig_graphics_activity_ratio", "mig_sm_activity_ratio", "mig_sm_occupancy_ratio",
 	"mig_tensor_activity_ratio",
 	"mig_pcie_throughput_tx_bytes_per_second", "mig_pcie_throughput_rx_bytes_per_second",
+	"mig_profile_instances_max", "mig_profile_instances_remaining", "mig_profile_placement_info",
+	// GPM profiling
+	"gpm_sm_activity_ratio", "gpm_sm_occupancy_ratio", "gpm_tensor_activity_ratio",
+	"gpm_dram_activity_ratio", "gpm_fp64_activity_ratio", "gpm_fp32_activity_ratio",
+	"gpm_fp16_activity_ratio",
+	"gpm_nvlink_throughput_tx_bytes_per_second", "gpm_nvlink_throughput_rx_bytes_per_second",
+	"gpm_pcie_throughput_tx_bytes_per_second", "gpm_pcie_throughput_rx_bytes_per_second",
+	// driver samples
+	"driver_samples_power_draw_watts", "driver_samples_utilization_gpu_ratio",
+	"driver_samples_utilization_memory_ratio", "driver_samples_clocks_graphics_hz",
+	"driver_samples_clocks_memory_hz", "driver_samples_power_draw_distribution_watts",
+	// accounting
+	"accounted_apps_completed_total", "accounted_apps_gpu_seconds_total",
+	"accounted_apps_max_memory_used_bytes",
+	// video sessions
+	"video_sessions", "video_session_average_fps", "video_session_average_latency_seconds",
+	// NVLink
+	"nvlink_state", "nvlink_version", "nvlink_remote_info",
+	"nvlink_tx_bytes_total", "nvlink_rx_bytes_total",
+	"nvlink_crc_flit_errors_total", "nvlink_crc_data_errors_total",
+	"nvlink_replay_errors_total", "nvlink_recovery_errors_total",
 	// XID
-	"xid_errors_total", "xid_last_timestamp_seconds",
+	"xid_errors_total", "xid_last_timestamp_seconds", "driver_events_total", "xid_info",
 }
 
 // reservedMetricNames returns the fully-qualified names no query field may
@@ -20,6 +20,7 @@ import (
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/exporter"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/xidcatalog"
 )
 
 const delta = 1e-9
@@ -292,7 +293,7 @@ func newTestExporter(
 
 	source := collect.NewLive(query, 0, nil, logger)
 
-	return exporter.New(ctx, prefix, resolved, source, exporter.Features{}, nil, exporter.ExecExitCodeMetric, logger)
+	return exporter.New(ctx, prefix, resolved, source, exporter.Features{}, nil, nil, exporter.ExecExitCodeMetric, logger)
 }
 
 // staticSource serves a fixed snapshot, for driving the render paths directly.
@@ -322,6 +323,7 @@ func newAppsExporter(t *testing.T, snapshot collect.Snapshot) *exporter.GPUExpor
 		&staticSource{snapshot: snapshot},
 		exporter.Features{ComputeApps: true},
 		nil,
+		nil,
 		exporter.ExecExitCodeMetric,
 		logger,
 	)
@@ -345,6 +347,7 @@ func newExtrasExporter(t *testing.T, features exporter.Features, snapshot collec
 		&staticSource{snapshot: snapshot},
 		features,
 		nil,
+		nil,
 		exporter.ExecExitCodeMetric,
 		logger,
 	)
@@ -559,7 +562,7 @@ func TestCollectDeliversMetricsOnFatalError(t *testing.T) {
 	}
 
 	source := collect.NewLive(query, 0, func(fatalErr error) { cancel(fatalErr) }, logger)
-	exp := exporter.New(ctx, "aaa", resolved, source, exporter.Features{}, nil, exporter.ExecExitCodeMetric, logger)
+	exp := exporter.New(ctx, "aaa", resolved, source, exporter.Features{}, nil, nil, exporter.ExecExitCodeMetric, logger)
 
 	families := gatherFamilies(t, exp)
 
@@ -667,6 +670,7 @@ func TestCollectComputeAppsDisabled(t *testing.T) {
 		source,
 		exporter.Features{},
 		nil,
+		nil,
 		exporter.ExecExitCodeMetric,
 		logger,
 	)
@@ -815,6 +819,9 @@ func migExtras() collect.Extras {
 				// memory and utilization unreadable: only the info series
 			},
 		},
+		MIGProfiles: []collect.MIGProfileCapacity{
+			{ParentUUID: "abc", Profile: "1g.10gb", Total: 7, Remaining: new(0)},
+		},
 	}
 }
 
@@ -858,6 +865,35 @@ func TestMIGExtrasRendered(t *testing.T) {
 	assertFloat(t, 1048576, pcieTx.GetMetric()[0].GetGauge().GetValue())
 }
 
+func TestMIGProfileCapacityRendered(t *testing.T) {
+	t.Parallel()
+
+	extras := collect.Extras{MIGProfiles: []collect.MIGProfileCapacity{
+		{
+			ParentUUID: "abc", Profile: "3g.40gb", Total: 2, Remaining: new(1),
+			Placements: []collect.MIGPlacement{{Start: 0, Size: 4}, {Start: 4, Size: 4}},
+		},
+		{ParentUUID: "abc", Profile: "7g.80gb", Total: 1},
+	}}
+
+	exp := newExtrasExporter(t, exporter.Features{MIG: true}, extrasSnapshot(gpuTable("GPU-ABC"), extras))
+	families := gatherFamilies(t, exp)
+
+	total := families["aaa_mig_profile_instances_max"].GetMetric()
+	require.Len(t, total, 2)
+	assert.Equal(t, "3g.40gb", labelValue(t, total[0], "profile"))
+	assertFloat(t, 2, total[0].GetGauge().GetValue())
+
+	remaining := families["aaa_mig_profile_instances_remaining"].GetMetric()
+	require.Len(t, remaining, 1, "an unknown remaining capacity must render nothing")
+	assertFloat(t, 1, remaining[0].GetGauge().GetValue())
+
+	placements := families["aaa_mig_profile_placement_info"].GetMetric()
+	require.Len(t, placements, 2)
+	assert.Equal(t, "4", labelValue(t, placements[1], "placement_start"))
+	assert.Equal(t, "4", labelValue(t, placements[1], "placement_size"))
+}
+
 func TestMIGSuppressedWhenOff(t *testing.T) {
 	t.Parallel()
 
@@ -898,13 +934,734 @@ func TestComputeAppMIGLabels(t *testing.T) {
 	require.Len(t, info.GetMetric()[0].GetLabel(), 3)
 }
 
-// staticXIDs is a canned XIDSource.
+func TestComputeAppTypesLabel(t *testing.T) {
+	t.Parallel()
+
+	apps := []nvidiasmi.ComputeApp{
+		{GPUUUID: "abc", PID: "42", ProcessName: "python", UsedMemory: "1 MiB", Type: nvidiasmi.ProcessTypeCompute},
+		{GPUUUID: "abc", PID: "7", ProcessName: "Xorg", UsedMemory: "3 MiB", Type: nvidiasmi.ProcessTypeGraphics},
+	}
+
+	exp := newExtrasExporter(t, exporter.Features{ComputeApps: true, ComputeAppTypes: true},
+		appsSnapshot(gpuTable("GPU-ABC"), apps, true))
+	families := gatherFamilies(t, exp)
+
+	memory, ok := families["aaa_compute_app_used_memory_bytes"]
+	require.True(t, ok)
+
+	types := map[string]string{}
+	for _, metric := range memory.GetMetric() {
+		types[labelValue(t, metric, "pid")] = labelValue(t, metric, "type")
+	}
+
+	assert.Equal(t, map[string]string{"42": "compute", "7": "graphics"}, types)
+
+	// the count is zero-filled per type, so "no MPS clients" is an explicit 0
+	count, ok := families["aaa_compute_apps"]
+	require.True(t, ok)
+
+	counts := map[string]float64{}
+	for _, metric := range count.GetMetric() {
+		counts[labelValue(t, metric, "type")] = metric.GetGauge().GetValue()
+	}
+
+	assert.Equal(t, map[string]float64{"compute": 1, "graphics": 1, "mps": 0}, counts)
+}
+
+func TestComputeAppUtilizationRendered(t *testing.T) {
+	t.Parallel()
+
+	extras := collect.Extras{ProcessUtilization: []collect.ProcessUtilization{{
+		UUID: "abc", PID: "42", ProcessName: "python",
+		SMRatio: 0.5, MemoryRatio: 0.2, EncoderRatio: 0.01, DecoderRatio: 0,
+	}}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{ComputeAppUtilization: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	for name, want := range map[string]float64{
+		"aaa_compute_app_sm_utilization_ratio":      0.5,
+		"aaa_compute_app_memory_utilization_ratio":  0.2,
+		"aaa_compute_app_encoder_utilization_ratio": 0.01,
+		"aaa_compute_app_decoder_utilization_ratio": 0,
+	} {
+		family, ok := families[name]
+		require.True(t, ok, name)
+		require.Len(t, family.GetMetric(), 1)
+		assertFloat(t, want, family.GetMetric()[0].GetGauge().GetValue())
+		assert.Equal(t, "42", labelValue(t, family.GetMetric()[0], "pid"))
+		assert.Equal(t, "python", labelValue(t, family.GetMetric()[0], "process_name"))
+	}
+
+	off := newExtrasExporter(t, exporter.Features{ComputeApps: true}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "_utilization_ratio",
+			"per-process utilization must not render when the feature is off")
+	}
+}
+
+func TestAccountingCountersRendered(t *testing.T) {
+	t.Parallel()
+
+	extras := collect.Extras{Accounting: []collect.AccountingCounter{{
+		UUID: "abc", Completed: 3, GPUSeconds: 42.5, PeakMemoryBytes: 1 << 30,
+	}}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{Accounting: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	for name, want := range map[string]float64{
+		"aaa_accounted_apps_completed_total":   3,
+		"aaa_accounted_apps_gpu_seconds_total": 42.5,
+	} {
+		family, ok := families[name]
+		require.True(t, ok, name)
+		require.Len(t, family.GetMetric(), 1)
+		assertFloat(t, want, family.GetMetric()[0].GetCounter().GetValue())
+		assert.Equal(t, "abc", labelValue(t, family.GetMetric()[0], "uuid"))
+	}
+
+	peak, ok := families["aaa_accounted_apps_max_memory_used_bytes"]
+	require.True(t, ok)
+	assertFloat(t, 1<<30, peak.GetMetric()[0].GetGauge().GetValue())
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "accounted_apps",
+			"the accounting counters must not render when the feature is off")
+	}
+}
+
+func TestAppContainersRendered(t *testing.T) {
+	t.Parallel()
+
+	extras := collect.Extras{Containers: []collect.ProcessContainer{{
+		UUID: "abc", PID: "42", ContainerID: "4f1c0a6b", Name: "trainer-1",
+		Image: "pytorch/pytorch:latest", ComposeProject: "ml",
+	}}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{ComputeAppContainers: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	family, ok := families["aaa_compute_app_container_info"]
+	require.True(t, ok)
+	require.Len(t, family.GetMetric(), 1)
+
+	metric := family.GetMetric()[0]
+	assertFloat(t, 1, metric.GetGauge().GetValue())
+
+	for label, want := range map[string]string{
+		"uuid": "abc", "pid": "42", "container_id": "4f1c0a6b", "container_name": "trainer-1",
+		"image": "pytorch/pytorch:latest", "compose_project": "ml",
+	} {
+		assert.Equal(t, want, labelValue(t, metric, label), label)
+	}
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	assert.NotContains(t, gatherFamilies(t, off), "aaa_compute_app_container_info",
+		"the container series must not render when the feature is off")
+}
+
+func TestMPSSharingRendered(t *testing.T) {
+	t.Parallel()
+
+	ratio, pinned := 0.25, uint64(8<<30)
+	extras := collect.Extras{MPS: collect.MPSSharing{
+		ControlDaemonPIDs: []string{"900"},
+		Servers:           []collect.MPSServer{{UUID: "abc", PID: "901"}},
+		Clients: []collect.MPSClient{
+			{UUID: "abc", PID: "1000", ActiveThreadRatio: &ratio, PinnedMemoryLimitBytes: &pinned},
+			{UUID: "abc", PID: "1001"},
+		},
+	}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{ComputeAppMPS: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	daemon, ok := families["aaa_mps_control_daemon_info"]
+	require.True(t, ok)
+	require.Len(t, daemon.GetMetric(), 1)
+	assert.Equal(t, "900", labelValue(t, daemon.GetMetric()[0], "pid"))
+
+	server, ok := families["aaa_mps_server_info"]
+	require.True(t, ok)
+	require.Len(t, server.GetMetric(), 1)
+	assert.Equal(t, "901", labelValue(t, server.GetMetric()[0], "pid"))
+
+	// the client without limits has no limit series, not zeros
+	for name, want := range map[string]float64{
+		"aaa_mps_client_active_thread_ratio":       0.25,
+		"aaa_mps_client_pinned_memory_limit_bytes": 8 << 30,
+	} {
+		family, ok := families[name]
+		require.True(t, ok, name)
+		require.Len(t, family.GetMetric(), 1, name)
+		assertFloat(t, want, family.GetMetric()[0].GetGauge().GetValue())
+		assert.Equal(t, "1000", labelValue(t, family.GetMetric()[0], "pid"))
+	}
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "mps_", "the MPS families must not render when the feature is off")
+	}
+}
+
+func TestVideoSessionsRendered(t *testing.T) {
+	t.Parallel()
+
+	extras := collect.Extras{VideoSessions: []collect.VideoSessions{
+		{UUID: "abc", Type: collect.VideoSessionEncoder, Sessions: []collect.VideoSession{
+			{
+				ID: "7", PID: "42", Codec: "hevc", Resolution: "3840x2160",
+				AverageFPS: 60, AverageLatencySeconds: 0.0045,
+			},
+			{ID: "8", PID: "42", Codec: "hevc", Resolution: "3840x2160", AverageFPS: 30},
+		}},
+		{UUID: "abc", Type: collect.VideoSessionFBC},
+	}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{VideoSessions: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	counts := map[string]float64{}
+	for _, metric := range families["aaa_video_sessions"].GetMetric() {
+		counts[labelValue(t, metric, "session_type")] = metric.GetGauge().GetValue()
+	}
+
+	assert.Equal(t, map[string]float64{"encoder": 2, "fbc": 0}, counts,
+		"a session type read with no sessions reports zero")
+
+	fps := families["aaa_video_session_average_fps"].GetMetric()
+	require.Len(t, fps, 2, "sessions of identical shape stay apart by session id")
+	assert.Equal(t, "hevc", labelValue(t, fps[0], "codec"))
+	assert.Equal(t, "3840x2160", labelValue(t, fps[0], "resolution"))
+
+	latency := families["aaa_video_session_average_latency_seconds"].GetMetric()
+	require.Len(t, latency, 2)
+	assertFloat(t, 0.0045, latency[0].GetGauge().GetValue())
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "video_session",
+			"the video session families must not render when the feature is off")
+	}
+}
+
+func TestNVLinkRendered(t *testing.T) {
+	t.Parallel()
+
+	txBytes, replays := 4096.0, 3.0
+	extras := collect.Extras{NVLink: []collect.NVLink{
+		{
+			UUID: "abc", Link: "0", Active: true, Version: 4,
+			RemoteBusID: "00000000:07:00.0", RemoteDeviceType: collect.NVLinkRemoteSwitch,
+			TXBytes: &txBytes, ReplayErrors: &replays,
+		},
+		{UUID: "abc", Link: "1"},
+	}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{NVLink: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	states := map[string]float64{}
+	for _, metric := range families["aaa_nvlink_state"].GetMetric() {
+		states[labelValue(t, metric, "link")] = metric.GetGauge().GetValue()
+	}
+
+	assert.Equal(t, map[string]float64{"0": 1, "1": 0}, states, "a link that is down reports state 0")
+
+	remote := families["aaa_nvlink_remote_info"].GetMetric()
+	require.Len(t, remote, 1, "a link that is down has no far end")
+	assert.Equal(t, "00000000:07:00.0", labelValue(t, remote[0], "remote_pci_bus_id"))
+	assert.Equal(t, "switch", labelValue(t, remote[0], "remote_device_type"))
+
+	version := families["aaa_nvlink_version"].GetMetric()
+	require.Len(t, version, 1)
+	assertFloat(t, 4, version[0].GetGauge().GetValue())
+
+	tx := families["aaa_nvlink_tx_bytes_total"].GetMetric()
+	require.Len(t, tx, 1)
+	assertFloat(t, 4096, tx[0].GetCounter().GetValue())
+
+	replay := families["aaa_nvlink_replay_errors_total"].GetMetric()
+	require.Len(t, replay, 1)
+	assertFloat(t, 3, replay[0].GetCounter().GetValue())
+
+	// counters the driver could not read have no series, not zeros
+	assert.NotContains(t, families, "aaa_nvlink_rx_bytes_total")
+	assert.NotContains(t, families, "aaa_nvlink_crc_data_errors_total")
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "nvlink_", "the NVLink families must not render when the feature is off")
+	}
+}
+
+func TestGPMRendered(t *testing.T) {
+	t.Parallel()
+
+	smActivity, nvlinkTx := 0.5, 2048.0
+	extras := collect.Extras{GPM: []collect.GPMProfile{
+		{UUID: "abc", SMActivityRatio: &smActivity, NVLinkTXBytesPerSecond: &nvlinkTx},
+	}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{GPM: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	sm := families["aaa_gpm_sm_activity_ratio"].GetMetric()
+	require.Len(t, sm, 1)
+	assert.Equal(t, "abc", labelValue(t, sm[0], "uuid"))
+	assertFloat(t, 0.5, sm[0].GetGauge().GetValue())
+
+	tx := families["aaa_gpm_nvlink_throughput_tx_bytes_per_second"].GetMetric()
+	require.Len(t, tx, 1)
+	assertFloat(t, 2048, tx[0].GetGauge().GetValue())
+
+	// metrics the driver could not compute have no series, not zeros
+	assert.NotContains(t, families, "aaa_gpm_dram_activity_ratio")
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "gpm_", "the GPM families must not render when the feature is off")
+	}
+}
+
+func TestTemperatureThresholdsRendered(t *testing.T) {
+	t.Parallel()
+
+	extras := collect.Extras{TemperatureThresholds: []collect.TemperatureThreshold{
+		{UUID: "abc", Kind: nvidiasmi.TemperatureThresholdSlowdown, Celsius: 97},
+		{UUID: "abc", Kind: nvidiasmi.TemperatureThresholdShutdown, Celsius: 100},
+		{UUID: "def", Kind: nvidiasmi.TemperatureThresholdSlowdown, Celsius: -2, TLimit: true},
+	}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{TemperatureThresholds: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	absolute := families["aaa_temperature_threshold_celsius"].GetMetric()
+	require.Len(t, absolute, 2)
+
+	for _, metric := range absolute {
+		assert.Equal(t, "abc", labelValue(t, metric, "uuid"))
+
+		if labelValue(t, metric, "threshold") == nvidiasmi.TemperatureThresholdSlowdown {
+			assertFloat(t, 97, metric.GetGauge().GetValue())
+		}
+	}
+
+	tLimit := families["aaa_temperature_tlimit_threshold_celsius"].GetMetric()
+	require.Len(t, tLimit, 1)
+	assert.Equal(t, "def", labelValue(t, tLimit[0], "uuid"))
+	assertFloat(t, -2, tLimit[0].GetGauge().GetValue())
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "threshold", "the threshold families must not render when the feature is off")
+	}
+}
+
+func TestPCIeErrorsRendered(t *testing.T) {
+	t.Parallel()
+
+	counter := func(v float64) *float64 { return &v }
+
+	extras := collect.Extras{PCIeErrors: []collect.PCIeErrorCounters{
+		{
+			UUID: "abc", Replays: counter(9), ReplayRollovers: counter(0),
+			CorrectableErrors: counter(41), UncorrectableErrors: counter(0), FatalErrors: counter(0),
+		},
+		// the exec backend reads no AER counts
+		{UUID: "def", Replays: counter(3), ReplayRollovers: counter(1)},
+	}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{PCIeErrors: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	replays := families["aaa_pcie_replays_total"].GetMetric()
+	require.Len(t, replays, 2)
+	assert.Equal(t, "abc", labelValue(t, replays[0], "uuid"))
+	assertFloat(t, 9, replays[0].GetCounter().GetValue())
+
+	require.Len(t, families["aaa_pcie_replay_rollovers_total"].GetMetric(), 2)
+
+	aer := families["aaa_pcie_aer_errors_total"].GetMetric()
+	require.Len(t, aer, 3, "only the GPU reading AER counts has the series")
+
+	for _, metric := range aer {
+		assert.Equal(t, "abc", labelValue(t, metric, "uuid"))
+
+		if labelValue(t, metric, "severity") == "correctable" {
+			assertFloat(t, 41, metric.GetCounter().GetValue())
+		}
+	}
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "pcie_", "the PCIe error families must not render when the feature is off")
+	}
+}
+
+func TestFansRendered(t *testing.T) {
+	t.Parallel()
+
+	ratio := func(v float64) *float64 { return &v }
+
+	extras := collect.Extras{Fans: []collect.Fan{
+		{UUID: "abc", Fan: "0", SpeedRatio: ratio(0.45), TargetSpeedRatio: ratio(0.45), Policy: collect.FanPolicyAuto},
+		{UUID: "abc", Fan: "1", SpeedRatio: ratio(0), TargetSpeedRatio: ratio(0.45), Policy: collect.FanPolicyAuto},
+		// a fan that can only report its speed
+		{UUID: "def", Fan: "0", SpeedRatio: ratio(0.3)},
+	}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{Fans: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	speeds := families["aaa_gpu_fan_speed_ratio"].GetMetric()
+	require.Len(t, speeds, 3)
+
+	for _, metric := range speeds {
+		if labelValue(t, metric, "uuid") == "abc" && labelValue(t, metric, "fan") == "1" {
+			assertFloat(t, 0, metric.GetGauge().GetValue())
+		}
+	}
+
+	require.Len(t, families["aaa_gpu_fan_target_speed_ratio"].GetMetric(), 2)
+
+	policies := families["aaa_gpu_fan_control_policy_info"].GetMetric()
+	require.Len(t, policies, 2)
+	assert.Equal(t, collect.FanPolicyAuto, labelValue(t, policies[0], "policy"))
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "gpu_fan", "the fan families must not render when the feature is off")
+	}
+}
+
+func TestConfidentialComputingRendered(t *testing.T) {
+	t.Parallel()
+
+	value := func(v float64) *float64 { return &v }
+
+	extras := collect.Extras{ConfidentialComputing: collect.ConfidentialComputing{
+		CPUCapability: "amd_sev_snp",
+		GPUCapability: "capable",
+		Mode:          collect.CCModeOn,
+		Environment:   "prod",
+		Ready:         value(1),
+		GPUs: []collect.ConfidentialComputingGPU{
+			{
+				UUID: "abc", Mode: collect.CCModeOn,
+				ProtectedMemoryBytes: value(80 << 30), UnprotectedMemoryBytes: value(1 << 30),
+			},
+			// a GPU that cannot report its memory split
+			{UUID: "def", Mode: collect.CCModeOn},
+		},
+	}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{ConfidentialComputing: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	capabilities := families["aaa_confidential_computing_capabilities_info"].GetMetric()
+	require.Len(t, capabilities, 1)
+	assert.Equal(t, "amd_sev_snp", labelValue(t, capabilities[0], "cpu"))
+
+	modes := families["aaa_confidential_computing_mode_info"].GetMetric()
+	require.Len(t, modes, 1)
+	assert.Equal(t, collect.CCModeOn, labelValue(t, modes[0], "mode"))
+	assert.Equal(t, "prod", labelValue(t, modes[0], "environment"))
+
+	ready := families["aaa_confidential_computing_gpus_ready"].GetMetric()
+	require.Len(t, ready, 1)
+	assertFloat(t, 1, ready[0].GetGauge().GetValue())
+
+	assert.NotContains(t, families, "aaa_confidential_computing_multi_gpu_mode_info", "unknown, so absent")
+	assert.NotContains(t, families, "aaa_confidential_computing_key_rotation_attacker_advantage")
+
+	require.Len(t, families["aaa_gpu_confidential_computing_mode_info"].GetMetric(), 2)
+
+	protected := families["aaa_gpu_confidential_computing_protected_memory_size_bytes"].GetMetric()
+	require.Len(t, protected, 1)
+	assert.Equal(t, "abc", labelValue(t, protected[0], "uuid"))
+	assertFloat(t, 80<<30, protected[0].GetGauge().GetValue())
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "confidential_computing",
+			"the confidential computing families must not render when the feature is off")
+	}
+}
+
+func TestVGPUsRendered(t *testing.T) {
+	t.Parallel()
+
+	value := func(v float64) *float64 { return &v }
+
+	extras := collect.Extras{VGPUs: []collect.VGPUHost{
+		{UUID: "abc", Instances: []collect.VGPUInstance{
+			{
+				UUID: "v1", VMID: "vm-1", Type: "NVIDIA H100-40C", Class: "Compute",
+				FramebufferUsedBytes: value(8 << 30), EncoderSessions: value(0),
+				SMRatio: value(0.5), MemoryRatio: value(0.2), EncoderRatio: value(0), DecoderRatio: value(0),
+			},
+			// not sampled in the window yet
+			{UUID: "v2", VMID: "vm-2", Type: "NVIDIA H100-40C", Class: "Compute"},
+		}},
+		// a host with nothing running
+		{UUID: "def", Instances: []collect.VGPUInstance{}},
+	}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{VGPU: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	active := families["aaa_vgpu_active_instances"].GetMetric()
+	require.Len(t, active, 2)
+
+	for _, metric := range active {
+		want := map[string]float64{"abc": 2, "def": 0}[labelValue(t, metric, "uuid")]
+		assertFloat(t, want, metric.GetGauge().GetValue())
+	}
+
+	info := families["aaa_vgpu_info"].GetMetric()
+	require.Len(t, info, 2)
+	assert.Equal(t, "abc", labelValue(t, info[0], "uuid"))
+	assert.Equal(t, "v1", labelValue(t, info[0], "vgpu_uuid"))
+	assert.Equal(t, "vm-1", labelValue(t, info[0], "vm_id"))
+	assert.Equal(t, "NVIDIA H100-40C", labelValue(t, info[0], "type"))
+	assert.Equal(t, "Compute", labelValue(t, info[0], "class"))
+
+	framebuffer := families["aaa_vgpu_framebuffer_used_bytes"].GetMetric()
+	require.Len(t, framebuffer, 1, "an unknown reading has no series")
+	assertFloat(t, 8<<30, framebuffer[0].GetGauge().GetValue())
+
+	sm := families["aaa_vgpu_sm_utilization_ratio"].GetMetric()
+	require.Len(t, sm, 1)
+	assert.Equal(t, "v1", labelValue(t, sm[0], "vgpu_uuid"))
+	assertFloat(t, 0.5, sm[0].GetGauge().GetValue())
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "vgpu_", "the vGPU families must not render when the feature is off")
+	}
+}
+
+func TestSuperchipRendered(t *testing.T) {
+	t.Parallel()
+
+	value := func(v float64) *float64 { return &v }
+
+	extras := collect.Extras{Superchips: []collect.Superchip{{
+		UUID:  "abc",
+		Links: value(2),
+		C2CLinks: []collect.C2CLink{
+			{
+				Link: "0", Up: value(1), MaxBandwidthBytesPerSecond: value(45e9),
+				CRCErrors: value(3), ReplayErrors: value(5), BackToBackReplayErrors: value(0), LowPower: value(0),
+			},
+			// an inactive link reports no bandwidth
+			{Link: "1", Up: value(0), LowPower: value(1)},
+		},
+		ModuleEnergyJoules: value(987.654),
+		MemoryNUMANode:     value(4),
+	}}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{Superchip: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	links := families["aaa_superchip_c2c_links"].GetMetric()
+	require.Len(t, links, 1)
+	assert.Equal(t, "abc", labelValue(t, links[0], "uuid"))
+	assertFloat(t, 2, links[0].GetGauge().GetValue())
+
+	up := families["aaa_superchip_c2c_link_up"].GetMetric()
+	require.Len(t, up, 2)
+
+	bandwidth := families["aaa_superchip_c2c_link_max_bandwidth_bytes_per_second"].GetMetric()
+	require.Len(t, bandwidth, 1, "an unknown reading has no series")
+	assert.Equal(t, "0", labelValue(t, bandwidth[0], "link"))
+	assertFloat(t, 45e9, bandwidth[0].GetGauge().GetValue())
+
+	linkErrors := families["aaa_superchip_c2c_link_errors_total"].GetMetric()
+	require.Len(t, linkErrors, 3)
+
+	for _, metric := range linkErrors {
+		want := map[string]float64{"crc": 3, "replay": 5, "replay_back_to_back": 0}[labelValue(t, metric, "type")]
+		assertFloat(t, want, metric.GetCounter().GetValue())
+	}
+
+	energy := families["aaa_superchip_module_energy_joules_total"].GetMetric()
+	require.Len(t, energy, 1)
+	assertFloat(t, 987.654, energy[0].GetCounter().GetValue())
+
+	numaNode := families["aaa_superchip_memory_numa_node"].GetMetric()
+	require.Len(t, numaNode, 1)
+	assertFloat(t, 4, numaNode[0].GetGauge().GetValue())
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "superchip_", "the superchip families must not render when the feature is off")
+	}
+}
+
+func TestDriverSamplesRendered(t *testing.T) {
+	t.Parallel()
+
+	var histogram collect.NativeHistogram
+
+	histogram.UUID = "abc"
+	for _, watts := range []float64{250, 640, 180} {
+		histogram.Observe(watts, time.Now())
+	}
+
+	extras := collect.Extras{
+		DriverSamples: []collect.DriverSampleWindow{
+			collect.NewDriverSampleWindow("abc", collect.DriverSamplePower, []float64{250, 640, 180}),
+			{UUID: "abc", Kind: "unknown", Min: 1, Max: 1, Avg: 1, Samples: 1},
+		},
+		PowerHistograms: []collect.NativeHistogram{histogram},
+	}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{DriverSamples: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	power := families["aaa_driver_samples_power_draw_watts"].GetMetric()
+	require.Len(t, power, 3)
+
+	stats := map[string]float64{}
+	for _, metric := range power {
+		assert.Equal(t, "abc", labelValue(t, metric, "uuid"))
+		stats[labelValue(t, metric, "stat")] = metric.GetGauge().GetValue()
+	}
+
+	assert.Equal(t, map[string]float64{"min": 180, "max": 640, "avg": 1070.0 / 3}, stats)
+	assert.NotContains(t, families, "aaa_driver_samples_utilization_gpu_ratio")
+
+	distribution := families["aaa_driver_samples_power_draw_distribution_watts"].GetMetric()
+	require.Len(t, distribution, 1)
+	assert.Equal(t, uint64(3), distribution[0].GetHistogram().GetSampleCount())
+	assert.Equal(t, int32(collect.NativeHistogramSchema), distribution[0].GetHistogram().GetSchema())
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "driver_samples", "the sample families must not render when the feature is off")
+	}
+}
+
+func TestCapabilitiesRendered(t *testing.T) {
+	t.Parallel()
+
+	cores := 16896.0
+	extras := collect.Extras{Capabilities: []collect.GPUCapabilities{
+		{
+			UUID: "abc", Architecture: "Hopper", Brand: "NVIDIA", BoardPartNumber: "692-2G520-0200-000",
+			PCIeMaxGeneration: 5, PCIeMaxWidth: 16, Cores: &cores,
+			MaxClocks: []collect.CapabilityClock{{Clock: collect.CapabilityClockMemory, Hz: 2619e6}},
+			SupportedClocks: []collect.SupportedClockRange{
+				{PState: "P0", Clock: collect.CapabilityClockGraphics, MinHz: 345e6, MaxHz: 1980e6},
+			},
+		},
+		{UUID: "def"},
+	}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{Capabilities: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	info := families["aaa_gpu_capabilities_info"].GetMetric()
+	require.Len(t, info, 2)
+
+	for _, metric := range info {
+		if labelValue(t, metric, "uuid") == "abc" {
+			assert.Equal(t, "Hopper", labelValue(t, metric, "architecture"))
+			assert.Equal(t, "5", labelValue(t, metric, "pcie_link_gen_max"))
+		} else {
+			assert.Empty(t, labelValue(t, metric, "pcie_link_gen_max"), "an unknown limit is an empty label")
+		}
+	}
+
+	coreMetrics := families["aaa_gpu_cores"].GetMetric()
+	require.Len(t, coreMetrics, 1, "a GPU without a core count has no series")
+	assertFloat(t, 16896, coreMetrics[0].GetGauge().GetValue())
+	assert.NotContains(t, families, "aaa_gpu_memory_bus_width_bits")
+
+	maxClock := families["aaa_gpu_max_clock_hz"].GetMetric()
+	require.Len(t, maxClock, 1)
+	assert.Equal(t, collect.CapabilityClockMemory, labelValue(t, maxClock[0], "clock"))
+
+	supported := families["aaa_gpu_supported_clock_hz"].GetMetric()
+	require.Len(t, supported, 2)
+
+	for _, metric := range supported {
+		assert.Equal(t, "P0", labelValue(t, metric, "pstate"))
+
+		if labelValue(t, metric, "bound") == "max" {
+			assertFloat(t, 1980e6, metric.GetGauge().GetValue())
+		}
+	}
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	for family := range gatherFamilies(t, off) {
+		assert.NotContains(t, family, "capabilities", "the capability families must not render when the feature is off")
+	}
+}
+
+func TestTopologyRendered(t *testing.T) {
+	t.Parallel()
+
+	extras := collect.Extras{Topology: collect.Topology{
+		Links: []collect.TopologyLink{
+			{UUID: "abc", PeerUUID: "def", Link: nvidiasmi.TopologyLinkNVLink},
+			{UUID: "def", PeerUUID: "abc", Link: nvidiasmi.TopologyLinkNVLink},
+		},
+		NUMA: []collect.NUMAAffinity{{UUID: "abc", Node: 1, CPUAffinity: "24-47"}},
+	}}
+	snapshot := extrasSnapshot(gpuTable("GPU-ABC"), extras)
+
+	exp := newExtrasExporter(t, exporter.Features{Topology: true}, snapshot)
+	families := gatherFamilies(t, exp)
+
+	links := families["aaa_gpu_topology_info"].GetMetric()
+	require.Len(t, links, 2, "each GPU lists its peer")
+
+	for _, metric := range links {
+		assert.Equal(t, nvidiasmi.TopologyLinkNVLink, labelValue(t, metric, "link"))
+		assert.NotEqual(t, labelValue(t, metric, "uuid"), labelValue(t, metric, "peer_uuid"))
+	}
+
+	numa := families["aaa_gpu_numa_node"].GetMetric()
+	require.Len(t, numa, 1)
+	assert.Equal(t, "24-47", labelValue(t, numa[0], "cpu_affinity"))
+	assertFloat(t, 1, numa[0].GetGauge().GetValue())
+
+	off := newExtrasExporter(t, exporter.Features{}, snapshot)
+	families = gatherFamilies(t, off)
+	assert.NotContains(t, families, "aaa_gpu_topology_info", "the topology families must not render when off")
+	assert.NotContains(t, families, "aaa_gpu_numa_node")
+}
+
+// staticXIDs is a canned XIDSource and DriverEventSource.
 type staticXIDs struct {
 	counters []collect.XIDCounter
+	events   []collect.DriverEventCounter
 }
 
 func (s *staticXIDs) XIDCounts() []collect.XIDCounter { return s.counters }
 
+func (s *staticXIDs) DriverEventCounts() []collect.DriverEventCounter { return s.events }
+
 func TestXIDsRenderEvenWhenCollectionFails(t *testing.T) {
 	t.Parallel()
 
@@ -914,9 +1671,13 @@ func TestXIDsRenderEvenWhenCollectionFails(t *testing.T) {
 		t.Context(), "bbb", "fan.speed", "", 0, nvidiasmi.DefaultRunFunc, logger)
 	require.NoError(t, err)
 
-	xids := &staticXIDs{counters: []collect.XIDCounter{
-		{UUID: "abc", XID: 79, Count: 3, LastSeen: time.Unix(1700000000, 0)},
-	}}
+	xids := &staticXIDs{
+		counters: []collect.XIDCounter{{UUID: "abc", XID: 79, Count: 3, LastSeen: time.Unix(1700000000, 0)}},
+		events: []collect.DriverEventCounter{
+			{UUID: "abc", Type: collect.DriverEventECCDoubleBit, Count: 1},
+			{UUID: "abc", Type: collect.DriverEventXID, Count: 3},
+		},
+	}
 
 	// a failed collection: no table at all, which is exactly when the XID
 	// counters must stay visible
@@ -929,6 +1690,7 @@ func TestXIDsRenderEvenWhenCollectionFails(t *testing.T) {
 		&staticSource{snapshot: failed},
 		exporter.Features{XIDEvents: true},
 		xids,
+		xids,
 		exporter.ExecExitCodeMetric,
 		logger,
 	)
@@ -945,6 +1707,13 @@ func TestXIDsRenderEvenWhenCollectionFails(t *testing.T) {
 	stamps, ok := families["aaa_xid_last_timestamp_seconds"]
 	require.True(t, ok)
 	assertFloat(t, 1700000000, stamps.GetMetric()[0].GetGauge().GetValue())
+
+	events, ok := families["aaa_driver_events_total"]
+	require.True(t, ok, "driver event counters must render without a table")
+	assert.Equal(t, dto.MetricType_COUNTER, events.GetType())
+	require.Len(t, events.GetMetric(), 2)
+	assert.Equal(t, collect.DriverEventECCDoubleBit, labelValue(t, events.GetMetric()[0], "type"))
+	assertFloat(t, 1, events.GetMetric()[0].GetCounter().GetValue())
 }
 
 func TestXIDsSuppressedWhenOff(t *testing.T) {
@@ -958,7 +1727,10 @@ func TestXIDsSuppressedWhenOff(t *testing.T) {
 
 	// a live, populated source: the feature gate alone must suppress the
 	// families
-	xids := &staticXIDs{counters: []collect.XIDCounter{{UUID: "abc", XID: 79, Count: 3}}}
+	xids := &staticXIDs{
+		counters: []collect.XIDCounter{{UUID: "abc", XID: 79, Count: 3}},
+		events:   []collect.DriverEventCounter{{UUID: "abc", Type: collect.DriverEventXID, Count: 3}},
+	}
 
 	exp := exporter.New(
 		t.Context(),
@@ -967,6 +1739,7 @@ func TestXIDsSuppressedWhenOff(t *testing.T) {
 		&staticSource{snapshot: extrasSnapshot(gpuTable("GPU-ABC"), collect.Extras{})},
 		exporter.Features{},
 		xids,
+		xids,
 		exporter.ExecExitCodeMetric,
 		logger,
 	)
@@ -975,4 +1748,35 @@ func TestXIDsSuppressedWhenOff(t *testing.T) {
 
 	assert.NotContains(t, families, "aaa_xid_errors_total")
 	assert.NotContains(t, families, "aaa_xid_last_timestamp_seconds")
+	assert.NotContains(t, families, "aaa_driver_events_total")
+}
+
+func TestXIDCatalogCollector(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(exporter.NewXIDCatalogCollector("aaa", xidcatalog.Builtin())))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "aaa_xid_info", families[0].GetName())
+
+	metrics := families[0].GetMetric()
+	require.Len(t, metrics, len(xidcatalog.Builtin().Entries()))
+
+	var fallen *dto.Metric
+
+	for _, metric := range metrics {
+		assertFloat(t, 1, metric.GetGauge().GetValue())
+
+		if labelValue(t, metric, "xid") == "79" {
+			fallen = metric
+		}
+	}
+
+	require.NotNil(t, fallen)
+	assert.Equal(t, "GPU has fallen off the bus", labelValue(t, fallen, "description"))
+	assert.Equal(t, xidcatalog.SeverityCritical, labelValue(t, fallen, "severity"))
+	assert.NotEmpty(t, labelValue(t, fallen, "action"))
 }
@@ -59,7 +59,7 @@ func FuzzExporterRegisters(f *testing.F) {
 		}
 
 		exp := New(context.Background(), DefaultPrefix, fields, emptySource{},
-			features, nil, NVMLReturnCodeMetric, slog.New(slog.DiscardHandler))
+			features, nil, nil, NVMLReturnCodeMetric, slog.New(slog.DiscardHandler))
 
 		if err := prometheus.NewRegistry().Register(exp); err != nil {
 			t.Fatalf("returned fields %q and %q make the exporter unregistrable: %v", first, second, err)
@@ -26,12 +26,16 @@ func TestReservedMetricNamesCoverAll(t *testing.T) {
 
 	features := Features{
 		ComputeApps: true, ComputeAppMIGLabels: true, PCIeThroughput: true,
-		Energy: true, MIG: true, XIDEvents: true,
+		Energy: true, MIG: true, XIDEvents: true, ComputeAppUtilization: true,
+		ComputeAppTypes: true, Accounting: true, VideoSessions: true, ComputeAppContainers: true,
+		ComputeAppMPS: true, NVLink: true, GPM: true, TemperatureThresholds: true, PCIeErrors: true, Fans: true,
+		DriverSamples: true, Capabilities: true, Topology: true, ConfidentialComputing: true, VGPU: true,
+		Superchip: true,
 	}
 
 	for _, exitCodeMetric := range []ExitCodeMetric{ExecExitCodeMetric, NVMLReturnCodeMetric} {
 		exp := New(context.Background(), DefaultPrefix, fields, emptySource{},
-			features, nil, exitCodeMetric, slog.New(slog.DiscardHandler))
+			features, nil, nil, exitCodeMetric, slog.New(slog.DiscardHandler))
 
 		reserved := reservedMetricNames(DefaultPrefix, exitCodeMetric)
 
@@ -87,11 +87,13 @@ const maxReportedMismatches = 25
 // volatileFieldPrefixes are readings that legitimately change between the
 // bracket samples; their numeric values must fall inside the bracket, and
 // their non-numeric states must match one of the brackets. The classes are
-// the ones established by the H100 diff sessions.
+// the ones established by the H100 diff sessions, plus the module power draw
+// and the black box run time the catalog gained since.
 var volatileFieldPrefixes = []string{
 	"timestamp", "temperature.", "power.draw", "utilization.", "clocks.current.",
 	"memory.used", "memory.free", "fan.speed", "pstate", "encoder.stats.",
 	"clocks_event_reasons", "clocks_throttle_reasons", "ecc.errors.",
+	"module.power.draw", "bbx.time_run",
 }
 
 func isVolatileField(q nvidiasmi.QField) bool {
@@ -289,7 +291,8 @@ func absoluteSlack(qField nvidiasmi.QField) float64 {
 		return 500 // MHz; clocks can step several bins between samples
 	case strings.HasPrefix(string(qField), "utilization."):
 		return 100 // percent; utilization can swing fully between samples
-	case strings.HasPrefix(string(qField), "power.draw"):
+	case strings.HasPrefix(string(qField), "power.draw"),
+		strings.HasPrefix(string(qField), "module.power.draw"):
 		return 50 // watts
 	case strings.HasPrefix(string(qField), "memory."):
 		return 512 // MiB; allocations move between samples
@@ -450,10 +453,12 @@ var nvmlOnlyFamilyPrefixes = []string{
 	"nvidia_smi_mig_sm_occupancy_ratio",
 	"nvidia_smi_mig_tensor_activity_ratio",
 	"nvidia_smi_mig_pcie_throughput_",
-	// xid series appear only after an event is observed, so their presence
-	// is asserted by the GPU-box runbook (which triggers a real XID), not
-	// here
+	"nvidia_smi_mig_profile_",
+	// xid and driver event series appear only after an event is observed,
+	// so their presence is asserted by the GPU-box runbook (which triggers a
+	// real XID), not here
 	"nvidia_smi_xid_",
+	"nvidia_smi_driver_events_total",
 }
 
 func isNVMLOnlyFamily(family string) bool {
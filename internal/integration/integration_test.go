@@ -2,12 +2,14 @@ package integration_test
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"io/fs"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -290,7 +292,8 @@ const demoExpectedFile = "demo__seeded.metrics"
 // and compares the deterministic part of the scrape against the expected
 // output file, pinning the whole synthesized surface: the extras families,
 // the MIG topology and its deterministic uuids, the per-process MIG
-// attribution, and the seeded XID counts. Run with -update to regenerate.
+// attribution, the MPS topology, and the seeded XID counts. Run with -update
+// to regenerate.
 func TestDemoBackendExpectedMetrics(t *testing.T) {
 	t.Parallel()
 
@@ -298,7 +301,8 @@ func TestDemoBackendExpectedMetrics(t *testing.T) {
 		"--collect.backend=demo",
 		"--demo-config="+filepath.Join("testdata", "demo-config.yaml"),
 		"--collect.compute-apps",
-		"--collect.compute-apps-mig")
+		"--collect.compute-apps-mig",
+		"--collect.compute-apps-mps")
 
 	got := filterDeterministic(scrape(t, baseURL))
 	expectedPath := filepath.Join("testdata", demoExpectedFile)
@@ -600,6 +604,11 @@ func TestRoutingAndHealth(t *testing.T) {
 	// pprof is not enabled, so its paths are unknown too
 	status, _ = httpGet(t, baseURL+"/debug/pprof/")
 	assert.Equal(t, http.StatusNotFound, status)
+
+	// the exec backend watches no driver events without --collect.xid-log
+	status, _ = httpGet(t, baseURL+"/api/events")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.NotContains(t, body, `href="/api/events"`)
 }
 
 // TestTelemetryPathValidation pins the startup validation of the telemetry
@@ -1023,10 +1032,17 @@ func TestDemoBackendDefaultConfig(t *testing.T) {
 		"nvidia_smi_mig_memory_used_bytes",
 		"nvidia_smi_xid_errors_total",
 		"nvidia_smi_xid_last_timestamp_seconds",
+		"nvidia_smi_driver_events_total",
+		"nvidia_smi_xid_info",
 	} {
 		assert.Contains(t, first, family+"{", "family %s must be served by the built-in demo config", family)
 	}
 
+	// the seeded XIDs are in the event log too
+	status, body := httpGet(t, baseURL+"/api/events?xid=79")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 2, strings.Count(body, `"xid":79`))
+
 	assert.NotContains(t, first, "nvidia_smi_mig_sm_activity_ratio",
 		"MIG utilization needs a sample pair, like the real backend")
 	assert.Contains(t, second, "nvidia_smi_mig_sm_activity_ratio{",
@@ -1116,6 +1132,283 @@ func TestDemoBackendInvalidConfigFailsStartup(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to set up the demo backend")
 }
 
+// TestXIDCatalogOverride proves the XID catalog is served on the exec
+// backend too once it counts XIDs, with an override file's entries replacing
+// the built-in ones.
+func TestXIDCatalogOverride(t *testing.T) {
+	t.Parallel()
+
+	catalogPath := filepath.Join(t.TempDir(), "xids.yaml")
+	require.NoError(t, os.WriteFile(catalogPath, []byte(`xids:
+  - xid: 79
+    description: GPU has fallen off the bus
+    severity: critical
+    action: Open a hardware ticket
+    reset-required: true
+`), 0o600))
+
+	logPath := filepath.Join(t.TempDir(), "kernel.log")
+	require.NoError(t, os.WriteFile(logPath, nil, 0o600))
+
+	baseURL := startExporter(t,
+		"--nvidia-smi-command="+fakeCommand(defaultCapture(t)),
+		"--collect.xid-log="+logPath,
+		"--collect.xid-catalog="+catalogPath)
+	metrics := scrape(t, baseURL)
+
+	assert.Contains(t, metrics, `nvidia_smi_xid_info{action="Open a hardware ticket",`+
+		`description="GPU has fallen off the bus",severity="critical",xid="79"} 1`)
+	assert.Regexp(t, `nvidia_smi_xid_info\{[^}]*xid="13"\} 1`, metrics,
+		"the codes the file does not mention keep their built-in entries")
+}
+
+// TestExecBackendXIDLog proves the exec backend serves the XID families from
+// a followed kernel log, attributing the driver's lines to the GPU by its PCI
+// bus id.
+func TestExecBackendXIDLog(t *testing.T) {
+	t.Parallel()
+
+	logPath := filepath.Join(t.TempDir(), "kernel.log")
+	require.NoError(t, os.WriteFile(logPath, nil, 0o600))
+
+	baseURL := startExporter(t,
+		"--nvidia-smi-command="+fakeCommand(defaultCapture(t)),
+		"--collect.xid-log="+logPath)
+
+	// the first scrape runs a collection, which lists the GPU's bus id
+	assert.NotContains(t, scrape(t, baseURL), "nvidia_smi_xid_errors_total{")
+
+	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+
+	_, err = file.WriteString("[ 5210.553712] NVRM: Xid (PCI:0000:0c:00): 79, pid=1422, name=nvidia-smi, " +
+		"GPU has fallen off the bus.\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		metrics := scrape(t, baseURL)
+		assert.Contains(c, metrics,
+			`nvidia_smi_xid_errors_total{uuid="00000000-0000-0000-0000-000000000000",xid="79"} 1`)
+		assert.Contains(c, metrics,
+			`nvidia_smi_driver_events_total{type="xid",uuid="00000000-0000-0000-0000-000000000000"} 1`)
+	}, 10*time.Second, 50*time.Millisecond)
+
+	status, body := httpGet(t, baseURL+"/api/events?xid=79&uuid=GPU-00000000-0000-0000-0000-000000000000")
+	require.Equal(t, http.StatusOK, status)
+
+	var log struct {
+		Events []struct {
+			UUID     string `json:"uuid"`
+			Type     string `json:"type"`
+			XID      uint64 `json:"xid"`
+			PCIBusID string `json:"pciBusId"`
+		} `json:"events"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(body), &log))
+	require.Len(t, log.Events, 1)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", log.Events[0].UUID)
+	assert.Equal(t, "xid", log.Events[0].Type)
+	assert.Equal(t, uint64(79), log.Events[0].XID)
+	assert.Equal(t, "0000:0c:00", log.Events[0].PCIBusID)
+
+	status, _ = httpGet(t, baseURL+"/api/events?since=later")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+// TestStateDirCarriesCountersAcrossRestarts proves the XID counters a
+// process observed are served again by the next one sharing its state
+// directory, and keep counting from there.
+func TestStateDirCarriesCountersAcrossRestarts(t *testing.T) {
+	t.Parallel()
+
+	logPath := filepath.Join(t.TempDir(), "kernel.log")
+	require.NoError(t, os.WriteFile(logPath, nil, 0o600))
+
+	args := []string{
+		"--nvidia-smi-command=" + fakeCommand(defaultCapture(t)),
+		"--collect.xid-log=" + logPath,
+		"--state.dir=" + t.TempDir(),
+	}
+
+	appendXID := func() {
+		file, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0)
+		require.NoError(t, err)
+
+		_, err = file.WriteString("NVRM: Xid (PCI:0000:0c:00): 79, pid=1422, GPU has fallen off the bus.\n")
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+	}
+
+	xidSeries := `nvidia_smi_xid_errors_total{uuid="00000000-0000-0000-0000-000000000000",xid="79"} `
+
+	// the subtest's cleanup stops the first process, writing its checkpoint
+	t.Run("first process", func(t *testing.T) {
+		baseURL := startExporter(t, args...)
+		scrape(t, baseURL)
+		appendXID()
+
+		assert.EventuallyWithT(t, func(c *assert.CollectT) {
+			assert.Contains(c, scrape(t, baseURL), xidSeries+"1")
+		}, 10*time.Second, 50*time.Millisecond)
+	})
+
+	baseURL := startExporter(t, args...)
+	assert.Contains(t, scrape(t, baseURL), xidSeries+"1", "the restored count is served before any new XID")
+
+	appendXID()
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		metrics := scrape(t, baseURL)
+		assert.Contains(c, metrics, xidSeries+"2")
+		assert.Contains(c, metrics,
+			`nvidia_smi_driver_events_total{type="xid",uuid="00000000-0000-0000-0000-000000000000"} 2`)
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
+// TestNotifyWebhook proves a GPU asking for a recovery and a new XID each
+// reach a local webhook as a JSON notification, without waiting for a scrape
+// of the event.
+func TestNotifyWebhook(t *testing.T) {
+	t.Parallel()
+
+	notifications := make(chan map[string]any, 10)
+
+	webhook := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		var notification map[string]any
+		if assert.NoError(t, json.NewDecoder(req.Body).Decode(&notification)) {
+			notifications <- notification
+		}
+	}))
+	t.Cleanup(webhook.Close)
+
+	logPath := filepath.Join(t.TempDir(), "kernel.log")
+	require.NoError(t, os.WriteFile(logPath, nil, 0o600))
+
+	baseURL := startExporter(t,
+		"--nvidia-smi-command="+fakeCommand(defaultCapture(t), "--set", "gpu_recovery_action=Reset"),
+		"--collect.xid-log="+logPath,
+		"--notify.webhook-url="+webhook.URL+"/hooks/secret",
+		"--notify.xids=79")
+
+	receive := func() map[string]any {
+		select {
+		case notification := <-notifications:
+			return notification
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "no notification arrived")
+
+			return nil
+		}
+	}
+
+	// the first collection finds the GPU asking for a reset
+	scrape(t, baseURL)
+
+	notification := receive()
+	assert.Equal(t, "recovery_action", notification["kind"])
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", notification["uuid"])
+	assert.Equal(t, "Reset", notification["to"])
+
+	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+
+	_, err = file.WriteString("NVRM: Xid (PCI:0000:0c:00): 13, pid=1422, Graphics Exception.\n" +
+		"NVRM: Xid (PCI:0000:0c:00): 79, pid=1422, GPU has fallen off the bus.\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	notification = receive()
+	assert.Equal(t, "driver_event", notification["kind"])
+	assert.InDelta(t, 79, notification["xid"], 0, "XID 13 is not asked for")
+	assert.Equal(t, "critical", notification["severity"])
+	assert.Equal(t, "0000:0c:00", notification["pciBusId"])
+}
+
+// TestNotifyFlagsRequireWebhookURL proves the notification filters are
+// rejected without a webhook to notify, rather than silently idle.
+func TestNotifyFlagsRequireWebhookURL(t *testing.T) {
+	t.Parallel()
+
+	err := app.Run(t.Context(), []string{
+		"--web.listen-address=127.0.0.1:0",
+		"--log.level=error",
+		"--nvidia-smi-command=" + fakeCommand(defaultCapture(t)),
+		"--notify.xids=79",
+	}, app.Options{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "require --notify.webhook-url")
+}
+
+// TestPCIeErrorsExec proves the exec backend reads the replay counters out
+// of the nvidia-smi -q -x report and attributes them to the GPU by bus id,
+// without the AER counts the report does not carry. No capture records the XML
+// report, so the test appends one to a copy of the H100 capture.
+func TestPCIeErrorsExec(t *testing.T) {
+	t.Parallel()
+
+	const capture = "linux-x86_64__nvidia-h100-80gb-hbm3__590.48.01.txt"
+
+	content, err := fs.ReadFile(captures.FS, capture)
+	require.NoError(t, err)
+
+	report := "\n" + strings.Repeat("#", 80) + "\n" +
+		"# capabilities :: query (-q -x)\n" +
+		"# $ nvidia-smi -q -x\n" +
+		strings.Repeat("#", 80) + "\n" +
+		`<?xml version="1.0" ?>` + "\n" +
+		`<nvidia_smi_log><gpu id="00000000:00:09.0"><pci>` +
+		`<replay_counter>9</replay_counter><replay_rollover_counter>0</replay_rollover_counter>` +
+		"</pci></gpu></nvidia_smi_log>\n"
+
+	capturePath := filepath.Join(t.TempDir(), capture)
+	require.NoError(t, os.WriteFile(capturePath, append(content, report...), 0o600))
+
+	baseURL := startExporter(t, "--nvidia-smi-command="+fakeCommand(capturePath), "--collect.pcie-errors")
+
+	body := scrape(t, baseURL)
+	assert.Regexp(t, `nvidia_smi_pcie_replays_total\{uuid="[^"]+"\} 9\n`, body)
+	assert.Regexp(t, `nvidia_smi_pcie_replay_rollovers_total\{uuid="[^"]+"\} 0\n`, body)
+	assert.NotContains(t, body, "nvidia_smi_pcie_aer_errors_total{")
+}
+
+// TestExecBackendXIDLogMissingFailsStartup proves an unreadable kernel log is
+// a startup error rather than silently empty counters.
+func TestExecBackendXIDLogMissingFailsStartup(t *testing.T) {
+	t.Parallel()
+
+	err := app.Run(t.Context(), []string{
+		"--web.listen-address=127.0.0.1:0",
+		"--log.level=error",
+		"--nvidia-smi-command=" + fakeCommand(defaultCapture(t)),
+		"--collect.xid-log=" + filepath.Join(t.TempDir(), "missing"),
+	}, app.Options{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to set up --collect.xid-log")
+}
+
+// TestInvalidXIDCatalogFailsStartup proves a broken catalog file is a startup
+// error rather than a silently built-in catalog.
+func TestInvalidXIDCatalogFailsStartup(t *testing.T) {
+	t.Parallel()
+
+	catalogPath := filepath.Join(t.TempDir(), "xids.yaml")
+	require.NoError(t, os.WriteFile(catalogPath, []byte("xids:\n  - xid: 79\n    severity: fatal\n"), 0o600))
+
+	err := app.Run(t.Context(), []string{
+		"--web.listen-address=127.0.0.1:0",
+		"--log.level=error",
+		"--nvidia-smi-command=" + fakeCommand(defaultCapture(t)),
+		"--collect.xid-catalog=" + catalogPath,
+	}, app.Options{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load --collect.xid-catalog")
+}
+
 // TestNVMLBackendStartupFailureWithoutDriver pins the startup behavior on
 // machines without a usable NVML setup: builds without the backend fail with
 // the unavailability message, cgo builds fail initializing the absent
@@ -0,0 +1,316 @@
+// Package mps reads the MPS (Multi-Process Service) sharing state of a node:
+// which MPS daemons run, and which limits each MPS client runs under. The
+// driver reports none of it, so it is read from the processes themselves: the
+// daemons by their command line, the limits from the environment variables
+// the CUDA runtime reads them from.
+package mps
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+const (
+	// ControlDaemonName and ServerName are the executables of the MPS
+	// daemons. Both share one 15-character comm ("nvidia-cuda-mps"), so the
+	// command line tells them apart.
+	ControlDaemonName = "nvidia-cuda-mps-control"
+	ServerName        = "nvidia-cuda-mps-server"
+
+	// ActiveThreadPercentageEnv and PinnedMemoryLimitEnv are the variables
+	// the CUDA runtime reads a client's limits from. Set on the control
+	// daemon, they become the defaults for every client.
+	ActiveThreadPercentageEnv = "CUDA_MPS_ACTIVE_THREAD_PERCENTAGE"
+	PinnedMemoryLimitEnv      = "CUDA_MPS_PINNED_DEVICE_MEM_LIMIT"
+
+	visibleDevicesEnv = "CUDA_VISIBLE_DEVICES"
+)
+
+// DefaultProcRoot is where the process information lives.
+const DefaultProcRoot = "/proc"
+
+// Inspect reads the sharing state under procRoot (normally /proc). gpus lists
+// the GPU uuids in index order, which is the order the CUDA device ordinals of
+// a pinned memory limit refer to; apps is the cycle's typed process list,
+// where servers and clients are found. Processes that exited or cannot be
+// read are skipped: the reads race against process exit by nature.
+func Inspect(procRoot string, gpus []string, apps []nvidiasmi.ComputeApp, logger *slog.Logger) collect.MPSSharing {
+	var sharing collect.MPSSharing
+
+	var defaults limits
+
+	for _, pid := range controlDaemons(procRoot, logger) {
+		sharing.ControlDaemonPIDs = append(sharing.ControlDaemonPIDs, pid)
+
+		if len(sharing.ControlDaemonPIDs) == 1 {
+			// with several daemons (one per user) there is no telling which
+			// one serves a client: the first one's defaults apply
+			defaults = readLimits(procRoot, pid, logger)
+		}
+	}
+
+	// environments are read once per pid, however many GPUs list it
+	clientLimits := map[string]limits{}
+	seen := map[string]bool{}
+
+	for _, app := range apps {
+		key := app.GPUUUID + "/" + app.PID
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		if path.Base(app.ProcessName) == ServerName {
+			sharing.Servers = append(sharing.Servers, collect.MPSServer{UUID: app.GPUUUID, PID: app.PID})
+
+			continue
+		}
+
+		if app.Type != nvidiasmi.ProcessTypeMPS {
+			continue
+		}
+
+		own, ok := clientLimits[app.PID]
+		if !ok {
+			own = readLimits(procRoot, app.PID, logger)
+			clientLimits[app.PID] = own
+		}
+
+		sharing.Clients = append(sharing.Clients, own.orDefaults(defaults).client(app, gpus))
+	}
+
+	return sharing
+}
+
+// limits is one process's raw MPS limit settings.
+type limits struct {
+	activeThreadPercentage string
+	pinnedMemoryLimit      string
+	visibleDevices         string
+}
+
+// orDefaults fills the settings the client leaves unset from the control
+// daemon's. The visible devices stay the client's own: they define its
+// device ordinals, whatever the daemon sees.
+func (l limits) orDefaults(defaults limits) limits {
+	if l.activeThreadPercentage == "" {
+		l.activeThreadPercentage = defaults.activeThreadPercentage
+	}
+
+	if l.pinnedMemoryLimit == "" {
+		l.pinnedMemoryLimit = defaults.pinnedMemoryLimit
+	}
+
+	return l
+}
+
+// client resolves the settings for the client's entry on one GPU. Settings
+// that do not parse count as unset, like the CUDA runtime ignoring them.
+func (l limits) client(app nvidiasmi.ComputeApp, gpus []string) collect.MPSClient {
+	client := collect.MPSClient{UUID: app.GPUUUID, PID: app.PID}
+
+	if percentage, err := strconv.ParseFloat(l.activeThreadPercentage, 64); err == nil &&
+		percentage > 0 && percentage <= 100 {
+		ratio := percentage / 100
+		client.ActiveThreadRatio = &ratio
+	}
+
+	ordinal := deviceOrdinal(l.visibleDevices, gpus, app.GPUUUID)
+	if ordinal < 0 {
+		return client
+	}
+
+	if limit, ok := pinnedMemoryLimits(l.pinnedMemoryLimit)[ordinal]; ok {
+		client.PinnedMemoryLimitBytes = &limit
+	}
+
+	return client
+}
+
+// controlDaemons lists the pids of the running control daemons.
+func controlDaemons(procRoot string, logger *slog.Logger) []string {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		logger.Debug("failed to list the processes", "err", err)
+
+		return nil
+	}
+
+	var pids []string
+
+	for _, entry := range entries {
+		if _, err = strconv.ParseUint(entry.Name(), 10, 64); err != nil {
+			continue
+		}
+
+		cmdline, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "cmdline"))
+		if err != nil {
+			continue
+		}
+
+		argv0, _, _ := bytes.Cut(cmdline, []byte{0})
+		if path.Base(string(argv0)) == ControlDaemonName {
+			pids = append(pids, entry.Name())
+		}
+	}
+
+	return pids
+}
+
+// readLimits reads one process's MPS settings from its environment. The
+// environment is the one the process started with; MPS reads its limits at
+// startup too, so the two agree. Reading another user's environment needs
+// privileges, so a failure is routine and logged at debug level only.
+func readLimits(procRoot, pid string, logger *slog.Logger) limits {
+	environ, err := os.ReadFile(filepath.Join(procRoot, pid, "environ"))
+	if err != nil {
+		logger.Debug("failed to read the environment of a process", "pid", pid, "err", err)
+
+		return limits{}
+	}
+
+	var found limits
+
+	for variable := range bytes.SplitSeq(environ, []byte{0}) {
+		name, value, _ := strings.Cut(string(variable), "=")
+
+		switch name {
+		case ActiveThreadPercentageEnv:
+			found.activeThreadPercentage = strings.TrimSpace(value)
+		case PinnedMemoryLimitEnv:
+			found.pinnedMemoryLimit = strings.TrimSpace(value)
+		case visibleDevicesEnv:
+			found.visibleDevices = strings.TrimSpace(value)
+		}
+	}
+
+	return found
+}
+
+// deviceOrdinal returns the CUDA device ordinal the GPU has in a process with
+// the given CUDA_VISIBLE_DEVICES, -1 when the GPU is not visible to it.
+// Without the variable the ordinals follow the GPU index order, which assumes
+// CUDA enumerates in PCI bus order: true with CUDA_DEVICE_ORDER=PCI_BUS_ID and
+// on nodes of identical GPUs, not necessarily on mixed ones. Like the CUDA
+// runtime, the list is read up to its first entry that names no GPU. MIG
+// device entries name no GPU here: the limits of a MIG client cannot be
+// attributed.
+func deviceOrdinal(visibleDevices string, gpus []string, uuid string) int {
+	if visibleDevices == "" {
+		for ordinal, gpu := range gpus {
+			if gpu == uuid {
+				return ordinal
+			}
+		}
+
+		return -1
+	}
+
+	for ordinal, entry := range strings.Split(visibleDevices, ",") {
+		gpu, ok := visibleGPU(strings.TrimSpace(entry), gpus)
+		if !ok {
+			return -1
+		}
+
+		if gpu == uuid {
+			return ordinal
+		}
+	}
+
+	return -1
+}
+
+// visibleGPU resolves one CUDA_VISIBLE_DEVICES entry: a GPU index, or a GPU
+// uuid, which CUDA also accepts abbreviated to any unique prefix.
+func visibleGPU(entry string, gpus []string) (string, bool) {
+	if index, err := strconv.Atoi(entry); err == nil {
+		if index < 0 || index >= len(gpus) {
+			return "", false
+		}
+
+		return gpus[index], true
+	}
+
+	if !strings.HasPrefix(strings.ToLower(entry), "gpu-") {
+		return "", false
+	}
+
+	prefix := nvidiasmi.NormalizeUUID(entry)
+
+	var match string
+
+	for _, gpu := range gpus {
+		if strings.HasPrefix(gpu, prefix) {
+			if match != "" {
+				return "", false
+			}
+
+			match = gpu
+		}
+	}
+
+	return match, match != ""
+}
+
+// pinnedMemoryLimits parses a CUDA_MPS_PINNED_DEVICE_MEM_LIMIT value, a comma
+// separated list of ordinal=limit pairs ("0=1G,1=512M"), into bytes per
+// device ordinal. Pairs that do not parse are skipped.
+func pinnedMemoryLimits(value string) map[int]uint64 {
+	result := map[int]uint64{}
+
+	for pair := range strings.SplitSeq(value, ",") {
+		ordinalRaw, limitRaw, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+
+		ordinal, err := strconv.Atoi(strings.TrimSpace(ordinalRaw))
+		if err != nil || ordinal < 0 {
+			continue
+		}
+
+		limit, err := parseMemorySize(strings.TrimSpace(limitRaw))
+		if err != nil {
+			continue
+		}
+
+		result[ordinal] = limit
+	}
+
+	return result
+}
+
+// errMemorySize reports a memory size that does not parse.
+var errMemorySize = errors.New("invalid memory size")
+
+// parseMemorySize parses a size with an optional binary unit suffix, the way
+// MPS spells its limits ("512M", "2G", "1GB"). A bare number is bytes.
+func parseMemorySize(raw string) (uint64, error) {
+	upper := strings.TrimSuffix(strings.ToUpper(raw), "B")
+
+	multiplier := uint64(1)
+
+	if unit := strings.IndexAny(upper, "KMGT"); unit >= 0 && unit == len(upper)-1 {
+		multiplier = uint64(1) << (10 * (strings.IndexByte("KMGT", upper[unit]) + 1))
+		upper = upper[:unit]
+	}
+
+	number, err := strconv.ParseUint(upper, 10, 64)
+	if err != nil || number > math.MaxUint64/multiplier {
+		return 0, fmt.Errorf("%w %q", errMemorySize, raw)
+	}
+
+	return number * multiplier, nil
+}
@@ -0,0 +1,129 @@
+package mps_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/neilotoole/slogt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/mps"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+const (
+	gpu0 = "11111111-2222-3333-4444-555555555555"
+	gpu1 = "66666666-7777-8888-9999-000000000000"
+)
+
+// fakeProcess writes /proc/<pid>/cmdline and environ under procRoot.
+func fakeProcess(t *testing.T, procRoot, pid string, argv, env []string) {
+	t.Helper()
+
+	dir := filepath.Join(procRoot, pid)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cmdline"), []byte(strings.Join(argv, "\x00")+"\x00"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "environ"), []byte(strings.Join(env, "\x00")+"\x00"), 0o600))
+}
+
+func ratio(v float64) *float64 { return &v }
+
+func limit(v uint64) *uint64 { return &v }
+
+func TestInspect(t *testing.T) {
+	t.Parallel()
+
+	procRoot := t.TempDir()
+	fakeProcess(t, procRoot, "900", []string{"/usr/bin/nvidia-cuda-mps-control", "-d"},
+		[]string{"PATH=/usr/bin", "CUDA_MPS_ACTIVE_THREAD_PERCENTAGE=50", "CUDA_MPS_PINNED_DEVICE_MEM_LIMIT=0=8G,1=4G"})
+	fakeProcess(t, procRoot, "901", []string{"nvidia-cuda-mps-server"}, nil)
+	fakeProcess(t, procRoot, "1000", []string{"python", "train.py"},
+		[]string{"CUDA_MPS_ACTIVE_THREAD_PERCENTAGE=25", "CUDA_MPS_PINNED_DEVICE_MEM_LIMIT=0=2G,1=512MB"})
+	fakeProcess(t, procRoot, "1001", []string{"python", "serve.py"}, []string{"HOME=/root"})
+	fakeProcess(t, procRoot, "1002", []string{"python", "eval.py"},
+		[]string{"CUDA_VISIBLE_DEVICES=1", "CUDA_MPS_PINNED_DEVICE_MEM_LIMIT=0=1073741824"})
+	fakeProcess(t, procRoot, "1003", []string{"python", "bad.py"},
+		[]string{"CUDA_MPS_ACTIVE_THREAD_PERCENTAGE=250", "CUDA_MPS_PINNED_DEVICE_MEM_LIMIT=0=lots"})
+	require.NoError(t, os.MkdirAll(filepath.Join(procRoot, "self"), 0o755))
+
+	apps := []nvidiasmi.ComputeApp{
+		{GPUUUID: gpu0, PID: "901", ProcessName: "nvidia-cuda-mps-server", Type: nvidiasmi.ProcessTypeCompute},
+		{GPUUUID: gpu1, PID: "901", ProcessName: "nvidia-cuda-mps-server", Type: nvidiasmi.ProcessTypeCompute},
+		{GPUUUID: gpu0, PID: "1000", ProcessName: "python", Type: nvidiasmi.ProcessTypeMPS},
+		{GPUUUID: gpu1, PID: "1000", ProcessName: "python", Type: nvidiasmi.ProcessTypeMPS},
+		{GPUUUID: gpu0, PID: "1001", ProcessName: "python", Type: nvidiasmi.ProcessTypeMPS},
+		{GPUUUID: gpu1, PID: "1002", ProcessName: "python", Type: nvidiasmi.ProcessTypeMPS},
+		{GPUUUID: gpu0, PID: "1003", ProcessName: "python", Type: nvidiasmi.ProcessTypeMPS},
+		{GPUUUID: gpu0, PID: "1004", ProcessName: "python", Type: nvidiasmi.ProcessTypeMPS}, // exited
+		{GPUUUID: gpu0, PID: "2000", ProcessName: "blender", Type: nvidiasmi.ProcessTypeGraphics},
+	}
+
+	sharing := mps.Inspect(procRoot, []string{gpu0, gpu1}, apps, slogt.New(t))
+
+	assert.Equal(t, []string{"900"}, sharing.ControlDaemonPIDs)
+	assert.Equal(t, []collect.MPSServer{{UUID: gpu0, PID: "901"}, {UUID: gpu1, PID: "901"}}, sharing.Servers)
+	assert.Equal(t, []collect.MPSClient{
+		// its own settings
+		{UUID: gpu0, PID: "1000", ActiveThreadRatio: ratio(0.25), PinnedMemoryLimitBytes: limit(2 << 30)},
+		{UUID: gpu1, PID: "1000", ActiveThreadRatio: ratio(0.25), PinnedMemoryLimitBytes: limit(512 << 20)},
+		// the control daemon's defaults
+		{UUID: gpu0, PID: "1001", ActiveThreadRatio: ratio(0.5), PinnedMemoryLimitBytes: limit(8 << 30)},
+		// ordinal 0 is gpu1 in a process seeing only gpu1
+		{UUID: gpu1, PID: "1002", ActiveThreadRatio: ratio(0.5), PinnedMemoryLimitBytes: limit(1 << 30)},
+		// invalid settings count as unset
+		{UUID: gpu0, PID: "1003"},
+		// the environment is gone, the daemon's defaults still apply
+		{UUID: gpu0, PID: "1004", ActiveThreadRatio: ratio(0.5), PinnedMemoryLimitBytes: limit(8 << 30)},
+	}, sharing.Clients)
+}
+
+func TestInspectVisibleDevices(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name           string
+		visibleDevices string
+		want           *uint64
+	}{
+		{name: "index", visibleDevices: "1,0", want: limit(1 << 20)},
+		{name: "uuid", visibleDevices: "GPU-" + gpu1 + ",GPU-" + gpu0, want: limit(1 << 20)},
+		{name: "uuid prefix", visibleDevices: "GPU-6666,GPU-1111", want: limit(1 << 20)},
+		{name: "not visible", visibleDevices: "1"},
+		{name: "list cut at an invalid entry", visibleDevices: "1,7,0"},
+		{name: "mig device", visibleDevices: "MIG-7a2b3c4d-0000-0000-0000-000000000000,0"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			procRoot := t.TempDir()
+			fakeProcess(t, procRoot, "1000", []string{"python"}, []string{
+				"CUDA_VISIBLE_DEVICES=" + tc.visibleDevices,
+				"CUDA_MPS_PINNED_DEVICE_MEM_LIMIT=0=8G,1=1M",
+			})
+
+			sharing := mps.Inspect(procRoot, []string{gpu0, gpu1}, []nvidiasmi.ComputeApp{
+				{GPUUUID: gpu0, PID: "1000", Type: nvidiasmi.ProcessTypeMPS},
+			}, slogt.New(t))
+
+			require.Len(t, sharing.Clients, 1)
+			assert.Equal(t, tc.want, sharing.Clients[0].PinnedMemoryLimitBytes)
+		})
+	}
+}
+
+func TestInspectWithoutMPS(t *testing.T) {
+	t.Parallel()
+
+	procRoot := t.TempDir()
+	fakeProcess(t, procRoot, "1000", []string{"python"}, nil)
+
+	sharing := mps.Inspect(procRoot, []string{gpu0}, []nvidiasmi.ComputeApp{
+		{GPUUUID: gpu0, PID: "1000", Type: nvidiasmi.ProcessTypeCompute},
+	}, slogt.New(t))
+
+	assert.Equal(t, collect.MPSSharing{}, sharing)
+}
@@ -0,0 +1,579 @@
+// Package notify pushes driver events and GPU recovery action changes to
+// webhooks as they happen. The Prometheus path (a scrape, a rule evaluation,
+// Alertmanager's grouping) takes minutes to page anyone; a GPU that fell off
+// the bus deserves seconds. The notifier is a shortcut beside that path, not
+// a replacement: deliveries are best-effort, retried a few times, then
+// dropped with a log line.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/eventlog"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/xidcatalog"
+)
+
+// The notification kinds.
+const (
+	KindDriverEvent    = "driver_event"
+	KindRecoveryAction = "recovery_action"
+)
+
+// DefaultEventTypes are the driver event types notified by default: the ones
+// that need an operator, unlike the routine clock and power source changes.
+var DefaultEventTypes = []string{collect.DriverEventXID, collect.DriverEventECCDoubleBit}
+
+// The delivery pacing: how often the event log is read, how many attempts a
+// delivery gets and how long each may take, the backoff between attempts,
+// and how many notifications may wait for a slow webhook.
+const (
+	pollInterval   = 500 * time.Millisecond
+	maxAttempts    = 4
+	attemptTimeout = 5 * time.Second
+	backoffStart   = time.Second
+	queueSize      = 100
+)
+
+// Notification is what a webhook receives: the JSON encoding of it by
+// default, or what the configured template renders from it.
+type Notification struct {
+	// Kind is KindDriverEvent or KindRecoveryAction.
+	Kind string `json:"kind"`
+	// Time is when the event was received or the change was collected.
+	Time time.Time `json:"time"`
+	// Host is the exporter's hostname.
+	Host string `json:"host"`
+	// UUID is the GPU uuid, normalized like every uuid label.
+	UUID string `json:"uuid"`
+	// Type is the driver event type, for a driver event.
+	Type string `json:"type,omitempty"`
+	// XID is the code of an xid event, and Description, Severity, Action
+	// and ResetRequired its XID catalog entry, when it has one.
+	XID           uint64 `json:"xid,omitempty"`
+	Description   string `json:"description,omitempty"`
+	Severity      string `json:"severity,omitempty"`
+	Action        string `json:"action,omitempty"`
+	ResetRequired bool   `json:"resetRequired,omitempty"`
+	// PCIBusID and Message carry the kernel log line of an event read
+	// from it.
+	PCIBusID string `json:"pciBusId,omitempty"`
+	Message  string `json:"message,omitempty"`
+	// From and To are the recovery actions before and after a change;
+	// From is empty for the first one collected.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// Config configures a Notifier.
+type Config struct {
+	// URLs are the webhooks every notification is posted to.
+	URLs []string
+	// Template renders the request body; nil posts the JSON encoding.
+	Template *template.Template
+	// EventTypes are the driver event types to notify.
+	EventTypes []string
+	// XIDs restricts the xid events to these codes; empty notifies all.
+	XIDs []uint64
+	// DedupWindow suppresses a notification identical to one sent within
+	// it: the same GPU, kind, event type and code, or the same recovery
+	// action.
+	DedupWindow time.Duration
+	// RateLimit caps the notifications per minute across all GPUs, with a
+	// burst of as many; the excess is dropped. 0 disables the cap.
+	RateLimit int
+}
+
+// LoadTemplate parses a Go text/template for the request body. Beside the
+// Notification fields it can call json, which encodes a value as JSON, so a
+// template building a JSON body quotes strings safely.
+func LoadTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the template: %w", err)
+	}
+
+	tmpl, err := template.New("notification").Funcs(template.FuncMap{"json": toJSON}).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// toJSON encodes a value for a template.
+func toJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+
+	return string(data), err //nolint:wrapcheck // reported by the template execution
+}
+
+// eventTypes are the driver event types a notification can be asked for.
+var eventTypes = []string{
+	collect.DriverEventXID,
+	collect.DriverEventECCSingleBit,
+	collect.DriverEventECCDoubleBit,
+	collect.DriverEventClockChange,
+	collect.DriverEventPowerSourceChange,
+	collect.DriverEventMIGConfigChange,
+}
+
+// ParseEventTypes reads a comma-separated list of driver event types.
+func ParseEventTypes(raw string) ([]string, error) {
+	var types []string
+
+	for field := range strings.SplitSeq(raw, ",") {
+		eventType := strings.TrimSpace(field)
+		if eventType == "" {
+			continue
+		}
+
+		if !slices.Contains(eventTypes, eventType) {
+			return nil, fmt.Errorf("unknown driver event type %q, want one of %s",
+				eventType, strings.Join(eventTypes, ", "))
+		}
+
+		types = append(types, eventType)
+	}
+
+	return types, nil
+}
+
+// ParseXIDs reads a comma-separated list of XID codes.
+func ParseXIDs(raw string) ([]uint64, error) {
+	var xids []uint64
+
+	for field := range strings.SplitSeq(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		xid, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid xid %q", field)
+		}
+
+		xids = append(xids, xid)
+	}
+
+	return xids, nil
+}
+
+// ValidateURL accepts an absolute http or https URL.
+func ValidateURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q: want an absolute http or https URL", parsed.Redacted())
+	}
+
+	return nil
+}
+
+// Notifier watches the driver event log and the collected recovery actions,
+// and posts a notification to every webhook for each new event and change.
+type Notifier struct {
+	cfg     Config
+	events  eventlog.Source
+	catalog *xidcatalog.Catalog
+	logger  *slog.Logger
+	client  *http.Client
+	host    string
+	now     func() time.Time
+	poll    time.Duration
+	backoff time.Duration
+
+	queues []chan []byte
+
+	mu sync.Mutex
+	// lastSeq is the sequence number of the last event log entry read.
+	lastSeq uint64
+	// actions holds each GPU's last collected recovery action.
+	actions map[string]string
+	// sent holds when each deduplication key was last notified.
+	sent    map[string]time.Time
+	limiter limiter
+	// dropWarned makes sustained rate limiting and full queues visible
+	// once until a notification gets through again.
+	dropWarned bool
+}
+
+// New builds a notifier over the backend's event log, which may be nil for
+// a backend without one: recovery action changes are notified regardless.
+// The catalog describes the notified XIDs.
+func New(cfg Config, events eventlog.Source, catalog *xidcatalog.Catalog, logger *slog.Logger) *Notifier {
+	host, err := os.Hostname()
+	if err != nil {
+		host = ""
+	}
+
+	notifier := &Notifier{
+		cfg:     cfg,
+		events:  events,
+		catalog: catalog,
+		logger:  logger,
+		client:  &http.Client{Timeout: attemptTimeout},
+		host:    host,
+		now:     time.Now,
+		poll:    pollInterval,
+		backoff: backoffStart,
+		actions: map[string]string{},
+		sent:    map[string]time.Time{},
+	}
+
+	for range cfg.URLs {
+		notifier.queues = append(notifier.queues, make(chan []byte, queueSize))
+	}
+
+	if cfg.RateLimit > 0 {
+		notifier.limiter = limiter{
+			burst:  float64(cfg.RateLimit),
+			rate:   float64(cfg.RateLimit) / time.Minute.Seconds(),
+			tokens: float64(cfg.RateLimit),
+		}
+	}
+
+	return notifier
+}
+
+// Run reads the event log and delivers the notifications until ctx ends.
+// Each webhook has its own delivery goroutine and queue, so a slow one
+// delays only its own notifications. It never returns an error.
+func (n *Notifier) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	for i, target := range n.cfg.URLs {
+		wg.Go(func() {
+			n.deliver(ctx, target, n.queues[i])
+		})
+	}
+
+	defer wg.Wait()
+
+	if n.events == nil {
+		<-ctx.Done()
+
+		return nil
+	}
+
+	ticker := time.NewTicker(n.poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			n.readEvents()
+		}
+	}
+}
+
+// readEvents notifies the event log entries added since the last read.
+func (n *Notifier) readEvents() {
+	for _, event := range n.events.RecentEvents(eventlog.Filter{}) {
+		n.mu.Lock()
+		seen := event.Seq <= n.lastSeq
+		n.lastSeq = max(n.lastSeq, event.Seq)
+		n.mu.Unlock()
+
+		if seen || !n.wanted(event) {
+			continue
+		}
+
+		notification := Notification{
+			Kind:     KindDriverEvent,
+			Time:     event.Time,
+			Host:     n.host,
+			UUID:     event.UUID,
+			Type:     event.Type,
+			XID:      event.XID,
+			PCIBusID: event.PCIBusID,
+			Message:  event.Message,
+		}
+
+		if entry, ok := n.catalog.Lookup(event.XID); ok && event.XID != 0 {
+			notification.Description = entry.Description
+			notification.Severity = entry.Severity
+			notification.Action = entry.Action
+			notification.ResetRequired = entry.ResetRequired
+		}
+
+		key := fmt.Sprintf("%s/%s/%s/%d", KindDriverEvent, event.UUID, event.Type, event.XID)
+		n.notify(key, notification)
+	}
+}
+
+// wanted reports whether an event passes the type and code filters.
+func (n *Notifier) wanted(event eventlog.Event) bool {
+	if !slices.Contains(n.cfg.EventTypes, event.Type) {
+		return false
+	}
+
+	return event.Type != collect.DriverEventXID || len(n.cfg.XIDs) == 0 || slices.Contains(n.cfg.XIDs, event.XID)
+}
+
+// WatchQueryFunc extends a collection cycle with the recovery action check:
+// each GPU's collected action is compared with the previous one, and a
+// change is notified. The first action collected for a GPU is notified only
+// when it asks for a recovery.
+func (n *Notifier) WatchQueryFunc(query collect.QueryFunc) collect.QueryFunc {
+	return func(ctx context.Context) (collect.Reading, int, error) {
+		reading, exitCode, err := query(ctx)
+		if err == nil && reading.Table != nil {
+			n.observeTable(reading.Table)
+		}
+
+		return reading, exitCode, err
+	}
+}
+
+// observeTable notifies the recovery action changes in a collected table.
+func (n *Notifier) observeTable(table *nvidiasmi.Table) {
+	for _, row := range table.Rows {
+		cell, ok := row.QFieldToCells[nvidiasmi.GPURecoveryActionQField]
+		if !ok {
+			continue
+		}
+
+		action := strings.TrimSpace(cell.RawValue)
+
+		// an unrecognized action is still a change worth a notification
+		value, err := nvidiasmi.TransformFieldValue(nvidiasmi.GPURecoveryActionQField, action, 1)
+		if errors.Is(err, nvidiasmi.ErrAbsentValue) || nvidiasmi.IsKnownAbsentValue(action) {
+			continue
+		}
+
+		uuid := nvidiasmi.NormalizeUUID(row.QFieldToCells[nvidiasmi.UUIDQField].RawValue)
+
+		n.mu.Lock()
+		previous, known := n.actions[uuid]
+		n.actions[uuid] = action
+		n.mu.Unlock()
+
+		changed := known && !strings.EqualFold(previous, action)
+		first := !known && (err != nil || value != 0)
+
+		if !changed && !first {
+			continue
+		}
+
+		n.notify(fmt.Sprintf("%s/%s/%s", KindRecoveryAction, uuid, strings.ToLower(action)), Notification{
+			Kind: KindRecoveryAction,
+			Time: n.now(),
+			Host: n.host,
+			UUID: uuid,
+			From: previous,
+			To:   action,
+		})
+	}
+}
+
+// notify deduplicates, rate limits, renders and queues a notification for
+// every webhook.
+func (n *Notifier) notify(key string, notification Notification) {
+	now := n.now()
+
+	n.mu.Lock()
+
+	for sentKey, at := range n.sent {
+		if now.Sub(at) >= n.cfg.DedupWindow {
+			delete(n.sent, sentKey)
+		}
+	}
+
+	if _, duplicate := n.sent[key]; duplicate {
+		n.mu.Unlock()
+		n.logger.Debug("suppressed a duplicate notification", "key", key)
+
+		return
+	}
+
+	if n.cfg.RateLimit > 0 && !n.limiter.allow(now) {
+		n.warnDropLocked("rate limit reached, dropping notifications", "key", key)
+		n.mu.Unlock()
+
+		return
+	}
+
+	if n.cfg.DedupWindow > 0 {
+		n.sent[key] = now
+	}
+
+	n.mu.Unlock()
+
+	body, err := n.render(notification)
+	if err != nil {
+		n.logger.Warn("failed to render a notification", "err", err)
+
+		return
+	}
+
+	for i, queue := range n.queues {
+		select {
+		case queue <- body:
+			n.mu.Lock()
+			n.dropWarned = false
+			n.mu.Unlock()
+		default:
+			n.mu.Lock()
+			n.warnDropLocked("webhook queue full, dropping notifications", "webhook", hostOf(n.cfg.URLs[i]))
+			n.mu.Unlock()
+		}
+	}
+}
+
+// warnDropLocked logs the first of consecutive drops. The caller holds mu.
+func (n *Notifier) warnDropLocked(msg string, args ...any) {
+	if n.dropWarned {
+		return
+	}
+
+	n.dropWarned = true
+
+	n.logger.Warn(msg, args...)
+}
+
+// render builds a notification's request body.
+func (n *Notifier) render(notification Notification) ([]byte, error) {
+	if n.cfg.Template == nil {
+		return json.Marshal(notification) //nolint:wrapcheck // logged by the caller as is
+	}
+
+	var buf bytes.Buffer
+	if err := n.cfg.Template.Execute(&buf, notification); err != nil {
+		return nil, err //nolint:wrapcheck // logged by the caller as is
+	}
+
+	return buf.Bytes(), nil
+}
+
+// deliver posts the queued bodies to one webhook, in order, until ctx ends.
+func (n *Notifier) deliver(ctx context.Context, target string, queue <-chan []byte) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case body := <-queue:
+			if err := n.post(ctx, target, body); err != nil && ctx.Err() == nil {
+				n.logger.Warn("failed to deliver a notification", "webhook", hostOf(target), "err", err)
+			}
+		}
+	}
+}
+
+// errPermanent marks a response retrying cannot fix.
+var errPermanent = errors.New("rejected")
+
+// post delivers one body, retrying a failed attempt with a doubling backoff.
+// A 4xx answer other than 429 is final: the request itself is wrong.
+func (n *Notifier) post(ctx context.Context, target string, body []byte) error {
+	backoff := n.backoff
+
+	var err error
+
+	for attempt := range maxAttempts {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+		}
+
+		if err = n.attempt(ctx, target, body); err == nil || errors.Is(err, errPermanent) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", maxAttempts, err)
+}
+
+// attempt posts a body once.
+func (n *Notifier) attempt(ctx context.Context, target string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPermanent, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// the error quotes the URL, which may carry a secret
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err //nolint:wrapcheck // unwrapped to drop the URL
+		}
+
+		return err //nolint:wrapcheck // see above
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return fmt.Errorf("%w with status %s", errPermanent, resp.Status)
+	default:
+		return fmt.Errorf("answered with status %s", resp.Status)
+	}
+}
+
+// hostOf names a webhook in the logs by its host alone: webhook URLs often
+// carry their secret in the path or query.
+func hostOf(target string) string {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Host
+}
+
+// limiter is a token bucket refilling at rate tokens per second up to burst.
+type limiter struct {
+	burst, rate, tokens float64
+	last                time.Time
+}
+
+// allow takes a token if one is left.
+func (l *limiter) allow(now time.Time) bool {
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+
+	l.tokens--
+
+	return true
+}
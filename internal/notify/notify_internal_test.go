@@ -0,0 +1,292 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/neilotoole/slogt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/eventlog"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/xidcatalog"
+)
+
+// webhook is a local HTTP server recording the bodies posted to it, and
+// answering with the queued statuses first.
+type webhook struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	bodies   []string
+	attempts int
+	statuses []int
+}
+
+func newWebhook(t *testing.T, statuses ...int) *webhook {
+	t.Helper()
+
+	hook := &webhook{statuses: statuses}
+	hook.Server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		hook.mu.Lock()
+		defer hook.mu.Unlock()
+
+		hook.attempts++
+
+		if len(hook.statuses) > 0 {
+			status := hook.statuses[0]
+			hook.statuses = hook.statuses[1:]
+
+			writer.WriteHeader(status)
+
+			return
+		}
+
+		hook.bodies = append(hook.bodies, string(body))
+	}))
+	t.Cleanup(hook.Close)
+
+	return hook
+}
+
+// received waits for the given number of delivered bodies.
+func (w *webhook) received(t *testing.T, count int) []string {
+	t.Helper()
+
+	var bodies []string
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		bodies = append([]string(nil), w.bodies...)
+		assert.Len(c, bodies, count)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return bodies
+}
+
+// start runs a notifier with fast polling and backoff until the test ends.
+func start(t *testing.T, cfg Config, events eventlog.Source) *Notifier {
+	t.Helper()
+
+	notifier := New(cfg, events, xidcatalog.Builtin(), slogt.New(t))
+	notifier.poll = 10 * time.Millisecond
+	notifier.backoff = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	var wg sync.WaitGroup
+
+	wg.Go(func() {
+		assert.NoError(t, notifier.Run(ctx))
+	})
+
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	return notifier
+}
+
+func TestNotifierPostsNewDriverEvents(t *testing.T) {
+	t.Parallel()
+
+	first, second := newWebhook(t), newWebhook(t)
+
+	var ring eventlog.Ring
+
+	at := time.Unix(1700000000, 0).UTC()
+
+	start(t, Config{
+		URLs:        []string{first.URL, second.URL},
+		EventTypes:  DefaultEventTypes,
+		XIDs:        []uint64{79, 94, 95},
+		DedupWindow: time.Minute,
+	}, &ring)
+
+	ring.Add(event(at, "gpu-a", collect.DriverEventXID, 79))
+	ring.Add(event(at, "gpu-a", collect.DriverEventXID, 79))        // a duplicate
+	ring.Add(event(at, "gpu-a", collect.DriverEventXID, 13))        // not an XID asked for
+	ring.Add(event(at, "gpu-a", collect.DriverEventClockChange, 0)) // not a type asked for
+	ring.Add(event(at, "gpu-b", collect.DriverEventXID, 79))
+	ring.Add(event(at, "gpu-b", collect.DriverEventECCDoubleBit, 0))
+
+	for _, hook := range []*webhook{first, second} {
+		bodies := hook.received(t, 3)
+
+		var notification Notification
+		require.NoError(t, json.Unmarshal([]byte(bodies[0]), &notification))
+		assert.Equal(t, KindDriverEvent, notification.Kind)
+		assert.Equal(t, "gpu-a", notification.UUID)
+		assert.Equal(t, uint64(79), notification.XID)
+		assert.Equal(t, xidcatalog.SeverityCritical, notification.Severity, "described by the XID catalog")
+		assert.True(t, notification.ResetRequired)
+		assert.True(t, notification.Time.Equal(at))
+
+		assert.Contains(t, bodies[1], `"uuid":"gpu-b"`)
+		assert.Contains(t, bodies[2], `"type":"ecc_double_bit"`)
+	}
+}
+
+func TestNotifierPostsRecoveryActionChanges(t *testing.T) {
+	t.Parallel()
+
+	hook := newWebhook(t)
+
+	var actions []string
+
+	query := start(t, Config{URLs: []string{hook.URL}, DedupWindow: time.Minute}, nil).WatchQueryFunc(
+		func(context.Context) (collect.Reading, int, error) {
+			table := &nvidiasmi.Table{}
+			for i, action := range actions {
+				table.Rows = append(table.Rows, nvidiasmi.Row{QFieldToCells: map[nvidiasmi.QField]nvidiasmi.Cell{
+					nvidiasmi.UUIDQField:              {RawValue: []string{"GPU-gpu-a", "GPU-gpu-b"}[i]},
+					nvidiasmi.GPURecoveryActionQField: {RawValue: action},
+				}})
+			}
+
+			return collect.Reading{Table: table}, 0, nil
+		})
+
+	for _, step := range [][]string{
+		{"None", "GPU Reset"}, // the first reading notifies the recovery asked for alone
+		{"None", "GPU Reset"},
+		{"Node Reboot", "[N/A]"},
+		{"None", "[N/A]"},
+	} {
+		actions = step
+
+		_, _, err := query(t.Context())
+		require.NoError(t, err)
+	}
+
+	bodies := hook.received(t, 3)
+
+	var notifications []Notification
+
+	for _, body := range bodies {
+		var notification Notification
+		require.NoError(t, json.Unmarshal([]byte(body), &notification))
+		notifications = append(notifications, notification)
+	}
+
+	assert.Equal(t, []string{"gpu-b", "gpu-a", "gpu-a"}, []string{
+		notifications[0].UUID, notifications[1].UUID, notifications[2].UUID,
+	})
+	assert.Equal(t, KindRecoveryAction, notifications[0].Kind)
+	assert.Equal(t, [2]string{"", "GPU Reset"}, [2]string{notifications[0].From, notifications[0].To})
+	assert.Equal(t, [2]string{"None", "Node Reboot"}, [2]string{notifications[1].From, notifications[1].To})
+	assert.Equal(t, [2]string{"Node Reboot", "None"}, [2]string{notifications[2].From, notifications[2].To})
+}
+
+func TestNotifierRetriesFailedDeliveries(t *testing.T) {
+	t.Parallel()
+
+	flaky := newWebhook(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	rejecting := newWebhook(t, http.StatusBadRequest)
+
+	var ring eventlog.Ring
+
+	start(t, Config{URLs: []string{flaky.URL, rejecting.URL}, EventTypes: DefaultEventTypes}, &ring)
+
+	ring.Add(event(time.Now(), "gpu-a", collect.DriverEventXID, 79))
+
+	flaky.received(t, 1)
+
+	// the 400 is final: the third attempt the flaky webhook needed never comes
+	time.Sleep(100 * time.Millisecond)
+
+	rejecting.mu.Lock()
+	defer rejecting.mu.Unlock()
+
+	assert.Equal(t, 1, rejecting.attempts)
+	assert.Empty(t, rejecting.bodies)
+}
+
+func TestNotifierRendersTheTemplate(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "template.txt")
+	require.NoError(t, os.WriteFile(path,
+		[]byte(`{"text": {{ printf "XID %d on %s: %s" .XID .UUID .Description | json }}}`), 0o600))
+
+	tmpl, err := LoadTemplate(path)
+	require.NoError(t, err)
+
+	hook := newWebhook(t)
+
+	var ring eventlog.Ring
+
+	start(t, Config{URLs: []string{hook.URL}, Template: tmpl, EventTypes: DefaultEventTypes}, &ring)
+
+	ring.Add(event(time.Now(), "gpu-a", collect.DriverEventXID, 79))
+
+	entry, ok := xidcatalog.Builtin().Lookup(79)
+	require.True(t, ok)
+
+	expected, err := json.Marshal(map[string]string{"text": "XID 79 on gpu-a: " + entry.Description})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), hook.received(t, 1)[0])
+
+	_, err = LoadTemplate(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+
+	at := time.Unix(1700000000, 0)
+	bucket := limiter{burst: 2, rate: 2 / time.Minute.Seconds(), tokens: 2}
+
+	assert.True(t, bucket.allow(at))
+	assert.True(t, bucket.allow(at))
+	assert.False(t, bucket.allow(at), "the burst is spent")
+	assert.False(t, bucket.allow(at.Add(20*time.Second)), "two thirds of a token refilled")
+	assert.True(t, bucket.allow(at.Add(30*time.Second)))
+	assert.True(t, bucket.allow(at.Add(time.Hour)), "refilled up to the burst")
+	assert.True(t, bucket.allow(at.Add(time.Hour)))
+	assert.False(t, bucket.allow(at.Add(time.Hour)))
+}
+
+func TestParseFlags(t *testing.T) {
+	t.Parallel()
+
+	types, err := ParseEventTypes("xid, ecc_single_bit,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"xid", "ecc_single_bit"}, types)
+
+	_, err = ParseEventTypes("xid,fallen_off_the_bus")
+	require.Error(t, err)
+
+	xids, err := ParseXIDs("79, 94,95")
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{79, 94, 95}, xids)
+
+	_, err = ParseXIDs("79,-1")
+	require.Error(t, err)
+
+	require.NoError(t, ValidateURL("https://hooks.example.com/services/secret"))
+
+	for _, raw := range []string{"hooks.example.com/path", "ftp://hooks.example.com", "https:///path", "%zz"} {
+		assert.Error(t, ValidateURL(raw), raw)
+	}
+}
+
+// event builds an event log entry, with the XID as the event data.
+func event(at time.Time, uuid, eventType string, xid uint64) eventlog.Event {
+	return eventlog.Event{Time: at, UUID: uuid, Type: eventType, XID: xid, Data: xid}
+}
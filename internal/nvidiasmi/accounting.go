@@ -0,0 +1,146 @@
+package nvidiasmi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// accountedAppsQFields is the field set queried from --query-accounted-apps,
+// fixed like computeAppsQFields. The output is read with nounits, so
+// max_memory_usage is a plain MiB number and time a plain millisecond one.
+const accountedAppsQFields = "gpu_uuid,pid,max_memory_usage,time"
+
+// accountedAppsNumCols is the column count accountedAppsQFields produces.
+const accountedAppsNumCols = 4
+
+// AccountedApp is one entry of a GPU's accounting buffer: a process that held
+// a graphics or compute context on the GPU while accounting mode was enabled.
+// The driver keeps the entry after the process exits, until the circular
+// buffer overwrites it.
+type AccountedApp struct {
+	// GPUUUID is normalized like the uuid label on all GPU metrics.
+	GPUUUID string
+	// PID is kept as a string: it is only ever part of an identity.
+	PID string
+	// MaxMemoryBytes is the most memory the context ever used, 0 when the
+	// driver could not report it.
+	MaxMemoryBytes uint64
+	// Duration is how long the context was active. The driver reports it
+	// only once the process has terminated.
+	Duration time.Duration
+	// Running is set while the process still holds its context.
+	Running bool
+}
+
+// QueryAccountedApps runs nvidia-smi --query-accounted-apps and parses the CSV
+// output, returning one entry per accounted process.
+func QueryAccountedApps(
+	ctx context.Context,
+	command string,
+	run RunFunc,
+	logger *slog.Logger,
+) ([]AccountedApp, error) {
+	stdout, _, err := execQuery(ctx, command, run,
+		"--query-accounted-apps="+accountedAppsQFields, "--format=csv,nounits")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseAccountedApps(stdout, logger)
+}
+
+// ParseAccountedApps parses --query-accounted-apps CSV output read with
+// nounits. A header-only output (accounting disabled, or nothing accounted
+// yet) parses to an empty result. nvidia-smi does not say whether a process
+// is still running; it reports a zero time until the process terminates, so
+// a zero time marks the entry as running. Unusable rows are skipped like
+// unusable --query-compute-apps rows.
+func ParseAccountedApps(output string, logger *slog.Logger) ([]AccountedApp, error) {
+	trimmed := strings.TrimSpace(output)
+	if trimmed == "" {
+		return nil, nil
+	}
+
+	lines := strings.Split(trimmed, "\n")
+
+	if numCols := len(parseCSVLine(lines[0])); numCols != accountedAppsNumCols {
+		return nil, fmt.Errorf(
+			"unexpected accounted apps header: expected %d columns, got %d: %q",
+			accountedAppsNumCols, numCols, strings.TrimSpace(lines[0]),
+		)
+	}
+
+	apps := make([]AccountedApp, 0, len(lines)-1)
+
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		app, ok := parseAccountedAppRow(line, logger)
+		if !ok {
+			continue
+		}
+
+		apps = append(apps, app)
+	}
+
+	return apps, nil
+}
+
+// parseAccountedAppRow parses one data row, reporting whether the row is
+// usable.
+func parseAccountedAppRow(line string, logger *slog.Logger) (AccountedApp, bool) {
+	fields := parseCSVLine(line)
+	if len(fields) != accountedAppsNumCols {
+		logger.Warn("skipping malformed accounted apps row", "row", strings.TrimSpace(line))
+
+		return AccountedApp{}, false
+	}
+
+	pid := strings.TrimSpace(fields[1])
+	if _, err := strconv.ParseUint(pid, 10, 64); err != nil {
+		if !IsKnownAbsentValue(pid) {
+			logger.Warn("skipping accounted apps row with unparseable pid",
+				"pid", pid, "row", strings.TrimSpace(line))
+		}
+
+		return AccountedApp{}, false
+	}
+
+	uuid := NormalizeUUID(fields[0])
+	if uuid == "" {
+		logger.Warn("skipping accounted apps row with no gpu uuid",
+			"pid", pid, "row", strings.TrimSpace(line))
+
+		return AccountedApp{}, false
+	}
+
+	app := AccountedApp{GPUUUID: uuid, PID: pid}
+
+	// an unreadable peak is just unknown; the entry still counts
+	if mib, err := strconv.ParseUint(strings.TrimSpace(fields[2]), 10, 64); err == nil {
+		app.MaxMemoryBytes = mib * UsedMemoryMultiplier
+	}
+
+	millis, err := strconv.ParseUint(strings.TrimSpace(fields[3]), 10, 64)
+	if err != nil {
+		// without a duration the entry cannot be told apart from a running
+		// one, so it is left for a later collection to account
+		if !IsKnownAbsentValue(fields[3]) {
+			logger.Warn("skipping accounted apps row with unparseable time",
+				"pid", pid, "row", strings.TrimSpace(line))
+		}
+
+		return AccountedApp{}, false
+	}
+
+	app.Duration = time.Duration(millis) * time.Millisecond
+	app.Running = millis == 0
+
+	return app, true
+}
@@ -0,0 +1,66 @@
+package nvidiasmi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/neilotoole/slogt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+const accountedAppsHeader = "gpu_uuid, pid, max_memory_usage [MiB], time [ms]"
+
+func TestParseAccountedApps(t *testing.T) {
+	t.Parallel()
+
+	output := accountedAppsHeader + "\n" +
+		"GPU-ae3738d5-9aa2-e1c9-53be-65b6d373d3d9, 10525, 1542, 83012\n" +
+		"GPU-ae3738d5-9aa2-e1c9-53be-65b6d373d3d9, 10550, 14410, 0\n" +
+		"GPU-ae3738d5-9aa2-e1c9-53be-65b6d373d3d9, 10551, [N/A], 250\n"
+
+	apps, err := nvidiasmi.ParseAccountedApps(output, slogt.New(t))
+
+	require.NoError(t, err)
+	require.Len(t, apps, 3)
+
+	assert.Equal(t, nvidiasmi.AccountedApp{
+		GPUUUID:        "ae3738d5-9aa2-e1c9-53be-65b6d373d3d9",
+		PID:            "10525",
+		MaxMemoryBytes: 1542 * nvidiasmi.UsedMemoryMultiplier,
+		Duration:       83012 * time.Millisecond,
+	}, apps[0])
+	assert.True(t, apps[1].Running, "a zero time means the process has not terminated")
+	assert.Zero(t, apps[2].MaxMemoryBytes)
+	assert.False(t, apps[2].Running)
+}
+
+func TestParseAccountedAppsSkipsUnusableRows(t *testing.T) {
+	t.Parallel()
+
+	output := accountedAppsHeader + "\n" +
+		"GPU-ae3738d5-9aa2-e1c9-53be-65b6d373d3d9, [Insufficient Permissions], 1542, 83012\n" +
+		", 10526, 1542, 83012\n" +
+		"GPU-ae3738d5-9aa2-e1c9-53be-65b6d373d3d9, 10527, 1542, [N/A]\n" +
+		"GPU-ae3738d5-9aa2-e1c9-53be-65b6d373d3d9, 10528\n" +
+		"GPU-ae3738d5-9aa2-e1c9-53be-65b6d373d3d9, 10529, 818, 1000\n"
+
+	apps, err := nvidiasmi.ParseAccountedApps(output, slogt.New(t))
+
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, "10529", apps[0].PID)
+}
+
+func TestParseAccountedAppsEmptyAndBadHeader(t *testing.T) {
+	t.Parallel()
+
+	apps, err := nvidiasmi.ParseAccountedApps(accountedAppsHeader+"\n", slogt.New(t))
+	require.NoError(t, err)
+	assert.Empty(t, apps)
+
+	_, err = nvidiasmi.ParseAccountedApps("gpu_uuid, pid\n", slogt.New(t))
+	require.Error(t, err)
+}
@@ -43,8 +43,21 @@ type ComputeApp struct {
 	// predates MIG and is never extended, per the comment above).
 	GPUInstanceID     string
 	ComputeInstanceID string
+	// Type is the kind of context the process holds, one of the ProcessType
+	// constants. It is filled only when the typed process query ran
+	// (--collect.compute-apps-types); this query lists compute contexts and
+	// leaves it empty. A process holding several kinds of context appears
+	// once per kind.
+	Type string
 }
 
+// The process types the typed process query reports.
+const (
+	ProcessTypeCompute  = "compute"
+	ProcessTypeGraphics = "graphics"
+	ProcessTypeMPS      = "mps"
+)
+
 // QueryComputeApps runs nvidia-smi --query-compute-apps and parses the CSV
 // output, returning one entry per process with a compute context.
 func QueryComputeApps(
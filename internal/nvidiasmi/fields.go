@@ -19,22 +19,33 @@ type RField string
 const (
 	// UUIDQField is the query field holding the GPU identity used as the metric label.
 	UUIDQField QField = "uuid"
+	// PCIBusIDQField is the query field holding the GPU's PCI bus id, the
+	// identity nvidia-smi's -q output is keyed by.
+	PCIBusIDQField QField = "pci.bus_id"
+	// IndexQField is the query field holding the GPU's index, the identity
+	// nvidia-smi's topo -m matrix is keyed by.
+	IndexQField QField = "index"
 
 	nameQField               QField = "name"
 	driverModelCurrentQField QField = "driver_model.current"
 	driverModelPendingQField QField = "driver_model.pending"
 	vBiosVersionQField       QField = "vbios_version"
 	driverVersionQField      QField = "driver_version"
-	pciBusIDQField           QField = "pci.bus_id"
 	serialQField             QField = "serial"
 	computeCapQField         QField = "compute_cap"
 	pciSubDeviceIDQField     QField = "pci.sub_device_id"
-	indexQField              QField = "index"
 
-	// Enum-valued fields whose string values are mapped to their native NVML
+	// GPURecoveryActionQField is the query field holding the recovery action
+	// the driver recommends, watched for transitions by the notifier. Like
+	// the fabric state, its string values are mapped to their native NVML
 	// enum integers (see fieldValueMappers in transform.go).
-	gpuRecoveryActionQField QField = "gpu_recovery_action"
-	fabricStateQField       QField = "fabric.state"
+	GPURecoveryActionQField QField = "gpu_recovery_action"
+	// AccountingModeQField is the query field holding whether the driver
+	// keeps the GPU's accounting buffer, which tells an empty buffer from a
+	// disabled one.
+	AccountingModeQField QField = "accounting.mode"
+
+	fabricStateQField QField = "fabric.state"
 
 	qFieldsAuto   = "AUTO"
 	DefaultQField = qFieldsAuto
@@ -0,0 +1,75 @@
+package nvidiasmi
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PCIeErrors is one GPU's PCIe replay counters as nvidia-smi -q -x reports
+// them. The report carries no AER counts.
+type PCIeErrors struct {
+	// BusID is the GPU's PCI bus id in upper case, the gpu element's id in
+	// the report.
+	BusID string
+	// Replays and ReplayRollovers are nil when the GPU reports them as N/A.
+	Replays         *float64
+	ReplayRollovers *float64
+}
+
+// pcieErrorsXML is the part of the nvidia-smi -q -x document the PCIe error
+// query reads: each GPU's bus id and its replay counters.
+type pcieErrorsXML struct {
+	GPUs []struct {
+		ID              string `xml:"id,attr"`
+		Replays         string `xml:"pci>replay_counter"`
+		ReplayRollovers string `xml:"pci>replay_rollover_counter"`
+	} `xml:"gpu"`
+}
+
+// QueryReport runs the full nvidia-smi -q -x and returns the XML report, for
+// the readings no -d display selection covers, such as the PCI section.
+// Callers reading several of them in one collection share a single run.
+func QueryReport(ctx context.Context, command string, run RunFunc) (string, error) {
+	stdout, _, err := execQuery(ctx, command, run, "-q", "-x")
+
+	return stdout, err
+}
+
+// ParsePCIeErrors parses the PCI sections of a full nvidia-smi -q -x report
+// (see QueryReport), returning the replay counters in GPU order. A GPU whose
+// PCI section reports neither counter is left out.
+func ParsePCIeErrors(output string) ([]PCIeErrors, error) {
+	var doc pcieErrorsXML
+	if err := xml.Unmarshal([]byte(output), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse the nvidia-smi XML report: %w", err)
+	}
+
+	var result []PCIeErrors
+
+	for _, gpu := range doc.GPUs {
+		entry := PCIeErrors{
+			BusID:           strings.ToUpper(strings.TrimSpace(gpu.ID)),
+			Replays:         parseCounter(gpu.Replays),
+			ReplayRollovers: parseCounter(gpu.ReplayRollovers),
+		}
+
+		if entry.Replays != nil || entry.ReplayRollovers != nil {
+			result = append(result, entry)
+		}
+	}
+
+	return result, nil
+}
+
+// parseCounter reads one counter element, nil when it is missing or N/A.
+func parseCounter(value string) *float64 {
+	count, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil
+	}
+
+	return &count
+}
@@ -0,0 +1,91 @@
+package nvidiasmi_test
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+// pcieOutput is the PCI part of a -q -x report for two GPUs, the second of
+// which cannot report its rollovers, beside a third reporting no counter at
+// all.
+const pcieOutput = `<?xml version="1.0" ?>
+<nvidia_smi_log>
+	<attached_gpus>3</attached_gpus>
+	<gpu id="00000000:19:00.0">
+		<product_name>NVIDIA H100 80GB HBM3</product_name>
+		<pci>
+			<pci_bus>19</pci_bus>
+			<pci_bus_id>00000000:19:00.0</pci_bus_id>
+			<pci_gpu_link_info>
+				<pcie_gen>
+					<max_link_gen>5</max_link_gen>
+					<current_link_gen>5</current_link_gen>
+				</pcie_gen>
+			</pci_gpu_link_info>
+			<replay_counter>9</replay_counter>
+			<replay_rollover_counter>0</replay_rollover_counter>
+			<tx_util>371 KB/s</tx_util>
+		</pci>
+		<fan_speed>N/A</fan_speed>
+	</gpu>
+	<gpu id="00000000:3b:00.0">
+		<pci>
+			<replay_counter>0</replay_counter>
+			<replay_rollover_counter>N/A</replay_rollover_counter>
+		</pci>
+	</gpu>
+	<gpu id="00000000:5D:00.0">
+		<pci>
+			<pci_bus_id>00000000:5D:00.0</pci_bus_id>
+		</pci>
+		<temperature>
+			<replay_counter>1</replay_counter>
+		</temperature>
+	</gpu>
+</nvidia_smi_log>
+`
+
+func TestParsePCIeErrors(t *testing.T) {
+	t.Parallel()
+
+	counters, err := nvidiasmi.ParsePCIeErrors(pcieOutput)
+	require.NoError(t, err)
+	require.Len(t, counters, 2)
+
+	assert.Equal(t, "00000000:19:00.0", counters[0].BusID)
+	require.NotNil(t, counters[0].Replays)
+	assert.InDelta(t, 9, *counters[0].Replays, 0)
+	require.NotNil(t, counters[0].ReplayRollovers)
+	assert.InDelta(t, 0, *counters[0].ReplayRollovers, 0)
+
+	assert.Equal(t, "00000000:3B:00.0", counters[1].BusID, "the bus id is upper-cased")
+	require.NotNil(t, counters[1].Replays)
+	assert.Nil(t, counters[1].ReplayRollovers, "N/A is no reading")
+
+	counters, err = nvidiasmi.ParsePCIeErrors("<nvidia_smi_log></nvidia_smi_log>")
+	require.NoError(t, err)
+	assert.Empty(t, counters)
+
+	_, err = nvidiasmi.ParsePCIeErrors("<nvidia_smi_log><gpu>")
+	require.Error(t, err)
+}
+
+func TestQueryReport(t *testing.T) {
+	t.Parallel()
+
+	report, err := nvidiasmi.QueryReport(t.Context(), "nvsmi", func(cmd *exec.Cmd) error {
+		assert.Equal(t, []string{"nvsmi", "-q", "-x"}, cmd.Args)
+
+		_, _ = cmd.Stdout.Write([]byte(pcieOutput))
+
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, pcieOutput, report)
+}
@@ -0,0 +1,117 @@
+package nvidiasmi
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// processesXML is the part of the nvidia-smi -q -x document the typed process
+// query reads: each GPU's identity and its process list. Everything else in
+// the document is ignored by the decoder.
+type processesXML struct {
+	GPUs []struct {
+		// ID is the GPU's PCI bus id. A report narrowed to the process
+		// section carries no uuid, so it is the only identity there.
+		ID        string `xml:"id,attr"`
+		UUID      string `xml:"uuid"`
+		Processes []struct {
+			PID         string `xml:"pid"`
+			Type        string `xml:"type"`
+			ProcessName string `xml:"process_name"`
+			UsedMemory  string `xml:"used_memory"`
+		} `xml:"processes>process_info"`
+	} `xml:"gpu"`
+}
+
+// processTypeLetters maps the context letters nvidia-smi prints in a
+// process's type ("C", "G", "M", combined as "C+G" or "M+C") onto the
+// ProcessType constants.
+//
+//nolint:gochecknoglobals // lookup table
+var processTypeLetters = map[string]string{
+	"C": ProcessTypeCompute,
+	"G": ProcessTypeGraphics,
+	"M": ProcessTypeMPS,
+}
+
+// QueryProcesses runs nvidia-smi -q -x -d PIDS and parses its process lists,
+// returning one entry per process and context type. Unlike
+// --query-compute-apps, the XML report lists graphics and MPS client
+// processes too. The report is narrowed to the process section, which names
+// each GPU by PCI bus id only; uuids maps the upper-cased bus ids to the GPU
+// uuids.
+func QueryProcesses(
+	ctx context.Context,
+	command string,
+	run RunFunc,
+	uuids map[string]string,
+	logger *slog.Logger,
+) ([]ComputeApp, error) {
+	stdout, _, err := execQuery(ctx, command, run, "-q", "-x", "-d", "PIDS")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseProcessesXML(stdout, uuids, logger)
+}
+
+// ParseProcessesXML parses the process lists of an nvidia-smi -q -x report.
+// A GPU the report gives no uuid for is looked up in uuids by its bus id. A
+// process holding several kinds of context ("C+G") yields one entry per
+// kind, matching what the nvml backend's separate per-kind lists produce.
+// Unusable entries are skipped like unusable --query-compute-apps rows.
+func ParseProcessesXML(output string, uuids map[string]string, logger *slog.Logger) ([]ComputeApp, error) {
+	var doc processesXML
+	if err := xml.Unmarshal([]byte(output), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse the nvidia-smi XML report: %w", err)
+	}
+
+	var apps []ComputeApp
+
+	for _, gpu := range doc.GPUs {
+		uuid := NormalizeUUID(gpu.UUID)
+		if uuid == "" {
+			uuid = uuids[strings.ToUpper(strings.TrimSpace(gpu.ID))]
+		}
+
+		for _, proc := range gpu.Processes {
+			pid := strings.TrimSpace(proc.PID)
+			if _, err := strconv.ParseUint(pid, 10, 64); err != nil {
+				if !IsKnownAbsentValue(pid) {
+					logger.Warn("skipping process entry with unparseable pid", "pid", pid, "uuid", uuid)
+				}
+
+				continue
+			}
+
+			if uuid == "" {
+				logger.Warn("skipping process entry with no gpu uuid", "pid", pid)
+
+				continue
+			}
+
+			for letter := range strings.SplitSeq(strings.TrimSpace(proc.Type), "+") {
+				procType, known := processTypeLetters[strings.TrimSpace(letter)]
+				if !known {
+					logger.Warn("skipping unknown process type", "type", proc.Type, "pid", pid)
+
+					continue
+				}
+
+				apps = append(apps, ComputeApp{
+					GPUUUID:     uuid,
+					PID:         pid,
+					ProcessName: strings.TrimSpace(proc.ProcessName),
+					UsedMemory:  strings.TrimSpace(proc.UsedMemory),
+					Type:        procType,
+				})
+			}
+		}
+	}
+
+	return apps, nil
+}
@@ -0,0 +1,74 @@
+package nvidiasmi_test
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/neilotoole/slogt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+func TestParseProcessesXML(t *testing.T) {
+	t.Parallel()
+
+	// shaped after the RTX 2080 SUPER capture's process list, plus the
+	// combined and MPS types a desktop GPU does not show
+	output, err := os.ReadFile("testdata/processes.xml")
+	require.NoError(t, err)
+
+	apps, err := nvidiasmi.ParseProcessesXML(string(output), nil, slogt.New(t))
+	require.NoError(t, err)
+
+	const uuid = "00000000-0000-0000-0000-000000000000"
+
+	assert.Equal(t, []nvidiasmi.ComputeApp{
+		{
+			GPUUUID: uuid, PID: "1696", ProcessName: "/usr/bin/gnome-shell",
+			UsedMemory: "176 MiB", Type: nvidiasmi.ProcessTypeGraphics,
+		},
+		{GPUUUID: uuid, PID: "5267", ProcessName: "ffmpeg", UsedMemory: "230 MiB", Type: nvidiasmi.ProcessTypeCompute},
+		{
+			GPUUUID: uuid, PID: "6120", ProcessName: "/opt/blender/blender",
+			UsedMemory: "1024 MiB", Type: nvidiasmi.ProcessTypeCompute,
+		},
+		{
+			GPUUUID: uuid, PID: "6120", ProcessName: "/opt/blender/blender",
+			UsedMemory: "1024 MiB", Type: nvidiasmi.ProcessTypeGraphics,
+		},
+		{GPUUUID: uuid, PID: "7001", ProcessName: "python3", UsedMemory: "512 MiB", Type: nvidiasmi.ProcessTypeMPS},
+	}, apps, "the unreadable entry is skipped, the combined one split per type")
+}
+
+func TestParseProcessesXMLMalformed(t *testing.T) {
+	t.Parallel()
+
+	_, err := nvidiasmi.ParseProcessesXML("<nvidia_smi_log><gpu>", nil, slogt.New(t))
+	require.Error(t, err)
+}
+
+func TestQueryProcesses(t *testing.T) {
+	t.Parallel()
+
+	// the report narrowed to the process section names the GPU by bus id
+	output := `<nvidia_smi_log><gpu id="00000000:0c:00.0"><processes><process_info>` +
+		"<pid>42</pid><type>G</type><process_name>Xorg</process_name><used_memory>3 MiB</used_memory>" +
+		"</process_info></processes></gpu></nvidia_smi_log>"
+	uuids := map[string]string{"00000000:0C:00.0": "abc"}
+
+	apps, err := nvidiasmi.QueryProcesses(t.Context(), "nvsmi", func(cmd *exec.Cmd) error {
+		assert.Equal(t, []string{"nvsmi", "-q", "-x", "-d", "PIDS"}, cmd.Args)
+
+		_, _ = cmd.Stdout.Write([]byte(output))
+
+		return nil
+	}, uuids, slogt.New(t))
+
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, "abc", apps[0].GPUUUID)
+	assert.Equal(t, nvidiasmi.ProcessTypeGraphics, apps[0].Type)
+}
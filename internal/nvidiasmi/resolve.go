@@ -36,11 +36,11 @@ var infoFields = []InfoField{
 	{QField: driverModelPendingQField, Label: "driver_model_pending"},
 	{QField: vBiosVersionQField, Label: "vbios_version"},
 	{QField: driverVersionQField, Label: "driver_version"},
-	{QField: pciBusIDQField, Label: "pci_bus_id"},
+	{QField: PCIBusIDQField, Label: "pci_bus_id"},
 	{QField: serialQField, Label: "serial"},
 	{QField: computeCapQField, Label: "compute_cap"},
 	{QField: pciSubDeviceIDQField, Label: "pci_sub_device_id"},
-	{QField: indexQField, Label: "index"},
+	{QField: IndexQField, Label: "index"},
 }
 
 // ResolveFields determines the query fields and their returned names, running
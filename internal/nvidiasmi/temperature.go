@@ -0,0 +1,129 @@
+package nvidiasmi
+
+import (
+	"bufio"
+	"context"
+	"strconv"
+	"strings"
+)
+
+// The temperature threshold kinds, named after the driver's
+// nvmlTemperatureThresholds_t. They are the threshold label values on every
+// backend.
+const (
+	TemperatureThresholdShutdown        = "shutdown"
+	TemperatureThresholdSlowdown        = "slowdown"
+	TemperatureThresholdMemoryMax       = "memory_max"
+	TemperatureThresholdGPUMax          = "gpu_max"
+	TemperatureThresholdAcousticMin     = "acoustic_min"
+	TemperatureThresholdAcousticCurrent = "acoustic_current"
+	TemperatureThresholdAcousticMax     = "acoustic_max"
+)
+
+// temperatureThresholdLine describes one threshold line of the -q Temperature
+// section.
+type temperatureThresholdLine struct {
+	kind   string
+	tLimit bool
+}
+
+// temperatureThresholdLines maps the -q Temperature section's threshold line
+// names to their kind. Datacenter GPUs report the T.Limit variants instead of
+// the absolute ones, and drivers renamed the target temperature line over
+// time. The acoustic bounds have no line of their own.
+//
+//nolint:gochecknoglobals // lookup table
+var temperatureThresholdLines = map[string]temperatureThresholdLine{
+	"GPU Shutdown Temp":                    {kind: TemperatureThresholdShutdown},
+	"GPU Slowdown Temp":                    {kind: TemperatureThresholdSlowdown},
+	"GPU Max Operating Temp":               {kind: TemperatureThresholdGPUMax},
+	"Memory Max Operating Temp":            {kind: TemperatureThresholdMemoryMax},
+	"GPU Target Temperature":               {kind: TemperatureThresholdAcousticCurrent},
+	"GPU Target Temperature Specification": {kind: TemperatureThresholdAcousticCurrent},
+	"GPU Shutdown T.Limit Temp":            {kind: TemperatureThresholdShutdown, tLimit: true},
+	"GPU Slowdown T.Limit Temp":            {kind: TemperatureThresholdSlowdown, tLimit: true},
+	"GPU Max Operating T.Limit Temp":       {kind: TemperatureThresholdGPUMax, tLimit: true},
+	"Memory Max Operating T.Limit Temp":    {kind: TemperatureThresholdMemoryMax, tLimit: true},
+}
+
+// TemperatureThreshold is one temperature threshold a GPU reports.
+type TemperatureThreshold struct {
+	// BusID is the GPU's PCI bus id in upper case, the only identity the
+	// -q -d TEMPERATURE output carries.
+	BusID string
+	// Kind is one of the TemperatureThreshold* constants.
+	Kind    string
+	Celsius float64
+	// TLimit marks a threshold relative to the GPU's T.Limit temperature
+	// rather than an absolute one: the reading it is compared against is
+	// temperature.gpu.tlimit, and a negative value lies past the limit.
+	TLimit bool
+}
+
+// QueryTemperatureThresholds runs nvidia-smi -q -d TEMPERATURE and parses the
+// thresholds out of its text output (the -d selection cannot be combined with
+// the XML output).
+func QueryTemperatureThresholds(ctx context.Context, command string, run RunFunc) ([]TemperatureThreshold, error) {
+	stdout, _, err := execQuery(ctx, command, run, "-q", "-d", "TEMPERATURE")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseTemperatureThresholds(stdout), nil
+}
+
+// ParseTemperatureThresholds parses the Temperature sections of nvidia-smi -q
+// text output, returning the thresholds in GPU order. Thresholds the GPU does
+// not report (N/A) and lines outside a GPU's Temperature section are skipped.
+func ParseTemperatureThresholds(output string) []TemperatureThreshold {
+	var (
+		thresholds    []TemperatureThreshold
+		busID         string
+		inTemperature bool
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+
+		switch {
+		case strings.HasPrefix(line, "GPU "):
+			// a GPU block header, "GPU 00000000:01:00.0"
+			busID = strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(line, "GPU ")))
+			inTemperature = false
+
+			continue
+		case strings.HasPrefix(line, "    ") && !strings.HasPrefix(line, "     "):
+			// a section header of the current GPU block
+			inTemperature = busID != "" && strings.TrimSpace(line) == "Temperature"
+
+			continue
+		case !inTemperature:
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		threshold, known := temperatureThresholdLines[strings.TrimSpace(name)]
+		if !known {
+			continue
+		}
+
+		celsius, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "C")), 64)
+		if err != nil {
+			continue
+		}
+
+		thresholds = append(thresholds, TemperatureThreshold{
+			BusID:   busID,
+			Kind:    threshold.kind,
+			Celsius: celsius,
+			TLimit:  threshold.tLimit,
+		})
+	}
+
+	return thresholds
+}
@@ -0,0 +1,81 @@
+package nvidiasmi_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+// temperatureOutput is -q -d TEMPERATURE output of a consumer GPU, reporting
+// absolute thresholds, beside a datacenter GPU, reporting T.Limit-relative
+// ones.
+const temperatureOutput = `
+==============NVSMI LOG==============
+
+Timestamp                                              : Fri Jul  3 22:10:45 2026
+Driver Version                                         : 610.57.04
+CUDA Version                                           : 13.2
+
+Attached GPUs                                          : 2
+GPU 00000000:01:00.0
+    Temperature
+        GPU Current Temp                               : 33 C
+        GPU Current T.Limit Temp                       : N/A
+        GPU Shutdown Temp                              : 100 C
+        GPU Slowdown Temp                              : 97 C
+        GPU Max Operating Temp                         : 89 C
+        GPU Target Temperature Specification           : 84 C
+        Memory Current Temp                            : N/A
+        Memory Max Operating Temp                      : N/A
+
+GPU 00000000:83:00.0
+    Temperature
+        GPU Current Temp                               : 28 C
+        GPU T.Limit Temp                               : 59 C
+        GPU Shutdown T.Limit Temp                      : -8 C
+        GPU Slowdown T.Limit Temp                      : -2 C
+        GPU Max Operating T.Limit Temp                 : 0 C
+        GPU Target Temperature                         : N/A
+        Memory Current Temp                            : 32 C
+        Memory Max Operating T.Limit Temp              : 0 C
+
+`
+
+func TestParseTemperatureThresholds(t *testing.T) {
+	t.Parallel()
+
+	thresholds := nvidiasmi.ParseTemperatureThresholds(temperatureOutput)
+
+	assert.Equal(t, []nvidiasmi.TemperatureThreshold{
+		{BusID: "00000000:01:00.0", Kind: nvidiasmi.TemperatureThresholdShutdown, Celsius: 100},
+		{BusID: "00000000:01:00.0", Kind: nvidiasmi.TemperatureThresholdSlowdown, Celsius: 97},
+		{BusID: "00000000:01:00.0", Kind: nvidiasmi.TemperatureThresholdGPUMax, Celsius: 89},
+		{BusID: "00000000:01:00.0", Kind: nvidiasmi.TemperatureThresholdAcousticCurrent, Celsius: 84},
+		{BusID: "00000000:83:00.0", Kind: nvidiasmi.TemperatureThresholdShutdown, Celsius: -8, TLimit: true},
+		{BusID: "00000000:83:00.0", Kind: nvidiasmi.TemperatureThresholdSlowdown, Celsius: -2, TLimit: true},
+		{BusID: "00000000:83:00.0", Kind: nvidiasmi.TemperatureThresholdGPUMax, Celsius: 0, TLimit: true},
+		{BusID: "00000000:83:00.0", Kind: nvidiasmi.TemperatureThresholdMemoryMax, Celsius: 0, TLimit: true},
+	}, thresholds)
+}
+
+func TestParseTemperatureThresholdsIgnoresOtherSections(t *testing.T) {
+	t.Parallel()
+
+	// a full -q output: only the Temperature section is read, and a bus id
+	// in lower case is normalized
+	output := "GPU 00000000:0a:00.0\n" +
+		"    Product Name                                   : NVIDIA GeForce RTX 2080 SUPER\n" +
+		"    Clocks Event Reasons\n" +
+		"        GPU Shutdown Temp                          : 1 C\n" +
+		"    Temperature\n" +
+		"        GPU Slowdown Temp                          : 97 C\n" +
+		"    GPU Power Readings\n" +
+		"        GPU Max Operating Temp                     : 2 C\n"
+
+	assert.Equal(t, []nvidiasmi.TemperatureThreshold{
+		{BusID: "00000000:0A:00.0", Kind: nvidiasmi.TemperatureThresholdSlowdown, Celsius: 97},
+	}, nvidiasmi.ParseTemperatureThresholds(output))
+	assert.Empty(t, nvidiasmi.ParseTemperatureThresholds(""))
+}
@@ -0,0 +1,188 @@
+package nvidiasmi
+
+import (
+	"bufio"
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The GPU-to-GPU link kinds, nvidia-smi topo -m's legend names lower-cased.
+// They are the link label values on every backend. A bonded set of NVLinks
+// (NV# in the matrix) is nvlink whatever its width; the width is the NVLink
+// family's business.
+const (
+	TopologyLinkNVLink = "nvlink"
+	TopologyLinkPIX    = "pix"
+	TopologyLinkPXB    = "pxb"
+	TopologyLinkPHB    = "phb"
+	TopologyLinkNode   = "node"
+	TopologyLinkSystem = "sys"
+)
+
+// topologyLinks maps topo -m matrix cells to their link kind. SOC is older
+// drivers' name for SYS.
+//
+//nolint:gochecknoglobals // lookup table
+var topologyLinks = map[string]string{
+	"PIX":  TopologyLinkPIX,
+	"PXB":  TopologyLinkPXB,
+	"PHB":  TopologyLinkPHB,
+	"NODE": TopologyLinkNode,
+	"SYS":  TopologyLinkSystem,
+	"SOC":  TopologyLinkSystem,
+}
+
+// nvlinkCell matches a matrix cell naming a bonded set of NVLinks, "NV18".
+var nvlinkCell = regexp.MustCompile(`^NV\d+$`)
+
+// ansiEscape matches the terminal escapes topo -m underlines its header with.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// Topology is the GPU topology nvidia-smi topo -m prints, keyed by GPU index,
+// the only identity the matrix carries.
+type Topology struct {
+	// Links holds one entry per ordered pair of distinct GPUs whose cell
+	// names a known link kind.
+	Links []TopologyLink
+	// Affinity holds the GPUs local to a single NUMA node.
+	Affinity []TopologyAffinity
+}
+
+// TopologyLink is the link between two GPUs.
+type TopologyLink struct {
+	GPU  int
+	Peer int
+	// Link is one of the TopologyLink* constants.
+	Link string
+}
+
+// TopologyAffinity is the NUMA node a GPU is local to.
+type TopologyAffinity struct {
+	GPU      int
+	NUMANode int
+	// CPUAffinity is the node's CPU list as nvidia-smi prints it, for
+	// example "0-23,48-71", empty when unknown.
+	CPUAffinity string
+}
+
+// QueryTopology runs nvidia-smi topo -m and parses its matrix.
+func QueryTopology(ctx context.Context, command string, run RunFunc) (Topology, error) {
+	stdout, _, err := execQuery(ctx, command, run, "topo", "-m")
+	if err != nil {
+		return Topology{}, err
+	}
+
+	return ParseTopology(stdout), nil
+}
+
+// ParseTopology parses the matrix of nvidia-smi topo -m output. The matrix is
+// tab-separated, its header names the columns, and its GPU rows follow up to
+// the first blank line. Rows of other devices (NICs) and columns after the
+// GPU ones other than the affinity pair are ignored. A GPU whose NUMA
+// affinity is unknown (N/A) or spans several nodes has no Affinity entry.
+func ParseTopology(output string) Topology {
+	var (
+		topology Topology
+		columns  []string
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		cells := topologyCells(scanner.Text())
+
+		if columns == nil {
+			if len(cells) > 0 && topologyGPUIndex(cells[0]) >= 0 {
+				// the header: a row label column that is always blank
+				columns = cells
+			}
+
+			continue
+		}
+
+		if len(cells) == 0 {
+			break
+		}
+
+		gpu := topologyGPUIndex(cells[0])
+		if gpu < 0 {
+			continue
+		}
+
+		topology.parseRow(gpu, columns, cells[1:])
+	}
+
+	return topology
+}
+
+// parseRow folds one GPU row of the matrix into the topology.
+func (t *Topology) parseRow(gpu int, columns, cells []string) {
+	affinity := TopologyAffinity{GPU: gpu, NUMANode: -1}
+
+	for i, cell := range cells {
+		if i >= len(columns) {
+			break
+		}
+
+		switch column := columns[i]; column {
+		case "CPU Affinity":
+			if cell != "N/A" {
+				affinity.CPUAffinity = cell
+			}
+		case "NUMA Affinity":
+			if node, err := strconv.Atoi(cell); err == nil {
+				affinity.NUMANode = node
+			}
+		default:
+			peer := topologyGPUIndex(column)
+			if peer < 0 || peer == gpu {
+				continue
+			}
+
+			link, ok := topologyLinks[cell]
+			if nvlinkCell.MatchString(cell) {
+				link, ok = TopologyLinkNVLink, true
+			}
+
+			if ok {
+				t.Links = append(t.Links, TopologyLink{GPU: gpu, Peer: peer, Link: link})
+			}
+		}
+	}
+
+	if affinity.NUMANode >= 0 {
+		t.Affinity = append(t.Affinity, affinity)
+	}
+}
+
+// topologyCells splits one matrix line into its trimmed, non-empty cells.
+// Empty cells only pad the columns: the GPU NUMA ID cell is preceded by an
+// extra tab.
+func topologyCells(line string) []string {
+	var cells []string
+
+	for cell := range strings.SplitSeq(ansiEscape.ReplaceAllString(line, ""), "\t") {
+		if cell = strings.TrimSpace(cell); cell != "" {
+			cells = append(cells, cell)
+		}
+	}
+
+	return cells
+}
+
+// topologyGPUIndex returns the index of a "GPU3" matrix label, or -1 for any
+// other label.
+func topologyGPUIndex(label string) int {
+	digits, ok := strings.CutPrefix(label, "GPU")
+	if !ok {
+		return -1
+	}
+
+	index, err := strconv.Atoi(digits)
+	if err != nil || index < 0 {
+		return -1
+	}
+
+	return index
+}
@@ -0,0 +1,90 @@
+package nvidiasmi_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+// topologyOutput is topo -m output of a four-GPU box: two NVLink-bridged
+// pairs on separate NUMA nodes, a NIC beside the first pair, and a GPU whose
+// NUMA affinity is unknown.
+const topologyOutput = "\t\x1b[4mGPU0\tGPU1\tGPU2\tGPU3\tNIC0\tCPU Affinity\tNUMA Affinity\tGPU NUMA ID\x1b[0m\n" +
+	"GPU0\t X \tNV4\tSYS\tSYS\tPXB\t0-23,48-71\t0\t\tN/A\n" +
+	"GPU1\tNV4\t X \tSYS\tSYS\tPXB\t0-23,48-71\t0\t\tN/A\n" +
+	"GPU2\tSYS\tSYS\t X \tNV4\tSYS\t24-47,72-95\t1\t\tN/A\n" +
+	"GPU3\tSYS\tSYS\tNV4\t X \tSYS\tN/A\tN/A\t\tN/A\n" +
+	"NIC0\tPXB\tPXB\tSYS\tSYS\t X \t\t\t\t\n" +
+	`
+Legend:
+
+  X    = Self
+  SYS  = Connection traversing PCIe as well as the SMP interconnect between NUMA nodes (e.g., QPI/UPI)
+  NV#  = Connection traversing a bonded set of # NVLinks
+
+NIC Legend:
+
+  NIC0: mlx5_0
+`
+
+func TestParseTopology(t *testing.T) {
+	t.Parallel()
+
+	topology := nvidiasmi.ParseTopology(topologyOutput)
+
+	assert.Equal(t, []nvidiasmi.TopologyLink{
+		{GPU: 0, Peer: 1, Link: nvidiasmi.TopologyLinkNVLink},
+		{GPU: 0, Peer: 2, Link: nvidiasmi.TopologyLinkSystem},
+		{GPU: 0, Peer: 3, Link: nvidiasmi.TopologyLinkSystem},
+		{GPU: 1, Peer: 0, Link: nvidiasmi.TopologyLinkNVLink},
+		{GPU: 1, Peer: 2, Link: nvidiasmi.TopologyLinkSystem},
+		{GPU: 1, Peer: 3, Link: nvidiasmi.TopologyLinkSystem},
+		{GPU: 2, Peer: 0, Link: nvidiasmi.TopologyLinkSystem},
+		{GPU: 2, Peer: 1, Link: nvidiasmi.TopologyLinkSystem},
+		{GPU: 2, Peer: 3, Link: nvidiasmi.TopologyLinkNVLink},
+		{GPU: 3, Peer: 0, Link: nvidiasmi.TopologyLinkSystem},
+		{GPU: 3, Peer: 1, Link: nvidiasmi.TopologyLinkSystem},
+		{GPU: 3, Peer: 2, Link: nvidiasmi.TopologyLinkNVLink},
+	}, topology.Links)
+	assert.Equal(t, []nvidiasmi.TopologyAffinity{
+		{GPU: 0, NUMANode: 0, CPUAffinity: "0-23,48-71"},
+		{GPU: 1, NUMANode: 0, CPUAffinity: "0-23,48-71"},
+		{GPU: 2, NUMANode: 1, CPUAffinity: "24-47,72-95"},
+	}, topology.Affinity)
+}
+
+func TestParseTopologySingleGPU(t *testing.T) {
+	t.Parallel()
+
+	// verbatim from an H200 capture: no peers, and the header underlined
+	output := "\t\x1b[4mGPU0\tCPU Affinity\tNUMA Affinity\tGPU NUMA ID\x1b[0m\n" +
+		"GPU0\t X \t0-23\t0\t\tN/A\n" +
+		"\nLegend:\n\n  X    = Self\n"
+
+	topology := nvidiasmi.ParseTopology(output)
+
+	assert.Empty(t, topology.Links)
+	assert.Equal(t, []nvidiasmi.TopologyAffinity{{GPU: 0, NUMANode: 0, CPUAffinity: "0-23"}}, topology.Affinity)
+	assert.Equal(t, nvidiasmi.Topology{}, nvidiasmi.ParseTopology(""))
+}
+
+func TestParseTopologyPCIeLinks(t *testing.T) {
+	t.Parallel()
+
+	// an older driver spelling SYS as SOC
+	output := "\tGPU0\tGPU1\tGPU2\tCPU Affinity\n" +
+		"GPU0\t X \tPIX\tSOC\t0-7\n" +
+		"GPU1\tPHB\t X \tNODE\t0-7\n" +
+		"GPU2\tSOC\tPXB\t X \t8-15\n"
+
+	assert.Equal(t, []nvidiasmi.TopologyLink{
+		{GPU: 0, Peer: 1, Link: nvidiasmi.TopologyLinkPIX},
+		{GPU: 0, Peer: 2, Link: nvidiasmi.TopologyLinkSystem},
+		{GPU: 1, Peer: 0, Link: nvidiasmi.TopologyLinkPHB},
+		{GPU: 1, Peer: 2, Link: nvidiasmi.TopologyLinkNode},
+		{GPU: 2, Peer: 0, Link: nvidiasmi.TopologyLinkSystem},
+		{GPU: 2, Peer: 1, Link: nvidiasmi.TopologyLinkPXB},
+	}, nvidiasmi.ParseTopology(output).Links)
+}
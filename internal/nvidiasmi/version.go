@@ -14,17 +14,29 @@ import (
 // that does not look like a version number must be ignored, not exported.
 var cudaVersionValue = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
 
-// QueryCudaVersion runs `nvidia-smi --version` once and extracts the CUDA
-// version the driver supports. It is best-effort: any failure returns the
-// empty string (logged once), which renders as an empty cuda_version label.
-// It runs at startup only, never per scrape.
-func QueryCudaVersion(
+// driverVersionValue is the shape of a plausible driver version value, which
+// the same renaming turned into prose on the legacy line.
+var driverVersionValue = regexp.MustCompile(`^[0-9]+(\.[0-9]+)+$`)
+
+// Versions are the versions `nvidia-smi --version` reports.
+type Versions struct {
+	// CUDA is the CUDA version the driver supports.
+	CUDA string
+	// Driver is the kernel driver version.
+	Driver string
+}
+
+// QueryVersions runs `nvidia-smi --version` once and extracts the CUDA
+// version the driver supports and the driver version. It is best-effort: any
+// failure returns empty versions (logged once), which renders as an empty
+// cuda_version label. It runs at startup only, never per scrape.
+func QueryVersions(
 	ctx context.Context,
 	command string,
 	timeout time.Duration,
 	run RunFunc,
 	logger *slog.Logger,
-) string {
+) Versions {
 	if timeout > 0 {
 		var cancel context.CancelFunc
 
@@ -37,16 +49,16 @@ func QueryCudaVersion(
 		logger.Warn("failed to read the CUDA version from nvidia-smi, "+
 			"the cuda_version label stays empty", "err", err)
 
-		return ""
+		return Versions{}
 	}
 
-	version := ParseCudaVersion(stdout)
-	if version == "" {
+	versions := Versions{CUDA: ParseCudaVersion(stdout), Driver: ParseDriverVersion(stdout)}
+	if versions.CUDA == "" {
 		logger.Warn("no CUDA version found in the nvidia-smi --version output, " +
 			"the cuda_version label stays empty")
 	}
 
-	return version
+	return versions
 }
 
 // ParseCudaVersion extracts the CUDA version from `nvidia-smi --version`
@@ -77,3 +89,32 @@ func ParseCudaVersion(output string) string {
 
 	return legacy
 }
+
+// ParseDriverVersion extracts the driver version from `nvidia-smi --version`
+// output. The 610 branch renamed it the same way as the CUDA version: the
+// "KMD version" line wins, and the legacy "DRIVER version" line is the
+// fallback.
+func ParseDriverVersion(output string) string {
+	legacy := ""
+
+	for line := range strings.SplitSeq(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if !driverVersionValue.MatchString(value) {
+			continue
+		}
+
+		switch strings.ToLower(strings.Join(strings.Fields(key), " ")) {
+		case "kmd version":
+			return value
+		case "driver version":
+			legacy = value
+		}
+	}
+
+	return legacy
+}
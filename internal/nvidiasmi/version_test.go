@@ -73,26 +73,41 @@ CUDA UMD version    : 13.3
 	}
 }
 
-func TestQueryCudaVersion(t *testing.T) {
+func TestParseDriverVersion(t *testing.T) {
+	t.Parallel()
+
+	for output, want := range map[string]string{
+		"NVIDIA-SMI version  : 590.48.01\nDRIVER version      : 590.48.01\nCUDA Version        : 13.1\n":   "590.48.01",
+		`DRIVER version      : Deprecated, see "KMD version" instead` + "\nKMD version         : 610.62\n": "610.62",
+		`DRIVER version      : Deprecated, see "KMD version" instead` + "\n":                               "",
+		"":                               "",
+		"DRIVER version      : N/A\n":    "",
+		"NVML version        : 590.48\n": "",
+	} {
+		assert.Equal(t, want, nvidiasmi.ParseDriverVersion(output), output)
+	}
+}
+
+func TestQueryVersions(t *testing.T) {
 	t.Parallel()
 
 	run := func(cmd *exec.Cmd) error {
 		assert.Equal(t, []string{"--version"}, cmd.Args[1:])
 
-		_, err := cmd.Stdout.Write([]byte("CUDA Version        : 13.1\n"))
+		_, err := cmd.Stdout.Write([]byte("DRIVER version      : 590.48.01\nCUDA Version        : 13.1\n"))
 
 		return err //nolint:wrapcheck // test stub
 	}
 
-	got := nvidiasmi.QueryCudaVersion(t.Context(), "nvidia-smi", time.Second, run, slogt.New(t))
-	assert.Equal(t, "13.1", got)
+	got := nvidiasmi.QueryVersions(t.Context(), "nvidia-smi", time.Second, run, slogt.New(t))
+	assert.Equal(t, nvidiasmi.Versions{CUDA: "13.1", Driver: "590.48.01"}, got)
 }
 
-func TestQueryCudaVersionCommandFailure(t *testing.T) {
+func TestQueryVersionsCommandFailure(t *testing.T) {
 	t.Parallel()
 
 	run := func(*exec.Cmd) error { return errors.New("boom") }
 
-	got := nvidiasmi.QueryCudaVersion(t.Context(), "nvidia-smi", time.Second, run, slogt.New(t))
+	got := nvidiasmi.QueryVersions(t.Context(), "nvidia-smi", time.Second, run, slogt.New(t))
 	assert.Empty(t, got)
 }
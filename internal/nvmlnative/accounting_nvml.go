@@ -0,0 +1,74 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+// accountingPass gathers one extras pass's accounting buffers, folded into
+// the backend's tracker once the pass ends.
+type accountingPass struct {
+	gpus []string
+	apps []nvidiasmi.AccountedApp
+}
+
+// collectAccounting reads one device's accounting buffer into the pass. A
+// device with accounting mode disabled, or one that cannot report it (or a
+// driver without the mode getter), is skipped silently; so is a buffer entry
+// overwritten between listing the pids and reading its stats. A device whose
+// buffer could not be read in full is left out of the pass. Reports whether
+// extras collection may continue.
+func (b *Backend) collectAccounting(dev device, uuid string, pass *accountingPass) bool {
+	mode, ret := dev.GetAccountingMode()
+
+	//nolint:exhaustive // every other return is a plain failure
+	switch ret {
+	case nvml.SUCCESS:
+		if mode != nvml.FEATURE_ENABLED {
+			return true
+		}
+	case nvml.ERROR_NOT_SUPPORTED, nvml.ERROR_FUNCTION_NOT_FOUND:
+		return true
+	default:
+		return b.extrasFailure("accounting", "cannot read the accounting mode", ret)
+	}
+
+	pids, ret := dev.GetAccountingPids()
+	if ret != nvml.SUCCESS {
+		return b.extrasFailure("accounting", "cannot list the accounted processes", ret)
+	}
+
+	apps := make([]nvidiasmi.AccountedApp, 0, len(pids))
+
+	for _, pid := range pids {
+		stats, ret := dev.GetAccountingStats(uint32(pid)) //nolint:gosec // G115: pids are non-negative
+
+		//nolint:exhaustive // every other return is a plain failure
+		switch ret {
+		case nvml.SUCCESS:
+		case nvml.ERROR_NOT_FOUND:
+			continue
+		default:
+			return b.extrasFailure("accounting", "cannot read the accounting stats", ret)
+		}
+
+		apps = append(apps, nvidiasmi.AccountedApp{
+			GPUUUID:        uuid,
+			PID:            strconv.Itoa(pid),
+			MaxMemoryBytes: stats.MaxMemoryUsage,
+			Duration:       time.Duration(stats.Time) * time.Millisecond, //nolint:gosec // G115: a process lifetime in ms
+			Running:        stats.IsRunning != 0,
+		})
+	}
+
+	pass.gpus = append(pass.gpus, uuid)
+	pass.apps = append(pass.apps, apps...)
+
+	return true
+}
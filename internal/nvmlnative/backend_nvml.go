@@ -17,6 +17,7 @@ import (
 	"github.com/NVIDIA/go-nvml/pkg/nvml"
 
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/eventlog"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
 )
 
@@ -35,16 +36,27 @@ type nvmlAPI struct {
 	cudaDriverVersion func() (int, nvml.Return)
 	processName       func(int) (string, nvml.Return)
 	validateInforom   func(nvml.Device) nvml.Return
+	// vgpuDriverCapability is a library-level query, answered once per
+	// cycle rather than per device
+	vgpuDriverCapability func(nvml.VgpuDriverCapability) (bool, nvml.Return)
 	// the GPM entry points are package-level in go-nvml AND its sample
 	// mock cannot pass through the real metrics call (a private concrete
 	// type assertion), so they must live on the seam
 	gpmSampleAlloc  func() (nvml.GpmSample, nvml.Return)
 	gpmSampleFree   func(nvml.GpmSample) nvml.Return
+	gpmSampleGet    func(nvml.Device, nvml.GpmSample) nvml.Return
 	gpmMigSampleGet func(nvml.Device, int, nvml.GpmSample) nvml.Return
 	gpmMetricsGet   func(*nvml.GpmMetricsGetType) nvml.Return
 	// eventSetCreate is package-level in go-nvml, hence on the seam; the
 	// set's Wait/Free are interface methods and mock naturally
 	eventSetCreate func() (nvml.EventSet, nvml.Return)
+	// the confidential computing state is system-wide, read once per
+	// cycle through the package-level getters
+	confComputeCapabilities func() (nvml.ConfComputeSystemCaps, nvml.Return)
+	confComputeState        func() (nvml.ConfComputeSystemState, nvml.Return)
+	confComputeSettings     func() (nvml.SystemConfComputeSettings, nvml.Return)
+	confComputeReadyState   func() (uint32, nvml.Return)
+	confComputeKeyRotation  func() (nvml.ConfComputeGetKeyRotationThresholdInfo, nvml.Return)
 	// lookupSymbol probes whether the driver library exports a symbol. A
 	// getter whose export is missing entirely does NOT answer with a polite
 	// FUNCTION_NOT_FOUND return: the lazily bound call crashes the process
@@ -64,15 +76,22 @@ func realNVML() nvmlAPI {
 		// libcuda and fails without it, while this one falls back to the
 		// driver's known supported version. The utility-only container
 		// capability (the documented deployment) injects no libcuda.
-		cudaDriverVersion: nvml.SystemGetCudaDriverVersion,
-		processName:       nvml.SystemGetProcessName,
-		validateInforom:   nvml.DeviceValidateInforom,
-		gpmSampleAlloc:    nvml.GpmSampleAlloc,
-		gpmSampleFree:     nvml.GpmSampleFree,
-		gpmMigSampleGet:   nvml.GpmMigSampleGet,
-		gpmMetricsGet:     nvml.GpmMetricsGet,
-		eventSetCreate:    nvml.EventSetCreate,
-		lookupSymbol:      func(name string) error { return nvml.Extensions().LookupSymbol(name) },
+		cudaDriverVersion:       nvml.SystemGetCudaDriverVersion,
+		processName:             nvml.SystemGetProcessName,
+		validateInforom:         nvml.DeviceValidateInforom,
+		vgpuDriverCapability:    nvml.GetVgpuDriverCapabilities,
+		gpmSampleAlloc:          nvml.GpmSampleAlloc,
+		gpmSampleFree:           nvml.GpmSampleFree,
+		gpmSampleGet:            nvml.GpmSampleGet,
+		gpmMigSampleGet:         nvml.GpmMigSampleGet,
+		gpmMetricsGet:           nvml.GpmMetricsGet,
+		eventSetCreate:          nvml.EventSetCreate,
+		confComputeCapabilities: nvml.SystemGetConfComputeCapabilities,
+		confComputeState:        nvml.SystemGetConfComputeState,
+		confComputeSettings:     nvml.SystemGetConfComputeSettings,
+		confComputeReadyState:   nvml.SystemGetConfComputeGpusReadyState,
+		confComputeKeyRotation:  nvml.SystemGetConfComputeKeyRotationThresholdInfo,
+		lookupSymbol:            func(name string) error { return nvml.Extensions().LookupSymbol(name) },
 	}
 }
 
@@ -100,15 +119,47 @@ type Backend struct {
 	// It is replaced wholesale on re-initialization rather than mutated, and
 	// read atomically because the XID watcher reads it without holding mu.
 	avail atomic.Pointer[availability]
-	// gpm retains one activity sample per GPU instance across cycles, so
-	// utilization can be computed over the inter-collection window. Guarded
+	// gpm retains one activity sample per GPU instance, and per whole GPU
+	// outside MIG mode, across cycles, so utilization can be computed over
+	// the inter-collection window. Guarded
 	// by mu like the rest of the cycle state; every sample is freed before
 	// any NVML shutdown.
 	gpm map[string]*gpmState
+	// procUtilSeen is the newest per-process utilization sample timestamp
+	// consumed per GPU uuid, the start of the next window. Guarded by mu
+	// like the rest of the cycle state.
+	procUtilSeen map[string]uint64
+	// vgpuUtilSeen is the newest vGPU utilization sample timestamp consumed
+	// per host GPU uuid, the start of the next window. Guarded by mu like
+	// the rest of the cycle state.
+	vgpuUtilSeen map[string]uint64
+	// samplesSeen is the newest driver sample timestamp consumed per GPU and
+	// sample buffer, the start of the next window, and powerHistograms the
+	// per-GPU distributions those samples accumulate into. Guarded by mu
+	// like the rest of the cycle state.
+	samplesSeen     map[driverSampleKey]uint64
+	powerHistograms map[string]*collect.NativeHistogram
+	// capabilities caches the GPUs' static capability descriptions by uuid,
+	// valid for NVML generation capabilitiesGeneration. Guarded by mu like
+	// the rest of the cycle state.
+	capabilities           map[string]collect.GPUCapabilities
+	capabilitiesGeneration uint64
+	// topology caches the GPU topology read for the device set and NVML
+	// generation topologyKey names. Guarded by mu like the rest of the cycle
+	// state.
+	topology    collect.Topology
+	topologyKey string
+	// accounting folds the accounting buffers into completed-process
+	// totals across cycles.
+	accounting collect.AccountingTracker
 	// now is the clock, injectable so the GPM window guards are testable.
 	// Set once at construction, immutable afterwards (the XID watcher reads
 	// it without the cycle lock).
 	now func() time.Time
+	// vgpuHandle reads the driver handle the vGPU utilization samples are
+	// keyed by, injectable because go-nvml's mocks carry none. Set once at
+	// construction, like now.
+	vgpuHandle func(nvml.VgpuInstance) (uint32, bool)
 	// lifecycleMu is the barrier between NVML shutdown and the XID
 	// watcher's in-flight driver calls: the watcher holds it shared around
 	// every NVML operation, shutdown takes it exclusively (bounded, so a
@@ -121,10 +172,13 @@ type Backend struct {
 	// genLive even when the exclusive lifecycle lock cannot be acquired.
 	generation atomic.Uint64
 	genLive    atomic.Bool
-	// xids accumulates the XID error events observed by the watcher. It
+	// events accumulates the driver events observed by the watcher. It
 	// has its own lock and is read at scrape time, outside the snapshot
 	// pipeline.
-	xids   xidAccumulator
+	events eventAccumulator
+	// recent keeps the latest driver events in order, for the event log
+	// endpoint.
+	recent eventlog.Ring
 	logger *slog.Logger
 }
 
@@ -135,7 +189,7 @@ func New(logger *slog.Logger) (*Backend, error) {
 }
 
 func newWithAPI(api nvmlAPI, logger *slog.Logger) (*Backend, error) {
-	backend := &Backend{api: api, now: time.Now, logger: logger}
+	backend := &Backend{api: api, now: time.Now, vgpuHandle: rawVgpuHandle, logger: logger}
 	if ret := backend.api.init(); ret != nvml.SUCCESS {
 		return nil, fmt.Errorf("failed to initialize NVML: %s", retString(ret))
 	}
@@ -306,7 +360,7 @@ func (b *Backend) collectCycle(
 	if opts.ComputeApps {
 		reading.AppsAttempted = true
 
-		apps, appsErr := b.collectComputeApps(ctx)
+		apps, appsErr := b.collectComputeApps(ctx, opts.ProcessTypes)
 		if appsErr != nil {
 			reading.AppsErr = appsErr
 		} else {
@@ -373,6 +427,14 @@ func (b *Backend) collectTable(
 
 	plan := newPlan(fields)
 
+	if plan.want("vgpu_driver_capability.heterogenous_multivGPU") {
+		shared.multiVGPU, shared.multiVGPURet = false, nvml.ERROR_FUNCTION_NOT_FOUND
+		if b.avail.Load().has("nvmlGetVgpuDriverCapabilities") {
+			shared.multiVGPU, shared.multiVGPURet = b.api.vgpuDriverCapability(
+				nvml.VGPU_DRIVER_CAP_HETEROGENEOUS_MULTI_VGPU)
+		}
+	}
+
 	rows := make([]nvidiasmi.Row, 0, count)
 	qFieldToCells := make(map[nvidiasmi.QField][]nvidiasmi.Cell, len(fields.Query))
 
@@ -549,6 +611,8 @@ type sharedValues struct {
 	driverVersionRet nvml.Return
 	count            int
 	countRet         nvml.Return
+	multiVGPU        bool
+	multiVGPURet     nvml.Return
 }
 
 // plan is the per-cycle collection plan: which canonical fields were
@@ -668,6 +732,15 @@ func (b *Backend) collectDevice(
 
 	coll.values["timestamp"] = shared.timestamp
 	coll.set("driver_version", shared.driverVersionRet, func() string { return shared.driverVersion })
+	// nvidia-smi prints the library's driver version for both on Linux
+	// (capture-verified)
+	coll.set("kmd_version", shared.driverVersionRet, func() string { return shared.driverVersion })
+
+	if reqs.want("vgpu_driver_capability.heterogenous_multivGPU") {
+		coll.set("vgpu_driver_capability.heterogenous_multivGPU", shared.multiVGPURet,
+			func() string { return supportedStr(shared.multiVGPU) })
+	}
+
 	coll.set("count", shared.countRet, func() string { return strconv.Itoa(shared.count) })
 
 	if reqs.want("name") {
@@ -703,6 +776,8 @@ func (b *Backend) collectDevice(
 		coll.set("pci.sub_device_id", ret, func() string { return fmt.Sprintf("0x%08X", pci.PciSubSystemId) })
 	}
 
+	collectVgpuCapabilities(dev, coll, reqs)
+
 	if reqs.want("pcie.link.gen.current", "pcie.link.gen.gpucurrent") {
 		gen, ret := dev.GetCurrPcieLinkGeneration()
 		coll.set("pcie.link.gen.current", ret, func() string { return strconv.Itoa(gen) })
@@ -860,6 +935,7 @@ func (b *Backend) collectDevice(
 	}
 
 	b.collectFieldValues(dev, coll, reqs)
+	b.collectPowerSmoothing(dev, coll, reqs)
 
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("device collection interrupted: %w", err)
@@ -1096,6 +1172,7 @@ func (b *Backend) collectDevice(
 	}
 
 	collectFabric(dev, coll, reqs)
+	collectFabricHealth(dev, coll, reqs)
 	collectPlatform(dev, coll, reqs)
 
 	if reqs.want("hostname") {
@@ -1103,6 +1180,11 @@ func (b *Backend) collectDevice(
 		coll.set("hostname", ret, func() string { return hostname })
 	}
 
+	if reqs.want("bbx.time_run") {
+		bbx, ret := dev.GetBBXTimeData_v1()
+		coll.set("bbx.time_run", ret, func() string { return strconv.FormatUint(uint64(bbx.TimeRun), 10) })
+	}
+
 	if len(coll.denied) > 0 && !b.permLogged {
 		b.permLogged = true
 
@@ -1129,60 +1211,75 @@ func (b *Backend) collectDevice(
 	return coll.values, nil
 }
 
-// collectFieldValues batches everything served by nvmlDeviceGetFieldValues.
-// The field-ID choices are trace-verified against nvidia-smi's own calls.
+// fieldValueEntry is one field served by nvmlDeviceGetFieldValues: the
+// field ID, the scope it is read in (a power scope, a smoothing profile), and
+// how the decoded value prints.
+type fieldValueEntry struct {
+	field  nvidiasmi.QField
+	id     uint32
+	scope  uint32
+	bare   bool
+	format func(v float64) string
+}
+
+// collectFieldValues batches everything served by nvmlDeviceGetFieldValues
+// outside power smoothing. The field-ID choices of the GPU-scope fields are
+// trace-verified against nvidia-smi's own calls; the module and base GPU
+// scopes read the same power fields in another power scope.
 //
-//nolint:cyclop,funlen // one linear pass over the batched entries and their outcomes
+//nolint:funlen // one flat table of the batched entries
 func (b *Backend) collectFieldValues(dev device, coll *devCollector, reqs plan) {
-	entries := []struct {
-		field  nvidiasmi.QField
-		id     uint32
-		bare   bool
-		format func(v float64) string
-	}{
+	watts := func(v float64) string { return fmt.Sprintf("%.2f W", v/1000.0) }
+	micros := func(v float64) string { return fmt.Sprintf("%d us", int64(v)) }
+
+	entries := []fieldValueEntry{
+		{"power.draw.average", nvml.FI_DEV_POWER_AVERAGE, nvml.POWER_SCOPE_GPU, false, watts},
+		{"power.draw.instant", nvml.FI_DEV_POWER_INSTANT, nvml.POWER_SCOPE_GPU, false, watts},
+		{"module.power.draw.average", nvml.FI_DEV_POWER_AVERAGE, nvml.POWER_SCOPE_MODULE, false, watts},
+		{"module.power.draw.instant", nvml.FI_DEV_POWER_INSTANT, nvml.POWER_SCOPE_MODULE, false, watts},
+		{"module.power.limit", nvml.FI_DEV_POWER_REQUESTED_LIMIT, nvml.POWER_SCOPE_MODULE, false, watts},
+		{"module.enforced.power.limit", nvml.FI_DEV_POWER_CURRENT_LIMIT, nvml.POWER_SCOPE_MODULE, false, watts},
+		{"module.power.default_limit", nvml.FI_DEV_POWER_DEFAULT_LIMIT, nvml.POWER_SCOPE_MODULE, false, watts},
+		{"module.power.min_limit", nvml.FI_DEV_POWER_MIN_LIMIT, nvml.POWER_SCOPE_MODULE, false, watts},
+		{"module.power.max_limit", nvml.FI_DEV_POWER_MAX_LIMIT, nvml.POWER_SCOPE_MODULE, false, watts},
+		{"gpu.base.current", nvml.FI_DEV_POWER_CURRENT_LIMIT, nvml.POWER_SCOPE_GPU_BASE, false, watts},
+		{"gpu.base.requested", nvml.FI_DEV_POWER_REQUESTED_LIMIT, nvml.POWER_SCOPE_GPU_BASE, false, watts},
+		{"gpu.base.default", nvml.FI_DEV_POWER_DEFAULT_LIMIT, nvml.POWER_SCOPE_GPU_BASE, false, watts},
+		{"gpu.base.min", nvml.FI_DEV_POWER_MIN_LIMIT, nvml.POWER_SCOPE_GPU_BASE, false, watts},
+		{"gpu.base.max", nvml.FI_DEV_POWER_MAX_LIMIT, nvml.POWER_SCOPE_GPU_BASE, false, watts},
 		{
-			"power.draw.average", nvml.FI_DEV_POWER_AVERAGE, false,
-			func(v float64) string { return fmt.Sprintf("%.2f W", v/1000.0) },
-		},
-		{
-			"power.draw.instant", nvml.FI_DEV_POWER_INSTANT, false,
-			func(v float64) string { return fmt.Sprintf("%.2f W", v/1000.0) },
-		},
-		{
-			"temperature.memory", nvml.FI_DEV_MEMORY_TEMP, true,
+			"temperature.memory", nvml.FI_DEV_MEMORY_TEMP, 0, true,
 			func(v float64) string { return strconv.FormatInt(int64(v), 10) },
 		},
 		{
-			"clocks_event_reasons_counters.sw_power_cap", nvml.FI_DEV_CLOCKS_EVENT_REASON_SW_POWER_CAP, false,
-			func(v float64) string { return fmt.Sprintf("%d us", int64(v)) },
+			"clocks_event_reasons_counters.sw_power_cap", nvml.FI_DEV_CLOCKS_EVENT_REASON_SW_POWER_CAP, 0, false,
+			micros,
 		},
 		{
-			"clocks_event_reasons_counters.sync_boost", nvml.FI_DEV_CLOCKS_EVENT_REASON_SYNC_BOOST, false,
-			func(v float64) string { return fmt.Sprintf("%d us", int64(v)) },
+			"clocks_event_reasons_counters.sync_boost", nvml.FI_DEV_CLOCKS_EVENT_REASON_SYNC_BOOST, 0, false,
+			micros,
 		},
 		{
 			"clocks_event_reasons_counters.sw_thermal_slowdown",
-			nvml.FI_DEV_CLOCKS_EVENT_REASON_SW_THERM_SLOWDOWN,
-			false,
-			func(v float64) string { return fmt.Sprintf("%d us", int64(v)) },
+			nvml.FI_DEV_CLOCKS_EVENT_REASON_SW_THERM_SLOWDOWN, 0, false,
+			micros,
 		},
 		{
 			"clocks_event_reasons_counters.hw_thermal_slowdown",
-			nvml.FI_DEV_CLOCKS_EVENT_REASON_HW_THERM_SLOWDOWN,
-			false,
-			func(v float64) string { return fmt.Sprintf("%d us", int64(v)) },
+			nvml.FI_DEV_CLOCKS_EVENT_REASON_HW_THERM_SLOWDOWN, 0, false,
+			micros,
 		},
 		{
 			"clocks_event_reasons_counters.hw_power_brake_slowdown",
-			nvml.FI_DEV_CLOCKS_EVENT_REASON_HW_POWER_BRAKE_SLOWDOWN, false,
-			func(v float64) string { return fmt.Sprintf("%d us", int64(v)) },
+			nvml.FI_DEV_CLOCKS_EVENT_REASON_HW_POWER_BRAKE_SLOWDOWN, 0, false,
+			micros,
 		},
 		{
-			"gpu_recovery_action", nvml.FI_DEV_GET_GPU_RECOVERY_ACTION, false,
+			"gpu_recovery_action", nvml.FI_DEV_GET_GPU_RECOVERY_ACTION, 0, false,
 			func(v float64) string { return recoveryActionStr(uint64(v)) },
 		},
 		{
-			"edpp_multiplier", edppMultiplierFieldID, false,
+			"edpp_multiplier", edppMultiplierFieldID, 0, false,
 			func(v float64) string { return fmt.Sprintf("%.2f %%", v) },
 		},
 	}
@@ -1195,18 +1292,30 @@ func (b *Backend) collectFieldValues(dev device, coll *devCollector, reqs plan)
 		}
 	}
 
-	if len(wanted) == 0 {
-		return
+	b.readFieldValues(dev, coll, wanted)
+}
+
+// readFieldValues reads a batch of field values in one driver call and
+// records each outcome, returning the decoded values of the fields that read
+// successfully.
+func (b *Backend) readFieldValues(
+	dev device,
+	coll *devCollector,
+	entries []fieldValueEntry,
+) map[nvidiasmi.QField]float64 {
+	if len(entries) == 0 {
+		return nil
 	}
 
-	values := make([]nvml.FieldValue, len(wanted))
-	for i, e := range wanted {
+	values := make([]nvml.FieldValue, len(entries))
+	for i, e := range entries {
 		values[i].FieldId = e.id
+		values[i].ScopeId = e.scope
 	}
 
 	ret := dev.GetFieldValues(values)
 	if ret != nvml.SUCCESS {
-		for _, entry := range wanted {
+		for _, entry := range entries {
 			if entry.bare {
 				coll.setBare(entry.field, ret, nil)
 			} else {
@@ -1214,10 +1323,12 @@ func (b *Backend) collectFieldValues(dev device, coll *devCollector, reqs plan)
 			}
 		}
 
-		return
+		return nil
 	}
 
-	for i, entry := range wanted {
+	decoded := make(map[nvidiasmi.QField]float64, len(entries))
+
+	for i, entry := range entries {
 		fieldValue := values[i]
 		//nolint:gosec // G115: the field carries an nvmlReturn_t
 		fret := nvml.Return(fieldValue.NvmlReturn)
@@ -1235,8 +1346,11 @@ func (b *Backend) collectFieldValues(dev device, coll *devCollector, reqs plan)
 			coll.set(entry.field, nvml.SUCCESS, nil)
 		default:
 			coll.values[entry.field] = entry.format(value)
+			decoded[entry.field] = value
 		}
 	}
+
+	return decoded
 }
 
 //nolint:gosec // G115: reinterpreting the C value union's bytes is the point
@@ -1371,6 +1485,114 @@ func collectFabric(dev device, coll *devCollector, reqs plan) {
 	coll.values["fabric.clusterUuid"] = uuidBytes(info.ClusterUuid)
 }
 
+// fabricHealthMaskParts lists the parts of the v3 fabric health mask with
+// their bit positions and spellings.
+//
+//nolint:gochecknoglobals // lookup table
+var fabricHealthMaskParts = []struct {
+	field  nvidiasmi.QField
+	shift  uint32
+	width  uint32
+	format func(uint32) string
+}{
+	{
+		"fabric.health.bandwidth",
+		nvml.GPU_FABRIC_HEALTH_MASK_SHIFT_DEGRADED_BW, nvml.GPU_FABRIC_HEALTH_MASK_WIDTH_DEGRADED_BW,
+		func(v uint32) string { return fabricHealthFlagStr(v, "Degraded", "Full") },
+	},
+	{
+		"fabric.health.route_recovery_in_progress",
+		nvml.GPU_FABRIC_HEALTH_MASK_SHIFT_ROUTE_RECOVERY, nvml.GPU_FABRIC_HEALTH_MASK_WIDTH_ROUTE_RECOVERY,
+		func(v uint32) string { return fabricHealthFlagStr(v, "True", "False") },
+	},
+	{
+		"fabric.health.route_unhealthy",
+		nvml.GPU_FABRIC_HEALTH_MASK_SHIFT_ROUTE_UNHEALTHY, nvml.GPU_FABRIC_HEALTH_MASK_WIDTH_ROUTE_UNHEALTHY,
+		func(v uint32) string { return fabricHealthFlagStr(v, "True", "False") },
+	},
+	{
+		"fabric.health.access_timeout_recovery",
+		nvml.GPU_FABRIC_HEALTH_MASK_SHIFT_ACCESS_TIMEOUT_RECOVERY,
+		nvml.GPU_FABRIC_HEALTH_MASK_WIDTH_ACCESS_TIMEOUT_RECOVERY,
+		func(v uint32) string { return fabricHealthFlagStr(v, "True", "False") },
+	},
+	{
+		"fabric.health.incorrect_configuration",
+		nvml.GPU_FABRIC_HEALTH_MASK_SHIFT_INCORRECT_CONFIGURATION,
+		nvml.GPU_FABRIC_HEALTH_MASK_WIDTH_INCORRECT_CONFIGURATION,
+		fabricIncorrectConfigurationStr,
+	},
+	{
+		"fabric.health.partition_assigned",
+		nvml.GPU_FABRIC_HEALTH_MASK_SHIFT_PARTITION_ASSIGNED, nvml.GPU_FABRIC_HEALTH_MASK_WIDTH_PARTITION_ASSIGNED,
+		func(v uint32) string { return fabricHealthFlagStr(v, "True", "False") },
+	},
+}
+
+// collectFabricHealth fills the fabric.health.* fields from the v3 fabric
+// info. It is a read of its own so that drivers predating v3 keep serving the
+// base fabric fields. A registered fabric can still report no health at all
+// (capture-verified on H100), so every part falls back to the bare N/A token
+// on its own.
+func collectFabricHealth(dev device, coll *devCollector, reqs plan) {
+	healthFields := []nvidiasmi.QField{"fabric.health.summary"}
+	for _, part := range fabricHealthMaskParts {
+		healthFields = append(healthFields, part.field)
+	}
+
+	if !reqs.want(healthFields...) {
+		return
+	}
+
+	info, ret := dev.GetGpuFabricInfoV3()
+	if ret != nvml.SUCCESS || info.State == 0 {
+		coll.classify("fabric.health.summary", ret)
+
+		for _, f := range healthFields {
+			coll.values[f] = tokenBareNotAvailable
+		}
+
+		return
+	}
+
+	coll.values["fabric.health.summary"] = fabricHealthSummaryStr(info.HealthSummary)
+
+	for _, part := range fabricHealthMaskParts {
+		coll.values[part.field] = part.format(info.HealthMask >> part.shift & part.width)
+	}
+}
+
+// collectVgpuCapabilities fills the vgpu_device_capability.* fields, one
+// driver call per capability. Outside a vGPU host the driver does not
+// support the query (capture-verified [N/A]).
+func collectVgpuCapabilities(dev device, coll *devCollector, reqs plan) {
+	for _, entry := range []struct {
+		field      nvidiasmi.QField
+		capability nvml.DeviceVgpuCapability
+		format     func(bool) string
+	}{
+		{"vgpu_device_capability.fractional_multiVgpu", nvml.DEVICE_VGPU_CAP_FRACTIONAL_MULTI_VGPU, supportedStr},
+		{
+			"vgpu_device_capability.heterogeneous_timeSlice_profile",
+			nvml.DEVICE_VGPU_CAP_HETEROGENEOUS_TIMESLICE_PROFILES, supportedStr,
+		},
+		{
+			"vgpu_device_capability.heterogeneous_timeSlice_sizes",
+			nvml.DEVICE_VGPU_CAP_HETEROGENEOUS_TIMESLICE_SIZES, supportedStr,
+		},
+		{"vgpu_device_capability.homogeneous_placements", nvml.DEVICE_VGPU_CAP_HOMOGENEOUS_PLACEMENTS, supportedStr},
+		{"vgpu_device_capability.mig_timeSlicing", nvml.DEVICE_VGPU_CAP_MIG_TIMESLICING_SUPPORTED, supportedStr},
+		{"vgpu_device_capability.mig_timeSlicing_mode", nvml.DEVICE_VGPU_CAP_MIG_TIMESLICING_ENABLED, onOff},
+	} {
+		if !reqs.want(entry.field) {
+			continue
+		}
+
+		value, ret := dev.GetVgpuCapabilities(entry.capability)
+		coll.set(entry.field, ret, func() string { return entry.format(value) })
+	}
+}
+
 func collectPlatform(dev device, coll *devCollector, reqs plan) {
 	if !reqs.want("platform.chassis_serial_number", "platform.slot_number", "platform.tray_index",
 		"platform.host_id", "platform.peer_type", "platform.module_id", "platform.gpu_fabric_guid") {
@@ -1397,13 +1619,15 @@ func collectPlatform(dev device, coll *devCollector, reqs plan) {
 }
 
 // collectComputeApps lists processes with a compute context, matching the
-// exec backend's --query-compute-apps output. It fails softly per the
+// exec backend's --query-compute-apps output. With types, graphics and MPS
+// client processes join the list and every entry carries its type, matching
+// the exec backend's XML process report. It fails softly per the
 // Reading contract, but lifecycle-class returns still mark the backend for
 // re-initialization so the next cycle recovers. The caller holds the
 // backend lock.
 //
 //nolint:cyclop // one linear pass over devices and their process lists
-func (b *Backend) collectComputeApps(ctx context.Context) ([]nvidiasmi.ComputeApp, error) {
+func (b *Backend) collectComputeApps(ctx context.Context, types bool) ([]nvidiasmi.ComputeApp, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("per-process collection interrupted: %w", err)
 	}
@@ -1435,39 +1659,83 @@ func (b *Backend) collectComputeApps(ctx context.Context) ([]nvidiasmi.ComputeAp
 			return nil, b.softLifecycle(ret, fmt.Errorf("failed to list processes of device %d", deviceIdx))
 		}
 
-		nameAvailable := b.avail.Load().has("nvmlSystemGetProcessName")
+		if !types {
+			apps = b.appendApps(apps, uuid, "", procs)
 
-		for _, proc := range procs {
-			name, nret := "", nvml.ERROR_FUNCTION_NOT_FOUND
-			if nameAvailable {
-				name, nret = b.api.processName(int(proc.Pid))
-			}
+			continue
+		}
 
-			if nret != nvml.SUCCESS {
-				name = tok(nret)
-			}
+		apps = b.appendApps(apps, uuid, nvidiasmi.ProcessTypeCompute, procs)
 
-			usedMemory := mib(proc.UsedGpuMemory)
-			if proc.UsedGpuMemory == math.MaxUint64 {
-				// NVML reports "value unknown" as ~0; nvidia-smi prints the
-				// absent token in that case (Windows WDDM behavior)
-				usedMemory = tokenNotAvailable
+		for _, list := range []struct {
+			procType string
+			get      func() ([]nvml.ProcessInfo, nvml.Return)
+		}{
+			{nvidiasmi.ProcessTypeGraphics, dev.GetGraphicsRunningProcesses},
+			{nvidiasmi.ProcessTypeMPS, dev.GetMPSComputeRunningProcesses},
+		} {
+			procs, ret := list.get()
+
+			//nolint:exhaustive // every other return is a plain failure
+			switch ret {
+			case nvml.SUCCESS:
+				apps = b.appendApps(apps, uuid, list.procType, procs)
+			case nvml.ERROR_NOT_SUPPORTED, nvml.ERROR_FUNCTION_NOT_FOUND:
+				// e.g. no MPS on Windows: the kind simply has no processes
+			default:
+				return nil, b.softLifecycle(ret,
+					fmt.Errorf("failed to list %s processes of device %d", list.procType, deviceIdx))
 			}
-
-			apps = append(apps, nvidiasmi.ComputeApp{
-				GPUUUID:           nvidiasmi.NormalizeUUID(uuid),
-				PID:               strconv.FormatUint(uint64(proc.Pid), 10),
-				ProcessName:       name,
-				UsedMemory:        usedMemory,
-				GPUInstanceID:     migAppID(proc.GpuInstanceId),
-				ComputeInstanceID: migAppID(proc.ComputeInstanceId),
-			})
 		}
 	}
 
 	return apps, nil
 }
 
+// appendApps converts one NVML process list of a device into per-process
+// entries of the given type (empty when the typed query is off).
+func (b *Backend) appendApps(
+	apps []nvidiasmi.ComputeApp,
+	uuid, procType string,
+	procs []nvml.ProcessInfo,
+) []nvidiasmi.ComputeApp {
+	for _, proc := range procs {
+		usedMemory := mib(proc.UsedGpuMemory)
+		if proc.UsedGpuMemory == math.MaxUint64 {
+			// NVML reports "value unknown" as ~0; nvidia-smi prints the
+			// absent token in that case (Windows WDDM behavior)
+			usedMemory = tokenNotAvailable
+		}
+
+		apps = append(apps, nvidiasmi.ComputeApp{
+			GPUUUID:           nvidiasmi.NormalizeUUID(uuid),
+			PID:               strconv.FormatUint(uint64(proc.Pid), 10),
+			ProcessName:       b.processName(proc.Pid),
+			UsedMemory:        usedMemory,
+			GPUInstanceID:     migAppID(proc.GpuInstanceId),
+			ComputeInstanceID: migAppID(proc.ComputeInstanceId),
+			Type:              procType,
+		})
+	}
+
+	return apps
+}
+
+// processName resolves a pid to its name, or to the absent token nvidia-smi
+// prints when the name cannot be read.
+func (b *Backend) processName(pid uint32) string {
+	if !b.avail.Load().has("nvmlSystemGetProcessName") {
+		return tok(nvml.ERROR_FUNCTION_NOT_FOUND)
+	}
+
+	name, ret := b.api.processName(int(pid))
+	if ret != nvml.SUCCESS {
+		return tok(ret)
+	}
+
+	return name
+}
+
 // softLifecycle marks the backend for re-initialization on lifecycle-class
 // returns but keeps the error plain: per-process failures never fail the
 // collection or trigger shutdown-on-error, per the Reading contract.
@@ -1519,7 +1787,11 @@ func (b *Backend) collectExtras(ctx context.Context, opts CollectOptions) collec
 		b.warnOnce("cuda-version", "cannot read the CUDA version", ret)
 	}
 
-	if !opts.Energy && !opts.PCIeThroughput && !opts.MIG {
+	if !opts.Energy && !opts.PCIeThroughput && !opts.MIG && !opts.ProcessUtilization &&
+		!opts.Accounting && !opts.VideoSessions && !opts.NVLink && !opts.GPM &&
+		!opts.TemperatureThresholds && !opts.PCIeErrors && !opts.Fans && !opts.DriverSamples &&
+		!opts.Capabilities && !opts.Topology && !opts.ConfidentialComputing && !opts.VGPU &&
+		!opts.Superchip {
 		return extras
 	}
 
@@ -1530,27 +1802,76 @@ func (b *Backend) collectExtras(ctx context.Context, opts CollectOptions) collec
 		return extras
 	}
 
-	seenGIs := map[string]bool{}
+	seen := extrasSeen{
+		gpus:       map[string]bool{},
+		gpmKeys:    map[string]bool{},
+		accounting: &accountingPass{},
+		topology:   &topologyPass{},
+	}
+
+	if opts.ConfidentialComputing && !b.collectConfComputeSystem(&extras) {
+		return extras
+	}
+
+	complete := true
 
 	for deviceIdx := range count {
-		if ctx.Err() != nil {
-			return extras
-		}
+		if ctx.Err() != nil || !b.collectDeviceExtras(ctx, deviceIdx, opts, &extras, seen) {
+			complete = false
 
-		if !b.collectDeviceExtras(ctx, deviceIdx, opts, &extras, seenGIs) {
-			return extras
+			break
 		}
 	}
 
-	if opts.MIG {
-		// only after a complete pass: an aborted cycle must not mistake
-		// unvisited GPU instances for disappeared ones
-		b.dropOrphanGPMStates(seenGIs)
+	if opts.Accounting {
+		// the buffers read before an abort still count: the tracker keeps
+		// the state of the GPUs the pass did not reach
+		extras.Accounting = b.accounting.Observe(seen.accounting.gpus, seen.accounting.apps)
+	}
+
+	if !complete {
+		return extras
+	}
+
+	// only after a complete pass: an aborted cycle must not mistake
+	// unvisited devices or GPU instances for disappeared ones
+	if opts.MIG || opts.GPM {
+		b.dropOrphanGPMStates(seen.gpmKeys)
+	}
+
+	if opts.ProcessUtilization {
+		b.dropOrphanProcUtilWindows(seen.gpus)
+	}
+
+	if opts.VGPU {
+		b.dropOrphanVGPUUtilWindows(seen.gpus)
+	}
+
+	if opts.Topology {
+		// pairwise, so only over the full device set
+		b.collectTopology(seen.topology, &extras)
+	}
+
+	if opts.DriverSamples {
+		b.dropOrphanDriverSamples(seen.gpus)
 	}
 
 	return extras
 }
 
+// extrasSeen records what one extras pass visited, so per-device state left
+// behind by departed devices can be dropped once the pass completes.
+type extrasSeen struct {
+	gpus map[string]bool
+	// gpmKeys holds the retained GPM sample keys touched this pass, GPU
+	// instances and whole GPUs alike.
+	gpmKeys map[string]bool
+	// accounting gathers the accounting buffers read on this pass.
+	accounting *accountingPass
+	// topology gathers the devices visited on this pass.
+	topology *topologyPass
+}
+
 // collectDeviceExtras gathers one device's extras families. Reports whether
 // extras collection may continue: a non-lifecycle failure skips just this
 // device, a lifecycle-class one aborts the pass.
@@ -1559,7 +1880,7 @@ func (b *Backend) collectDeviceExtras(
 	deviceIdx int,
 	opts CollectOptions,
 	extras *collect.Extras,
-	seenGIs map[string]bool,
+	seen extrasSeen,
 ) bool {
 	dev, ret := b.device(deviceIdx)
 	if ret != nvml.SUCCESS {
@@ -1572,16 +1893,74 @@ func (b *Backend) collectDeviceExtras(
 	}
 
 	uuid = nvidiasmi.NormalizeUUID(uuid)
+	seen.gpus[uuid] = true
 
 	if opts.Energy && !b.collectEnergy(dev, uuid, extras) {
 		return false
 	}
 
+	if opts.TemperatureThresholds && !b.collectTemperatureThresholds(dev, uuid, extras) {
+		return false
+	}
+
+	if opts.PCIeErrors && !b.collectPCIeErrors(dev, uuid, extras) {
+		return false
+	}
+
+	if opts.Fans && !b.collectFans(dev, uuid, extras) {
+		return false
+	}
+
+	if opts.Capabilities && !b.collectCapabilities(dev, uuid, extras) {
+		return false
+	}
+
+	if opts.ConfidentialComputing && !b.collectConfComputeGPU(dev, uuid, extras) {
+		return false
+	}
+
+	if opts.Topology {
+		seen.topology.devices = append(seen.topology.devices, dev)
+		seen.topology.uuids = append(seen.topology.uuids, uuid)
+	}
+
 	if opts.PCIeThroughput && !b.collectPcie(ctx, dev, uuid, extras) {
 		return false
 	}
 
-	if opts.MIG && !b.collectMIG(dev, uuid, extras, seenGIs) {
+	if opts.MIG && !b.collectMIG(dev, uuid, extras, seen.gpmKeys) {
+		return false
+	}
+
+	if opts.GPM && !b.collectGPM(dev, uuid, extras, seen.gpmKeys) {
+		return false
+	}
+
+	if opts.ProcessUtilization && !b.collectProcessUtilization(dev, uuid, extras) {
+		return false
+	}
+
+	if opts.VGPU && !b.collectVGPUs(dev, uuid, extras) {
+		return false
+	}
+
+	if opts.Accounting && !b.collectAccounting(dev, uuid, seen.accounting) {
+		return false
+	}
+
+	if opts.VideoSessions && !b.collectVideoSessions(dev, uuid, extras) {
+		return false
+	}
+
+	if opts.NVLink && !b.collectNVLink(dev, uuid, extras) {
+		return false
+	}
+
+	if opts.Superchip && !b.collectSuperchip(dev, uuid, extras) {
+		return false
+	}
+
+	if opts.DriverSamples && !b.collectDriverSamples(dev, uuid, extras) {
 		return false
 	}
 
@@ -1657,6 +2036,18 @@ func (b *Backend) extrasFailure(family, msg string, ret nvml.Return) bool {
 	return true
 }
 
+// softRead reports whether extras collection may continue after one read of
+// the family. A reading the GPU does not support is no failure, and neither
+// is one whose export the driver lacks; anything else goes to
+// extrasFailure.
+func (b *Backend) softRead(family, msg string, ret nvml.Return) bool {
+	if ret == nvml.SUCCESS || ret == nvml.ERROR_NOT_SUPPORTED || ret == nvml.ERROR_FUNCTION_NOT_FOUND {
+		return true
+	}
+
+	return b.extrasFailure(family, msg, ret)
+}
+
 // symbolPresent reports whether the driver library exports the symbol,
 // cached for the process lifetime. The caller holds the backend lock.
 func (b *Backend) symbolPresent(name string) bool {
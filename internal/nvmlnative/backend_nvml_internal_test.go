@@ -4,8 +4,11 @@ package nvmlnative
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -56,6 +59,9 @@ func (f *fakeAPI) api() nvmlAPI {
 		processName:       func(int) (string, nvml.Return) { return "/usr/bin/burn", nvml.SUCCESS },
 		lookupSymbol:      func(string) error { return nil },
 		validateInforom:   func(nvml.Device) nvml.Return { return nvml.SUCCESS },
+		vgpuDriverCapability: func(nvml.VgpuDriverCapability) (bool, nvml.Return) {
+			return false, nvml.ERROR_NOT_SUPPORTED
+		},
 	}
 }
 
@@ -100,6 +106,26 @@ func newTestBackend(t *testing.T, fake *fakeAPI) *Backend {
 	return backend
 }
 
+// newTestBackendWithoutExports is newTestBackend on a driver library that
+// lacks the given exports.
+func newTestBackendWithoutExports(t *testing.T, fake *fakeAPI, missing ...string) *Backend {
+	t.Helper()
+
+	api := fake.api()
+	api.lookupSymbol = func(name string) error {
+		if slices.Contains(missing, name) {
+			return errors.New("undefined symbol")
+		}
+
+		return nil
+	}
+
+	backend, err := newWithAPI(api, slogt.New(t))
+	require.NoError(t, err)
+
+	return backend
+}
+
 func cellValue(t *testing.T, table *nvidiasmi.Table, qField nvidiasmi.QField) string {
 	t.Helper()
 
@@ -265,6 +291,72 @@ func TestComputeAppsHappyPath(t *testing.T) {
 	assert.Equal(t, "11111111-2222-3333-4444-555555555555", reading.Apps[0].GPUUUID)
 }
 
+func TestComputeAppsTypes(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 100000, nvml.SUCCESS }
+	dev.GetComputeRunningProcessesFunc = func() ([]nvml.ProcessInfo, nvml.Return) {
+		return []nvml.ProcessInfo{{Pid: 4242, UsedGpuMemory: 1024 * 1024}}, nvml.SUCCESS
+	}
+	dev.GetGraphicsRunningProcessesFunc = func() ([]nvml.ProcessInfo, nvml.Return) {
+		return []nvml.ProcessInfo{{Pid: 1696, UsedGpuMemory: 176 * 1024 * 1024}}, nvml.SUCCESS
+	}
+	dev.GetMPSComputeRunningProcessesFunc = func() ([]nvml.ProcessInfo, nvml.Return) {
+		return nil, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	opts := CollectOptions{ComputeApps: true, ProcessTypes: true}
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), opts)(t.Context())
+	require.NoError(t, err)
+	require.True(t, reading.AppsSuccess, "an unsupported MPS list is empty, not a failure")
+	require.Len(t, reading.Apps, 2)
+	assert.Equal(t, nvidiasmi.ProcessTypeCompute, reading.Apps[0].Type)
+	assert.Equal(t, "1696", reading.Apps[1].PID)
+	assert.Equal(t, nvidiasmi.ProcessTypeGraphics, reading.Apps[1].Type)
+	assert.Equal(t, "176 MiB", reading.Apps[1].UsedMemory)
+
+	// without the opt-in the graphics and MPS getters have no business
+	// being called, and the compute entries stay untyped
+	untyped := identityDevice()
+	untyped.GetPowerUsageFunc = dev.GetPowerUsageFunc
+	untyped.GetComputeRunningProcessesFunc = dev.GetComputeRunningProcessesFunc
+
+	backend = newTestBackend(t, &fakeAPI{devices: []nvml.Device{untyped}})
+
+	reading, _, err = backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{ComputeApps: true})(t.Context())
+	require.NoError(t, err)
+	require.Len(t, reading.Apps, 1)
+	assert.Empty(t, reading.Apps[0].Type)
+}
+
+func TestComputeAppsTypesFailSoftly(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 100000, nvml.SUCCESS }
+	dev.GetComputeRunningProcessesFunc = func() ([]nvml.ProcessInfo, nvml.Return) { return nil, nvml.SUCCESS }
+	dev.GetGraphicsRunningProcessesFunc = func() ([]nvml.ProcessInfo, nvml.Return) {
+		return nil, nvml.ERROR_UNKNOWN
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	opts := CollectOptions{ComputeApps: true, ProcessTypes: true}
+
+	reading, code, err := backend.QueryFunc(resolveFields(t, "power.draw"), opts)(t.Context())
+	require.NoError(t, err, "per-process failures must not fail the collection")
+	assert.Equal(t, 0, code)
+	assert.False(t, reading.AppsSuccess)
+	require.ErrorContains(t, reading.AppsErr, "graphics")
+	assert.Equal(t, int64(0), fake.shutdowns.Load())
+}
+
 func TestAbandonedCollectionFailsFastAndRecovers(t *testing.T) {
 	t.Parallel()
 
@@ -621,6 +713,300 @@ func TestExtrasPcieNotSupportedSkipsDevice(t *testing.T) {
 	assert.Equal(t, int64(0), fake.shutdowns.Load(), "NOT_SUPPORTED is not a lifecycle error")
 }
 
+func TestExtrasProcessUtilizationAveragesTheWindow(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1_700_000_000, 0)
+
+	var asked []uint64
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetProcessUtilizationFunc = func(lastSeen uint64) ([]nvml.ProcessUtilizationSample, nvml.Return) {
+		asked = append(asked, lastSeen)
+
+		base := uint64(start.UnixMicro())
+
+		return []nvml.ProcessUtilizationSample{
+			{Pid: 42, TimeStamp: base + 1, SmUtil: 40, MemUtil: 10, EncUtil: 0, DecUtil: 4},
+			{Pid: 7, TimeStamp: base + 2, SmUtil: 5, MemUtil: 1},
+			{Pid: 42, TimeStamp: base + 3, SmUtil: 60, MemUtil: 30, EncUtil: 2, DecUtil: 0},
+			// older than the window: already consumed, must not count
+			{Pid: 42, TimeStamp: base - 5, SmUtil: 100},
+		}, nvml.SUCCESS
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+	backend.now = func() time.Time { return start }
+
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{ProcessUtilization: true})
+
+	// the first cycle only opens the window
+	reading, _, err := query(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.ProcessUtilization)
+	assert.Empty(t, asked)
+
+	reading, _, err = query(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []uint64{uint64(start.UnixMicro())}, asked)
+
+	utils := reading.Extras.ProcessUtilization
+	require.Len(t, utils, 2)
+	assert.Equal(t, "7", utils[0].PID, "sorted by pid")
+	assert.Equal(t, "42", utils[1].PID)
+	assert.Equal(t, "11111111-2222-3333-4444-555555555555", utils[1].UUID)
+	assert.Equal(t, "/usr/bin/burn", utils[1].ProcessName)
+	assert.InDelta(t, 0.5, utils[1].SMRatio, 1e-9)
+	assert.InDelta(t, 0.2, utils[1].MemoryRatio, 1e-9)
+	assert.InDelta(t, 0.01, utils[1].EncoderRatio, 1e-9)
+	assert.InDelta(t, 0.02, utils[1].DecoderRatio, 1e-9)
+
+	// the next window starts at the newest sample consumed
+	_, _, err = query(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(start.UnixMicro())+3, asked[1])
+}
+
+func TestExtrasProcessUtilizationFailsSoftly(t *testing.T) {
+	t.Parallel()
+
+	ret := nvml.ERROR_NOT_FOUND
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetProcessUtilizationFunc = func(uint64) ([]nvml.ProcessUtilizationSample, nvml.Return) {
+		return nil, ret
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{ProcessUtilization: true})
+
+	// the first cycle only opens the window
+	_, _, err := query(t.Context())
+	require.NoError(t, err)
+
+	for _, ret = range []nvml.Return{nvml.ERROR_NOT_FOUND, nvml.ERROR_NOT_SUPPORTED, nvml.ERROR_UNKNOWN} {
+		reading, code, err := query(t.Context())
+		require.NoError(t, err, "%s must not fail the collection", retString(ret))
+		assert.Equal(t, 0, code)
+		assert.Empty(t, reading.Extras.ProcessUtilization)
+	}
+
+	assert.Equal(t, int64(0), fake.shutdowns.Load())
+
+	ret = nvml.ERROR_GPU_IS_LOST
+
+	_, _, err = query(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "a lifecycle error must mark the backend for re-init")
+}
+
+func TestExtrasProcessUtilizationOnADriverWithoutTheGetter(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackendWithoutExports(t, fake, "nvmlDeviceGetProcessUtilization")
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{ProcessUtilization: true})
+
+	// the first cycle only opens the window
+	for range 2 {
+		reading, _, err := query(t.Context())
+		require.NoError(t, err)
+		assert.Empty(t, reading.Extras.ProcessUtilization)
+	}
+
+	assert.False(t, backend.extrasWarned["process-utilization"], "a missing export is absence, not a failure")
+}
+
+func TestExtrasAccountingCountsCompletedProcesses(t *testing.T) {
+	t.Parallel()
+
+	stats := map[uint32]nvml.AccountingStats{
+		100: {Time: 30000, MaxMemoryUsage: 1 << 30},
+		101: {IsRunning: 1, MaxMemoryUsage: 1 << 20},
+	}
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetAccountingModeFunc = func() (nvml.EnableState, nvml.Return) { return nvml.FEATURE_ENABLED, nvml.SUCCESS }
+	dev.GetAccountingPidsFunc = func() ([]int, nvml.Return) { return []int{100, 101, 102}, nvml.SUCCESS }
+	dev.GetAccountingStatsFunc = func(pid uint32) (nvml.AccountingStats, nvml.Return) {
+		entry, ok := stats[pid]
+		if !ok {
+			// overwritten between listing and reading
+			return nvml.AccountingStats{}, nvml.ERROR_NOT_FOUND
+		}
+
+		return entry, nvml.SUCCESS
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Accounting: true})
+
+	// the first cycle only records the processes completed before it
+	reading, _, err := query(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []collect.AccountingCounter{
+		{UUID: "11111111-2222-3333-4444-555555555555"},
+	}, reading.Extras.Accounting)
+
+	stats[101] = nvml.AccountingStats{Time: 1500, MaxMemoryUsage: 2 << 20}
+
+	reading, _, err = query(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []collect.AccountingCounter{{
+		UUID:            "11111111-2222-3333-4444-555555555555",
+		Completed:       1,
+		GPUSeconds:      1.5,
+		PeakMemoryBytes: 2 << 20,
+	}}, reading.Extras.Accounting)
+}
+
+func TestExtrasAccountingFailsSoftly(t *testing.T) {
+	t.Parallel()
+
+	mode, ret := nvml.FEATURE_DISABLED, nvml.SUCCESS
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetAccountingModeFunc = func() (nvml.EnableState, nvml.Return) { return mode, nvml.SUCCESS }
+	dev.GetAccountingPidsFunc = func() ([]int, nvml.Return) { return nil, ret }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Accounting: true})
+
+	// accounting mode disabled: the buffer is never read
+	reading, _, err := query(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.Accounting)
+
+	mode = nvml.FEATURE_ENABLED
+
+	for _, ret = range []nvml.Return{nvml.ERROR_NOT_SUPPORTED, nvml.ERROR_UNKNOWN} {
+		reading, code, err := query(t.Context())
+		require.NoError(t, err, "%s must not fail the collection", retString(ret))
+		assert.Equal(t, 0, code)
+		assert.Empty(t, reading.Extras.Accounting)
+	}
+
+	assert.Equal(t, int64(0), fake.shutdowns.Load())
+
+	ret = nvml.ERROR_GPU_IS_LOST
+
+	_, _, err = query(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "a lifecycle error must mark the backend for re-init")
+}
+
+func TestExtrasAccountingOnADriverWithoutTheModeGetter(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackendWithoutExports(t, fake, "nvmlDeviceGetAccountingMode")
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Accounting: true})(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.Accounting)
+	assert.False(t, backend.extrasWarned["accounting"], "a missing export is absence, not a failure")
+}
+
+func TestExtrasVideoSessions(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetEncoderSessionsFunc = func() ([]nvml.EncoderSessionInfo, nvml.Return) {
+		return []nvml.EncoderSessionInfo{
+			{
+				SessionId: 7, Pid: 4242, CodecType: uint32(nvml.ENCODER_QUERY_HEVC),
+				HResolution: 3840, VResolution: 2160, AverageFps: 60, AverageLatency: 4500,
+			},
+			{SessionId: 8, Pid: 4243, CodecType: 42, HResolution: 1280, VResolution: 720},
+		}, nvml.SUCCESS
+	}
+	dev.GetFBCSessionsFunc = func() ([]nvml.FBCSessionInfo, nvml.Return) {
+		return nil, nvml.SUCCESS
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{VideoSessions: true})(t.Context())
+	require.NoError(t, err)
+
+	uuid := "11111111-2222-3333-4444-555555555555"
+	assert.Equal(t, []collect.VideoSessions{
+		{UUID: uuid, Type: collect.VideoSessionEncoder, Sessions: []collect.VideoSession{
+			{
+				ID: "7", PID: "4242", Codec: "hevc", Resolution: "3840x2160",
+				AverageFPS: 60, AverageLatencySeconds: 0.0045,
+			},
+			{ID: "8", PID: "4243", Codec: "unknown", Resolution: "1280x720"},
+		}},
+		{UUID: uuid, Type: collect.VideoSessionFBC, Sessions: []collect.VideoSession{}},
+	}, reading.Extras.VideoSessions)
+}
+
+func TestExtrasVideoSessionsFailSoftly(t *testing.T) {
+	t.Parallel()
+
+	encoderRet := nvml.ERROR_NOT_SUPPORTED
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetEncoderSessionsFunc = func() ([]nvml.EncoderSessionInfo, nvml.Return) { return nil, encoderRet }
+	dev.GetFBCSessionsFunc = func() ([]nvml.FBCSessionInfo, nvml.Return) {
+		return nil, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{VideoSessions: true})
+
+	for _, encoderRet = range []nvml.Return{nvml.ERROR_NOT_SUPPORTED, nvml.ERROR_UNKNOWN} {
+		reading, code, err := query(t.Context())
+		require.NoError(t, err, "%s must not fail the collection", retString(encoderRet))
+		assert.Equal(t, 0, code)
+		assert.Empty(t, reading.Extras.VideoSessions)
+	}
+
+	assert.Equal(t, int64(0), fake.shutdowns.Load())
+
+	encoderRet = nvml.ERROR_GPU_IS_LOST
+
+	_, _, err := query(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "a lifecycle error must mark the backend for re-init")
+}
+
+func TestExtrasVideoSessionsOnADriverWithoutTheGetters(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackendWithoutExports(t, fake, "nvmlDeviceGetEncoderSessions", "nvmlDeviceGetFBCSessions")
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{VideoSessions: true})(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.VideoSessions)
+	assert.False(t, backend.extrasWarned["video-sessions"], "a missing export is absence, not a failure")
+}
+
 func TestRemappedRowsInactiveFields(t *testing.T) {
 	t.Parallel()
 
@@ -698,3 +1084,34 @@ func TestRemappedRowsInactiveAbsentWhenSymbolMissing(t *testing.T) {
 	assert.Equal(t, "[Function Not Found]", cellValue(t, reading.Table, "remapped_rows.correctable_inactive"))
 	assert.Equal(t, "12.34 W", cellValue(t, reading.Table, "power.draw"))
 }
+
+func TestPowerScopesReadTheirOwnScope(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetFieldValuesFunc = func(values []nvml.FieldValue) nvml.Return {
+		for i := range values {
+			values[i].NvmlReturn = uint32(nvml.SUCCESS)
+			values[i].ValueType = uint32(nvml.VALUE_TYPE_UNSIGNED_INT)
+			// milliwatts telling the field and the scope apart
+			binary.LittleEndian.PutUint32(values[i].Value[:], values[i].FieldId*1000+values[i].ScopeId*100)
+		}
+
+		return nvml.SUCCESS
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	fields := resolveFields(t, "power.draw.average,module.power.draw.average,gpu.base.max,"+
+		"kmd_version,vgpu_driver_capability.heterogenous_multivGPU")
+
+	reading, _, err := backend.QueryFunc(fields, CollectOptions{})(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%d.00 W", nvml.FI_DEV_POWER_AVERAGE), cellValue(t, reading.Table, "power.draw.average"))
+	assert.Equal(t, fmt.Sprintf("%d.10 W", nvml.FI_DEV_POWER_AVERAGE),
+		cellValue(t, reading.Table, "module.power.draw.average"))
+	assert.Equal(t, fmt.Sprintf("%d.30 W", nvml.FI_DEV_POWER_MAX_LIMIT), cellValue(t, reading.Table, "gpu.base.max"))
+	assert.Equal(t, "590.48.01", cellValue(t, reading.Table, "kmd_version"))
+	assert.Equal(t, "[N/A]", cellValue(t, reading.Table, "vgpu_driver_capability.heterogenous_multivGPU"))
+}
@@ -8,7 +8,9 @@ import (
 	"log/slog"
 
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/eventlog"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/xidcatalog"
 )
 
 // Available reports whether this build carries the NVML backend.
@@ -41,7 +43,7 @@ func (b *Backend) QueryFunc(_ nvidiasmi.ResolvedFields, _ CollectOptions) collec
 }
 
 // RunXIDWatcher is never reachable: New always fails first.
-func (b *Backend) RunXIDWatcher(_ context.Context) error {
+func (b *Backend) RunXIDWatcher(_ context.Context, _ *xidcatalog.Catalog) error {
 	panic("nvml backend is not available in this build")
 }
 
@@ -49,3 +51,13 @@ func (b *Backend) RunXIDWatcher(_ context.Context) error {
 func (b *Backend) XIDCounts() []collect.XIDCounter {
 	panic("nvml backend is not available in this build")
 }
+
+// DriverEventCounts is never reachable: New always fails first.
+func (b *Backend) DriverEventCounts() []collect.DriverEventCounter {
+	panic("nvml backend is not available in this build")
+}
+
+// RecentEvents is never reachable: New always fails first.
+func (b *Backend) RecentEvents(_ eventlog.Filter) []eventlog.Event {
+	panic("nvml backend is not available in this build")
+}
@@ -0,0 +1,222 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// architectureNames spells the product architectures the way nvidia-smi -q
+// does. Hopper, Ada Lovelace and Turing are capture-verified; the rest follow
+// NVIDIA's product naming.
+//
+//nolint:gochecknoglobals // lookup table
+var architectureNames = map[nvml.DeviceArchitecture]string{
+	nvml.DEVICE_ARCH_KEPLER:    "Kepler",
+	nvml.DEVICE_ARCH_MAXWELL:   "Maxwell",
+	nvml.DEVICE_ARCH_PASCAL:    "Pascal",
+	nvml.DEVICE_ARCH_VOLTA:     "Volta",
+	nvml.DEVICE_ARCH_TURING:    "Turing",
+	nvml.DEVICE_ARCH_AMPERE:    "Ampere",
+	nvml.DEVICE_ARCH_ADA:       "Ada Lovelace",
+	nvml.DEVICE_ARCH_HOPPER:    "Hopper",
+	nvml.DEVICE_ARCH_BLACKWELL: "Blackwell",
+	nvml.DEVICE_ARCH_RUBIN:     "Rubin",
+}
+
+// brandNames spells the product brands the way nvidia-smi -q does. GeForce
+// and NVIDIA are capture-verified; the rest follow the driver's brand names.
+//
+//nolint:gochecknoglobals // lookup table
+var brandNames = map[nvml.BrandType]string{
+	nvml.BRAND_QUADRO:              "Quadro",
+	nvml.BRAND_TESLA:               "Tesla",
+	nvml.BRAND_NVS:                 "NVS",
+	nvml.BRAND_GRID:                "Grid",
+	nvml.BRAND_GEFORCE:             "GeForce",
+	nvml.BRAND_TITAN:               "Titan",
+	nvml.BRAND_NVIDIA_VAPPS:        "NVIDIA Virtual Applications",
+	nvml.BRAND_NVIDIA_VPC:          "NVIDIA Virtual PC",
+	nvml.BRAND_NVIDIA_VCS:          "NVIDIA Virtual Compute Server",
+	nvml.BRAND_NVIDIA_VWS:          "NVIDIA RTX Virtual Workstation",
+	nvml.BRAND_NVIDIA_CLOUD_GAMING: "NVIDIA Cloud Gaming",
+	nvml.BRAND_QUADRO_RTX:          "Quadro RTX",
+	nvml.BRAND_NVIDIA_RTX:          "NVIDIA RTX",
+	nvml.BRAND_NVIDIA:              "NVIDIA",
+	nvml.BRAND_GEFORCE_RTX:         "GeForce RTX",
+	nvml.BRAND_TITAN_RTX:           "Titan RTX",
+}
+
+// capabilityClocks pairs the driver's clock domains with their clock label
+// values. The driver keeps no per-state range for the SM and video clocks.
+//
+//nolint:gochecknoglobals // lookup table
+var capabilityClocks = []struct {
+	clockType nvml.ClockType
+	clock     string
+	perPState bool
+}{
+	{nvml.CLOCK_GRAPHICS, collect.CapabilityClockGraphics, true},
+	{nvml.CLOCK_SM, collect.CapabilityClockSM, false},
+	{nvml.CLOCK_MEM, collect.CapabilityClockMemory, true},
+	{nvml.CLOCK_VIDEO, collect.CapabilityClockVideo, false},
+}
+
+// collectCapabilities appends one device's capability description. None of
+// it changes while the driver stays loaded, so a GPU is read once per NVML
+// generation: a re-initialization, after which the driver may have been
+// replaced, starts the cache over. A description a failed read left partial
+// is served for the cycle but not cached, so the next cycle reads the GPU
+// again. Reports whether extras collection may continue.
+func (b *Backend) collectCapabilities(dev device, uuid string, extras *collect.Extras) bool {
+	if generation := b.generation.Load(); b.capabilities == nil || b.capabilitiesGeneration != generation {
+		b.capabilities = map[string]collect.GPUCapabilities{}
+		b.capabilitiesGeneration = generation
+	}
+
+	capabilities, cached := b.capabilities[uuid]
+	if !cached {
+		var complete, ok bool
+
+		capabilities, complete, ok = b.readCapabilities(dev, uuid)
+		if !ok {
+			return false
+		}
+
+		if complete {
+			b.capabilities[uuid] = capabilities
+		}
+	}
+
+	// the cached slices are never written again, so the snapshots may share
+	// them
+	extras.Capabilities = append(extras.Capabilities, capabilities)
+
+	return true
+}
+
+// readCapabilities reads one device's capability description. A reading the
+// GPU does not support is left out silently. The first flag reports whether
+// every read succeeded or was unsupported, so the description may be cached.
+// The second is false when a lifecycle error stops the extras work; the
+// partial description is then discarded, so the next generation reads the
+// GPU again.
+func (b *Backend) readCapabilities(dev device, uuid string) (collect.GPUCapabilities, bool, bool) {
+	capabilities := collect.GPUCapabilities{UUID: uuid}
+	complete := true
+
+	read := func(ret nvml.Return) bool {
+		if ret != nvml.SUCCESS && ret != nvml.ERROR_NOT_SUPPORTED && ret != nvml.ERROR_FUNCTION_NOT_FOUND {
+			complete = false
+		}
+
+		return b.softRead("capabilities", "cannot read a GPU capability", ret)
+	}
+
+	reads := []func() nvml.Return{
+		func() nvml.Return {
+			arch, ret := dev.GetArchitecture()
+			capabilities.Architecture = architectureNames[arch]
+
+			return ret
+		},
+		func() nvml.Return {
+			brand, ret := dev.GetBrand()
+			capabilities.Brand = brandNames[brand]
+
+			return ret
+		},
+		func() nvml.Return {
+			var ret nvml.Return
+
+			capabilities.BoardPartNumber, ret = dev.GetBoardPartNumber()
+
+			return ret
+		},
+		func() nvml.Return {
+			var ret nvml.Return
+
+			capabilities.PCIeMaxGeneration, ret = dev.GetGpuMaxPcieLinkGeneration()
+
+			return ret
+		},
+		func() nvml.Return {
+			var ret nvml.Return
+
+			capabilities.PCIeMaxWidth, ret = dev.GetMaxPcieLinkWidth()
+
+			return ret
+		},
+		func() nvml.Return {
+			cores, ret := dev.GetNumGpuCores()
+			if ret == nvml.SUCCESS {
+				value := float64(cores)
+				capabilities.Cores = &value
+			}
+
+			return ret
+		},
+		func() nvml.Return {
+			busWidth, ret := dev.GetMemoryBusWidth()
+			if ret == nvml.SUCCESS {
+				value := float64(busWidth)
+				capabilities.MemoryBusWidthBits = &value
+			}
+
+			return ret
+		},
+	}
+
+	for _, domain := range capabilityClocks {
+		reads = append(reads, func() nvml.Return {
+			mhz, ret := dev.GetMaxClockInfo(domain.clockType)
+			if ret == nvml.SUCCESS {
+				capabilities.MaxClocks = append(capabilities.MaxClocks,
+					collect.CapabilityClock{Clock: domain.clock, Hz: float64(mhz) * 1e6})
+			}
+
+			return ret
+		})
+	}
+
+	for _, getter := range reads {
+		if !read(getter()) {
+			return capabilities, false, false
+		}
+	}
+
+	pstates, ret := dev.GetSupportedPerformanceStates()
+	if !read(ret) {
+		return capabilities, false, false
+	}
+
+	for _, pstate := range pstates {
+		for _, domain := range capabilityClocks {
+			if !domain.perPState {
+				continue
+			}
+
+			minMHz, maxMHz, ret := dev.GetMinMaxClockOfPState(domain.clockType, pstate)
+			if ret != nvml.SUCCESS {
+				if !read(ret) {
+					return capabilities, false, false
+				}
+
+				continue
+			}
+
+			capabilities.SupportedClocks = append(capabilities.SupportedClocks, collect.SupportedClockRange{
+				PState: fmt.Sprintf("P%d", pstate),
+				Clock:  domain.clock,
+				MinHz:  float64(minMHz) * 1e6,
+				MaxHz:  float64(maxMHz) * 1e6,
+			})
+		}
+	}
+
+	return capabilities, complete, true
+}
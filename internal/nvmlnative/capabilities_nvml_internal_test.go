@@ -0,0 +1,177 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// capabilitiesDevice describes an H100 whose driver keeps no memory bus
+// width, with two performance states.
+func capabilitiesDevice() *mock.Device {
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetArchitectureFunc = func() (nvml.DeviceArchitecture, nvml.Return) {
+		return nvml.DEVICE_ARCH_HOPPER, nvml.SUCCESS
+	}
+	dev.GetBrandFunc = func() (nvml.BrandType, nvml.Return) { return nvml.BRAND_NVIDIA, nvml.SUCCESS }
+	dev.GetBoardPartNumberFunc = func() (string, nvml.Return) { return "692-2G520-0200-000", nvml.SUCCESS }
+	dev.GetGpuMaxPcieLinkGenerationFunc = func() (int, nvml.Return) { return 5, nvml.SUCCESS }
+	dev.GetMaxPcieLinkWidthFunc = func() (int, nvml.Return) { return 16, nvml.SUCCESS }
+	dev.GetNumGpuCoresFunc = func() (int, nvml.Return) { return 16896, nvml.SUCCESS }
+	dev.GetMemoryBusWidthFunc = func() (uint32, nvml.Return) { return 0, nvml.ERROR_NOT_SUPPORTED }
+	dev.GetMaxClockInfoFunc = func(clockType nvml.ClockType) (uint32, nvml.Return) {
+		switch clockType {
+		case nvml.CLOCK_GRAPHICS, nvml.CLOCK_SM:
+			return 1980, nvml.SUCCESS
+		case nvml.CLOCK_MEM:
+			return 2619, nvml.SUCCESS
+		default:
+			return 0, nvml.ERROR_NOT_SUPPORTED
+		}
+	}
+	dev.GetSupportedPerformanceStatesFunc = func() ([]nvml.Pstates, nvml.Return) {
+		return []nvml.Pstates{nvml.PSTATE_0, nvml.PSTATE_8}, nvml.SUCCESS
+	}
+	dev.GetMinMaxClockOfPStateFunc = func(clockType nvml.ClockType, pstate nvml.Pstates) (uint32, uint32, nvml.Return) {
+		if pstate == nvml.PSTATE_8 {
+			return 345, 345, nvml.SUCCESS
+		}
+
+		if clockType == nvml.CLOCK_MEM {
+			return 2619, 2619, nvml.SUCCESS
+		}
+
+		return 345, 1980, nvml.SUCCESS
+	}
+
+	return dev
+}
+
+func TestExtrasCapabilities(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeAPI{devices: []nvml.Device{capabilitiesDevice()}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Capabilities: true})(t.Context())
+	require.NoError(t, err)
+	require.Len(t, reading.Extras.Capabilities, 1)
+
+	capabilities := reading.Extras.Capabilities[0]
+	assert.Equal(t, "11111111-2222-3333-4444-555555555555", capabilities.UUID)
+	assert.Equal(t, "Hopper", capabilities.Architecture)
+	assert.Equal(t, "NVIDIA", capabilities.Brand)
+	assert.Equal(t, "692-2G520-0200-000", capabilities.BoardPartNumber)
+	assert.Equal(t, 5, capabilities.PCIeMaxGeneration)
+	assert.Equal(t, 16, capabilities.PCIeMaxWidth)
+	require.NotNil(t, capabilities.Cores)
+	assert.InDelta(t, 16896, *capabilities.Cores, 0)
+	assert.Nil(t, capabilities.MemoryBusWidthBits, "an unsupported reading stays unset")
+	assert.Equal(t, []collect.CapabilityClock{
+		{Clock: collect.CapabilityClockGraphics, Hz: 1980e6},
+		{Clock: collect.CapabilityClockSM, Hz: 1980e6},
+		{Clock: collect.CapabilityClockMemory, Hz: 2619e6},
+	}, capabilities.MaxClocks)
+	assert.Equal(t, []collect.SupportedClockRange{
+		{PState: "P0", Clock: collect.CapabilityClockGraphics, MinHz: 345e6, MaxHz: 1980e6},
+		{PState: "P0", Clock: collect.CapabilityClockMemory, MinHz: 2619e6, MaxHz: 2619e6},
+		{PState: "P8", Clock: collect.CapabilityClockGraphics, MinHz: 345e6, MaxHz: 345e6},
+		{PState: "P8", Clock: collect.CapabilityClockMemory, MinHz: 345e6, MaxHz: 345e6},
+	}, capabilities.SupportedClocks)
+}
+
+func TestExtrasCapabilitiesReadOncePerGeneration(t *testing.T) {
+	t.Parallel()
+
+	dev := capabilitiesDevice()
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Capabilities: true})
+
+	for range 3 {
+		reading, _, err := query(t.Context())
+		require.NoError(t, err)
+		require.Len(t, reading.Extras.Capabilities, 1, "a cached description is still reported")
+	}
+
+	assert.Len(t, dev.GetArchitectureCalls(), 1)
+
+	// a re-initialization may come with a replaced driver
+	backend.generation.Add(1)
+
+	_, _, err := query(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, dev.GetArchitectureCalls(), 2)
+}
+
+func TestExtrasCapabilitiesLifecycleDiscardsThePartialRead(t *testing.T) {
+	t.Parallel()
+
+	coresRet := nvml.ERROR_GPU_IS_LOST
+
+	dev := capabilitiesDevice()
+	dev.GetNumGpuCoresFunc = func() (int, nvml.Return) { return 16896, coresRet }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Capabilities: true})
+
+	reading, _, err := query(t.Context())
+	require.NoError(t, err, "a failed capability read must not fail the collection")
+	assert.Empty(t, reading.Extras.Capabilities)
+	assert.Empty(t, dev.GetMemoryBusWidthCalls(), "the reads stop at the lifecycle error")
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "a lifecycle error must mark the backend for re-init")
+
+	coresRet = nvml.SUCCESS
+
+	reading, _, err = query(t.Context())
+	require.NoError(t, err)
+	require.Len(t, reading.Extras.Capabilities, 1)
+	require.NotNil(t, reading.Extras.Capabilities[0].Cores)
+}
+
+func TestExtrasCapabilitiesPartialReadIsNotCached(t *testing.T) {
+	t.Parallel()
+
+	partRet := nvml.ERROR_TIMEOUT
+
+	dev := capabilitiesDevice()
+	dev.GetBoardPartNumberFunc = func() (string, nvml.Return) {
+		if partRet != nvml.SUCCESS {
+			return "", partRet
+		}
+
+		return "692-2G520-0200-000", nvml.SUCCESS
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Capabilities: true})
+
+	reading, _, err := query(t.Context())
+	require.NoError(t, err)
+	require.Len(t, reading.Extras.Capabilities, 1, "the partial description is served for the cycle")
+	assert.Empty(t, reading.Extras.Capabilities[0].BoardPartNumber)
+	assert.Equal(t, "Hopper", reading.Extras.Capabilities[0].Architecture)
+	assert.Equal(t, int64(0), fake.shutdowns.Load(), "a transient failure is no lifecycle error")
+
+	partRet = nvml.SUCCESS
+
+	for range 2 {
+		reading, _, err = query(t.Context())
+		require.NoError(t, err)
+		require.Len(t, reading.Extras.Capabilities, 1)
+		assert.Equal(t, "692-2G520-0200-000", reading.Extras.Capabilities[0].BoardPartNumber)
+	}
+
+	assert.Len(t, dev.GetBoardPartNumberCalls(), 2, "the GPU is read again until a read completes, then cached")
+}
@@ -6,11 +6,9 @@
 // nvidia-smi byte-for-byte; they were verified against a live H100 (driver
 // 590.48.01) via the diff harness in _work/nvml-experiment.
 //
-// Fields nvidia-smi knows but this catalog does not (vGPU capabilities,
-// Blackwell power smoothing, GB200 module power, kmd_version...) are simply
-// absent from the backend's vocabulary, the same way an older nvidia-smi
-// does not know newer fields. On hardware where they exist they read [N/A]
-// in exec mode anyway, so the exported metric surface stays the same.
+// Fields nvidia-smi knows but this catalog does not (see deferredFields) are
+// simply absent from the backend's vocabulary, the same way an older
+// nvidia-smi does not know newer fields.
 package nvmlnative
 
 import (
@@ -30,6 +28,8 @@ import (
 var fieldOrder = []nvidiasmi.QField{
 	"timestamp",
 	"driver_version",
+	"kmd_version",
+	"vgpu_driver_capability.heterogenous_multivGPU",
 	"count",
 	"name",
 	"serial",
@@ -42,6 +42,12 @@ var fieldOrder = []nvidiasmi.QField{
 	"pci.subClass",
 	"pci.device_id",
 	"pci.sub_device_id",
+	"vgpu_device_capability.fractional_multiVgpu",
+	"vgpu_device_capability.heterogeneous_timeSlice_profile",
+	"vgpu_device_capability.heterogeneous_timeSlice_sizes",
+	"vgpu_device_capability.homogeneous_placements",
+	"vgpu_device_capability.mig_timeSlicing",
+	"vgpu_device_capability.mig_timeSlicing_mode",
 	"pcie.link.gen.current",
 	"pcie.link.gen.gpucurrent",
 	"pcie.link.gen.max",
@@ -175,6 +181,51 @@ var fieldOrder = []nvidiasmi.QField{
 	"power.default_limit",
 	"power.min_limit",
 	"power.max_limit",
+	"module.power.draw.average",
+	"module.power.draw.instant",
+	"module.power.limit",
+	"module.enforced.power.limit",
+	"module.power.default_limit",
+	"module.power.min_limit",
+	"module.power.max_limit",
+	"gpu.base.current",
+	"gpu.base.requested",
+	"gpu.base.default",
+	"gpu.base.min",
+	"gpu.base.max",
+	"power_smoothing.tmp_floor",
+	"power_smoothing.tmp_ceil",
+	"power_smoothing.hw_lifetime_remaining_percent",
+	"power_smoothing.enabled",
+	"power_smoothing.delayed_power_smoothing_supported",
+	"power_smoothing.soc_power_smoothing_enabled",
+	"power_smoothing.priv_level",
+	"power_smoothing.imm_ramp_down",
+	"power_smoothing.max_percent_tmp_floor",
+	"power_smoothing.min_percent_tmp_floor",
+	"power_smoothing.primary_power_floor",
+	"power_smoothing.secondary_power_floor",
+	"power_smoothing.min_primary_floor_activation_offset",
+	"power_smoothing.min_primary_floor_activation_point",
+	"power_smoothing.window_multiplier",
+	"power_smoothing.num_preset_profiles",
+	"power_smoothing.curr_profile.percent_tmp_floor",
+	"power_smoothing.curr_profile.ramp_up_rate",
+	"power_smoothing.curr_profile.ramp_down_rate",
+	"power_smoothing.curr_profile.ramp_down_hysteresis",
+	"power_smoothing.curr_profile.secondary_power_floor",
+	"power_smoothing.curr_profile.primary_floor_act_window_multiplier",
+	"power_smoothing.curr_profile.primary_floor_tar_window_multiplier",
+	"power_smoothing.curr_profile.primary_floor_act_offset",
+	"power_smoothing.active_profile",
+	"power_smoothing.admin_override.percent_tmp_floor",
+	"power_smoothing.admin_override.ramp_up_rate",
+	"power_smoothing.admin_override.ramp_down_rate",
+	"power_smoothing.admin_override.ramp_down_hysteresis",
+	"power_smoothing.admin_override.secondary_power_floor",
+	"power_smoothing.admin_override.primary_floor_act_window_multiplier",
+	"power_smoothing.admin_override.primary_floor_tar_window_multiplier",
+	"power_smoothing.admin_override.primary_floor_act_offset",
 	"edpp_multiplier",
 	"clocks.current.graphics",
 	"clocks.current.sm",
@@ -199,6 +250,13 @@ var fieldOrder = []nvidiasmi.QField{
 	"fabric.status",
 	"fabric.cliqueId",
 	"fabric.clusterUuid",
+	"fabric.health.summary",
+	"fabric.health.bandwidth",
+	"fabric.health.route_recovery_in_progress",
+	"fabric.health.route_unhealthy",
+	"fabric.health.access_timeout_recovery",
+	"fabric.health.incorrect_configuration",
+	"fabric.health.partition_assigned",
 	"platform.chassis_serial_number",
 	"platform.slot_number",
 	"platform.tray_index",
@@ -207,6 +265,7 @@ var fieldOrder = []nvidiasmi.QField{
 	"platform.module_id",
 	"platform.gpu_fabric_guid",
 	"hostname",
+	"bbx.time_run",
 }
 
 // catalogRFields maps every catalogued query field to the returned-header
@@ -394,6 +453,75 @@ var catalogRFields = map[nvidiasmi.QField]nvidiasmi.RField{
 	"hostname":                                                "hostname",
 	"pci.baseClass":                                           "pci.baseClass",
 	"pci.subClass":                                            "pci.subClass",
+
+	// driver and vGPU capabilities
+	"kmd_version": "kmd_version",
+	"vgpu_driver_capability.heterogenous_multivGPU":          "vgpu_driver_capability.heterogenous_multivGPU",
+	"vgpu_device_capability.fractional_multiVgpu":            "vgpu_device_capability.fractional_multiVgpu",
+	"vgpu_device_capability.heterogeneous_timeSlice_profile": "vgpu_device_capability.heterogeneous_timeSlice_profile",
+	"vgpu_device_capability.heterogeneous_timeSlice_sizes":   "vgpu_device_capability.heterogeneous_timeSlice_sizes",
+	"vgpu_device_capability.homogeneous_placements":          "vgpu_device_capability.homogeneous_placements",
+	"vgpu_device_capability.mig_timeSlicing":                 "vgpu_device_capability.mig_timeSlicing",
+	"vgpu_device_capability.mig_timeSlicing_mode":            "vgpu_device_capability.mig_timeSlicing_mode",
+
+	// module and base GPU power scopes
+	"module.power.draw.average":   "module.power.draw.average [W]",
+	"module.power.draw.instant":   "module.power.draw.instant [W]",
+	"module.power.limit":          "module.power.limit [W]",
+	"module.enforced.power.limit": "module.enforced.power.limit [W]",
+	"module.power.default_limit":  "module.power.default_limit [W]",
+	"module.power.min_limit":      "module.power.min_limit [W]",
+	"module.power.max_limit":      "module.power.max_limit [W]",
+	"gpu.base.current":            "gpu.base.current [W]",
+	"gpu.base.requested":          "gpu.base.requested [W]",
+	"gpu.base.default":            "gpu.base.default [W]",
+	"gpu.base.min":                "gpu.base.min [W]",
+	"gpu.base.max":                "gpu.base.max [W]",
+
+	// power smoothing
+	"power_smoothing.tmp_floor":                                          "power_smoothing.tmp_floor [W]",
+	"power_smoothing.tmp_ceil":                                           "power_smoothing.tmp_ceil [W]",
+	"power_smoothing.hw_lifetime_remaining_percent":                      "power_smoothing.hw_lifetime_remaining_percent [%]",
+	"power_smoothing.enabled":                                            "power_smoothing.enabled",
+	"power_smoothing.delayed_power_smoothing_supported":                  "power_smoothing.delayed_power_smoothing_supported",
+	"power_smoothing.soc_power_smoothing_enabled":                        "power_smoothing.soc_power_smoothing_enabled",
+	"power_smoothing.priv_level":                                         "power_smoothing.priv_level",
+	"power_smoothing.imm_ramp_down":                                      "power_smoothing.imm_ramp_down",
+	"power_smoothing.max_percent_tmp_floor":                              "power_smoothing.max_percent_tmp_floor [%]",
+	"power_smoothing.min_percent_tmp_floor":                              "power_smoothing.min_percent_tmp_floor [%]",
+	"power_smoothing.primary_power_floor":                                "power_smoothing.primary_power_floor [W]",
+	"power_smoothing.secondary_power_floor":                              "power_smoothing.secondary_power_floor [W]",
+	"power_smoothing.min_primary_floor_activation_offset":                "power_smoothing.min_primary_floor_activation_offset [W]",
+	"power_smoothing.min_primary_floor_activation_point":                 "power_smoothing.min_primary_floor_activation_point [W]",
+	"power_smoothing.window_multiplier":                                  "power_smoothing.window_multiplier [ms]",
+	"power_smoothing.num_preset_profiles":                                "power_smoothing.num_preset_profiles",
+	"power_smoothing.curr_profile.percent_tmp_floor":                     "power_smoothing.curr_profile.percent_tmp_floor [%]",
+	"power_smoothing.curr_profile.ramp_up_rate":                          "power_smoothing.curr_profile.ramp_up_rate [W/s]",
+	"power_smoothing.curr_profile.ramp_down_rate":                        "power_smoothing.curr_profile.ramp_down_rate [W/s]",
+	"power_smoothing.curr_profile.ramp_down_hysteresis":                  "power_smoothing.curr_profile.ramp_down_hysteresis [ms]",
+	"power_smoothing.curr_profile.secondary_power_floor":                 "power_smoothing.curr_profile.secondary_power_floor [W]",
+	"power_smoothing.curr_profile.primary_floor_act_window_multiplier":   "power_smoothing.curr_profile.primary_floor_act_window_multiplier",
+	"power_smoothing.curr_profile.primary_floor_tar_window_multiplier":   "power_smoothing.curr_profile.primary_floor_tar_window_multiplier",
+	"power_smoothing.curr_profile.primary_floor_act_offset":              "power_smoothing.curr_profile.primary_floor_act_offset [W]",
+	"power_smoothing.active_profile":                                     "power_smoothing.active_profile",
+	"power_smoothing.admin_override.percent_tmp_floor":                   "power_smoothing.admin_override.percent_tmp_floor [%]",
+	"power_smoothing.admin_override.ramp_up_rate":                        "power_smoothing.admin_override.ramp_up_rate [W/s]",
+	"power_smoothing.admin_override.ramp_down_rate":                      "power_smoothing.admin_override.ramp_down_rate [W/s]",
+	"power_smoothing.admin_override.ramp_down_hysteresis":                "power_smoothing.admin_override.ramp_down_hysteresis [ms]",
+	"power_smoothing.admin_override.secondary_power_floor":               "power_smoothing.admin_override.secondary_power_floor [W]",
+	"power_smoothing.admin_override.primary_floor_act_window_multiplier": "power_smoothing.admin_override.primary_floor_act_window_multiplier",
+	"power_smoothing.admin_override.primary_floor_tar_window_multiplier": "power_smoothing.admin_override.primary_floor_tar_window_multiplier",
+	"power_smoothing.admin_override.primary_floor_act_offset":            "power_smoothing.admin_override.primary_floor_act_offset [W]",
+
+	// fabric health and black box
+	"fabric.health.summary":                    "fabric.health.summary",
+	"fabric.health.bandwidth":                  "fabric.health.bandwidth",
+	"fabric.health.route_recovery_in_progress": "fabric.health.route_recovery_in_progress",
+	"fabric.health.route_unhealthy":            "fabric.health.route_unhealthy",
+	"fabric.health.access_timeout_recovery":    "fabric.health.access_timeout_recovery",
+	"fabric.health.incorrect_configuration":    "fabric.health.incorrect_configuration",
+	"fabric.health.partition_assigned":         "fabric.health.partition_assigned",
+	"bbx.time_run":                             "bbx.time_run [seconds]",
 }
 
 // throttleAliases maps the legacy clocks_throttle_reasons.* spellings to the
@@ -511,23 +639,20 @@ const deprecatedFieldsMinDriverMajor = 590
 
 // deferredFields are query fields nvidia-smi advertises that this backend
 // consciously does not serve yet: no verified NVML mapping exists, or the
-// family needs hardware nobody has captured. On the hardware captured so far
-// they all read as absent, so both backends export nothing for them. The
-// corpus drift test fails when a NEW unknown field shows a real value in the
-// newest capture and it is not listed here; adding a field here is the
-// explicit "defer" decision, adding it to the catalog is the fix.
+// family needs hardware nobody has captured. The corpus drift test fails when
+// a NEW unknown field shows a real value in the newest capture and it is not
+// listed here; adding a field here is the explicit "defer" decision, adding
+// it to the catalog is the fix.
+//
+// pcie.link.gen.hostmax is the host root port's maximum link generation. It
+// reads a real value on workstation captures, but no public NVML entry point
+// reports it: the device's max link generation is a different reading, and
+// the root port's own sysfs capability is not nvidia-smi's source either (the
+// server captures print [N/A] for ports that plainly have one).
 var deferredFields = map[nvidiasmi.QField]bool{
-	"kmd_version":           true,
 	"pcie.link.gen.hostmax": true,
 }
 
-// deferredFieldPrefixes covers whole deferred families (vGPU capabilities,
-// Blackwell power smoothing, GB200 module power, fabric health...).
-var deferredFieldPrefixes = []string{
-	"vgpu_", "module.power.", "module.enforced.", "gpu.base.", "power_smoothing.",
-	"bbx.", "fabric.health.",
-}
-
 // IsDeferredField reports whether an unknown field is a recorded, conscious
 // deferral rather than new drift. The GPU parity test uses it to apply the
 // same policy as the corpus drift test.
@@ -538,15 +663,5 @@ func IsDeferredField(qField nvidiasmi.QField) bool {
 // isDeferredField reports whether an unknown field is a recorded, conscious
 // deferral rather than new drift.
 func isDeferredField(qField nvidiasmi.QField) bool {
-	if deferredFields[qField] {
-		return true
-	}
-
-	for _, prefix := range deferredFieldPrefixes {
-		if strings.HasPrefix(string(qField), prefix) {
-			return true
-		}
-	}
-
-	return false
+	return deferredFields[qField]
 }
@@ -0,0 +1,155 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// confComputeFamily names the confidential computing family in the extras
+// warnings.
+const confComputeFamily = "confidential-computing"
+
+// ccCPUCapabilities, ccGPUCapabilities, ccEnvironments and ccMultiGPUModes
+// map the driver's confidential computing enums onto their label values. A
+// value missing from them (a newer driver's) leaves its label empty.
+//
+//nolint:gochecknoglobals // lookup tables
+var (
+	ccCPUCapabilities = map[uint32]string{
+		nvml.CC_SYSTEM_CPU_CAPS_NONE:         "none",
+		nvml.CC_SYSTEM_CPU_CAPS_AMD_SEV:      "amd_sev",
+		nvml.CC_SYSTEM_CPU_CAPS_INTEL_TDX:    "intel_tdx",
+		nvml.CC_SYSTEM_CPU_CAPS_AMD_SEV_SNP:  "amd_sev_snp",
+		nvml.CC_SYSTEM_CPU_CAPS_AMD_SNP_VTOM: "amd_snp_vtom",
+	}
+	ccGPUCapabilities = map[uint32]string{
+		nvml.CC_SYSTEM_GPUS_CC_NOT_CAPABLE: "not_capable",
+		nvml.CC_SYSTEM_GPUS_CC_CAPABLE:     "capable",
+	}
+	ccEnvironments = map[uint32]string{
+		nvml.CC_SYSTEM_ENVIRONMENT_UNAVAILABLE: "unavailable",
+		nvml.CC_SYSTEM_ENVIRONMENT_SIM:         "sim",
+		nvml.CC_SYSTEM_ENVIRONMENT_PROD:        "prod",
+	}
+	ccMultiGPUModes = map[uint32]string{
+		nvml.CC_SYSTEM_MULTIGPU_NONE:           "none",
+		nvml.CC_SYSTEM_MULTIGPU_PROTECTED_PCIE: "protected_pcie",
+		nvml.CC_SYSTEM_MULTIGPU_NVLE:           "nvle",
+	}
+)
+
+// collectConfComputeSystem reads the system-wide confidential computing
+// state into extras, once per cycle and before the devices, whose entries
+// repeat the mode. The getters are package-level, so each is probed here
+// rather than by the device guard; one an older driver does not export
+// leaves its readings empty. Reports whether extras collection may
+// continue.
+func (b *Backend) collectConfComputeSystem(extras *collect.Extras) bool {
+	avail := b.avail.Load()
+	state := &extras.ConfidentialComputing
+
+	if avail.has("nvmlSystemGetConfComputeCapabilities") {
+		caps, ret := b.api.confComputeCapabilities()
+		if !b.softRead(confComputeFamily, "cannot read the confidential computing capabilities", ret) {
+			return false
+		}
+
+		if ret == nvml.SUCCESS {
+			state.CPUCapability = ccCPUCapabilities[caps.CpuCaps]
+			state.GPUCapability = ccGPUCapabilities[caps.GpusCaps]
+		}
+	}
+
+	if avail.has("nvmlSystemGetConfComputeState") {
+		systemState, ret := b.api.confComputeState()
+		if !b.softRead(confComputeFamily, "cannot read the confidential computing state", ret) {
+			return false
+		}
+
+		if ret == nvml.SUCCESS {
+			state.Mode = ccMode(systemState.CcFeature, systemState.DevToolsMode)
+			state.Environment = ccEnvironments[systemState.Environment]
+		}
+	}
+
+	if avail.has("nvmlSystemGetConfComputeSettings") {
+		settings, ret := b.api.confComputeSettings()
+		if !b.softRead(confComputeFamily, "cannot read the confidential computing settings", ret) {
+			return false
+		}
+
+		if ret == nvml.SUCCESS {
+			state.MultiGPUMode = ccMultiGPUModes[settings.MultiGpuMode]
+		}
+	}
+
+	if avail.has("nvmlSystemGetConfComputeGpusReadyState") {
+		accepting, ret := b.api.confComputeReadyState()
+		if !b.softRead(confComputeFamily, "cannot read the confidential computing ready state", ret) {
+			return false
+		}
+
+		if ret == nvml.SUCCESS {
+			ready := 0.0
+			if accepting == nvml.CC_ACCEPTING_CLIENT_REQUESTS_TRUE {
+				ready = 1
+			}
+
+			state.Ready = &ready
+		}
+	}
+
+	if avail.has("nvmlSystemGetConfComputeKeyRotationThresholdInfo") {
+		threshold, ret := b.api.confComputeKeyRotation()
+		if !b.softRead(confComputeFamily, "cannot read the confidential computing key rotation threshold", ret) {
+			return false
+		}
+
+		if ret == nvml.SUCCESS {
+			advantage := float64(threshold.AttackerAdvantage)
+			state.KeyRotationAttackerAdvantage = &advantage
+		}
+	}
+
+	return true
+}
+
+// collectConfComputeGPU appends one device's confidential computing entry:
+// the system's mode and the device's protected memory split. A device with
+// neither contributes no entry. Reports whether extras collection may
+// continue.
+func (b *Backend) collectConfComputeGPU(dev device, uuid string, extras *collect.Extras) bool {
+	entry := collect.ConfidentialComputingGPU{UUID: uuid, Mode: extras.ConfidentialComputing.Mode}
+
+	sizes, ret := dev.GetConfComputeMemSizeInfo()
+	if !b.softRead(confComputeFamily, "cannot read the protected memory size", ret) {
+		return false
+	}
+
+	if ret == nvml.SUCCESS {
+		protected := float64(sizes.ProtectedMemSizeKib) * 1024
+		unprotected := float64(sizes.UnprotectedMemSizeKib) * 1024
+		entry.ProtectedMemoryBytes, entry.UnprotectedMemoryBytes = &protected, &unprotected
+	}
+
+	if entry.Mode != "" || entry.ProtectedMemoryBytes != nil {
+		extras.ConfidentialComputing.GPUs = append(extras.ConfidentialComputing.GPUs, entry)
+	}
+
+	return true
+}
+
+// ccMode folds the feature and developer tools switches into one mode.
+func ccMode(feature, devTools uint32) string {
+	switch {
+	case feature != nvml.CC_SYSTEM_FEATURE_ENABLED:
+		return collect.CCModeOff
+	case devTools == nvml.CC_SYSTEM_DEVTOOLS_MODE_ON:
+		return collect.CCModeDevtools
+	default:
+		return collect.CCModeOn
+	}
+}
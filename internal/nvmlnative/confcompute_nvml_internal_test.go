@@ -0,0 +1,143 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/neilotoole/slogt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// installConfCompute serves a confidential computing system in production
+// mode, ready for clients.
+func installConfCompute(api *nvmlAPI) {
+	api.confComputeCapabilities = func() (nvml.ConfComputeSystemCaps, nvml.Return) {
+		return nvml.ConfComputeSystemCaps{
+			CpuCaps:  nvml.CC_SYSTEM_CPU_CAPS_AMD_SEV_SNP,
+			GpusCaps: nvml.CC_SYSTEM_GPUS_CC_CAPABLE,
+		}, nvml.SUCCESS
+	}
+	api.confComputeState = func() (nvml.ConfComputeSystemState, nvml.Return) {
+		return nvml.ConfComputeSystemState{
+			Environment:  nvml.CC_SYSTEM_ENVIRONMENT_PROD,
+			CcFeature:    nvml.CC_SYSTEM_FEATURE_ENABLED,
+			DevToolsMode: nvml.CC_SYSTEM_DEVTOOLS_MODE_OFF,
+		}, nvml.SUCCESS
+	}
+	api.confComputeSettings = func() (nvml.SystemConfComputeSettings, nvml.Return) {
+		return nvml.SystemConfComputeSettings{}, nvml.ERROR_NOT_SUPPORTED
+	}
+	api.confComputeReadyState = func() (uint32, nvml.Return) {
+		return nvml.CC_ACCEPTING_CLIENT_REQUESTS_TRUE, nvml.SUCCESS
+	}
+	api.confComputeKeyRotation = func() (nvml.ConfComputeGetKeyRotationThresholdInfo, nvml.Return) {
+		return nvml.ConfComputeGetKeyRotationThresholdInfo{AttackerAdvantage: 60}, nvml.SUCCESS
+	}
+}
+
+func TestExtrasConfidentialComputing(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetConfComputeMemSizeInfoFunc = func() (nvml.ConfComputeMemSizeInfo, nvml.Return) {
+		return nvml.ConfComputeMemSizeInfo{ProtectedMemSizeKib: 80 << 20, UnprotectedMemSizeKib: 256 << 10}, nvml.SUCCESS
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	api := fake.api()
+	installConfCompute(&api)
+
+	backend, err := newWithAPI(api, slogt.New(t))
+	require.NoError(t, err)
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"),
+		CollectOptions{ConfidentialComputing: true})(t.Context())
+	require.NoError(t, err)
+
+	value := func(v float64) *float64 { return &v }
+
+	assert.Equal(t, collect.ConfidentialComputing{
+		CPUCapability:                "amd_sev_snp",
+		GPUCapability:                "capable",
+		Mode:                         collect.CCModeOn,
+		Environment:                  "prod",
+		Ready:                        value(1),
+		KeyRotationAttackerAdvantage: value(60),
+		GPUs: []collect.ConfidentialComputingGPU{{
+			UUID:                   "11111111-2222-3333-4444-555555555555",
+			Mode:                   collect.CCModeOn,
+			ProtectedMemoryBytes:   value(80 << 30),
+			UnprotectedMemoryBytes: value(256 << 20),
+		}},
+	}, reading.Extras.ConfidentialComputing, "the unsupported settings leave the multi-GPU mode empty")
+}
+
+func TestExtrasConfidentialComputingOlderDriver(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	api := fake.api()
+	installConfCompute(&api)
+
+	// a driver predating the settings and key rotation getters: calling
+	// either would crash the process, so their probes must keep them out,
+	// and the device getter the mock does not stub must not be reached
+	api.confComputeSettings, api.confComputeKeyRotation = nil, nil
+	api.lookupSymbol = func(name string) error {
+		if strings.HasSuffix(name, "ConfComputeSettings") || strings.HasSuffix(name, "KeyRotationThresholdInfo") ||
+			name == "nvmlDeviceGetConfComputeMemSizeInfo" {
+			return errors.New("undefined symbol")
+		}
+
+		return nil
+	}
+
+	backend, err := newWithAPI(api, slogt.New(t))
+	require.NoError(t, err)
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"),
+		CollectOptions{ConfidentialComputing: true})(t.Context())
+	require.NoError(t, err)
+
+	state := reading.Extras.ConfidentialComputing
+	assert.Equal(t, collect.CCModeOn, state.Mode)
+	assert.Empty(t, state.MultiGPUMode)
+	assert.Nil(t, state.KeyRotationAttackerAdvantage)
+	assert.Equal(t, []collect.ConfidentialComputingGPU{
+		{UUID: "11111111-2222-3333-4444-555555555555", Mode: collect.CCModeOn},
+	}, state.GPUs)
+}
+
+func TestExtrasConfidentialComputingLifecycleAborts(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	api := fake.api()
+	installConfCompute(&api)
+	api.confComputeState = func() (nvml.ConfComputeSystemState, nvml.Return) {
+		return nvml.ConfComputeSystemState{}, nvml.ERROR_DRIVER_NOT_LOADED
+	}
+
+	backend, err := newWithAPI(api, slogt.New(t))
+	require.NoError(t, err)
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"),
+		CollectOptions{ConfidentialComputing: true})(t.Context())
+	require.NoError(t, err, "extras must never fail the collection")
+	assert.Empty(t, reading.Extras.ConfidentialComputing.GPUs)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "the lifecycle error must mark the backend for re-init")
+}
@@ -382,7 +382,7 @@ func TestDriftRulesSelfCheck(t *testing.T) {
 	assert.True(t, isHardcodedDeprecated("display_mode"))
 	assert.False(t, isHardcodedDeprecated("power.draw"))
 
-	assert.True(t, isDeferredField("power_smoothing.enabled"))
-	assert.True(t, isDeferredField("kmd_version"))
+	assert.True(t, isDeferredField("pcie.link.gen.hostmax"))
+	assert.False(t, isDeferredField("power_smoothing.enabled"))
 	assert.False(t, isDeferredField("power.draw"))
 }
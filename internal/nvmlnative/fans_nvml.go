@@ -0,0 +1,73 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"strconv"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// fanPolicies maps the driver's fan control policies onto their label values.
+//
+//nolint:gochecknoglobals // lookup table
+var fanPolicies = map[nvml.FanControlPolicy]string{
+	nvml.FAN_POLICY_TEMPERATURE_CONTINOUS_SW: collect.FanPolicyAuto,
+	nvml.FAN_POLICY_MANUAL:                   collect.FanPolicyManual,
+}
+
+// collectFans appends one device's per-fan readings. Datacenter GPUs cooled
+// by the enclosure report no fans and contribute nothing; a reading a fan
+// cannot report is left out of its entry. Reports whether extras collection
+// may continue.
+func (b *Backend) collectFans(dev device, uuid string, extras *collect.Extras) bool {
+	count, ret := dev.GetNumFans()
+
+	//nolint:exhaustive // every other return is a plain failure
+	switch ret {
+	case nvml.SUCCESS:
+	case nvml.ERROR_NOT_SUPPORTED, nvml.ERROR_FUNCTION_NOT_FOUND:
+		return true
+	default:
+		return b.extrasFailure("fans", "cannot read the fan count", ret)
+	}
+
+	for fan := range count {
+		entry := collect.Fan{UUID: uuid, Fan: strconv.Itoa(fan)}
+
+		speed, ret := dev.GetFanSpeed_v2(fan)
+		if !b.softRead("fans", "cannot read a fan speed", ret) {
+			return false
+		}
+
+		if ret == nvml.SUCCESS {
+			ratio := float64(speed) / 100
+			entry.SpeedRatio = &ratio
+		}
+
+		target, ret := dev.GetTargetFanSpeed(fan)
+		if !b.softRead("fans", "cannot read a fan target speed", ret) {
+			return false
+		}
+
+		if ret == nvml.SUCCESS {
+			ratio := float64(target) / 100
+			entry.TargetSpeedRatio = &ratio
+		}
+
+		policy, ret := dev.GetFanControlPolicy_v2(fan)
+		if !b.softRead("fans", "cannot read a fan control policy", ret) {
+			return false
+		}
+
+		if ret == nvml.SUCCESS {
+			entry.Policy = fanPolicies[policy]
+		}
+
+		extras.Fans = append(extras.Fans, entry)
+	}
+
+	return true
+}
@@ -0,0 +1,137 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/neilotoole/slogt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+func TestExtrasFans(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetNumFansFunc = func() (int, nvml.Return) { return 2, nvml.SUCCESS }
+	dev.GetFanSpeed_v2Func = func(fan int) (uint32, nvml.Return) {
+		// the second fan has stalled
+		return []uint32{45, 0}[fan], nvml.SUCCESS
+	}
+	dev.GetTargetFanSpeedFunc = func(fan int) (int, nvml.Return) {
+		if fan == 1 {
+			return 0, nvml.ERROR_NOT_SUPPORTED
+		}
+
+		return 45, nvml.SUCCESS
+	}
+	dev.GetFanControlPolicy_v2Func = func(fan int) (nvml.FanControlPolicy, nvml.Return) {
+		return []nvml.FanControlPolicy{nvml.FAN_POLICY_TEMPERATURE_CONTINOUS_SW, nvml.FAN_POLICY_MANUAL}[fan],
+			nvml.SUCCESS
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Fans: true})(t.Context())
+	require.NoError(t, err)
+
+	ratio := func(v float64) *float64 { return &v }
+	uuid := "11111111-2222-3333-4444-555555555555"
+
+	assert.Equal(t, []collect.Fan{
+		{UUID: uuid, Fan: "0", SpeedRatio: ratio(0.45), TargetSpeedRatio: ratio(0.45), Policy: collect.FanPolicyAuto},
+		{UUID: uuid, Fan: "1", SpeedRatio: ratio(0), Policy: collect.FanPolicyManual},
+	}, reading.Extras.Fans)
+}
+
+func TestExtrasFansWithoutFans(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	// any per-fan getter would panic the mock: a fanless GPU stops at the count
+	dev.GetNumFansFunc = func() (int, nvml.Return) { return 0, nvml.ERROR_NOT_SUPPORTED }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, code, err := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Fans: true})(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, reading.Extras.Fans)
+}
+
+func TestExtrasFansLifecycleAborts(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetNumFansFunc = func() (int, nvml.Return) { return 2, nvml.SUCCESS }
+	dev.GetFanSpeed_v2Func = func(int) (uint32, nvml.Return) { return 0, nvml.ERROR_GPU_IS_LOST }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Fans: true})(t.Context())
+	require.NoError(t, err, "extras must never fail the collection")
+	assert.Empty(t, reading.Extras.Fans)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "the lifecycle error must mark the backend for re-init")
+}
+
+func TestExtrasFansOnADriverWithoutTheGetters(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		missing []string
+		fans    int
+	}{
+		"per-fan getters": {
+			missing: []string{
+				"nvmlDeviceGetFanSpeed_v2", "nvmlDeviceGetTargetFanSpeed", "nvmlDeviceGetFanControlPolicy_v2",
+			},
+			fans: 1,
+		},
+		"fan count": {missing: []string{"nvmlDeviceGetNumFans"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dev := identityDevice()
+			dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+			dev.GetNumFansFunc = func() (int, nvml.Return) { return 1, nvml.SUCCESS }
+
+			fake := &fakeAPI{devices: []nvml.Device{dev}}
+			api := fake.api()
+			api.lookupSymbol = func(symbol string) error {
+				for _, export := range tc.missing {
+					if symbol == export {
+						return errors.New("undefined symbol")
+					}
+				}
+
+				return nil
+			}
+
+			backend, err := newWithAPI(api, slogt.New(t))
+			require.NoError(t, err)
+
+			reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Fans: true})(t.Context())
+			require.NoError(t, err)
+			require.Len(t, reading.Extras.Fans, tc.fans)
+
+			for _, fan := range reading.Extras.Fans {
+				assert.Nil(t, fan.SpeedRatio)
+				assert.Empty(t, fan.Policy)
+			}
+
+			assert.False(t, backend.extrasWarned["fans"], "a missing export is absence, not a failure")
+		})
+	}
+}
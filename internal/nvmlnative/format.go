@@ -155,6 +155,78 @@ func recoveryActionStr(action uint64) string {
 	}
 }
 
+// fabricHealthSummaryStr spells nvmlGpuFabricHealthSummary values. Only the
+// not-supported bare N/A is capture-verified; the other spellings follow the
+// NVML enum names.
+func fabricHealthSummaryStr(summary uint8) string {
+	switch summary {
+	case 0:
+		return tokenBareNotAvailable
+	case 1:
+		return "Healthy"
+	case 2:
+		return "Unhealthy"
+	case 3:
+		return "Limited Capacity"
+	default:
+		return fmt.Sprintf("Unknown(%d)", summary)
+	}
+}
+
+// fabricHealthFlagStr spells one two-state part of the fabric health mask,
+// where 0 is not supported, 1 true and 2 false. As with the summary, only the
+// bare N/A is capture-verified.
+func fabricHealthFlagStr(value uint32, whenTrue, whenFalse string) string {
+	switch value {
+	case 0:
+		return tokenBareNotAvailable
+	case 1:
+		return whenTrue
+	case 2:
+		return whenFalse
+	default:
+		return fmt.Sprintf("Unknown(%d)", value)
+	}
+}
+
+// fabricIncorrectConfigurationStr spells the incorrect-configuration part of
+// the fabric health mask, following the NVML enum names (UNVERIFIED beyond
+// the bare N/A).
+func fabricIncorrectConfigurationStr(value uint32) string {
+	switch value {
+	case 0:
+		return tokenBareNotAvailable
+	case 1:
+		return "None"
+	case 2:
+		return "Incorrect SysGUID"
+	case 3:
+		return "Incorrect Chassis Serial Number"
+	case 4:
+		return "No Partition"
+	case 5:
+		return "Insufficient NVLinks"
+	case 6:
+		return "Incompatible GPU Firmware"
+	case 7:
+		return "Invalid Location"
+	case 8:
+		return "GPU State Invalid"
+	default:
+		return fmt.Sprintf("Unknown(%d)", value)
+	}
+}
+
+// supportedStr spells a vGPU capability. No capture comes from a vGPU host,
+// so the spelling is UNVERIFIED: it follows the -q vGPU capability section.
+func supportedStr(supported bool) string {
+	if supported {
+		return "Supported"
+	}
+
+	return "Not Supported"
+}
+
 func activeNotActive(mask, bit uint64) string {
 	if mask&bit != 0 {
 		return "Active"
@@ -45,6 +45,13 @@ func TestEnumSpellings(t *testing.T) {
 	assert.Equal(t, "HMM", addressingModeStr(1))
 	assert.Equal(t, "Completed", fabricStateStr(3))
 	assert.Equal(t, "N/A", fabricStateStr(0))
+	assert.Equal(t, "N/A", fabricHealthSummaryStr(0))
+	assert.Equal(t, "Healthy", fabricHealthSummaryStr(1))
+	assert.Equal(t, "Full", fabricHealthFlagStr(2, "Degraded", "Full"))
+	assert.Equal(t, "N/A", fabricHealthFlagStr(0, "Degraded", "Full"))
+	assert.Equal(t, "None", fabricIncorrectConfigurationStr(1))
+	assert.Equal(t, "Unknown(9)", fabricIncorrectConfigurationStr(9))
+	assert.Equal(t, "Not Supported", supportedStr(false))
 	assert.Equal(t, "None", recoveryActionStr(0))
 	assert.Equal(t, "Active", activeNotActive(0x5, 0x1))
 	assert.Equal(t, "Not Active", activeNotActive(0x4, 0x1))
@@ -0,0 +1,82 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// deviceGPMMetrics are the metrics sampled per whole GPU.
+//
+//nolint:gochecknoglobals // lookup table
+var deviceGPMMetrics = []uint32{
+	gpmMetricSMUtil, gpmMetricSMOccupancy, gpmMetricAnyTensorUtil, gpmMetricDRAMBWUtil,
+	gpmMetricFP64Util, gpmMetricFP32Util, gpmMetricFP16Util,
+	gpmMetricNVLinkTotalTxPerSec, gpmMetricNVLinkTotalRxPerSec,
+	gpmMetricPcieTxPerSec, gpmMetricPcieRxPerSec,
+}
+
+// collectGPM appends one device's whole-GPU profiling readings. A GPU in MIG
+// mode is skipped: its activity is sampled per GPU instance instead
+// (--collect.mig), and the whole-device sample is not available there. seenGPM
+// records the GPM state key touched this cycle for orphan cleanup. Reports
+// whether extras collection may continue.
+func (b *Backend) collectGPM(dev device, uuid string, extras *collect.Extras, seenGPM map[string]bool) bool {
+	mode, _, ret := dev.GetMigMode()
+
+	switch {
+	case ret == nvml.SUCCESS && mode == nvml.DEVICE_MIG_ENABLE:
+		return true
+	case ret != nvml.SUCCESS && ret != nvml.ERROR_NOT_SUPPORTED && ret != nvml.ERROR_FUNCTION_NOT_FOUND:
+		return b.extrasFailure("gpm", "cannot read the MIG mode", ret)
+	}
+
+	support, ret := dev.GpmQueryDeviceSupport()
+
+	switch {
+	case isLifecycleError(ret):
+		return b.extrasFailure("gpm", "cannot probe the GPU activity support", ret)
+	case ret != nvml.SUCCESS, support.IsSupportedDevice == 0:
+		// pre-Hopper, or a driver without the GPM interface
+		return true
+	}
+
+	// marked before the sample is taken: a transient sampling failure must
+	// not get the retained sample mistaken for an orphan
+	seenGPM[uuid] = true
+
+	// the same all-or-nothing export set as the per-GPU-instance sampling
+	if !b.gpmAvailable("nvmlGpmSampleGet") {
+		return true
+	}
+
+	values, ok := b.gpmWindow(uuid, "", gpmTarget{family: "gpm", subject: "GPU"}, deviceGPMMetrics,
+		func(sample nvml.GpmSample) nvml.Return { return b.api.gpmSampleGet(dev.raw(), sample) })
+	if !ok {
+		return false
+	}
+
+	if values == nil {
+		// first sight: the window opens with this cycle
+		return true
+	}
+
+	extras.GPM = append(extras.GPM, collect.GPMProfile{
+		UUID:                   uuid,
+		SMActivityRatio:        gpmRatio(values, gpmMetricSMUtil),
+		SMOccupancyRatio:       gpmRatio(values, gpmMetricSMOccupancy),
+		TensorActivityRatio:    gpmRatio(values, gpmMetricAnyTensorUtil),
+		DRAMActivityRatio:      gpmRatio(values, gpmMetricDRAMBWUtil),
+		FP64ActivityRatio:      gpmRatio(values, gpmMetricFP64Util),
+		FP32ActivityRatio:      gpmRatio(values, gpmMetricFP32Util),
+		FP16ActivityRatio:      gpmRatio(values, gpmMetricFP16Util),
+		NVLinkTXBytesPerSecond: gpmBytesPerSecond(values, gpmMetricNVLinkTotalTxPerSec),
+		NVLinkRXBytesPerSecond: gpmBytesPerSecond(values, gpmMetricNVLinkTotalRxPerSec),
+		PCIeTXBytesPerSecond:   gpmBytesPerSecond(values, gpmMetricPcieTxPerSec),
+		PCIeRXBytesPerSecond:   gpmBytesPerSecond(values, gpmMetricPcieRxPerSec),
+	})
+
+	return true
+}
@@ -0,0 +1,195 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/neilotoole/slogt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gpmDevice stubs a GPU outside MIG mode, flagged for GPM support as
+// requested. migEnabled is read on every mode probe.
+func gpmDevice(gpmSupported bool, migEnabled *bool) *mock.Device {
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetMigModeFunc = func() (int, int, nvml.Return) {
+		if migEnabled != nil && *migEnabled {
+			return nvml.DEVICE_MIG_ENABLE, nvml.DEVICE_MIG_ENABLE, nvml.SUCCESS
+		}
+
+		return nvml.DEVICE_MIG_DISABLE, nvml.DEVICE_MIG_DISABLE, nvml.SUCCESS
+	}
+	dev.GpmQueryDeviceSupportFunc = func() (nvml.GpmSupport, nvml.Return) {
+		supported := uint32(0)
+		if gpmSupported {
+			supported = 1
+		}
+
+		return nvml.GpmSupport{IsSupportedDevice: supported}, nvml.SUCCESS
+	}
+
+	return dev
+}
+
+func gpmOpts() CollectOptions { return CollectOptions{GPM: true} }
+
+func TestGPMCrossCycle(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeAPI{devices: []nvml.Device{gpmDevice(true, nil)}}
+
+	gpm := &gpmFake{values: map[uint32]float64{
+		gpmMetricSMUtil:              90.668,
+		gpmMetricSMOccupancy:         50,
+		gpmMetricAnyTensorUtil:       22.7059,
+		gpmMetricDRAMBWUtil:          64,
+		gpmMetricFP64Util:            1,
+		gpmMetricFP32Util:            2,
+		gpmMetricFP16Util:            3,
+		gpmMetricNVLinkTotalTxPerSec: 300,
+		gpmMetricNVLinkTotalRxPerSec: 200,
+		gpmMetricPcieTxPerSec:        100,
+		gpmMetricPcieRxPerSec:        50,
+	}}
+
+	api := fake.api()
+	gpm.install(&api)
+
+	backend, err := newWithAPI(api, slogt.New(t))
+	require.NoError(t, err)
+
+	current := time.Now()
+	backend.now = func() time.Time { return current }
+
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), gpmOpts())
+
+	// first sight seeds the sample and emits nothing
+	reading, _, err := query(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.GPM)
+	assert.Equal(t, 1, gpm.live(), "the seed sample must be retained")
+
+	current = current.Add(5 * time.Second)
+
+	reading, _, err = query(t.Context())
+	require.NoError(t, err)
+	require.Len(t, reading.Extras.GPM, 1)
+
+	profile := reading.Extras.GPM[0]
+	assert.Equal(t, "11111111-2222-3333-4444-555555555555", profile.UUID)
+	require.NotNil(t, profile.SMActivityRatio)
+	assert.InDelta(t, 0.90668, *profile.SMActivityRatio, 1e-9)
+	require.NotNil(t, profile.DRAMActivityRatio)
+	assert.InDelta(t, 0.64, *profile.DRAMActivityRatio, 1e-9)
+	require.NotNil(t, profile.FP16ActivityRatio)
+	assert.InDelta(t, 0.03, *profile.FP16ActivityRatio, 1e-9)
+	require.NotNil(t, profile.NVLinkTXBytesPerSecond)
+	assert.InDelta(t, 300*1048576, *profile.NVLinkTXBytesPerSecond, 1e-9, "GPM NVLink is MiB/s")
+	require.NotNil(t, profile.PCIeRXBytesPerSecond)
+	assert.InDelta(t, 50*1048576, *profile.PCIeRXBytesPerSecond, 1e-9)
+	assert.Equal(t, 1, gpm.live(), "exactly one retained sample per GPU")
+
+	// a rapid follow-up scrape serves the same values without sampling
+	current = current.Add(100 * time.Millisecond)
+	allocsBefore := gpm.allocs
+
+	reading, _, err = query(t.Context())
+	require.NoError(t, err)
+	require.Len(t, reading.Extras.GPM, 1)
+	assert.InDelta(t, 0.90668, *reading.Extras.GPM[0].SMActivityRatio, 1e-9)
+	assert.Equal(t, allocsBefore, gpm.allocs, "the min-window guard must not take a new sample")
+}
+
+func TestGPMWithoutSupportTakesNoSample(t *testing.T) {
+	t.Parallel()
+
+	// pre-Hopper: one support probe, no sample
+	fake := &fakeAPI{devices: []nvml.Device{gpmDevice(false, nil)}}
+
+	gpm := &gpmFake{}
+
+	api := fake.api()
+	gpm.install(&api)
+
+	backend, err := newWithAPI(api, slogt.New(t))
+	require.NoError(t, err)
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), gpmOpts())(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.GPM)
+	assert.Equal(t, 0, gpm.allocs)
+}
+
+func TestGPMOnADriverWithoutTheMIGModeGetter(t *testing.T) {
+	t.Parallel()
+
+	// a driver predating MIG: the GPU cannot be in MIG mode
+	fake := &fakeAPI{devices: []nvml.Device{gpmDevice(false, nil)}}
+	backend := newTestBackendWithoutExports(t, fake, "nvmlDeviceGetMigMode")
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), gpmOpts())(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.GPM)
+	assert.False(t, backend.extrasWarned["gpm"], "a missing export is absence, not a failure")
+}
+
+func TestGPMMIGModeDropsTheWholeGPUSample(t *testing.T) {
+	t.Parallel()
+
+	migEnabled := false
+	dev := gpmDevice(true, &migEnabled)
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+
+	gpm := &gpmFake{values: map[uint32]float64{gpmMetricSMUtil: 50}}
+
+	api := fake.api()
+	gpm.install(&api)
+
+	backend, err := newWithAPI(api, slogt.New(t))
+	require.NoError(t, err)
+
+	current := time.Now()
+	backend.now = func() time.Time { return current }
+
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), gpmOpts())
+
+	_, _, err = query(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, gpm.live())
+
+	// switched into MIG mode: the whole-GPU sample is no longer taken, and
+	// the retained one must be freed
+	migEnabled = true
+	current = current.Add(5 * time.Second)
+
+	reading, _, err := query(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.GPM)
+	assert.Equal(t, 0, gpm.live(), "the orphaned whole-GPU sample must be freed")
+}
+
+func TestGPMSampleGetLifecycleAborts(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeAPI{devices: []nvml.Device{gpmDevice(true, nil)}}
+
+	gpm := &gpmFake{sampleRet: nvml.ERROR_GPU_IS_LOST}
+
+	api := fake.api()
+	gpm.install(&api)
+
+	backend, err := newWithAPI(api, slogt.New(t))
+	require.NoError(t, err)
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), gpmOpts())(t.Context())
+	require.NoError(t, err, "extras must never fail the collection")
+	assert.Empty(t, reading.Extras.GPM)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "the lifecycle error must mark the backend for re-init")
+	assert.Equal(t, 0, gpm.live(), "no sample may leak through the abort")
+}
@@ -19,13 +19,22 @@ import "github.com/NVIDIA/go-nvml/pkg/nvml"
 type device interface {
 	GetAccountingBufferSize() (int, nvml.Return)
 	GetAccountingMode() (nvml.EnableState, nvml.Return)
+	GetAccountingPids() ([]int, nvml.Return)
+	GetAccountingStats(pid uint32) (nvml.AccountingStats, nvml.Return)
+	GetActiveVgpus() ([]vgpuInstance, nvml.Return)
 	GetAddressingMode() (nvml.DeviceAddressingMode, nvml.Return)
+	GetArchitecture() (nvml.DeviceArchitecture, nvml.Return)
+	GetBBXTimeData_v1() (nvml.BBXTimeData_v1, nvml.Return)
+	GetBoardPartNumber() (string, nvml.Return)
+	GetBrand() (nvml.BrandType, nvml.Return)
 	GetC2cModeInfoV1() (nvml.C2cModeInfo_v1, nvml.Return)
 	GetClockInfo(clockType nvml.ClockType) (uint32, nvml.Return)
 	GetComputeInstanceId() (int, nvml.Return)
 	GetComputeMode() (nvml.ComputeMode, nvml.Return)
 	GetComputeRunningProcesses() ([]nvml.ProcessInfo, nvml.Return)
+	GetConfComputeMemSizeInfo() (nvml.ConfComputeMemSizeInfo, nvml.Return)
 	GetConfComputeProtectedMemoryUsage() (nvml.Memory, nvml.Return)
+	GetCpuAffinity(numCPUs int) ([]uint, nvml.Return)
 	GetCudaComputeCapability() (int, int, nvml.Return)
 	GetCurrentClocksEventReasons() (uint64, nvml.Return)
 	GetCurrPcieLinkGeneration() (int, nvml.Return)
@@ -36,15 +45,24 @@ type device interface {
 	GetDramEncryptionMode() (nvml.DramEncryptionInfo, nvml.DramEncryptionInfo, nvml.Return)
 	GetDriverModel() (nvml.DriverModel, nvml.DriverModel, nvml.Return)
 	GetEccMode() (nvml.EnableState, nvml.EnableState, nvml.Return)
+	GetEncoderSessions() ([]nvml.EncoderSessionInfo, nvml.Return)
 	GetEncoderStats() (int, uint32, uint32, nvml.Return)
 	GetEncoderUtilization() (uint32, uint32, nvml.Return)
 	GetEnforcedPowerLimit() (uint32, nvml.Return)
+	GetFanControlPolicy_v2(fan int) (nvml.FanControlPolicy, nvml.Return)
 	GetFanSpeed() (uint32, nvml.Return)
+	GetFanSpeed_v2(fan int) (uint32, nvml.Return)
+	GetFBCSessions() ([]nvml.FBCSessionInfo, nvml.Return)
 	GetFieldValues(values []nvml.FieldValue) nvml.Return
 	GetGpuFabricInfoV2() (nvml.GpuFabricInfo_v2, nvml.Return)
+	GetGpuFabricInfoV3() (nvml.GpuFabricInfo_v3, nvml.Return)
 	GetGpuInstanceId() (int, nvml.Return)
+	GetGpuInstancePossiblePlacements(info *nvml.GpuInstanceProfileInfo) ([]nvml.GpuInstancePlacement, nvml.Return)
+	GetGpuInstanceProfileInfo(profile int) (nvml.GpuInstanceProfileInfo, nvml.Return)
+	GetGpuInstanceRemainingCapacity(info *nvml.GpuInstanceProfileInfo) (int, nvml.Return)
 	GetGpuMaxPcieLinkGeneration() (int, nvml.Return)
 	GetGpuOperationMode() (nvml.GpuOperationMode, nvml.GpuOperationMode, nvml.Return)
+	GetGraphicsRunningProcesses() ([]nvml.ProcessInfo, nvml.Return)
 	GetGspFirmwareMode() (bool, bool, nvml.Return)
 	GetHostname_v1() (string, nvml.Return)
 	GetIndex() (int, nvml.Return)
@@ -56,6 +74,8 @@ type device interface {
 	GetMaxMigDeviceCount() (int, nvml.Return)
 	GetMaxPcieLinkGeneration() (int, nvml.Return)
 	GetMaxPcieLinkWidth() (int, nvml.Return)
+	GetMemoryAffinity(numNodes int, scope nvml.AffinityScope) ([]uint, nvml.Return)
+	GetMemoryBusWidth() (uint32, nvml.Return)
 	GetMemoryErrorCounter(
 		errorType nvml.MemoryErrorType,
 		counterType nvml.EccCounterType,
@@ -64,8 +84,18 @@ type device interface {
 	GetMemoryInfo_v2() (nvml.Memory_v2, nvml.Return)
 	GetMigDeviceHandleByIndex(index int) (device, nvml.Return)
 	GetMigMode() (int, int, nvml.Return)
+	GetMinMaxClockOfPState(clockType nvml.ClockType, pstate nvml.Pstates) (uint32, uint32, nvml.Return)
+	GetMPSComputeRunningProcesses() ([]nvml.ProcessInfo, nvml.Return)
 	GetName() (string, nvml.Return)
+	GetNumaNodeId() (int, nvml.Return)
+	GetNumFans() (int, nvml.Return)
+	GetNumGpuCores() (int, nvml.Return)
+	GetNvLinkRemoteDeviceType(link int) (nvml.IntNvLinkDeviceType, nvml.Return)
+	GetNvLinkRemotePciInfo(link int) (nvml.PciInfo, nvml.Return)
+	GetNvLinkState(link int) (nvml.EnableState, nvml.Return)
+	GetNvLinkVersion(link int) (uint32, nvml.Return)
 	GetOfaUtilization() (uint32, uint32, nvml.Return)
+	GetP2PStatus(peer device, p2pIndex nvml.GpuP2PCapsIndex) (nvml.GpuP2PStatus, nvml.Return)
 	GetPcieThroughput(counter nvml.PcieUtilCounter) (uint32, nvml.Return)
 	GetPciInfoExt() (nvml.PciInfoExt, nvml.Return)
 	GetPerformanceState() (nvml.Pstates, nvml.Return)
@@ -75,20 +105,29 @@ type device interface {
 	GetPowerManagementLimit() (uint32, nvml.Return)
 	GetPowerManagementLimitConstraints() (uint32, uint32, nvml.Return)
 	GetPowerUsage() (uint32, nvml.Return)
+	GetProcessUtilization(lastSeenTimestamp uint64) ([]nvml.ProcessUtilizationSample, nvml.Return)
 	GetRemappedRows() (int, int, bool, bool, nvml.Return)
 	GetRemappedRows_v2() (nvml.RemappedRowsInfo_v2, nvml.Return)
 	GetRetiredPages(cause nvml.PageRetirementCause) ([]uint64, nvml.Return)
 	GetRetiredPagesPendingStatus() (nvml.EnableState, nvml.Return)
 	GetRowRemapperHistogram() (nvml.RowRemapperHistogramValues, nvml.Return)
+	GetSamples(samplingType nvml.SamplingType, lastSeenTimestamp uint64) (nvml.ValueType, []nvml.Sample, nvml.Return)
 	GetSerial() (string, nvml.Return)
 	GetSramEccErrorStatus() (nvml.EccSramErrorStatus, nvml.Return)
 	GetSupportedClocksEventReasons() (uint64, nvml.Return)
+	GetSupportedEventTypes() (uint64, nvml.Return)
+	GetSupportedPerformanceStates() ([]nvml.Pstates, nvml.Return)
+	GetTargetFanSpeed(fan int) (int, nvml.Return)
 	GetTemperature(sensor nvml.TemperatureSensors) (uint32, nvml.Return)
+	GetTemperatureThreshold(thresholdType nvml.TemperatureThresholds) (uint32, nvml.Return)
+	GetTopologyCommonAncestor(peer device) (nvml.GpuTopologyLevel, nvml.Return)
 	GetTotalEccErrors(errorType nvml.MemoryErrorType, counterType nvml.EccCounterType) (uint64, nvml.Return)
 	GetTotalEnergyConsumption() (uint64, nvml.Return)
 	GetUtilizationRates() (nvml.Utilization, nvml.Return)
 	GetUUID() (string, nvml.Return)
 	GetVbiosVersion() (string, nvml.Return)
+	GetVgpuCapabilities(capability nvml.DeviceVgpuCapability) (bool, nvml.Return)
+	GetVgpuUtilization(lastSeenTimestamp uint64) (nvml.ValueType, []nvml.VgpuInstanceUtilizationSample, nvml.Return)
 	GpmQueryDeviceSupport() (nvml.GpmSupport, nvml.Return)
 	RegisterEvents(eventTypes uint64, set nvml.EventSet) nvml.Return
 
@@ -123,6 +162,42 @@ func (g guardedDevice) GetAccountingMode() (nvml.EnableState, nvml.Return) {
 	return g.dev.GetAccountingMode()
 }
 
+func (g guardedDevice) GetAccountingPids() ([]int, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetAccountingPids") {
+		return nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetAccountingPids()
+}
+
+func (g guardedDevice) GetAccountingStats(pid uint32) (nvml.AccountingStats, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetAccountingStats") {
+		var z0 nvml.AccountingStats
+
+		return z0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetAccountingStats(pid)
+}
+
+func (g guardedDevice) GetActiveVgpus() ([]vgpuInstance, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetActiveVgpus") {
+		return nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	raw, ret := g.dev.GetActiveVgpus()
+	if ret != nvml.SUCCESS {
+		return nil, ret
+	}
+
+	instances := make([]vgpuInstance, 0, len(raw))
+	for _, inst := range raw {
+		instances = append(instances, guardedVgpuInstance{inst: inst, avail: g.avail})
+	}
+
+	return instances, ret
+}
+
 func (g guardedDevice) GetAddressingMode() (nvml.DeviceAddressingMode, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetAddressingMode") {
 		var z0 nvml.DeviceAddressingMode
@@ -133,6 +208,45 @@ func (g guardedDevice) GetAddressingMode() (nvml.DeviceAddressingMode, nvml.Retu
 	return g.dev.GetAddressingMode()
 }
 
+func (g guardedDevice) GetArchitecture() (nvml.DeviceArchitecture, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetArchitecture") {
+		var z0 nvml.DeviceArchitecture
+
+		return z0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetArchitecture()
+}
+
+//nolint:revive // the name must match go-nvml's Device interface to delegate
+func (g guardedDevice) GetBBXTimeData_v1() (nvml.BBXTimeData_v1, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetBBXTimeData_v1") {
+		var z0 nvml.BBXTimeData_v1
+
+		return z0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetBBXTimeData_v1()
+}
+
+func (g guardedDevice) GetBoardPartNumber() (string, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetBoardPartNumber") {
+		return "", nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetBoardPartNumber()
+}
+
+func (g guardedDevice) GetBrand() (nvml.BrandType, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetBrand") {
+		var z0 nvml.BrandType
+
+		return z0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetBrand()
+}
+
 func (g guardedDevice) GetC2cModeInfoV1() (nvml.C2cModeInfo_v1, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetC2cModeInfoV") {
 		var z0 nvml.C2cModeInfo_v1
@@ -185,6 +299,16 @@ func (g guardedDevice) GetComputeRunningProcesses() ([]nvml.ProcessInfo, nvml.Re
 	return g.dev.GetComputeRunningProcesses()
 }
 
+func (g guardedDevice) GetConfComputeMemSizeInfo() (nvml.ConfComputeMemSizeInfo, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetConfComputeMemSizeInfo") {
+		var z0 nvml.ConfComputeMemSizeInfo
+
+		return z0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetConfComputeMemSizeInfo()
+}
+
 func (g guardedDevice) GetConfComputeProtectedMemoryUsage() (nvml.Memory, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetConfComputeProtectedMemoryUsage") {
 		var z0 nvml.Memory
@@ -195,6 +319,14 @@ func (g guardedDevice) GetConfComputeProtectedMemoryUsage() (nvml.Memory, nvml.R
 	return g.dev.GetConfComputeProtectedMemoryUsage()
 }
 
+func (g guardedDevice) GetCpuAffinity(p0 int) ([]uint, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetCpuAffinity") {
+		return nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetCpuAffinity(p0)
+}
+
 func (g guardedDevice) GetCudaComputeCapability() (int, int, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetCudaComputeCapability") {
 		return 0, 0, nvml.ERROR_FUNCTION_NOT_FOUND
@@ -298,6 +430,14 @@ func (g guardedDevice) GetEccMode() (nvml.EnableState, nvml.EnableState, nvml.Re
 	return g.dev.GetEccMode()
 }
 
+func (g guardedDevice) GetEncoderSessions() ([]nvml.EncoderSessionInfo, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetEncoderSessions") {
+		return nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetEncoderSessions()
+}
+
 func (g guardedDevice) GetEncoderStats() (int, uint32, uint32, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetEncoderStats") {
 		return 0, 0, 0, nvml.ERROR_FUNCTION_NOT_FOUND
@@ -322,6 +462,14 @@ func (g guardedDevice) GetEnforcedPowerLimit() (uint32, nvml.Return) {
 	return g.dev.GetEnforcedPowerLimit()
 }
 
+func (g guardedDevice) GetFanControlPolicy_v2(p0 int) (nvml.FanControlPolicy, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetFanControlPolicy_v2") {
+		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetFanControlPolicy_v2(p0)
+}
+
 func (g guardedDevice) GetFanSpeed() (uint32, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetFanSpeed") {
 		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
@@ -330,6 +478,22 @@ func (g guardedDevice) GetFanSpeed() (uint32, nvml.Return) {
 	return g.dev.GetFanSpeed()
 }
 
+func (g guardedDevice) GetFanSpeed_v2(p0 int) (uint32, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetFanSpeed_v2") {
+		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetFanSpeed_v2(p0)
+}
+
+func (g guardedDevice) GetFBCSessions() ([]nvml.FBCSessionInfo, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetFBCSessions") {
+		return nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetFBCSessions()
+}
+
 func (g guardedDevice) GetFieldValues(p0 []nvml.FieldValue) nvml.Return {
 	if !g.avail.has("nvmlDeviceGetFieldValues") {
 		return nvml.ERROR_FUNCTION_NOT_FOUND
@@ -348,6 +512,16 @@ func (g guardedDevice) GetGpuFabricInfoV2() (nvml.GpuFabricInfo_v2, nvml.Return)
 	return g.dev.GetGpuFabricInfoV().V2()
 }
 
+func (g guardedDevice) GetGpuFabricInfoV3() (nvml.GpuFabricInfo_v3, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetGpuFabricInfoV") {
+		var z0 nvml.GpuFabricInfo_v3
+
+		return z0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetGpuFabricInfoV().V3()
+}
+
 //nolint:revive // the name must match go-nvml's Device interface to delegate
 func (g guardedDevice) GetGpuInstanceId() (int, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetGpuInstanceId") {
@@ -357,6 +531,36 @@ func (g guardedDevice) GetGpuInstanceId() (int, nvml.Return) {
 	return g.dev.GetGpuInstanceId()
 }
 
+func (g guardedDevice) GetGpuInstancePossiblePlacements(
+	p0 *nvml.GpuInstanceProfileInfo,
+) ([]nvml.GpuInstancePlacement, nvml.Return) {
+	// go-nvml falls back to the classic export when the driver lacks v2
+	if !g.avail.hasAny("nvmlDeviceGetGpuInstancePossiblePlacements_v2",
+		"nvmlDeviceGetGpuInstancePossiblePlacements") {
+		return nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetGpuInstancePossiblePlacements(p0)
+}
+
+func (g guardedDevice) GetGpuInstanceProfileInfo(p0 int) (nvml.GpuInstanceProfileInfo, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetGpuInstanceProfileInfo") {
+		var z0 nvml.GpuInstanceProfileInfo
+
+		return z0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetGpuInstanceProfileInfo(p0)
+}
+
+func (g guardedDevice) GetGpuInstanceRemainingCapacity(p0 *nvml.GpuInstanceProfileInfo) (int, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetGpuInstanceRemainingCapacity") {
+		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetGpuInstanceRemainingCapacity(p0)
+}
+
 func (g guardedDevice) GetGpuMaxPcieLinkGeneration() (int, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetGpuMaxPcieLinkGeneration") {
 		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
@@ -378,6 +582,16 @@ func (g guardedDevice) GetGpuOperationMode() (nvml.GpuOperationMode, nvml.GpuOpe
 	return g.dev.GetGpuOperationMode()
 }
 
+func (g guardedDevice) GetGraphicsRunningProcesses() ([]nvml.ProcessInfo, nvml.Return) {
+	// versioned by go-nvml at load time, like GetComputeRunningProcesses
+	if !g.avail.hasAny("nvmlDeviceGetGraphicsRunningProcesses",
+		"nvmlDeviceGetGraphicsRunningProcesses_v2", "nvmlDeviceGetGraphicsRunningProcesses_v3") {
+		return nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetGraphicsRunningProcesses()
+}
+
 func (g guardedDevice) GetGspFirmwareMode() (bool, bool, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetGspFirmwareMode") {
 		return false, false, nvml.ERROR_FUNCTION_NOT_FOUND
@@ -469,6 +683,22 @@ func (g guardedDevice) GetMaxPcieLinkWidth() (int, nvml.Return) {
 	return g.dev.GetMaxPcieLinkWidth()
 }
 
+func (g guardedDevice) GetMemoryAffinity(p0 int, p1 nvml.AffinityScope) ([]uint, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetMemoryAffinity") {
+		return nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetMemoryAffinity(p0, p1)
+}
+
+func (g guardedDevice) GetMemoryBusWidth() (uint32, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetMemoryBusWidth") {
+		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetMemoryBusWidth()
+}
+
 func (g guardedDevice) GetMemoryErrorCounter(
 	p0 nvml.MemoryErrorType,
 	p1 nvml.EccCounterType,
@@ -514,6 +744,24 @@ func (g guardedDevice) GetMigMode() (int, int, nvml.Return) {
 	return g.dev.GetMigMode()
 }
 
+func (g guardedDevice) GetMinMaxClockOfPState(p0 nvml.ClockType, p1 nvml.Pstates) (uint32, uint32, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetMinMaxClockOfPState") {
+		return 0, 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetMinMaxClockOfPState(p0, p1)
+}
+
+func (g guardedDevice) GetMPSComputeRunningProcesses() ([]nvml.ProcessInfo, nvml.Return) {
+	// versioned by go-nvml at load time, like GetComputeRunningProcesses
+	if !g.avail.hasAny("nvmlDeviceGetMPSComputeRunningProcesses",
+		"nvmlDeviceGetMPSComputeRunningProcesses_v2", "nvmlDeviceGetMPSComputeRunningProcesses_v3") {
+		return nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetMPSComputeRunningProcesses()
+}
+
 func (g guardedDevice) GetName() (string, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetName") {
 		return "", nvml.ERROR_FUNCTION_NOT_FOUND
@@ -522,6 +770,70 @@ func (g guardedDevice) GetName() (string, nvml.Return) {
 	return g.dev.GetName()
 }
 
+//nolint:revive // the name must match go-nvml's Device interface to delegate
+func (g guardedDevice) GetNumaNodeId() (int, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetNumaNodeId") {
+		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetNumaNodeId()
+}
+
+func (g guardedDevice) GetNumFans() (int, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetNumFans") {
+		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetNumFans()
+}
+
+func (g guardedDevice) GetNumGpuCores() (int, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetNumGpuCores") {
+		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetNumGpuCores()
+}
+
+func (g guardedDevice) GetNvLinkRemoteDeviceType(p0 int) (nvml.IntNvLinkDeviceType, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetNvLinkRemoteDeviceType") {
+		var z0 nvml.IntNvLinkDeviceType
+
+		return z0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetNvLinkRemoteDeviceType(p0)
+}
+
+func (g guardedDevice) GetNvLinkRemotePciInfo(p0 int) (nvml.PciInfo, nvml.Return) {
+	// versioned by go-nvml at load time, like GetComputeRunningProcesses
+	if !g.avail.hasAny("nvmlDeviceGetNvLinkRemotePciInfo", "nvmlDeviceGetNvLinkRemotePciInfo_v2") {
+		var z0 nvml.PciInfo
+
+		return z0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetNvLinkRemotePciInfo(p0)
+}
+
+func (g guardedDevice) GetNvLinkState(p0 int) (nvml.EnableState, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetNvLinkState") {
+		var z0 nvml.EnableState
+
+		return z0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetNvLinkState(p0)
+}
+
+func (g guardedDevice) GetNvLinkVersion(p0 int) (uint32, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetNvLinkVersion") {
+		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetNvLinkVersion(p0)
+}
+
 func (g guardedDevice) GetOfaUtilization() (uint32, uint32, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetOfaUtilization") {
 		return 0, 0, nvml.ERROR_FUNCTION_NOT_FOUND
@@ -530,6 +842,16 @@ func (g guardedDevice) GetOfaUtilization() (uint32, uint32, nvml.Return) {
 	return g.dev.GetOfaUtilization()
 }
 
+func (g guardedDevice) GetP2PStatus(p0 device, p1 nvml.GpuP2PCapsIndex) (nvml.GpuP2PStatus, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetP2PStatus") {
+		var z0 nvml.GpuP2PStatus
+
+		return z0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetP2PStatus(p0.raw(), p1)
+}
+
 func (g guardedDevice) GetPcieThroughput(p0 nvml.PcieUtilCounter) (uint32, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetPcieThroughput") {
 		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
@@ -610,6 +932,14 @@ func (g guardedDevice) GetPowerUsage() (uint32, nvml.Return) {
 	return g.dev.GetPowerUsage()
 }
 
+func (g guardedDevice) GetProcessUtilization(p0 uint64) ([]nvml.ProcessUtilizationSample, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetProcessUtilization") {
+		return nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetProcessUtilization(p0)
+}
+
 func (g guardedDevice) GetRemappedRows() (int, int, bool, bool, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetRemappedRows") {
 		return 0, 0, false, false, nvml.ERROR_FUNCTION_NOT_FOUND
@@ -657,6 +987,16 @@ func (g guardedDevice) GetRowRemapperHistogram() (nvml.RowRemapperHistogramValue
 	return g.dev.GetRowRemapperHistogram()
 }
 
+func (g guardedDevice) GetSamples(p0 nvml.SamplingType, p1 uint64) (nvml.ValueType, []nvml.Sample, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetSamples") {
+		var z0 nvml.ValueType
+
+		return z0, nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetSamples(p0, p1)
+}
+
 func (g guardedDevice) GetSerial() (string, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetSerial") {
 		return "", nvml.ERROR_FUNCTION_NOT_FOUND
@@ -683,6 +1023,30 @@ func (g guardedDevice) GetSupportedClocksEventReasons() (uint64, nvml.Return) {
 	return g.dev.GetSupportedClocksEventReasons()
 }
 
+func (g guardedDevice) GetSupportedEventTypes() (uint64, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetSupportedEventTypes") {
+		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetSupportedEventTypes()
+}
+
+func (g guardedDevice) GetSupportedPerformanceStates() ([]nvml.Pstates, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetSupportedPerformanceStates") {
+		return nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetSupportedPerformanceStates()
+}
+
+func (g guardedDevice) GetTargetFanSpeed(p0 int) (int, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetTargetFanSpeed") {
+		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetTargetFanSpeed(p0)
+}
+
 func (g guardedDevice) GetTemperature(p0 nvml.TemperatureSensors) (uint32, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetTemperature") {
 		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
@@ -691,6 +1055,24 @@ func (g guardedDevice) GetTemperature(p0 nvml.TemperatureSensors) (uint32, nvml.
 	return g.dev.GetTemperature(p0)
 }
 
+func (g guardedDevice) GetTemperatureThreshold(p0 nvml.TemperatureThresholds) (uint32, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetTemperatureThreshold") {
+		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetTemperatureThreshold(p0)
+}
+
+func (g guardedDevice) GetTopologyCommonAncestor(p0 device) (nvml.GpuTopologyLevel, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetTopologyCommonAncestor") {
+		var z0 nvml.GpuTopologyLevel
+
+		return z0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetTopologyCommonAncestor(p0.raw())
+}
+
 func (g guardedDevice) GetTotalEccErrors(p0 nvml.MemoryErrorType, p1 nvml.EccCounterType) (uint64, nvml.Return) {
 	if !g.avail.has("nvmlDeviceGetTotalEccErrors") {
 		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
@@ -733,6 +1115,26 @@ func (g guardedDevice) GetVbiosVersion() (string, nvml.Return) {
 	return g.dev.GetVbiosVersion()
 }
 
+func (g guardedDevice) GetVgpuCapabilities(p0 nvml.DeviceVgpuCapability) (bool, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetVgpuCapabilities") {
+		return false, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetVgpuCapabilities(p0)
+}
+
+func (g guardedDevice) GetVgpuUtilization(
+	p0 uint64,
+) (nvml.ValueType, []nvml.VgpuInstanceUtilizationSample, nvml.Return) {
+	if !g.avail.has("nvmlDeviceGetVgpuUtilization") {
+		var z0 nvml.ValueType
+
+		return z0, nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.dev.GetVgpuUtilization(p0)
+}
+
 func (g guardedDevice) GpmQueryDeviceSupport() (nvml.GpmSupport, nvml.Return) {
 	if !g.avail.has("nvmlGpmQueryDeviceSupport") {
 		var z0 nvml.GpmSupport
@@ -756,3 +1158,109 @@ func (g guardedDevice) RegisterEvents(p0 uint64, p1 nvml.EventSet) nvml.Return {
 //
 //nolint:ireturn // hands back go-nvml's own interface type by definition
 func (g guardedDevice) raw() nvml.Device { return g.dev }
+
+// vgpuInstance is the narrow surface of one active vGPU instance, handed out
+// by device.GetActiveVgpus already guarded, so a vGPU getter cannot reach the
+// driver unprobed either.
+type vgpuInstance interface {
+	GetEncoderStats() (int, uint32, uint32, nvml.Return)
+	GetFbUsage() (uint64, nvml.Return)
+	GetType() (vgpuType, nvml.Return)
+	GetUUID() (string, nvml.Return)
+	GetVmID() (string, nvml.VgpuVmIdType, nvml.Return)
+
+	// raw exposes the underlying handle, which the utilization samples name
+	// their instance by. It must never be used to make a call.
+	raw() nvml.VgpuInstance
+}
+
+// vgpuType is the narrow surface of a vGPU type, handed out by
+// vgpuInstance.GetType.
+type vgpuType interface {
+	GetClass() (string, nvml.Return)
+	GetName() (string, nvml.Return)
+}
+
+// guardedVgpuInstance wraps a vGPU instance handle with the availability
+// snapshot of the device it came from.
+type guardedVgpuInstance struct {
+	inst  nvml.VgpuInstance
+	avail *availability
+}
+
+func (g guardedVgpuInstance) GetEncoderStats() (int, uint32, uint32, nvml.Return) {
+	if !g.avail.has("nvmlVgpuInstanceGetEncoderStats") {
+		return 0, 0, 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.inst.GetEncoderStats()
+}
+
+func (g guardedVgpuInstance) GetFbUsage() (uint64, nvml.Return) {
+	if !g.avail.has("nvmlVgpuInstanceGetFbUsage") {
+		return 0, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.inst.GetFbUsage()
+}
+
+//nolint:ireturn // a vGPU type must arrive wrapped, never raw
+func (g guardedVgpuInstance) GetType() (vgpuType, nvml.Return) {
+	if !g.avail.has("nvmlVgpuInstanceGetType") {
+		return nil, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	typeID, ret := g.inst.GetType()
+	if ret != nvml.SUCCESS {
+		return nil, ret
+	}
+
+	return guardedVgpuType{typeID: typeID, avail: g.avail}, ret
+}
+
+func (g guardedVgpuInstance) GetUUID() (string, nvml.Return) {
+	if !g.avail.has("nvmlVgpuInstanceGetUUID") {
+		return "", nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.inst.GetUUID()
+}
+
+func (g guardedVgpuInstance) GetVmID() (string, nvml.VgpuVmIdType, nvml.Return) {
+	if !g.avail.has("nvmlVgpuInstanceGetVmID") {
+		var z1 nvml.VgpuVmIdType
+
+		return "", z1, nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.inst.GetVmID()
+}
+
+// raw exposes the underlying handle for identity matching only. It must never
+// be used to make a call.
+//
+//nolint:ireturn // hands back go-nvml's own interface type by definition
+func (g guardedVgpuInstance) raw() nvml.VgpuInstance { return g.inst }
+
+// guardedVgpuType wraps a vGPU type id with the availability snapshot of the
+// instance it came from.
+type guardedVgpuType struct {
+	typeID nvml.VgpuTypeId
+	avail  *availability
+}
+
+func (g guardedVgpuType) GetClass() (string, nvml.Return) {
+	if !g.avail.has("nvmlVgpuTypeGetClass") {
+		return "", nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.typeID.GetClass()
+}
+
+func (g guardedVgpuType) GetName() (string, nvml.Return) {
+	if !g.avail.has("nvmlVgpuTypeGetName") {
+		return "", nvml.ERROR_FUNCTION_NOT_FOUND
+	}
+
+	return g.typeID.GetName()
+}
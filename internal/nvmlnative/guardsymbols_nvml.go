@@ -20,12 +20,21 @@ package nvmlnative
 var guardedSymbols = []string{
 	"nvmlDeviceGetAccountingBufferSize",
 	"nvmlDeviceGetAccountingMode",
+	"nvmlDeviceGetAccountingPids",
+	"nvmlDeviceGetAccountingStats",
+	"nvmlDeviceGetActiveVgpus",
 	"nvmlDeviceGetAddressingMode",
+	"nvmlDeviceGetArchitecture",
+	"nvmlDeviceGetBBXTimeData_v1",
+	"nvmlDeviceGetBoardPartNumber",
+	"nvmlDeviceGetBrand",
 	"nvmlDeviceGetC2cModeInfoV",
 	"nvmlDeviceGetClockInfo",
 	"nvmlDeviceGetComputeInstanceId",
 	"nvmlDeviceGetComputeMode",
+	"nvmlDeviceGetConfComputeMemSizeInfo",
 	"nvmlDeviceGetConfComputeProtectedMemoryUsage",
+	"nvmlDeviceGetCpuAffinity",
 	"nvmlDeviceGetCudaComputeCapability",
 	"nvmlDeviceGetCurrPcieLinkGeneration",
 	"nvmlDeviceGetCurrPcieLinkWidth",
@@ -35,13 +44,21 @@ var guardedSymbols = []string{
 	"nvmlDeviceGetDisplayMode",
 	"nvmlDeviceGetDramEncryptionMode",
 	"nvmlDeviceGetEccMode",
+	"nvmlDeviceGetEncoderSessions",
 	"nvmlDeviceGetEncoderStats",
 	"nvmlDeviceGetEncoderUtilization",
 	"nvmlDeviceGetEnforcedPowerLimit",
+	"nvmlDeviceGetFanControlPolicy_v2",
 	"nvmlDeviceGetFanSpeed",
+	"nvmlDeviceGetFanSpeed_v2",
+	"nvmlDeviceGetFBCSessions",
 	"nvmlDeviceGetFieldValues",
 	"nvmlDeviceGetGpuFabricInfoV",
 	"nvmlDeviceGetGpuInstanceId",
+	"nvmlDeviceGetGpuInstancePossiblePlacements",
+	"nvmlDeviceGetGpuInstancePossiblePlacements_v2",
+	"nvmlDeviceGetGpuInstanceProfileInfo",
+	"nvmlDeviceGetGpuInstanceRemainingCapacity",
 	"nvmlDeviceGetGpuMaxPcieLinkGeneration",
 	"nvmlDeviceGetGpuOperationMode",
 	"nvmlDeviceGetGspFirmwareMode",
@@ -55,12 +72,24 @@ var guardedSymbols = []string{
 	"nvmlDeviceGetMaxMigDeviceCount",
 	"nvmlDeviceGetMaxPcieLinkGeneration",
 	"nvmlDeviceGetMaxPcieLinkWidth",
+	"nvmlDeviceGetMemoryAffinity",
+	"nvmlDeviceGetMemoryBusWidth",
 	"nvmlDeviceGetMemoryErrorCounter",
 	"nvmlDeviceGetMemoryInfo_v2",
 	"nvmlDeviceGetMigDeviceHandleByIndex",
 	"nvmlDeviceGetMigMode",
+	"nvmlDeviceGetMinMaxClockOfPState",
 	"nvmlDeviceGetName",
+	"nvmlDeviceGetNumaNodeId",
+	"nvmlDeviceGetNumFans",
+	"nvmlDeviceGetNumGpuCores",
+	"nvmlDeviceGetNvLinkRemoteDeviceType",
+	"nvmlDeviceGetNvLinkRemotePciInfo",
+	"nvmlDeviceGetNvLinkRemotePciInfo_v2",
+	"nvmlDeviceGetNvLinkState",
+	"nvmlDeviceGetNvLinkVersion",
 	"nvmlDeviceGetOfaUtilization",
+	"nvmlDeviceGetP2PStatus",
 	"nvmlDeviceGetPciInfoExt",
 	"nvmlDeviceGetPcieThroughput",
 	"nvmlDeviceGetPerformanceState",
@@ -70,23 +99,40 @@ var guardedSymbols = []string{
 	"nvmlDeviceGetPowerManagementLimit",
 	"nvmlDeviceGetPowerManagementLimitConstraints",
 	"nvmlDeviceGetPowerUsage",
+	"nvmlDeviceGetProcessUtilization",
 	"nvmlDeviceGetRemappedRows",
 	"nvmlDeviceGetRemappedRows_v2",
 	"nvmlDeviceGetRetiredPages",
 	"nvmlDeviceGetRetiredPagesPendingStatus",
 	"nvmlDeviceGetRowRemapperHistogram",
+	"nvmlDeviceGetSamples",
 	"nvmlDeviceGetSerial",
 	"nvmlDeviceGetSramEccErrorStatus",
 	"nvmlDeviceGetSupportedClocksEventReasons",
+	"nvmlDeviceGetSupportedEventTypes",
+	"nvmlDeviceGetSupportedPerformanceStates",
+	"nvmlDeviceGetTargetFanSpeed",
 	"nvmlDeviceGetTemperature",
+	"nvmlDeviceGetTemperatureThreshold",
+	"nvmlDeviceGetTopologyCommonAncestor",
 	"nvmlDeviceGetTotalEccErrors",
 	"nvmlDeviceGetTotalEnergyConsumption",
 	"nvmlDeviceGetUUID",
 	"nvmlDeviceGetUtilizationRates",
 	"nvmlDeviceGetVbiosVersion",
+	"nvmlDeviceGetVgpuCapabilities",
+	"nvmlDeviceGetVgpuUtilization",
+	"nvmlVgpuInstanceGetEncoderStats",
+	"nvmlVgpuInstanceGetFbUsage",
+	"nvmlVgpuInstanceGetType",
+	"nvmlVgpuInstanceGetUUID",
+	"nvmlVgpuInstanceGetVmID",
+	"nvmlVgpuTypeGetClass",
+	"nvmlVgpuTypeGetName",
 	"nvmlDeviceValidateInforom",
 	"nvmlGpmSampleAlloc",
 	"nvmlGpmSampleFree",
+	"nvmlGpmSampleGet",
 	"nvmlGpmMigSampleGet",
 	"nvmlGpmMetricsGet",
 	"nvmlEventSetCreate",
@@ -94,11 +140,23 @@ var guardedSymbols = []string{
 	"nvmlSystemGetProcessName",
 	"nvmlSystemGetDriverVersion",
 	"nvmlSystemGetCudaDriverVersion",
+	"nvmlGetVgpuDriverCapabilities",
+	"nvmlSystemGetConfComputeCapabilities",
+	"nvmlSystemGetConfComputeState",
+	"nvmlSystemGetConfComputeSettings",
+	"nvmlSystemGetConfComputeGpusReadyState",
+	"nvmlSystemGetConfComputeKeyRotationThresholdInfo",
 	"nvmlDeviceGetDriverModel",
 	"nvmlDeviceGetDriverModel_v2",
 	"nvmlDeviceGetComputeRunningProcesses",
 	"nvmlDeviceGetComputeRunningProcesses_v2",
 	"nvmlDeviceGetComputeRunningProcesses_v3",
+	"nvmlDeviceGetGraphicsRunningProcesses",
+	"nvmlDeviceGetGraphicsRunningProcesses_v2",
+	"nvmlDeviceGetGraphicsRunningProcesses_v3",
+	"nvmlDeviceGetMPSComputeRunningProcesses",
+	"nvmlDeviceGetMPSComputeRunningProcesses_v2",
+	"nvmlDeviceGetMPSComputeRunningProcesses_v3",
 	"nvmlDeviceRegisterEvents",
 	"nvmlGpmQueryDeviceSupport",
 }
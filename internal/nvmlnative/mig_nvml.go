@@ -3,6 +3,7 @@
 package nvmlnative
 
 import (
+	"fmt"
 	"math"
 	"sort"
 	"strconv"
@@ -15,18 +16,24 @@ import (
 )
 
 // The GPM metric ids this backend samples, from the NVML metric catalog.
-// Activity metrics report percentages (0-100), the PCIe metrics MiB/s.
+// Activity metrics report percentages (0-100), the bandwidth metrics MiB/s.
 const (
-	gpmMetricGraphicsUtil  = 1
-	gpmMetricSMUtil        = 2
-	gpmMetricSMOccupancy   = 3
-	gpmMetricAnyTensorUtil = 5
-	gpmMetricPcieTxPerSec  = 20
-	gpmMetricPcieRxPerSec  = 21
+	gpmMetricGraphicsUtil        = 1
+	gpmMetricSMUtil              = 2
+	gpmMetricSMOccupancy         = 3
+	gpmMetricAnyTensorUtil       = 5
+	gpmMetricDRAMBWUtil          = 10
+	gpmMetricFP64Util            = 11
+	gpmMetricFP32Util            = 12
+	gpmMetricFP16Util            = 13
+	gpmMetricPcieTxPerSec        = 20
+	gpmMetricPcieRxPerSec        = 21
+	gpmMetricNVLinkTotalRxPerSec = 60
+	gpmMetricNVLinkTotalTxPerSec = 61
 )
 
-// gpmMibMultiplier converts the GPM PCIe metrics (documented as MiB/sec, a
-// different unit from the whole-GPU KB/s throughput call) to bytes/s.
+// gpmMibMultiplier converts the GPM bandwidth metrics (documented as MiB/sec,
+// a different unit from the whole-GPU KB/s throughput call) to bytes/s.
 const gpmMibMultiplier = 1024 * 1024
 
 // gpmMinWindow keeps the sampling window from collapsing when several
@@ -44,7 +51,26 @@ const gpmMaxWindow = 10 * time.Minute
 // instance id fields.
 const migInvalidID = 0xFFFFFFFF
 
-// gpmState is the retained previous GPM sample of one GPU instance.
+// migProfileSuffixes are the name suffixes nvidia-smi gives the GPU instance
+// profile variants; the plain profiles and their REV2 (doubled memory)
+// variants carry none.
+//
+//nolint:gochecknoglobals // lookup table
+var migProfileSuffixes = map[int]string{
+	nvml.GPU_INSTANCE_PROFILE_1_SLICE_REV1:   "+me",
+	nvml.GPU_INSTANCE_PROFILE_2_SLICE_REV1:   "+me",
+	nvml.GPU_INSTANCE_PROFILE_1_SLICE_GFX:    "+gfx",
+	nvml.GPU_INSTANCE_PROFILE_2_SLICE_GFX:    "+gfx",
+	nvml.GPU_INSTANCE_PROFILE_3_SLICE_GFX:    "+gfx",
+	nvml.GPU_INSTANCE_PROFILE_4_SLICE_GFX:    "+gfx",
+	nvml.GPU_INSTANCE_PROFILE_1_SLICE_NO_ME:  "-me",
+	nvml.GPU_INSTANCE_PROFILE_2_SLICE_NO_ME:  "-me",
+	nvml.GPU_INSTANCE_PROFILE_1_SLICE_ALL_ME: "+me.all",
+	nvml.GPU_INSTANCE_PROFILE_2_SLICE_ALL_ME: "+me.all",
+}
+
+// gpmState is the retained previous GPM sample of one GPU instance, or of a
+// whole GPU outside MIG mode.
 type gpmState struct {
 	sample nvml.GpmSample
 	taken  time.Time
@@ -53,9 +79,17 @@ type gpmState struct {
 	// samples across two different instances that happen to share an id
 	// would produce a plausible-looking wrong value.
 	fingerprint string
-	// last holds the most recent computed utilization, served again while
-	// the window guard keeps the sample anchored.
-	last *collect.MIGUtilization
+	// last holds the most recent computed metric values by id, served
+	// again while the window guard keeps the sample anchored.
+	last map[uint32]float64
+}
+
+// migGPMMetrics are the metrics sampled per GPU instance.
+//
+//nolint:gochecknoglobals // lookup table
+var migGPMMetrics = []uint32{
+	gpmMetricGraphicsUtil, gpmMetricSMUtil, gpmMetricSMOccupancy,
+	gpmMetricAnyTensorUtil, gpmMetricPcieTxPerSec, gpmMetricPcieRxPerSec,
 }
 
 // migGroup accumulates one GPU instance's members during enumeration, to
@@ -66,13 +100,13 @@ type migGroup struct {
 }
 
 // collectMIG gathers one parent device's MIG inventory, memory and GPM
-// utilization into extras. seenGIs records the GPM state keys touched this
+// utilization into extras. seenGPM records the GPM state keys touched this
 // cycle for orphan cleanup. Reports whether extras collection may continue.
 func (b *Backend) collectMIG(
 	dev device,
 	parentUUID string,
 	extras *collect.Extras,
-	seenGIs map[string]bool,
+	seenGPM map[string]bool,
 ) bool {
 	mode, _, ret := dev.GetMigMode()
 
@@ -91,6 +125,12 @@ func (b *Backend) collectMIG(
 		return false
 	}
 
+	// an empty GPU is where the capacity matters most, so it is read before
+	// the early return below
+	if !b.collectMIGCapacity(dev, parentUUID, extras) {
+		return false
+	}
+
 	if len(groups) == 0 {
 		return true
 	}
@@ -99,13 +139,13 @@ func (b *Backend) collectMIG(
 	// says below: mark them seen first, so a transient probe failure cannot
 	// get their retained samples mistaken for orphans
 	for _, group := range groups {
-		seenGIs[giKey(parentUUID, group.gi)] = true
+		seenGPM[giKey(parentUUID, group.gi)] = true
 	}
 
 	// GPM is an all-or-nothing group: sampling allocates a handle that must be
 	// freed through a second export, so a driver providing only part of the set
 	// would either leak or crash on teardown.
-	if !b.gpmAvailable() {
+	if !b.gpmAvailable("nvmlGpmMigSampleGet") {
 		return false
 	}
 
@@ -134,12 +174,20 @@ func (b *Backend) collectMIGUtilization(
 	utilByGI := map[string]*collect.MIGUtilization{}
 
 	for _, group := range groups {
-		util, ok := b.gpmUtilization(dev, giKey(parentUUID, group.gi), group)
+		values, ok := b.gpmWindow(
+			giKey(parentUUID, group.gi),
+			strings.Join(group.members, ","),
+			gpmTarget{family: "mig-gpm", subject: "GPU instance"},
+			migGPMMetrics,
+			func(sample nvml.GpmSample) nvml.Return {
+				return b.api.gpmMigSampleGet(dev.raw(), group.gi, sample)
+			},
+		)
 		if !ok {
 			return false
 		}
 
-		utilByGI[strconv.Itoa(group.gi)] = util
+		utilByGI[strconv.Itoa(group.gi)] = migUtilization(values)
 	}
 
 	// stamp the GPU instance's utilization onto each of its instances,
@@ -156,6 +204,101 @@ func (b *Backend) collectMIGUtilization(
 	return true
 }
 
+// collectMIGCapacity reads, for each GPU instance profile the parent offers,
+// how many instances the empty GPU can host, how many more fit beside the
+// existing ones and where they can be placed. Reports whether extras
+// collection may continue.
+//
+//nolint:cyclop // one pass over the profiles with per-getter fallbacks
+func (b *Backend) collectMIGCapacity(dev device, parentUUID string, extras *collect.Extras) bool {
+	// the profile names are derived from the GPU's memory size; without it
+	// they fall back to the profile's own rounded size
+	var deviceMemory uint64
+
+	memory, ret := dev.GetMemoryInfo_v2()
+
+	switch {
+	case ret == nvml.SUCCESS:
+		deviceMemory = memory.Total
+	case isLifecycleError(ret):
+		return b.extrasFailure("mig", "cannot read the GPU memory size", ret)
+	}
+
+	for id := range nvml.GPU_INSTANCE_PROFILE_COUNT {
+		info, ret := dev.GetGpuInstanceProfileInfo(id)
+
+		switch {
+		case ret == nvml.ERROR_FUNCTION_NOT_FOUND:
+			// a driver predating the profile queries
+			return true
+		case ret == nvml.ERROR_NOT_SUPPORTED, ret == nvml.ERROR_INVALID_ARGUMENT:
+			// a profile this GPU does not offer
+			continue
+		case ret != nvml.SUCCESS:
+			if !b.extrasFailure("mig", "cannot read a GPU instance profile", ret) {
+				return false
+			}
+
+			continue
+		}
+
+		if info.InstanceCount == 0 {
+			continue
+		}
+
+		capacity := collect.MIGProfileCapacity{
+			ParentUUID: parentUUID,
+			Profile:    migProfileName(id, info, deviceMemory),
+			Total:      int(info.InstanceCount),
+		}
+
+		remaining, ret := dev.GetGpuInstanceRemainingCapacity(&info)
+
+		switch {
+		case ret == nvml.SUCCESS:
+			capacity.Remaining = &remaining
+		case isLifecycleError(ret):
+			return b.extrasFailure("mig", "cannot read a GPU instance profile capacity", ret)
+		}
+
+		placements, ret := dev.GetGpuInstancePossiblePlacements(&info)
+
+		switch {
+		case ret == nvml.SUCCESS:
+			for _, placement := range placements {
+				capacity.Placements = append(capacity.Placements, collect.MIGPlacement{
+					Start: int(placement.Start),
+					Size:  int(placement.Size),
+				})
+			}
+		case isLifecycleError(ret):
+			return b.extrasFailure("mig", "cannot read a GPU instance profile placements", ret)
+		}
+
+		extras.MIGProfiles = append(extras.MIGProfiles, capacity)
+	}
+
+	return true
+}
+
+// migProfileName names a GPU instance profile the way nvidia-smi does,
+// "<slices>g.<memory>gb" plus the variant suffix. The memory is the
+// profile's share of the GPU rounded up to eighths, applied to the GPU's
+// size in whole GiB, so a 1/8 share of an H200 reads 18gb rather than the
+// 16gb its MemorySizeMB alone would give. A zero deviceMemory (unknown)
+// falls back to the rounded MemorySizeMB.
+func migProfileName(id int, info nvml.GpuInstanceProfileInfo, deviceMemory uint64) string {
+	const gib = 1 << 30
+
+	gb := math.Round(float64(info.MemorySizeMB) / 1024)
+	if deviceMemory > 0 {
+		share := math.Ceil(float64(info.MemorySizeMB)*(1<<20)/float64(deviceMemory)*8) / 8
+		gb = math.Round(share * math.Ceil(float64(deviceMemory)/gib))
+	}
+
+	return fmt.Sprintf("%dg.%dgb%s", info.SliceCount, int(gb), migProfileSuffixes[id])
+}
+
 // giKey is the GPM retention key of one GPU instance.
 func giKey(parentUUID string, gi int) string {
 	return parentUUID + "/" + strconv.Itoa(gi)
@@ -264,14 +407,27 @@ func (b *Backend) enumerateMIG(
 	return groups, true
 }
 
-// gpmUtilization computes one GPU instance's utilization from the retained
-// previous sample and a fresh one, rotating the retention. A first sight (or
-// an invalidated retention) seeds the state and reports nil values. Reports
-// whether extras collection may continue.
+// gpmTarget names what a GPM window samples, for the failure logs: the
+// warn-once family and the sampled entity.
+type gpmTarget struct {
+	family  string
+	subject string
+}
+
+// gpmWindow computes one sampled entity's metric values over the window
+// between the retained previous sample and a fresh one taken through
+// sampleGet, rotating the retention under key. A first sight (or an
+// invalidated retention) seeds the state and reports nil values. A changed
+// fingerprint invalidates the retention. Reports whether extras collection
+// may continue.
 //
 //nolint:cyclop // the retention guards are a linear rule set
-func (b *Backend) gpmUtilization(dev device, key string, group migGroup) (*collect.MIGUtilization, bool) {
-	fingerprint := strings.Join(group.members, ",")
+func (b *Backend) gpmWindow(
+	key, fingerprint string,
+	target gpmTarget,
+	ids []uint32,
+	sampleGet func(nvml.GpmSample) nvml.Return,
+) (map[uint32]float64, bool) {
 	now := b.now()
 
 	state := b.gpm[key]
@@ -294,13 +450,13 @@ func (b *Backend) gpmUtilization(dev device, key string, group migGroup) (*colle
 
 	sample, ret := b.api.gpmSampleAlloc()
 	if ret != nvml.SUCCESS {
-		return nil, b.extrasFailure("mig-gpm", "cannot allocate a GPM sample", ret)
+		return nil, b.extrasFailure(target.family, "cannot allocate a GPM sample", ret)
 	}
 
-	if ret := b.api.gpmMigSampleGet(dev.raw(), group.gi, sample); ret != nvml.SUCCESS {
+	if ret := sampleGet(sample); ret != nvml.SUCCESS {
 		_ = b.api.gpmSampleFree(sample)
 
-		return nil, b.extrasFailure("mig-gpm", "cannot sample the GPU instance activity", ret)
+		return nil, b.extrasFailure(target.family, "cannot sample the "+target.subject+" activity", ret)
 	}
 
 	if state == nil {
@@ -313,7 +469,7 @@ func (b *Backend) gpmUtilization(dev device, key string, group migGroup) (*colle
 		return nil, true
 	}
 
-	util, ok := b.gpmMetrics(state.sample, sample)
+	values, ok := b.gpmMetrics(state.sample, sample, target, ids)
 	if !ok {
 		// a lifecycle-class failure: markLost already released the retained
 		// samples and emptied the map, so only the fresh local sample is
@@ -326,26 +482,24 @@ func (b *Backend) gpmUtilization(dev device, key string, group migGroup) (*colle
 	_ = b.api.gpmSampleFree(state.sample)
 	state.sample = sample
 	state.taken = now
-	state.last = util
+	state.last = values
 
-	return util, true
+	return values, true
 }
 
-// gpmMetrics diffs two samples into utilization values. A metric whose
-// per-metric status is not success, or whose value is not finite, stays nil.
-// Reports whether extras collection may continue: a lifecycle-class failure,
-// outer or per-metric, marks the backend lost and aborts.
-//
-//nolint:cyclop // one linear decode pass with per-metric classification
-func (b *Backend) gpmMetrics(prev, cur nvml.GpmSample) (*collect.MIGUtilization, bool) {
-	ids := []uint32{
-		gpmMetricGraphicsUtil, gpmMetricSMUtil, gpmMetricSMOccupancy,
-		gpmMetricAnyTensorUtil, gpmMetricPcieTxPerSec, gpmMetricPcieRxPerSec,
-	}
-
+// gpmMetrics diffs two samples into the values of the given metric ids. A
+// metric whose per-metric status is not success, or whose value is not
+// finite, is left out. Reports whether extras collection may continue: a
+// lifecycle-class failure, outer or per-metric, marks the backend lost and
+// aborts.
+func (b *Backend) gpmMetrics(
+	prev, cur nvml.GpmSample,
+	target gpmTarget,
+	ids []uint32,
+) (map[uint32]float64, bool) {
 	var metricsGet nvml.GpmMetricsGetType
 
-	metricsGet.NumMetrics = uint32(len(ids)) //nolint:gosec // G115: len(ids) is a small constant
+	metricsGet.NumMetrics = uint32(len(ids)) //nolint:gosec // G115: the id lists are small constants
 	metricsGet.Sample1 = prev
 	metricsGet.Sample2 = cur
 
@@ -354,10 +508,10 @@ func (b *Backend) gpmMetrics(prev, cur nvml.GpmSample) (*collect.MIGUtilization,
 	}
 
 	if ret := b.api.gpmMetricsGet(&metricsGet); ret != nvml.SUCCESS {
-		return nil, b.extrasFailure("mig-gpm", "cannot compute the GPU instance activity metrics", ret)
+		return nil, b.extrasFailure(target.family, "cannot compute the "+target.subject+" activity metrics", ret)
 	}
 
-	util := &collect.MIGUtilization{}
+	values := make(map[uint32]float64, len(ids))
 
 	for i, id := range ids {
 		metric := metricsGet.Metrics[i]
@@ -365,7 +519,8 @@ func (b *Backend) gpmMetrics(prev, cur nvml.GpmSample) (*collect.MIGUtilization,
 		metricRet := nvml.Return(metric.NvmlReturn) //nolint:gosec // G115: the field carries an nvmlReturn_t
 		if metricRet != nvml.SUCCESS {
 			if isLifecycleError(metricRet) {
-				return nil, b.extrasFailure("mig-gpm", "GPU instance activity metric hit a lifecycle error", metricRet)
+				return nil, b.extrasFailure(target.family,
+					target.subject+" activity metric hit a lifecycle error", metricRet)
 			}
 
 			continue
@@ -375,23 +530,48 @@ func (b *Backend) gpmMetrics(prev, cur nvml.GpmSample) (*collect.MIGUtilization,
 			continue
 		}
 
-		switch id {
-		case gpmMetricGraphicsUtil:
-			util.GraphicsActivityRatio = new(metric.Value / 100)
-		case gpmMetricSMUtil:
-			util.SMActivityRatio = new(metric.Value / 100)
-		case gpmMetricSMOccupancy:
-			util.SMOccupancyRatio = new(metric.Value / 100)
-		case gpmMetricAnyTensorUtil:
-			util.TensorActivityRatio = new(metric.Value / 100)
-		case gpmMetricPcieTxPerSec:
-			util.PCIeTXBytesPerSecond = new(metric.Value * gpmMibMultiplier)
-		case gpmMetricPcieRxPerSec:
-			util.PCIeRXBytesPerSecond = new(metric.Value * gpmMibMultiplier)
-		}
+		values[id] = metric.Value
+	}
+
+	return values, true
+}
+
+// migUtilization converts a GPU instance's GPM values into its utilization,
+// nil when the window produced none (a first sight).
+func migUtilization(values map[uint32]float64) *collect.MIGUtilization {
+	if values == nil {
+		return nil
+	}
+
+	return &collect.MIGUtilization{
+		GraphicsActivityRatio: gpmRatio(values, gpmMetricGraphicsUtil),
+		SMActivityRatio:       gpmRatio(values, gpmMetricSMUtil),
+		SMOccupancyRatio:      gpmRatio(values, gpmMetricSMOccupancy),
+		TensorActivityRatio:   gpmRatio(values, gpmMetricAnyTensorUtil),
+		PCIeTXBytesPerSecond:  gpmBytesPerSecond(values, gpmMetricPcieTxPerSec),
+		PCIeRXBytesPerSecond:  gpmBytesPerSecond(values, gpmMetricPcieRxPerSec),
+	}
+}
+
+// gpmRatio reads a percentage metric as a 0-1 ratio, nil when absent.
+func gpmRatio(values map[uint32]float64, id uint32) *float64 {
+	value, ok := values[id]
+	if !ok {
+		return nil
+	}
+
+	return new(value / 100)
+}
+
+// gpmBytesPerSecond reads a MiB/s bandwidth metric in bytes/s, nil when
+// absent.
+func gpmBytesPerSecond(values map[uint32]float64, id uint32) *float64 {
+	value, ok := values[id]
+	if !ok {
+		return nil
 	}
 
-	return util, true
+	return new(value * gpmMibMultiplier)
 }
 
 // freeGPMSamples releases every retained GPM sample. It must run before an
@@ -404,11 +584,12 @@ func (b *Backend) freeGPMSamples() {
 	}
 }
 
-// dropOrphanGPMStates frees the retained samples of GPU instances that
-// disappeared (a MIG reconfiguration between two cycles).
-func (b *Backend) dropOrphanGPMStates(seenGIs map[string]bool) {
+// dropOrphanGPMStates frees the retained samples of GPU instances and GPUs
+// that disappeared (a MIG reconfiguration between two cycles, or a GPU
+// switched into or out of MIG mode).
+func (b *Backend) dropOrphanGPMStates(seenKeys map[string]bool) {
 	for key, state := range b.gpm {
-		if seenGIs[key] {
+		if seenKeys[key] {
 			continue
 		}
 
@@ -462,13 +643,14 @@ func migAppID(id uint32) string {
 }
 
 // gpmAvailable reports whether the driver exports every GPM entry point the
-// sampling path uses. They are probed as a set because the family allocates a
-// sample through one export and releases it through another.
-func (b *Backend) gpmAvailable() bool {
+// sampling path uses, with sampleGet the sampling export of the caller's
+// kind. They are probed as a set because the family allocates a sample
+// through one export and releases it through another.
+func (b *Backend) gpmAvailable(sampleGet string) bool {
 	return b.avail.Load().hasAll(
 		"nvmlGpmSampleAlloc",
 		"nvmlGpmSampleFree",
-		"nvmlGpmMigSampleGet",
+		sampleGet,
 		"nvmlGpmMetricsGet",
 	)
 }
@@ -12,6 +12,8 @@ import (
 	"github.com/neilotoole/slogt/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
 )
 
 // gpmFake tracks the seam-level GPM calls: sample lifecycle accounting
@@ -19,7 +21,7 @@ import (
 // values keyed by metric id.
 type gpmFake struct {
 	allocs, frees int
-	migSampleRet  nvml.Return
+	sampleRet     nvml.Return
 	metricsRet    nvml.Return
 	values        map[uint32]float64
 }
@@ -35,8 +37,11 @@ func (g *gpmFake) install(api *nvmlAPI) {
 
 		return nvml.SUCCESS
 	}
+	api.gpmSampleGet = func(nvml.Device, nvml.GpmSample) nvml.Return {
+		return g.sampleRet
+	}
 	api.gpmMigSampleGet = func(nvml.Device, int, nvml.GpmSample) nvml.Return {
-		return g.migSampleRet
+		return g.sampleRet
 	}
 	api.gpmMetricsGet = func(metricsGet *nvml.GpmMetricsGetType) nvml.Return {
 		if g.metricsRet != nvml.SUCCESS {
@@ -70,7 +75,8 @@ func migDevice(uuid string, gi int) *mock.Device {
 }
 
 // migParent builds a MIG-enabled parent device serving the given MIG
-// handles, with GPM support flagged as requested.
+// handles, with GPM support flagged as requested. It offers no GPU instance
+// profile; migProfileTable stubs some.
 func migParent(gpmSupported bool, migs ...nvml.Device) *mock.Device {
 	dev := identityDevice()
 	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
@@ -94,10 +100,69 @@ func migParent(gpmSupported bool, migs ...nvml.Device) *mock.Device {
 
 		return nvml.GpmSupport{IsSupportedDevice: supported}, nvml.SUCCESS
 	}
+	dev.GetMemoryInfo_v2Func = func() (nvml.Memory_v2, nvml.Return) {
+		return nvml.Memory_v2{Total: 81559 * 1024 * 1024}, nvml.SUCCESS
+	}
+	dev.GetGpuInstanceProfileInfoFunc = func(int) (nvml.GpuInstanceProfileInfo, nvml.Return) {
+		return nvml.GpuInstanceProfileInfo{}, nvml.ERROR_NOT_SUPPORTED
+	}
 
 	return dev
 }
 
+// migProfileTable stubs part of an H100 80GB's GPU instance profiles on a
+// parent from migParent, with the remaining capacity of each by profile id.
+func migProfileTable(dev *mock.Device, remaining map[uint32]int) {
+	profiles := map[int]nvml.GpuInstanceProfileInfo{
+		nvml.GPU_INSTANCE_PROFILE_1_SLICE: {
+			Id: nvml.GPU_INSTANCE_PROFILE_1_SLICE, SliceCount: 1, InstanceCount: 7, MemorySizeMB: 9856,
+		},
+		nvml.GPU_INSTANCE_PROFILE_1_SLICE_REV1: {
+			Id: nvml.GPU_INSTANCE_PROFILE_1_SLICE_REV1, SliceCount: 1, InstanceCount: 1, MemorySizeMB: 9856,
+		},
+		nvml.GPU_INSTANCE_PROFILE_3_SLICE: {
+			Id: nvml.GPU_INSTANCE_PROFILE_3_SLICE, SliceCount: 3, InstanceCount: 2, MemorySizeMB: 40192,
+		},
+		nvml.GPU_INSTANCE_PROFILE_7_SLICE: {
+			Id: nvml.GPU_INSTANCE_PROFILE_7_SLICE, SliceCount: 7, InstanceCount: 1, MemorySizeMB: 80384,
+		},
+	}
+
+	dev.GetGpuInstanceProfileInfoFunc = func(id int) (nvml.GpuInstanceProfileInfo, nvml.Return) {
+		info, ok := profiles[id]
+		if !ok {
+			return info, nvml.ERROR_NOT_SUPPORTED
+		}
+
+		return info, nvml.SUCCESS
+	}
+	dev.GetGpuInstanceRemainingCapacityFunc = func(info *nvml.GpuInstanceProfileInfo) (int, nvml.Return) {
+		count, ok := remaining[info.Id]
+		if !ok {
+			return 0, nvml.ERROR_NO_PERMISSION
+		}
+
+		return count, nvml.SUCCESS
+	}
+	dev.GetGpuInstancePossiblePlacementsFunc = func(
+		info *nvml.GpuInstanceProfileInfo,
+	) ([]nvml.GpuInstancePlacement, nvml.Return) {
+		var placements []nvml.GpuInstancePlacement
+
+		size := info.SliceCount
+		if size == 3 || size == 7 {
+			// 3g and 7g instances span a power-of-two memory slice range
+			size++
+		}
+
+		for start := uint32(0); start+size <= 8 && len(placements) < int(info.InstanceCount); start += size {
+			placements = append(placements, nvml.GpuInstancePlacement{Start: start, Size: size})
+		}
+
+		return placements, nvml.SUCCESS
+	}
+}
+
 func migOpts() CollectOptions { return CollectOptions{MIG: true} }
 
 func TestMIGInventoryAndMemory(t *testing.T) {
@@ -128,6 +193,87 @@ func TestMIGInventoryAndMemory(t *testing.T) {
 	assert.Nil(t, first.Utilization, "no GPM support must mean inventory and memory only")
 }
 
+func TestMIGProfileCapacity(t *testing.T) {
+	t.Parallel()
+
+	// a GPU in MIG mode without any instance yet: the case capacity matters
+	// most for
+	parent := migParent(false)
+	migProfileTable(parent, map[uint32]int{
+		nvml.GPU_INSTANCE_PROFILE_1_SLICE:      7,
+		nvml.GPU_INSTANCE_PROFILE_1_SLICE_REV1: 1,
+		nvml.GPU_INSTANCE_PROFILE_7_SLICE:      1,
+	})
+
+	fake := &fakeAPI{devices: []nvml.Device{parent}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), migOpts())(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.MIG)
+
+	parentUUID := "11111111-2222-3333-4444-555555555555"
+
+	assert.Equal(t, []collect.MIGProfileCapacity{
+		{
+			ParentUUID: parentUUID, Profile: "1g.10gb", Total: 7, Remaining: new(7),
+			Placements: []collect.MIGPlacement{
+				{Start: 0, Size: 1}, {Start: 1, Size: 1}, {Start: 2, Size: 1}, {Start: 3, Size: 1},
+				{Start: 4, Size: 1}, {Start: 5, Size: 1}, {Start: 6, Size: 1},
+			},
+		},
+		{
+			ParentUUID: parentUUID, Profile: "3g.40gb", Total: 2,
+			Placements: []collect.MIGPlacement{{Start: 0, Size: 4}, {Start: 4, Size: 4}},
+		},
+		{
+			ParentUUID: parentUUID, Profile: "7g.80gb", Total: 1, Remaining: new(1),
+			Placements: []collect.MIGPlacement{{Start: 0, Size: 8}},
+		},
+		{
+			ParentUUID: parentUUID, Profile: "1g.10gb+me", Total: 1, Remaining: new(1),
+			Placements: []collect.MIGPlacement{{Start: 0, Size: 1}},
+		},
+	}, reading.Extras.MIGProfiles, "an unreadable remaining capacity is left unset, not zero")
+}
+
+func TestMIGProfileCapacityLifecycleAborts(t *testing.T) {
+	t.Parallel()
+
+	parent := migParent(false, migDevice("MIG-AAAAAAAA-1111-1111-1111-111111111111", 1))
+	migProfileTable(parent, nil)
+	parent.GetGpuInstanceRemainingCapacityFunc = func(*nvml.GpuInstanceProfileInfo) (int, nvml.Return) {
+		return 0, nvml.ERROR_GPU_IS_LOST
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{parent}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), migOpts())(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.MIGProfiles)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "a lost GPU during the capacity read must mark for re-init")
+}
+
+func TestMIGProfileName(t *testing.T) {
+	t.Parallel()
+
+	// an H200: 1/8 of its memory rounds up to 18gb, not the 16gb of the
+	// profile's own size
+	h200 := uint64(143771) * 1024 * 1024
+	oneSlice := nvml.GpuInstanceProfileInfo{SliceCount: 1, MemorySizeMB: 16384}
+
+	assert.Equal(t, "1g.18gb", migProfileName(nvml.GPU_INSTANCE_PROFILE_1_SLICE, oneSlice, h200))
+	assert.Equal(t, "1g.18gb+me", migProfileName(nvml.GPU_INSTANCE_PROFILE_1_SLICE_REV1, oneSlice, h200))
+	assert.Equal(t, "1g.18gb-me", migProfileName(nvml.GPU_INSTANCE_PROFILE_1_SLICE_NO_ME, oneSlice, h200))
+	assert.Equal(t, "1g.35gb", migProfileName(nvml.GPU_INSTANCE_PROFILE_1_SLICE_REV2,
+		nvml.GpuInstanceProfileInfo{SliceCount: 1, MemorySizeMB: 32768}, h200))
+	assert.Equal(t, "7g.141gb", migProfileName(nvml.GPU_INSTANCE_PROFILE_7_SLICE,
+		nvml.GpuInstanceProfileInfo{SliceCount: 7, MemorySizeMB: 143360}, h200))
+	assert.Equal(t, "1g.16gb", migProfileName(nvml.GPU_INSTANCE_PROFILE_1_SLICE, oneSlice, 0),
+		"an unknown GPU memory size falls back to the profile's own")
+}
+
 func TestMIGDisabledTouchesNoMIGGetter(t *testing.T) {
 	t.Parallel()
 
@@ -494,7 +640,7 @@ func TestMIGGPMSampleGetLifecycleAborts(t *testing.T) {
 	parent := migParent(true, migDevice("MIG-AAAAAAAA-1111-1111-1111-111111111111", 1))
 	fake := &fakeAPI{devices: []nvml.Device{parent}}
 
-	gpm := &gpmFake{migSampleRet: nvml.ERROR_GPU_IS_LOST}
+	gpm := &gpmFake{sampleRet: nvml.ERROR_GPU_IS_LOST}
 
 	api := fake.api()
 	gpm.install(&api)
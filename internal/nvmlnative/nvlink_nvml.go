@@ -0,0 +1,229 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"strconv"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// nvlinkRemoteTypes names the device types a link's far end can be.
+//
+//nolint:gochecknoglobals // lookup table
+var nvlinkRemoteTypes = map[nvml.IntNvLinkDeviceType]string{
+	nvml.NVLINK_DEVICE_TYPE_GPU:     collect.NVLinkRemoteGPU,
+	nvml.NVLINK_DEVICE_TYPE_SWITCH:  collect.NVLinkRemoteSwitch,
+	nvml.NVLINK_DEVICE_TYPE_IBMNPU:  collect.NVLinkRemoteIBMNPU,
+	nvml.NVLINK_DEVICE_TYPE_UNKNOWN: collect.NVLinkRemoteUnknown,
+}
+
+// nvlinkLegacyErrorFields are the per-link error counter fields, indexed by
+// link. The driver numbers them per link and only for links 0 to 11; later
+// links are read through the link-scoped data-link fields, which lack the
+// CRC data counter.
+//
+//nolint:gochecknoglobals // lookup table
+var nvlinkLegacyErrorFields = [...]struct{ crcFlit, crcData, replay, recovery uint32 }{
+	{
+		nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L0, nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L0,
+		nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L0, nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L0,
+	},
+	{
+		nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L1, nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L1,
+		nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L1, nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L1,
+	},
+	{
+		nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L2, nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L2,
+		nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L2, nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L2,
+	},
+	{
+		nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L3, nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L3,
+		nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L3, nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L3,
+	},
+	{
+		nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L4, nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L4,
+		nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L4, nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L4,
+	},
+	{
+		nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L5, nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L5,
+		nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L5, nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L5,
+	},
+	{
+		nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L6, nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L6,
+		nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L6, nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L6,
+	},
+	{
+		nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L7, nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L7,
+		nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L7, nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L7,
+	},
+	{
+		nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L8, nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L8,
+		nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L8, nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L8,
+	},
+	{
+		nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L9, nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L9,
+		nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L9, nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L9,
+	},
+	{
+		nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L10, nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L10,
+		nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L10, nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L10,
+	},
+	{
+		nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L11, nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L11,
+		nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L11, nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L11,
+	},
+}
+
+// nvlinkKiB converts the driver's throughput counters to bytes.
+const nvlinkKiB = 1024
+
+// collectNVLink appends one device's NVLink links. A GPU without NVLink
+// answers every link as not supported and contributes nothing; links the
+// GPU does not have, and every link on a driver without the state getter,
+// are skipped the same way. Reports whether extras collection may continue.
+func (b *Backend) collectNVLink(dev device, uuid string, extras *collect.Extras) bool {
+	var (
+		links   []collect.NVLink
+		indexes []int
+	)
+
+	for link := range nvml.NVLINK_MAX_LINKS {
+		state, ret := dev.GetNvLinkState(link)
+
+		//nolint:exhaustive // every other return is a plain failure
+		switch ret {
+		case nvml.SUCCESS:
+		case nvml.ERROR_NOT_SUPPORTED, nvml.ERROR_INVALID_ARGUMENT, nvml.ERROR_FUNCTION_NOT_FOUND:
+			continue
+		default:
+			return b.extrasFailure("nvlink", "cannot read the NVLink link state", ret)
+		}
+
+		entry := collect.NVLink{UUID: uuid, Link: strconv.Itoa(link), Active: state == nvml.FEATURE_ENABLED}
+		if entry.Active && !b.readNVLinkPeer(dev, link, &entry) {
+			return false
+		}
+
+		links = append(links, entry)
+		indexes = append(indexes, link)
+	}
+
+	if len(links) == 0 {
+		return true
+	}
+
+	if !b.readNVLinkCounters(dev, links, indexes) {
+		return false
+	}
+
+	extras.NVLink = append(extras.NVLink, links...)
+
+	return true
+}
+
+// readNVLinkPeer fills an active link's version and far end. Each reading
+// is optional on its own; a lifecycle-class failure aborts the pass.
+func (b *Backend) readNVLinkPeer(dev device, link int, entry *collect.NVLink) bool {
+	version, ret := dev.GetNvLinkVersion(link)
+	if ret == nvml.SUCCESS {
+		entry.Version = version
+	} else if !b.softRead("nvlink", "cannot read the NVLink version", ret) {
+		return false
+	}
+
+	pci, ret := dev.GetNvLinkRemotePciInfo(link)
+	if ret == nvml.SUCCESS {
+		entry.RemoteBusID = i8str(pci.BusId[:])
+	} else if !b.softRead("nvlink", "cannot read the NVLink remote PCI info", ret) {
+		return false
+	}
+
+	remoteType, ret := dev.GetNvLinkRemoteDeviceType(link)
+	if ret == nvml.SUCCESS {
+		entry.RemoteDeviceType = nvlinkRemoteTypes[remoteType]
+	} else if !b.softRead("nvlink", "cannot read the NVLink remote device type", ret) {
+		return false
+	}
+
+	return true
+}
+
+// readNVLinkCounters reads the throughput and error counters of the active
+// links in one batched field-value call; indexes holds each entry's link
+// index. A counter the driver cannot report for a link stays nil.
+func (b *Backend) readNVLinkCounters(dev device, links []collect.NVLink, indexes []int) bool {
+	var (
+		values  []nvml.FieldValue
+		targets []**float64
+	)
+
+	add := func(fieldID uint32, link int, target **float64) {
+		//nolint:gosec // G115: link indexes stay below NVLINK_MAX_LINKS
+		values = append(values, nvml.FieldValue{FieldId: fieldID, ScopeId: uint32(link)})
+		targets = append(targets, target)
+	}
+
+	for i := range links {
+		entry := &links[i]
+		if !entry.Active {
+			continue
+		}
+
+		link := indexes[i]
+
+		// the throughput fields are scoped to a link through the scope id
+		add(nvml.FI_DEV_NVLINK_THROUGHPUT_DATA_TX, link, &entry.TXBytes)
+		add(nvml.FI_DEV_NVLINK_THROUGHPUT_DATA_RX, link, &entry.RXBytes)
+
+		if link < len(nvlinkLegacyErrorFields) {
+			fields := nvlinkLegacyErrorFields[link]
+			add(fields.crcFlit, link, &entry.CRCFlitErrors)
+			add(fields.crcData, link, &entry.CRCDataErrors)
+			add(fields.replay, link, &entry.ReplayErrors)
+			add(fields.recovery, link, &entry.RecoveryErrors)
+
+			continue
+		}
+
+		add(nvml.FI_DEV_NVLINK_ERROR_DL_CRC, link, &entry.CRCFlitErrors)
+		add(nvml.FI_DEV_NVLINK_ERROR_DL_REPLAY, link, &entry.ReplayErrors)
+		add(nvml.FI_DEV_NVLINK_ERROR_DL_RECOVERY, link, &entry.RecoveryErrors)
+	}
+
+	if len(values) == 0 {
+		return true
+	}
+
+	ret := dev.GetFieldValues(values)
+	if ret != nvml.SUCCESS {
+		if ret == nvml.ERROR_NOT_SUPPORTED {
+			return true
+		}
+
+		return b.extrasFailure("nvlink", "cannot read the NVLink counters", ret)
+	}
+
+	for i, fieldValue := range values {
+		//nolint:gosec // G115: the field carries an nvmlReturn_t
+		if nvml.Return(fieldValue.NvmlReturn) != nvml.SUCCESS {
+			continue
+		}
+
+		value, ok := decodeFieldValue(fieldValue)
+		if !ok {
+			continue
+		}
+
+		if fieldValue.FieldId == nvml.FI_DEV_NVLINK_THROUGHPUT_DATA_TX ||
+			fieldValue.FieldId == nvml.FI_DEV_NVLINK_THROUGHPUT_DATA_RX {
+			value *= nvlinkKiB
+		}
+
+		*targets[i] = &value
+	}
+
+	return true
+}
@@ -0,0 +1,206 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// nvlinkDevice stubs a GPU whose links 0 and 12 are up, link 1 is down and
+// every other link is absent. Link 0 answers the per-link error fields, link
+// 12 the link-scoped ones. Every counter reads fieldId*100+link, except the
+// CRC data counter of link 0, which the driver does not report.
+func nvlinkDevice() *mock.Device {
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetNvLinkStateFunc = func(link int) (nvml.EnableState, nvml.Return) {
+		switch link {
+		case 0, 12:
+			return nvml.FEATURE_ENABLED, nvml.SUCCESS
+		case 1:
+			return nvml.FEATURE_DISABLED, nvml.SUCCESS
+		default:
+			return nvml.FEATURE_DISABLED, nvml.ERROR_INVALID_ARGUMENT
+		}
+	}
+	dev.GetNvLinkVersionFunc = func(int) (uint32, nvml.Return) { return 4, nvml.SUCCESS }
+	dev.GetNvLinkRemotePciInfoFunc = func(link int) (nvml.PciInfo, nvml.Return) {
+		if link == 12 {
+			return nvml.PciInfo{}, nvml.ERROR_NOT_SUPPORTED
+		}
+
+		var pci nvml.PciInfo
+		for i, c := range "00000000:07:00.0" {
+			pci.BusId[i] = int8(c)
+		}
+
+		return pci, nvml.SUCCESS
+	}
+	dev.GetNvLinkRemoteDeviceTypeFunc = func(link int) (nvml.IntNvLinkDeviceType, nvml.Return) {
+		if link == 12 {
+			return nvml.NVLINK_DEVICE_TYPE_SWITCH, nvml.SUCCESS
+		}
+
+		return nvml.NVLINK_DEVICE_TYPE_GPU, nvml.SUCCESS
+	}
+	dev.GetFieldValuesFunc = func(values []nvml.FieldValue) nvml.Return {
+		for i := range values {
+			if values[i].FieldId == nvml.FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_L0 {
+				values[i].NvmlReturn = uint32(nvml.ERROR_NOT_SUPPORTED)
+
+				continue
+			}
+
+			values[i].NvmlReturn = uint32(nvml.SUCCESS)
+			values[i].ValueType = uint32(nvml.VALUE_TYPE_UNSIGNED_LONG_LONG)
+			binary.LittleEndian.PutUint64(values[i].Value[:], uint64(values[i].FieldId)*100+uint64(values[i].ScopeId))
+		}
+
+		return nvml.SUCCESS
+	}
+
+	return dev
+}
+
+func TestExtrasNVLink(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeAPI{devices: []nvml.Device{nvlinkDevice()}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{NVLink: true})(t.Context())
+	require.NoError(t, err)
+
+	counter := func(fieldID uint32, link uint32) *float64 {
+		value := float64(fieldID*100 + link)
+
+		return &value
+	}
+
+	kib := func(fieldID uint32, link uint32) *float64 {
+		value := float64(fieldID*100+link) * 1024
+
+		return &value
+	}
+
+	uuid := "11111111-2222-3333-4444-555555555555"
+	assert.Equal(t, []collect.NVLink{
+		{
+			UUID: uuid, Link: "0", Active: true, Version: 4,
+			RemoteBusID: "00000000:07:00.0", RemoteDeviceType: collect.NVLinkRemoteGPU,
+			TXBytes:        kib(nvml.FI_DEV_NVLINK_THROUGHPUT_DATA_TX, 0),
+			RXBytes:        kib(nvml.FI_DEV_NVLINK_THROUGHPUT_DATA_RX, 0),
+			CRCFlitErrors:  counter(nvml.FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_L0, 0),
+			ReplayErrors:   counter(nvml.FI_DEV_NVLINK_REPLAY_ERROR_COUNT_L0, 0),
+			RecoveryErrors: counter(nvml.FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_L0, 0),
+		},
+		{UUID: uuid, Link: "1"},
+		{
+			UUID: uuid, Link: "12", Active: true, Version: 4,
+			RemoteDeviceType: collect.NVLinkRemoteSwitch,
+			TXBytes:          kib(nvml.FI_DEV_NVLINK_THROUGHPUT_DATA_TX, 12),
+			RXBytes:          kib(nvml.FI_DEV_NVLINK_THROUGHPUT_DATA_RX, 12),
+			CRCFlitErrors:    counter(nvml.FI_DEV_NVLINK_ERROR_DL_CRC, 12),
+			ReplayErrors:     counter(nvml.FI_DEV_NVLINK_ERROR_DL_REPLAY, 12),
+			RecoveryErrors:   counter(nvml.FI_DEV_NVLINK_ERROR_DL_RECOVERY, 12),
+		},
+	}, reading.Extras.NVLink)
+}
+
+func TestExtrasNVLinkWithoutNVLink(t *testing.T) {
+	t.Parallel()
+
+	// a PCIe-only GPU answers every link as not supported; the counters are
+	// then never read (the mock would panic on the unstubbed call)
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetNvLinkStateFunc = func(int) (nvml.EnableState, nvml.Return) {
+		return nvml.FEATURE_DISABLED, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{NVLink: true})(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.NVLink)
+}
+
+func TestExtrasNVLinkOnADriverWithoutTheGetters(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		missing []string
+		links   int
+	}{
+		"link state": {missing: []string{"nvmlDeviceGetNvLinkState"}},
+		"far end": {
+			missing: []string{
+				"nvmlDeviceGetNvLinkRemotePciInfo", "nvmlDeviceGetNvLinkRemotePciInfo_v2",
+				"nvmlDeviceGetNvLinkRemoteDeviceType",
+			},
+			links: 3,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			fake := &fakeAPI{devices: []nvml.Device{nvlinkDevice()}}
+			backend := newTestBackendWithoutExports(t, fake, tc.missing...)
+
+			reading, _, err := backend.QueryFunc(
+				resolveFields(t, "power.draw"), CollectOptions{NVLink: true})(t.Context())
+			require.NoError(t, err)
+			require.Len(t, reading.Extras.NVLink, tc.links)
+
+			for _, link := range reading.Extras.NVLink {
+				assert.Empty(t, link.RemoteBusID)
+				assert.Empty(t, link.RemoteDeviceType)
+			}
+
+			assert.False(t, backend.extrasWarned["nvlink"], "a missing export is absence, not a failure")
+		})
+	}
+}
+
+func TestExtrasNVLinkFailSoftly(t *testing.T) {
+	t.Parallel()
+
+	countersRet := nvml.ERROR_UNKNOWN
+
+	dev := nvlinkDevice()
+	dev.GetFieldValuesFunc = func([]nvml.FieldValue) nvml.Return { return countersRet }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{NVLink: true})
+
+	// the links are still reported, only without counters
+	reading, code, err := query(t.Context())
+	require.NoError(t, err, "a failed counter read must not fail the collection")
+	assert.Equal(t, 0, code)
+	require.Len(t, reading.Extras.NVLink, 3)
+
+	for _, link := range reading.Extras.NVLink {
+		assert.Nil(t, link.TXBytes)
+		assert.Nil(t, link.CRCFlitErrors)
+	}
+
+	assert.Equal(t, int64(0), fake.shutdowns.Load())
+
+	countersRet = nvml.ERROR_GPU_IS_LOST
+
+	_, _, err = query(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "a lifecycle error must mark the backend for re-init")
+}
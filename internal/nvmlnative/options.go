@@ -7,6 +7,10 @@ package nvmlnative
 type CollectOptions struct {
 	// ComputeApps enables the per-process query.
 	ComputeApps bool
+	// ProcessTypes extends the per-process query to graphics and MPS client
+	// processes and tags every entry with its type
+	// (--collect.compute-apps-types).
+	ProcessTypes bool
 	// PCIeThroughput enables per-GPU PCIe throughput sampling. Each reading
 	// blocks inside the driver for a 20ms sampling window per direction, so
 	// this is a deliberate opt-in (--collect.pcie-throughput).
@@ -16,4 +20,58 @@ type CollectOptions struct {
 	// MIG enables the per-MIG-instance readings. GPUs without MIG mode
 	// enabled contribute nothing beyond one mode probe.
 	MIG bool
+	// ProcessUtilization enables the per-process SM, memory, encoder and
+	// decoder utilization readings (--collect.compute-apps-utilization).
+	ProcessUtilization bool
+	// Accounting enables reading the driver's per-process accounting
+	// buffers into completed-process totals (--collect.accounting). GPUs
+	// with accounting mode disabled contribute nothing beyond one mode
+	// probe.
+	Accounting bool
+	// VideoSessions enables the per-session encoder and NvFBC readings
+	// (--collect.video-sessions).
+	VideoSessions bool
+	// NVLink enables the per-link NVLink state and counter readings
+	// (--collect.nvlink). GPUs without NVLink contribute nothing beyond one
+	// state probe per possible link.
+	NVLink bool
+	// GPM enables the whole-GPU profiling readings (--collect.gpm) on GPUs
+	// outside MIG mode. GPUs without GPM support (pre-Hopper) contribute
+	// nothing beyond one MIG mode and one support probe.
+	GPM bool
+	// TemperatureThresholds enables the per-GPU temperature threshold
+	// readings (--collect.temperature-thresholds). Thresholds a GPU does not
+	// define are left out.
+	TemperatureThresholds bool
+	// PCIeErrors enables the per-GPU PCIe replay and AER error counters
+	// (--collect.pcie-errors), read in one field value batch per GPU.
+	PCIeErrors bool
+	// Fans enables the per-fan speed, target speed and control policy
+	// readings (--collect.fans). GPUs without fans of their own contribute
+	// nothing beyond one fan count probe.
+	Fans bool
+	// DriverSamples enables draining the driver's power, utilization and
+	// clock sample buffers every cycle (--collect.driver-samples).
+	DriverSamples bool
+	// Capabilities enables the per-GPU static capability description
+	// (--collect.capabilities). A GPU is read once per NVML generation and
+	// served from the cache afterwards.
+	Capabilities bool
+	// Topology enables the GPU interconnect and NUMA affinity readings
+	// (--collect.topology). They are read once per device set, pairwise
+	// over all of its GPUs.
+	Topology bool
+	// ConfidentialComputing enables the system confidential computing state
+	// and the per-GPU protected memory split
+	// (--collect.confidential-computing). The system state is read once per
+	// cycle, before the devices.
+	ConfidentialComputing bool
+	// VGPU enables the per-instance readings of the vGPUs a hypervisor GPU
+	// hosts (--collect.vgpu). GPUs that host none contribute nothing beyond
+	// one listing probe.
+	VGPU bool
+	// Superchip enables the C2C link, module energy and memory placement
+	// readings of superchip GPUs (--collect.superchip). GPUs without C2C
+	// contribute nothing beyond one C2C mode probe.
+	Superchip bool
 }
@@ -0,0 +1,62 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// collectPCIeErrors appends one device's PCIe error counters, read in a
+// single field value batch. The replay counter field is what
+// nvmlDeviceGetPcieReplayCounter reads; the AER counts have no getter of
+// their own. A counter the GPU does not report is left nil, and a GPU
+// reporting none of them contributes no entry. Reports whether extras
+// collection may continue.
+func (b *Backend) collectPCIeErrors(dev device, uuid string, extras *collect.Extras) bool {
+	counters := collect.PCIeErrorCounters{UUID: uuid}
+
+	targets := []**float64{
+		&counters.Replays,
+		&counters.ReplayRollovers,
+		&counters.CorrectableErrors,
+		&counters.UncorrectableErrors,
+		&counters.FatalErrors,
+	}
+	values := []nvml.FieldValue{
+		{FieldId: nvml.FI_DEV_PCIE_REPLAY_COUNTER},
+		{FieldId: nvml.FI_DEV_PCIE_REPLAY_ROLLOVER_COUNTER},
+		{FieldId: nvml.FI_DEV_PCIE_COUNT_CORRECTABLE_ERRORS},
+		{FieldId: nvml.FI_DEV_PCIE_COUNT_NON_FATAL_ERROR},
+		{FieldId: nvml.FI_DEV_PCIE_COUNT_FATAL_ERROR},
+	}
+
+	ret := dev.GetFieldValues(values)
+	if ret != nvml.SUCCESS {
+		return b.softRead("pcie-errors", "cannot read the PCIe error counters", ret)
+	}
+
+	found := false
+
+	for i, fieldValue := range values {
+		//nolint:gosec // G115: the field carries an nvmlReturn_t
+		if nvml.Return(fieldValue.NvmlReturn) != nvml.SUCCESS {
+			continue
+		}
+
+		value, ok := decodeFieldValue(fieldValue)
+		if !ok {
+			continue
+		}
+
+		*targets[i] = &value
+		found = true
+	}
+
+	if found {
+		extras.PCIeErrors = append(extras.PCIeErrors, counters)
+	}
+
+	return true
+}
@@ -0,0 +1,112 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+func TestExtrasPCIeErrors(t *testing.T) {
+	t.Parallel()
+
+	readings := map[uint32]uint64{
+		nvml.FI_DEV_PCIE_REPLAY_COUNTER:           9,
+		nvml.FI_DEV_PCIE_REPLAY_ROLLOVER_COUNTER:  0,
+		nvml.FI_DEV_PCIE_COUNT_CORRECTABLE_ERRORS: 41,
+		nvml.FI_DEV_PCIE_COUNT_NON_FATAL_ERROR:    2,
+	}
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetFieldValuesFunc = func(values []nvml.FieldValue) nvml.Return {
+		for i := range values {
+			value, ok := readings[values[i].FieldId]
+			if !ok {
+				// a driver predating the fatal error count
+				values[i].NvmlReturn = uint32(nvml.ERROR_NOT_SUPPORTED)
+
+				continue
+			}
+
+			values[i].NvmlReturn = uint32(nvml.SUCCESS)
+			values[i].ValueType = uint32(nvml.VALUE_TYPE_UNSIGNED_LONG_LONG)
+			binary.LittleEndian.PutUint64(values[i].Value[:], value)
+		}
+
+		return nvml.SUCCESS
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{PCIeErrors: true})(t.Context())
+	require.NoError(t, err)
+
+	counter := func(v float64) *float64 { return &v }
+
+	assert.Equal(t, []collect.PCIeErrorCounters{{
+		UUID:                "11111111-2222-3333-4444-555555555555",
+		Replays:             counter(9),
+		ReplayRollovers:     counter(0),
+		CorrectableErrors:   counter(41),
+		UncorrectableErrors: counter(2),
+	}}, reading.Extras.PCIeErrors)
+}
+
+func TestExtrasPCIeErrorsUnsupported(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetFieldValuesFunc = func([]nvml.FieldValue) nvml.Return { return nvml.ERROR_NOT_SUPPORTED }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, code, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{PCIeErrors: true})(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, reading.Extras.PCIeErrors, "a GPU reporting no counter contributes no entry")
+}
+
+func TestExtrasPCIeErrorsOnADriverWithoutFieldValues(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackendWithoutExports(t, fake, "nvmlDeviceGetFieldValues")
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{PCIeErrors: true})(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.PCIeErrors)
+	assert.False(t, backend.extrasWarned["pcie-errors"], "a missing export is absence, not a failure")
+}
+
+func TestExtrasPCIeErrorsLifecycleAborts(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetFieldValuesFunc = func([]nvml.FieldValue) nvml.Return { return nvml.ERROR_GPU_IS_LOST }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{PCIeErrors: true})(t.Context())
+	require.NoError(t, err, "extras must never fail the collection")
+	assert.Empty(t, reading.Extras.PCIeErrors)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "the lifecycle error must mark the backend for re-init")
+}
@@ -0,0 +1,198 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+// powerSmoothingOverrideNotSet is NVML_POWER_SMOOTHING_ADMIN_OVERRIDE_NOT_SET,
+// the value an admin override field reads when no override is in place.
+const powerSmoothingOverrideNotSet = 0xFFFFFFFF
+
+// The power smoothing value formatters. The driver reports floors and
+// offsets in watts and ramp rates in milliwatts per second. No captured GPU
+// supports power smoothing, so the cell spellings are UNVERIFIED: they follow
+// the units of the returned headers and the (Yes/No) and (Enabled/Disabled)
+// hints of nvidia-smi --help-query-gpu.
+func smoothingWatts(v float64) string   { return fmt.Sprintf("%.2f W", v) }
+func smoothingRate(v float64) string    { return fmt.Sprintf("%.2f W/s", v/1000.0) }
+func smoothingPercent(v float64) string { return fmt.Sprintf("%.2f %%", v) }
+func smoothingMillis(v float64) string  { return fmt.Sprintf("%d ms", int64(v)) }
+func smoothingNumber(v float64) string  { return strconv.FormatInt(int64(v), 10) }
+func smoothingYesNo(v float64) string   { return yesNo(v != 0) }
+func smoothingOnOff(v float64) string   { return onOff(v != 0) }
+
+// adminOverride formats an admin override field, absent when not set.
+func adminOverride(format func(float64) string) func(float64) string {
+	return func(v float64) string {
+		if v == powerSmoothingOverrideNotSet {
+			return tokenNotAvailable
+		}
+
+		return format(v)
+	}
+}
+
+// powerSmoothingDeviceEntries are the power smoothing fields read in the
+// device scope.
+//
+//nolint:gochecknoglobals // lookup table
+var powerSmoothingDeviceEntries = []fieldValueEntry{
+	{"power_smoothing.tmp_floor", nvml.FI_PWR_SMOOTHING_APPLIED_TMP_FLOOR, 0, false, smoothingWatts},
+	{"power_smoothing.tmp_ceil", nvml.FI_PWR_SMOOTHING_APPLIED_TMP_CEIL, 0, false, smoothingWatts},
+	{
+		"power_smoothing.hw_lifetime_remaining_percent",
+		nvml.FI_PWR_SMOOTHING_HW_CIRCUITRY_PERCENT_LIFETIME_REMAINING, 0, false, smoothingPercent,
+	},
+	{"power_smoothing.enabled", nvml.FI_PWR_SMOOTHING_ENABLED, 0, false, smoothingYesNo},
+	{
+		"power_smoothing.delayed_power_smoothing_supported",
+		nvml.FI_PWR_SMOOTHING_DELAYED_PWR_SMOOTHING_SUPPORTED, 0, false, smoothingYesNo,
+	},
+	{
+		"power_smoothing.soc_power_smoothing_enabled",
+		nvml.FI_PWR_SMOOTHING_SOC_POWER_SMOOTHING_ENABLED, 0, false, smoothingOnOff,
+	},
+	{"power_smoothing.priv_level", nvml.FI_PWR_SMOOTHING_PRIV_LVL, 0, false, smoothingNumber},
+	{"power_smoothing.imm_ramp_down", nvml.FI_PWR_SMOOTHING_IMM_RAMP_DOWN_ENABLED, 0, false, smoothingOnOff},
+	{
+		"power_smoothing.max_percent_tmp_floor",
+		nvml.FI_PWR_SMOOTHING_MAX_PERCENT_TMP_FLOOR_SETTING, 0, false, smoothingPercent,
+	},
+	{
+		"power_smoothing.min_percent_tmp_floor",
+		nvml.FI_PWR_SMOOTHING_MIN_PERCENT_TMP_FLOOR_SETTING, 0, false, smoothingPercent,
+	},
+	{"power_smoothing.primary_power_floor", nvml.FI_PWR_SMOOTHING_PRIMARY_POWER_FLOOR, 0, false, smoothingWatts},
+	{"power_smoothing.secondary_power_floor", nvml.FI_PWR_SMOOTHING_SECONDARY_POWER_FLOOR, 0, false, smoothingWatts},
+	{
+		"power_smoothing.min_primary_floor_activation_offset",
+		nvml.FI_PWR_SMOOTHING_MIN_PRIMARY_FLOOR_ACT_OFFSET, 0, false, smoothingWatts,
+	},
+	{
+		"power_smoothing.min_primary_floor_activation_point",
+		nvml.FI_PWR_SMOOTHING_MIN_PRIMARY_FLOOR_ACT_POINT, 0, false, smoothingWatts,
+	},
+	{"power_smoothing.window_multiplier", nvml.FI_PWR_SMOOTHING_WINDOW_MULTIPLIER, 0, false, smoothingMillis},
+	{
+		"power_smoothing.num_preset_profiles",
+		nvml.FI_PWR_SMOOTHING_MAX_NUM_PRESET_PROFILES, 0, false, smoothingNumber,
+	},
+	{powerSmoothingActiveProfile, nvml.FI_PWR_SMOOTHING_ACTIVE_PRESET_PROFILE, 0, false, smoothingNumber},
+	{
+		"power_smoothing.admin_override.percent_tmp_floor",
+		nvml.FI_PWR_SMOOTHING_ADMIN_OVERRIDE_PERCENT_TMP_FLOOR, 0, false, adminOverride(smoothingPercent),
+	},
+	{
+		"power_smoothing.admin_override.ramp_up_rate",
+		nvml.FI_PWR_SMOOTHING_ADMIN_OVERRIDE_RAMP_UP_RATE, 0, false, adminOverride(smoothingRate),
+	},
+	{
+		"power_smoothing.admin_override.ramp_down_rate",
+		nvml.FI_PWR_SMOOTHING_ADMIN_OVERRIDE_RAMP_DOWN_RATE, 0, false, adminOverride(smoothingRate),
+	},
+	{
+		"power_smoothing.admin_override.ramp_down_hysteresis",
+		nvml.FI_PWR_SMOOTHING_ADMIN_OVERRIDE_RAMP_DOWN_HYST_VAL, 0, false, adminOverride(smoothingMillis),
+	},
+	{
+		"power_smoothing.admin_override.secondary_power_floor",
+		nvml.FI_PWR_SMOOTHING_ADMIN_OVERRIDE_SECONDARY_POWER_FLOOR, 0, false, adminOverride(smoothingWatts),
+	},
+	{
+		"power_smoothing.admin_override.primary_floor_act_window_multiplier",
+		nvml.FI_PWR_SMOOTHING_ADMIN_OVERRIDE_PRIMARY_FLOOR_ACT_WIN_MULT, 0, false, adminOverride(smoothingNumber),
+	},
+	{
+		"power_smoothing.admin_override.primary_floor_tar_window_multiplier",
+		nvml.FI_PWR_SMOOTHING_ADMIN_OVERRIDE_PRIMARY_FLOOR_TAR_WIN_MULT, 0, false, adminOverride(smoothingNumber),
+	},
+	{
+		"power_smoothing.admin_override.primary_floor_act_offset",
+		nvml.FI_PWR_SMOOTHING_ADMIN_OVERRIDE_PRIMARY_FLOOR_ACT_OFFSET, 0, false, adminOverride(smoothingWatts),
+	},
+}
+
+// powerSmoothingProfileEntries are the current profile's fields, read in the
+// scope of the active preset profile.
+//
+//nolint:gochecknoglobals // lookup table
+var powerSmoothingProfileEntries = []fieldValueEntry{
+	{
+		"power_smoothing.curr_profile.percent_tmp_floor",
+		nvml.FI_PWR_SMOOTHING_PROFILE_PERCENT_TMP_FLOOR, 0, false, smoothingPercent,
+	},
+	{"power_smoothing.curr_profile.ramp_up_rate", nvml.FI_PWR_SMOOTHING_PROFILE_RAMP_UP_RATE, 0, false, smoothingRate},
+	{
+		"power_smoothing.curr_profile.ramp_down_rate",
+		nvml.FI_PWR_SMOOTHING_PROFILE_RAMP_DOWN_RATE, 0, false, smoothingRate,
+	},
+	{
+		"power_smoothing.curr_profile.ramp_down_hysteresis",
+		nvml.FI_PWR_SMOOTHING_PROFILE_RAMP_DOWN_HYST_VAL, 0, false, smoothingMillis,
+	},
+	{
+		"power_smoothing.curr_profile.secondary_power_floor",
+		nvml.FI_PWR_SMOOTHING_PROFILE_SECONDARY_POWER_FLOOR, 0, false, smoothingWatts,
+	},
+	{
+		"power_smoothing.curr_profile.primary_floor_act_window_multiplier",
+		nvml.FI_PWR_SMOOTHING_PROFILE_PRIMARY_FLOOR_ACT_WIN_MULT, 0, false, smoothingNumber,
+	},
+	{
+		"power_smoothing.curr_profile.primary_floor_tar_window_multiplier",
+		nvml.FI_PWR_SMOOTHING_PROFILE_PRIMARY_FLOOR_TAR_WIN_MULT, 0, false, smoothingNumber,
+	},
+	{
+		"power_smoothing.curr_profile.primary_floor_act_offset",
+		nvml.FI_PWR_SMOOTHING_PROFILE_PRIMARY_FLOOR_ACT_OFFSET, 0, false, smoothingWatts,
+	},
+}
+
+const powerSmoothingActiveProfile = "power_smoothing.active_profile"
+
+// collectPowerSmoothing fills the power_smoothing.* fields. The current
+// profile's fields are per-profile readings, so they take a second batch
+// scoped by the active profile, which the first batch reads even when it was
+// not requested itself. Without an active profile to scope by, the profile
+// fields share its outcome.
+func (b *Backend) collectPowerSmoothing(dev device, coll *devCollector, reqs plan) {
+	var deviceWanted, profileWanted []fieldValueEntry
+
+	for _, entry := range powerSmoothingProfileEntries {
+		if reqs.want(entry.field) {
+			profileWanted = append(profileWanted, entry)
+		}
+	}
+
+	for _, entry := range powerSmoothingDeviceEntries {
+		if reqs.want(entry.field) || (entry.field == powerSmoothingActiveProfile && len(profileWanted) > 0) {
+			deviceWanted = append(deviceWanted, entry)
+		}
+	}
+
+	decoded := b.readFieldValues(dev, coll, deviceWanted)
+
+	if len(profileWanted) == 0 {
+		return
+	}
+
+	active, ok := decoded[powerSmoothingActiveProfile]
+	if !ok {
+		for _, entry := range profileWanted {
+			coll.values[entry.field] = coll.values[powerSmoothingActiveProfile]
+		}
+
+		return
+	}
+
+	for i := range profileWanted {
+		profileWanted[i].scope = uint32(active)
+	}
+
+	b.readFieldValues(dev, coll, profileWanted)
+}
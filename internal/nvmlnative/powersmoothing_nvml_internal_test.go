@@ -0,0 +1,96 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// powerSmoothingDevice serves the power smoothing field values: preset
+// profile 2 is active, and a profile field reads its scope times 1000, so a
+// read in the wrong scope shows.
+func powerSmoothingDevice(scopes map[uint32]uint32) *mock.Device {
+	dev := identityDevice()
+	dev.GetFieldValuesFunc = func(values []nvml.FieldValue) nvml.Return {
+		for i := range values {
+			var value uint64
+
+			switch values[i].FieldId {
+			case nvml.FI_PWR_SMOOTHING_ACTIVE_PRESET_PROFILE:
+				value = 2
+			case nvml.FI_PWR_SMOOTHING_ENABLED:
+				value = 1
+			case nvml.FI_PWR_SMOOTHING_ADMIN_OVERRIDE_RAMP_UP_RATE:
+				value = powerSmoothingOverrideNotSet
+			case nvml.FI_PWR_SMOOTHING_PROFILE_RAMP_UP_RATE:
+				scopes[values[i].FieldId] = values[i].ScopeId
+				value = uint64(values[i].ScopeId) * 1000
+			default:
+				values[i].NvmlReturn = uint32(nvml.ERROR_NOT_SUPPORTED)
+
+				continue
+			}
+
+			values[i].NvmlReturn = uint32(nvml.SUCCESS)
+			values[i].ValueType = uint32(nvml.VALUE_TYPE_UNSIGNED_LONG_LONG)
+			binary.LittleEndian.PutUint64(values[i].Value[:], value)
+		}
+
+		return nvml.SUCCESS
+	}
+
+	return dev
+}
+
+func TestPowerSmoothingProfileFieldsReadTheActiveProfile(t *testing.T) {
+	t.Parallel()
+
+	scopes := map[uint32]uint32{}
+
+	fake := &fakeAPI{devices: []nvml.Device{powerSmoothingDevice(scopes)}}
+	backend := newTestBackend(t, fake)
+
+	// the active profile itself is not requested, the profile field still
+	// needs it for its scope
+	fields := resolveFields(t, "power_smoothing.enabled,power_smoothing.curr_profile.ramp_up_rate,"+
+		"power_smoothing.admin_override.ramp_up_rate,power_smoothing.tmp_floor")
+
+	reading, _, err := backend.QueryFunc(fields, CollectOptions{})(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), scopes[nvml.FI_PWR_SMOOTHING_PROFILE_RAMP_UP_RATE])
+	assert.Equal(t, "Yes", cellValue(t, reading.Table, "power_smoothing.enabled"))
+	assert.Equal(t, "2.00 W/s", cellValue(t, reading.Table, "power_smoothing.curr_profile.ramp_up_rate"))
+	assert.Equal(t, "[N/A]", cellValue(t, reading.Table, "power_smoothing.admin_override.ramp_up_rate"))
+	assert.Equal(t, "[N/A]", cellValue(t, reading.Table, "power_smoothing.tmp_floor"))
+}
+
+func TestPowerSmoothingProfileFieldsWithoutActiveProfile(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetFieldValuesFunc = func(values []nvml.FieldValue) nvml.Return {
+		for i := range values {
+			// a GPU without power smoothing: no profile to scope by, so the
+			// profile fields must not be read at all
+			require.NotEqual(t, uint32(nvml.FI_PWR_SMOOTHING_PROFILE_RAMP_UP_RATE), values[i].FieldId)
+
+			values[i].NvmlReturn = uint32(nvml.ERROR_NOT_SUPPORTED)
+		}
+
+		return nvml.SUCCESS
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power_smoothing.curr_profile.ramp_up_rate"), CollectOptions{})(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "[N/A]", cellValue(t, reading.Table, "power_smoothing.curr_profile.ramp_up_rate"))
+}
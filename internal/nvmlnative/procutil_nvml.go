@@ -0,0 +1,113 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// procUtilSum accumulates one process's samples within a window.
+type procUtilSum struct {
+	samples              int
+	sm, memory, enc, dec uint64
+}
+
+// collectProcessUtilization appends one device's per-process utilization,
+// averaged over the driver samples newer than the previous collection. The
+// first cycle that sees a device only opens its window: the driver keeps a
+// buffer of older samples, and averaging all of them would report a window
+// unrelated to the scrape interval. A device that cannot report per-process
+// utilization (pre-Maxwell, some vGPU guests), or a driver without the
+// getter, is skipped silently. Reports whether extras collection may
+// continue.
+func (b *Backend) collectProcessUtilization(dev device, uuid string, extras *collect.Extras) bool {
+	if b.procUtilSeen == nil {
+		b.procUtilSeen = map[string]uint64{}
+	}
+
+	lastSeen, known := b.procUtilSeen[uuid]
+	if !known {
+		// sample timestamps are CPU time in microseconds since the epoch
+		b.procUtilSeen[uuid] = uint64(b.now().UnixMicro())
+
+		return true
+	}
+
+	samples, ret := dev.GetProcessUtilization(lastSeen)
+
+	//nolint:exhaustive // every other return is a plain failure
+	switch ret {
+	case nvml.SUCCESS:
+	case nvml.ERROR_NOT_FOUND:
+		// no sample newer than the window start: nothing ran on the GPU
+		return true
+	case nvml.ERROR_NOT_SUPPORTED, nvml.ERROR_FUNCTION_NOT_FOUND:
+		return true
+	default:
+		return b.extrasFailure("process-utilization", "cannot read the per-process utilization", ret)
+	}
+
+	sums := map[uint32]*procUtilSum{}
+
+	for _, sample := range samples {
+		if sample.TimeStamp <= lastSeen {
+			continue
+		}
+
+		b.procUtilSeen[uuid] = max(b.procUtilSeen[uuid], sample.TimeStamp)
+
+		sum := sums[sample.Pid]
+		if sum == nil {
+			sum = &procUtilSum{}
+			sums[sample.Pid] = sum
+		}
+
+		sum.samples++
+		sum.sm += uint64(sample.SmUtil)
+		sum.memory += uint64(sample.MemUtil)
+		sum.enc += uint64(sample.EncUtil)
+		sum.dec += uint64(sample.DecUtil)
+	}
+
+	pids := make([]uint32, 0, len(sums))
+	for pid := range sums {
+		pids = append(pids, pid)
+	}
+
+	slices.Sort(pids)
+
+	for _, pid := range pids {
+		sum := sums[pid]
+		ratio := func(total uint64) float64 {
+			return float64(total) / float64(sum.samples) / 100
+		}
+
+		extras.ProcessUtilization = append(extras.ProcessUtilization, collect.ProcessUtilization{
+			UUID:         uuid,
+			PID:          strconv.FormatUint(uint64(pid), 10),
+			ProcessName:  b.processName(pid),
+			SMRatio:      ratio(sum.sm),
+			MemoryRatio:  ratio(sum.memory),
+			EncoderRatio: ratio(sum.enc),
+			DecoderRatio: ratio(sum.dec),
+		})
+	}
+
+	return true
+}
+
+// dropOrphanProcUtilWindows forgets the windows of GPUs that disappeared, so
+// one that returns (after a reset) starts over instead of averaging across
+// its absence.
+func (b *Backend) dropOrphanProcUtilWindows(seenGPUs map[string]bool) {
+	for uuid := range b.procUtilSeen {
+		if !seenGPUs[uuid] {
+			delete(b.procUtilSeen, uuid)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// driverSampleKey identifies one GPU's sample buffer of one kind.
+type driverSampleKey struct {
+	uuid     string
+	sampling nvml.SamplingType
+}
+
+// driverSampleBuffers lists the drained sample buffers with the factor that
+// brings their values to base units: the driver samples power in milliwatts,
+// utilization in percent and clocks in MHz.
+//
+//nolint:gochecknoglobals // lookup table
+var driverSampleBuffers = []struct {
+	sampling nvml.SamplingType
+	kind     string
+	scale    float64
+}{
+	{nvml.TOTAL_POWER_SAMPLES, collect.DriverSamplePower, 1.0 / 1000},
+	{nvml.GPU_UTILIZATION_SAMPLES, collect.DriverSampleGPUUtilization, 1.0 / 100},
+	{nvml.MEMORY_UTILIZATION_SAMPLES, collect.DriverSampleMemoryUtilization, 1.0 / 100},
+	{nvml.PROCESSOR_CLK_SAMPLES, collect.DriverSampleGraphicsClock, 1e6},
+	{nvml.MEMORY_CLK_SAMPLES, collect.DriverSampleMemoryClock, 1e6},
+}
+
+// collectDriverSamples drains one device's driver sample buffers, summarizing
+// the samples newer than the previous collection per kind, and folds the
+// power samples into the device's power histogram. The driver refreshes the
+// buffers far more often than any scrape interval, so a window catches the
+// short spikes a single reading misses. As with the per-process utilization,
+// the first cycle that sees a buffer only opens its window. A buffer the
+// device does not keep, or any on a driver without the getter, is skipped
+// silently. Reports whether extras collection may continue.
+func (b *Backend) collectDriverSamples(dev device, uuid string, extras *collect.Extras) bool {
+	if b.samplesSeen == nil {
+		b.samplesSeen = map[driverSampleKey]uint64{}
+		b.powerHistograms = map[string]*collect.NativeHistogram{}
+	}
+
+	now := b.now()
+
+	for _, buffer := range driverSampleBuffers {
+		key := driverSampleKey{uuid: uuid, sampling: buffer.sampling}
+
+		lastSeen, known := b.samplesSeen[key]
+		if !known {
+			// sample timestamps are CPU time in microseconds since the epoch
+			b.samplesSeen[key] = uint64(now.UnixMicro())
+
+			continue
+		}
+
+		valueType, samples, ret := dev.GetSamples(buffer.sampling, lastSeen)
+
+		//nolint:exhaustive // every other return is a plain failure
+		switch ret {
+		case nvml.SUCCESS:
+		case nvml.ERROR_NOT_FOUND, nvml.ERROR_NOT_SUPPORTED, nvml.ERROR_FUNCTION_NOT_FOUND:
+			// no sample newer than the window start, or no such buffer
+			continue
+		default:
+			if !b.extrasFailure("driver-samples", "cannot read the driver samples", ret) {
+				return false
+			}
+
+			continue
+		}
+
+		values := make([]float64, 0, len(samples))
+
+		for _, sample := range samples {
+			if sample.TimeStamp <= lastSeen {
+				continue
+			}
+
+			b.samplesSeen[key] = max(b.samplesSeen[key], sample.TimeStamp)
+
+			value, ok := decodeFieldValue(nvml.FieldValue{
+				ValueType: uint32(valueType), //nolint:gosec // G115: a small enum
+				Value:     sample.SampleValue,
+			})
+			if !ok {
+				continue
+			}
+
+			values = append(values, value*buffer.scale)
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+
+		extras.DriverSamples = append(extras.DriverSamples,
+			collect.NewDriverSampleWindow(uuid, buffer.kind, values))
+
+		if buffer.sampling == nvml.TOTAL_POWER_SAMPLES {
+			b.observePower(uuid, values, now)
+		}
+	}
+
+	if histogram := b.powerHistograms[uuid]; histogram != nil {
+		extras.PowerHistograms = append(extras.PowerHistograms, histogram.Clone())
+	}
+
+	return true
+}
+
+// observePower folds one window's power samples into the GPU's histogram.
+func (b *Backend) observePower(uuid string, watts []float64, now time.Time) {
+	histogram := b.powerHistograms[uuid]
+	if histogram == nil {
+		histogram = &collect.NativeHistogram{UUID: uuid}
+		b.powerHistograms[uuid] = histogram
+	}
+
+	for _, value := range watts {
+		histogram.Observe(value, now)
+	}
+}
+
+// dropOrphanDriverSamples forgets the windows and histograms of GPUs that
+// disappeared: one that returns (after a reset) starts over, its histogram
+// included, rather than reporting a window across its absence.
+func (b *Backend) dropOrphanDriverSamples(seenGPUs map[string]bool) {
+	for key := range b.samplesSeen {
+		if !seenGPUs[key.uuid] {
+			delete(b.samplesSeen, key)
+		}
+	}
+
+	for uuid := range b.powerHistograms {
+		if !seenGPUs[uuid] {
+			delete(b.powerHistograms, uuid)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// uintSample builds one unsigned-int driver sample.
+func uintSample(timestamp uint64, value uint32) nvml.Sample {
+	sample := nvml.Sample{TimeStamp: timestamp}
+	binary.LittleEndian.PutUint32(sample.SampleValue[:], value)
+
+	return sample
+}
+
+func TestExtrasDriverSamples(t *testing.T) {
+	t.Parallel()
+
+	var requested []uint64
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetSamplesFunc = func(
+		sampling nvml.SamplingType,
+		lastSeen uint64,
+	) (nvml.ValueType, []nvml.Sample, nvml.Return) {
+		switch sampling {
+		case nvml.TOTAL_POWER_SAMPLES:
+			requested = append(requested, lastSeen)
+
+			// the driver returns its whole buffer, older samples included
+			return nvml.VALUE_TYPE_UNSIGNED_INT, []nvml.Sample{
+				uintSample(lastSeen-10, 999000),
+				uintSample(lastSeen+100, 250000),
+				uintSample(lastSeen+200, 640000),
+				uintSample(lastSeen+300, 180000),
+			}, nvml.SUCCESS
+		case nvml.GPU_UTILIZATION_SAMPLES:
+			return nvml.VALUE_TYPE_UNSIGNED_INT, []nvml.Sample{uintSample(lastSeen+1, 40)}, nvml.SUCCESS
+		case nvml.MEMORY_UTILIZATION_SAMPLES:
+			return nvml.VALUE_TYPE_UNSIGNED_INT, nil, nvml.ERROR_NOT_FOUND
+		default:
+			return nvml.VALUE_TYPE_UNSIGNED_INT, nil, nvml.ERROR_NOT_SUPPORTED
+		}
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	current := time.UnixMicro(1_000_000)
+	backend.now = func() time.Time { return current }
+
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{DriverSamples: true})
+
+	// first sight opens the windows and reads nothing
+	reading, _, err := query(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.DriverSamples)
+	assert.Empty(t, reading.Extras.PowerHistograms)
+	assert.Empty(t, requested)
+
+	reading, _, err = query(t.Context())
+	require.NoError(t, err)
+
+	uuid := "11111111-2222-3333-4444-555555555555"
+	assert.Equal(t, []collect.DriverSampleWindow{
+		{UUID: uuid, Kind: collect.DriverSamplePower, Min: 180, Max: 640, Avg: 356.6666666666667, Samples: 3},
+		{UUID: uuid, Kind: collect.DriverSampleGPUUtilization, Min: 0.4, Max: 0.4, Avg: 0.4, Samples: 1},
+	}, reading.Extras.DriverSamples)
+	require.Len(t, reading.Extras.PowerHistograms, 1)
+	assert.Equal(t, uint64(3), reading.Extras.PowerHistograms[0].Count)
+
+	// the next window starts at the newest sample consumed, and the
+	// histogram keeps counting
+	reading, _, err = query(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1_000_000, 1_000_300}, requested)
+	require.Len(t, reading.Extras.PowerHistograms, 1)
+	assert.Equal(t, uint64(6), reading.Extras.PowerHistograms[0].Count)
+}
+
+func TestExtrasDriverSamplesOnADriverWithoutTheGetter(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackendWithoutExports(t, fake, "nvmlDeviceGetSamples")
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{DriverSamples: true})
+
+	// the first cycle only opens the windows
+	for range 2 {
+		reading, _, err := query(t.Context())
+		require.NoError(t, err)
+		assert.Empty(t, reading.Extras.DriverSamples)
+	}
+
+	assert.False(t, backend.extrasWarned["driver-samples"], "a missing export is absence, not a failure")
+}
+
+func TestExtrasDriverSamplesLifecycleAborts(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetSamplesFunc = func(nvml.SamplingType, uint64) (nvml.ValueType, []nvml.Sample, nvml.Return) {
+		return nvml.VALUE_TYPE_UNSIGNED_INT, nil, nvml.ERROR_GPU_IS_LOST
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{DriverSamples: true})
+
+	_, _, err := query(t.Context())
+	require.NoError(t, err)
+
+	reading, _, err := query(t.Context())
+	require.NoError(t, err, "extras must never fail the collection")
+	assert.Empty(t, reading.Extras.DriverSamples)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "the lifecycle error must mark the backend for re-init")
+}
@@ -0,0 +1,139 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"strconv"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// maxC2CLinks bounds the per-link batch, well above the links any superchip
+// has, so a corrupt link count cannot grow it without limit.
+const maxC2CLinks = 32
+
+// c2cLinkFields are the per-link field ids, read with the link index as the
+// scope.
+//
+//nolint:gochecknoglobals // lookup table
+var c2cLinkFields = []uint32{
+	nvml.FI_DEV_C2C_LINK_GET_STATUS,
+	nvml.FI_DEV_C2C_LINK_GET_MAX_BW,
+	nvml.FI_DEV_C2C_LINK_ERROR_INTR,
+	nvml.FI_DEV_C2C_LINK_ERROR_REPLAY,
+	nvml.FI_DEV_C2C_LINK_ERROR_REPLAY_B2B,
+	nvml.FI_DEV_C2C_LINK_POWER_STATE,
+}
+
+// collectSuperchip appends one device's superchip readings. The C2C link
+// count is the probe, read in one field value batch with the module energy:
+// a GPU without C2C links (every PCIe card) is skipped silently after it.
+// The per-link readings follow in a second batch. Reports whether extras
+// collection may continue.
+func (b *Backend) collectSuperchip(dev device, uuid string, extras *collect.Extras) bool {
+	values := []nvml.FieldValue{
+		{FieldId: nvml.FI_DEV_C2C_LINK_COUNT},
+		{FieldId: nvml.FI_DEV_ENERGY, ScopeId: nvml.POWER_SCOPE_MODULE},
+	}
+	if !b.superchipFields(dev, values) {
+		return false
+	}
+
+	links := fieldReading(values[0], 1)
+	if links == nil || *links <= 0 {
+		return true
+	}
+
+	entry := collect.Superchip{
+		UUID:               uuid,
+		Links:              links,
+		ModuleEnergyJoules: fieldReading(values[1], 1.0/1000),
+	}
+
+	if !b.collectC2CLinks(dev, min(int(*links), maxC2CLinks), &entry) {
+		return false
+	}
+
+	node, ret := dev.GetNumaNodeId()
+	if !b.softRead("superchip", "cannot read the GPU memory NUMA node", ret) {
+		return false
+	}
+
+	if ret == nvml.SUCCESS {
+		numaNode := float64(node)
+		entry.MemoryNUMANode = &numaNode
+	}
+
+	extras.Superchips = append(extras.Superchips, entry)
+
+	return true
+}
+
+// collectC2CLinks fills the entry's per-link readings, every link's fields in
+// one batch. Reports whether extras collection may continue.
+func (b *Backend) collectC2CLinks(dev device, links int, entry *collect.Superchip) bool {
+	values := make([]nvml.FieldValue, 0, links*len(c2cLinkFields))
+
+	for link := range links {
+		for _, field := range c2cLinkFields {
+			//nolint:gosec // G115: bounded by maxC2CLinks
+			values = append(values, nvml.FieldValue{FieldId: field, ScopeId: uint32(link)})
+		}
+	}
+
+	if !b.superchipFields(dev, values) {
+		return false
+	}
+
+	for link := range links {
+		fields := values[link*len(c2cLinkFields):]
+
+		entry.C2CLinks = append(entry.C2CLinks, collect.C2CLink{
+			Link:                       strconv.Itoa(link),
+			Up:                         fieldReading(fields[0], 1),
+			MaxBandwidthBytesPerSecond: fieldReading(fields[1], 1e6),
+			CRCErrors:                  fieldReading(fields[2], 1),
+			ReplayErrors:               fieldReading(fields[3], 1),
+			BackToBackReplayErrors:     fieldReading(fields[4], 1),
+			LowPower:                   fieldReading(fields[5], 1),
+		})
+	}
+
+	return true
+}
+
+// superchipFields reads one field value batch. A batch the device does not
+// support leaves every value unread, which is no failure. Reports whether
+// extras collection may continue.
+func (b *Backend) superchipFields(dev device, values []nvml.FieldValue) bool {
+	ret := dev.GetFieldValues(values)
+	if ret == nvml.SUCCESS {
+		return true
+	}
+
+	for i := range values {
+		values[i].NvmlReturn = uint32(ret) //nolint:gosec // G115: an nvmlReturn_t
+	}
+
+	return b.softRead("superchip", "cannot read the superchip field values", ret)
+}
+
+// fieldReading decodes one field value, scaled; nil when the driver did not
+// report it.
+func fieldReading(fieldValue nvml.FieldValue, scale float64) *float64 {
+	//nolint:gosec // G115: the field carries an nvmlReturn_t
+	if nvml.Return(fieldValue.NvmlReturn) != nvml.SUCCESS {
+		return nil
+	}
+
+	value, ok := decodeFieldValue(fieldValue)
+	if !ok {
+		return nil
+	}
+
+	value *= scale
+
+	return &value
+}
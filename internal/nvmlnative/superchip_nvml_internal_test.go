@@ -0,0 +1,132 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+func TestExtrasSuperchip(t *testing.T) {
+	t.Parallel()
+
+	type key struct{ field, scope uint32 }
+
+	readings := map[key]uint64{
+		{nvml.FI_DEV_C2C_LINK_COUNT, 0}:               2,
+		{nvml.FI_DEV_ENERGY, nvml.POWER_SCOPE_MODULE}: 987654,
+		{nvml.FI_DEV_C2C_LINK_GET_STATUS, 0}:          1,
+		{nvml.FI_DEV_C2C_LINK_GET_MAX_BW, 0}:          45000,
+		{nvml.FI_DEV_C2C_LINK_ERROR_INTR, 0}:          3,
+		{nvml.FI_DEV_C2C_LINK_ERROR_REPLAY, 0}:        5,
+		{nvml.FI_DEV_C2C_LINK_ERROR_REPLAY_B2B, 0}:    0,
+		{nvml.FI_DEV_C2C_LINK_POWER_STATE, 0}:         nvml.C2C_POWER_STATE_FULL_POWER,
+		{nvml.FI_DEV_C2C_LINK_GET_STATUS, 1}:          0,
+		{nvml.FI_DEV_C2C_LINK_ERROR_INTR, 1}:          0,
+		{nvml.FI_DEV_C2C_LINK_ERROR_REPLAY, 1}:        0,
+		{nvml.FI_DEV_C2C_LINK_ERROR_REPLAY_B2B, 1}:    0,
+		{nvml.FI_DEV_C2C_LINK_POWER_STATE, 1}:         nvml.C2C_POWER_STATE_LOW_POWER,
+	}
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetNumaNodeIdFunc = func() (int, nvml.Return) { return 4, nvml.SUCCESS }
+	dev.GetFieldValuesFunc = func(values []nvml.FieldValue) nvml.Return {
+		for i := range values {
+			value, ok := readings[key{values[i].FieldId, values[i].ScopeId}]
+			if !ok {
+				// an inactive link reports no bandwidth
+				values[i].NvmlReturn = uint32(nvml.ERROR_NOT_SUPPORTED)
+
+				continue
+			}
+
+			values[i].NvmlReturn = uint32(nvml.SUCCESS)
+			values[i].ValueType = uint32(nvml.VALUE_TYPE_UNSIGNED_LONG_LONG)
+			binary.LittleEndian.PutUint64(values[i].Value[:], value)
+		}
+
+		return nvml.SUCCESS
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{Superchip: true})(t.Context())
+	require.NoError(t, err)
+
+	value := func(v float64) *float64 { return &v }
+
+	assert.Equal(t, []collect.Superchip{{
+		UUID:  "11111111-2222-3333-4444-555555555555",
+		Links: value(2),
+		C2CLinks: []collect.C2CLink{
+			{
+				Link:                       "0",
+				Up:                         value(1),
+				MaxBandwidthBytesPerSecond: value(45e9),
+				CRCErrors:                  value(3),
+				ReplayErrors:               value(5),
+				BackToBackReplayErrors:     value(0),
+				LowPower:                   value(0),
+			},
+			{
+				Link:                   "1",
+				Up:                     value(0),
+				CRCErrors:              value(0),
+				ReplayErrors:           value(0),
+				BackToBackReplayErrors: value(0),
+				LowPower:               value(1),
+			},
+		},
+		ModuleEnergyJoules: value(987.654),
+		MemoryNUMANode:     value(4),
+	}}, reading.Extras.Superchips)
+}
+
+func TestExtrasSuperchipAbsentOnPCIeCards(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetFieldValuesFunc = func(values []nvml.FieldValue) nvml.Return {
+		for i := range values {
+			values[i].NvmlReturn = uint32(nvml.ERROR_NOT_SUPPORTED)
+		}
+
+		return nvml.SUCCESS
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, code, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{Superchip: true})(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, reading.Extras.Superchips, "a GPU without C2C links contributes no entry")
+}
+
+func TestExtrasSuperchipLifecycleAborts(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetFieldValuesFunc = func([]nvml.FieldValue) nvml.Return { return nvml.ERROR_GPU_IS_LOST }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{Superchip: true})(t.Context())
+	require.NoError(t, err, "extras must never fail the collection")
+	assert.Empty(t, reading.Extras.Superchips)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "the lifecycle error must mark the backend for re-init")
+}
@@ -27,6 +27,12 @@ var nvmlSymbolRequirements = []symbolRequirement{
 		anyOf:  []string{"nvmlDeviceGetGpuFabricInfoV"},
 		serves: "fabric.*",
 	},
+	{
+		// and its V3, which adds the health mask.
+		goCall: "GetGpuFabricInfoV3",
+		anyOf:  []string{"nvmlDeviceGetGpuFabricInfoV"},
+		serves: "fabric.health.*",
+	},
 	{goCall: "init", anyOf: []string{"nvmlInit_v2", "nvmlInit"}, serves: "NVML initialization"},
 	{goCall: "shutdown", anyOf: []string{"nvmlShutdown"}, serves: "NVML shutdown"},
 	{
@@ -71,20 +77,43 @@ var nvmlSymbolRequirements = []symbolRequirement{
 		anyOf:  []string{"nvmlDeviceGetComputeInstanceId"},
 		serves: "mig_* metrics, per-process MIG attribution",
 	},
+	{
+		goCall: "GetGpuInstanceProfileInfo",
+		anyOf:  []string{"nvmlDeviceGetGpuInstanceProfileInfo"},
+		serves: "mig_profile_* metrics",
+	},
+	{
+		goCall: "GetGpuInstanceRemainingCapacity",
+		anyOf:  []string{"nvmlDeviceGetGpuInstanceRemainingCapacity"},
+		serves: "mig_profile_instances_remaining",
+	},
+	{
+		goCall: "GetGpuInstancePossiblePlacements",
+		anyOf: []string{
+			"nvmlDeviceGetGpuInstancePossiblePlacements_v2",
+			"nvmlDeviceGetGpuInstancePossiblePlacements",
+		},
+		serves: "mig_profile_placement_info",
+	},
 	{
 		goCall: "GpmQueryDeviceSupport",
 		anyOf:  []string{"nvmlGpmQueryDeviceSupport"},
-		serves: "mig_*_ratio, mig_pcie_throughput_*",
+		serves: "mig_*_ratio, mig_pcie_throughput_*, gpm_*",
 	},
 	{
 		goCall: "gpmSampleAlloc",
 		anyOf:  []string{"nvmlGpmSampleAlloc"},
-		serves: "mig_*_ratio, mig_pcie_throughput_*",
+		serves: "mig_*_ratio, mig_pcie_throughput_*, gpm_*",
 	},
 	{
 		goCall: "gpmSampleFree",
 		anyOf:  []string{"nvmlGpmSampleFree"},
-		serves: "mig_*_ratio, mig_pcie_throughput_*",
+		serves: "mig_*_ratio, mig_pcie_throughput_*, gpm_*",
+	},
+	{
+		goCall: "gpmSampleGet",
+		anyOf:  []string{"nvmlGpmSampleGet"},
+		serves: "gpm_*",
 	},
 	{
 		goCall: "gpmMigSampleGet",
@@ -94,17 +123,22 @@ var nvmlSymbolRequirements = []symbolRequirement{
 	{
 		goCall: "gpmMetricsGet",
 		anyOf:  []string{"nvmlGpmMetricsGet"},
-		serves: "mig_*_ratio, mig_pcie_throughput_*",
+		serves: "mig_*_ratio, mig_pcie_throughput_*, gpm_*",
 	},
 	{
 		goCall: "eventSetCreate",
 		anyOf:  []string{"nvmlEventSetCreate"},
-		serves: "xid_errors_total",
+		serves: "xid_errors_total, driver_events_total",
 	},
 	{
 		goCall: "RegisterEvents",
 		anyOf:  []string{"nvmlDeviceRegisterEvents"},
-		serves: "xid_errors_total",
+		serves: "xid_errors_total, driver_events_total",
+	},
+	{
+		goCall: "GetSupportedEventTypes",
+		anyOf:  []string{"nvmlDeviceGetSupportedEventTypes"},
+		serves: "driver_events_total",
 	},
 	{
 		// reached as interface methods on the event set (not scannable call
@@ -112,12 +146,12 @@ var nvmlSymbolRequirements = []symbolRequirement{
 		// carries them
 		goCall: "eventSetWait",
 		anyOf:  []string{"nvmlEventSetWait_v2", "nvmlEventSetWait"},
-		serves: "xid_errors_total",
+		serves: "xid_errors_total, driver_events_total",
 	},
 	{
 		goCall: "eventSetFree",
 		anyOf:  []string{"nvmlEventSetFree"},
-		serves: "xid_errors_total",
+		serves: "xid_errors_total, driver_events_total",
 	},
 	{
 		// the unversioned symbol is accepted as an alternative because the
@@ -478,6 +512,59 @@ var nvmlSymbolRequirements = []symbolRequirement{
 		},
 		serves: "temperature.gpu.tlimit",
 	},
+	{
+		goCall: "GetSamples",
+		anyOf:  []string{"nvmlDeviceGetSamples"},
+		serves: "driver_samples_*",
+	},
+	{
+		goCall: "GetTemperatureThreshold",
+		anyOf:  []string{"nvmlDeviceGetTemperatureThreshold"},
+		serves: "temperature_threshold_celsius",
+	},
+	{goCall: "GetNumFans", anyOf: []string{"nvmlDeviceGetNumFans"}, serves: "gpu_fan_* families"},
+	{goCall: "GetFanSpeed_v2", anyOf: []string{"nvmlDeviceGetFanSpeed_v2"}, serves: "gpu_fan_speed_ratio"},
+	{
+		goCall: "GetTargetFanSpeed",
+		anyOf:  []string{"nvmlDeviceGetTargetFanSpeed"},
+		serves: "gpu_fan_target_speed_ratio",
+	},
+	{
+		goCall: "GetFanControlPolicy_v2",
+		anyOf:  []string{"nvmlDeviceGetFanControlPolicy_v2"},
+		serves: "gpu_fan_control_policy_info",
+	},
+	{goCall: "GetArchitecture", anyOf: []string{"nvmlDeviceGetArchitecture"}, serves: "gpu_capabilities_info"},
+	{goCall: "GetBrand", anyOf: []string{"nvmlDeviceGetBrand"}, serves: "gpu_capabilities_info"},
+	{
+		goCall: "GetBoardPartNumber",
+		anyOf:  []string{"nvmlDeviceGetBoardPartNumber"},
+		serves: "gpu_capabilities_info",
+	},
+	{goCall: "GetNumGpuCores", anyOf: []string{"nvmlDeviceGetNumGpuCores"}, serves: "gpu_cores"},
+	{
+		goCall: "GetMemoryBusWidth",
+		anyOf:  []string{"nvmlDeviceGetMemoryBusWidth"},
+		serves: "gpu_memory_bus_width_bits",
+	},
+	{
+		goCall: "GetSupportedPerformanceStates",
+		anyOf:  []string{"nvmlDeviceGetSupportedPerformanceStates"},
+		serves: "gpu_supported_clock_hz",
+	},
+	{
+		goCall: "GetMinMaxClockOfPState",
+		anyOf:  []string{"nvmlDeviceGetMinMaxClockOfPState"},
+		serves: "gpu_supported_clock_hz",
+	},
+	{
+		goCall: "GetTopologyCommonAncestor",
+		anyOf:  []string{"nvmlDeviceGetTopologyCommonAncestor"},
+		serves: "gpu_topology_info",
+	},
+	{goCall: "GetP2PStatus", anyOf: []string{"nvmlDeviceGetP2PStatus"}, serves: "gpu_topology_info"},
+	{goCall: "GetMemoryAffinity", anyOf: []string{"nvmlDeviceGetMemoryAffinity"}, serves: "gpu_numa_node"},
+	{goCall: "GetCpuAffinity", anyOf: []string{"nvmlDeviceGetCpuAffinity"}, serves: "gpu_numa_node"},
 	{
 		goCall: "GetPowerUsage",
 		anyOf:  []string{"nvmlDeviceGetPowerUsage", "nvmlDeviceGetPowerUsage_v2", "nvmlDeviceGetPowerUsage_v3"},
@@ -548,6 +635,11 @@ var nvmlSymbolRequirements = []symbolRequirement{
 		anyOf:  []string{"nvmlDeviceGetC2cModeInfoV", "nvmlDeviceGetC2cModeInfoV_v2", "nvmlDeviceGetC2cModeInfoV_v3"},
 		serves: "c2c.mode",
 	},
+	{
+		goCall: "GetNumaNodeId",
+		anyOf:  []string{"nvmlDeviceGetNumaNodeId"},
+		serves: "superchip_memory_numa_node",
+	},
 	{
 		goCall: "GetConfComputeProtectedMemoryUsage",
 		anyOf: []string{
@@ -557,6 +649,81 @@ var nvmlSymbolRequirements = []symbolRequirement{
 		},
 		serves: "protected_memory.*",
 	},
+	{
+		goCall: "GetConfComputeMemSizeInfo",
+		anyOf:  []string{"nvmlDeviceGetConfComputeMemSizeInfo"},
+		serves: "gpu_confidential_computing_*_memory_size_bytes",
+	},
+	{
+		goCall: "confComputeCapabilities",
+		anyOf:  []string{"nvmlSystemGetConfComputeCapabilities"},
+		serves: "confidential_computing_capabilities_info",
+	},
+	{
+		goCall: "confComputeState",
+		anyOf:  []string{"nvmlSystemGetConfComputeState"},
+		serves: "confidential_computing_mode_info",
+	},
+	{
+		goCall: "confComputeSettings",
+		anyOf:  []string{"nvmlSystemGetConfComputeSettings"},
+		serves: "confidential_computing_multi_gpu_mode_info",
+	},
+	{
+		goCall: "confComputeReadyState",
+		anyOf:  []string{"nvmlSystemGetConfComputeGpusReadyState"},
+		serves: "confidential_computing_gpus_ready",
+	},
+	{
+		goCall: "confComputeKeyRotation",
+		anyOf:  []string{"nvmlSystemGetConfComputeKeyRotationThresholdInfo"},
+		serves: "confidential_computing_key_rotation_attacker_advantage",
+	},
+	{
+		goCall: "GetActiveVgpus",
+		anyOf:  []string{"nvmlDeviceGetActiveVgpus"},
+		serves: "vgpu_active_instances",
+	},
+	{
+		goCall: "GetVgpuUtilization",
+		anyOf:  []string{"nvmlDeviceGetVgpuUtilization"},
+		serves: "vgpu_*_utilization_ratio",
+	},
+	{
+		goCall: "GetUUID",
+		anyOf:  []string{"nvmlVgpuInstanceGetUUID"},
+		serves: "vgpu_uuid",
+	},
+	{
+		goCall: "GetVmID",
+		anyOf:  []string{"nvmlVgpuInstanceGetVmID"},
+		serves: "vgpu_info (vm_id)",
+	},
+	{
+		goCall: "GetType",
+		anyOf:  []string{"nvmlVgpuInstanceGetType"},
+		serves: "vgpu_info (type, class)",
+	},
+	{
+		goCall: "GetName",
+		anyOf:  []string{"nvmlVgpuTypeGetName"},
+		serves: "vgpu_info (type)",
+	},
+	{
+		goCall: "GetClass",
+		anyOf:  []string{"nvmlVgpuTypeGetClass"},
+		serves: "vgpu_info (class)",
+	},
+	{
+		goCall: "GetFbUsage",
+		anyOf:  []string{"nvmlVgpuInstanceGetFbUsage"},
+		serves: "vgpu_framebuffer_used_bytes",
+	},
+	{
+		goCall: "GetEncoderStats",
+		anyOf:  []string{"nvmlVgpuInstanceGetEncoderStats"},
+		serves: "vgpu_encoder_sessions",
+	},
 	{
 		goCall: "GetGpuFabricInfoV",
 		anyOf: []string{
@@ -572,6 +739,17 @@ var nvmlSymbolRequirements = []symbolRequirement{
 		serves: "platform.*",
 	},
 	{goCall: "GetHostname_v1", anyOf: []string{"nvmlDeviceGetHostname_v1"}, serves: "hostname"},
+	{goCall: "GetBBXTimeData_v1", anyOf: []string{"nvmlDeviceGetBBXTimeData_v1"}, serves: "bbx.time_run"},
+	{
+		goCall: "vgpuDriverCapability",
+		anyOf:  []string{"nvmlGetVgpuDriverCapabilities"},
+		serves: "vgpu_driver_capability.*",
+	},
+	{
+		goCall: "GetVgpuCapabilities",
+		anyOf:  []string{"nvmlDeviceGetVgpuCapabilities"},
+		serves: "vgpu_device_capability.*",
+	},
 	{
 		goCall: "GetComputeRunningProcesses",
 		anyOf: []string{
@@ -581,4 +759,67 @@ var nvmlSymbolRequirements = []symbolRequirement{
 		},
 		serves: "per-process metrics",
 	},
+	{
+		goCall: "GetGraphicsRunningProcesses",
+		anyOf: []string{
+			"nvmlDeviceGetGraphicsRunningProcesses",
+			"nvmlDeviceGetGraphicsRunningProcesses_v2",
+			"nvmlDeviceGetGraphicsRunningProcesses_v3",
+		},
+		serves: "per-process metrics (type=graphics)",
+	},
+	{
+		goCall: "GetMPSComputeRunningProcesses",
+		anyOf: []string{
+			"nvmlDeviceGetMPSComputeRunningProcesses",
+			"nvmlDeviceGetMPSComputeRunningProcesses_v2",
+			"nvmlDeviceGetMPSComputeRunningProcesses_v3",
+		},
+		serves: "per-process metrics (type=mps)",
+	},
+	{
+		goCall: "GetProcessUtilization",
+		anyOf:  []string{"nvmlDeviceGetProcessUtilization"},
+		serves: "compute_app_*_utilization_ratio",
+	},
+	{
+		goCall: "GetAccountingPids",
+		anyOf:  []string{"nvmlDeviceGetAccountingPids"},
+		serves: "accounted_apps_*",
+	},
+	{
+		goCall: "GetAccountingStats",
+		anyOf:  []string{"nvmlDeviceGetAccountingStats"},
+		serves: "accounted_apps_*",
+	},
+	{
+		goCall: "GetEncoderSessions",
+		anyOf:  []string{"nvmlDeviceGetEncoderSessions"},
+		serves: "video_session* (session_type=encoder)",
+	},
+	{
+		goCall: "GetFBCSessions",
+		anyOf:  []string{"nvmlDeviceGetFBCSessions"},
+		serves: "video_session* (session_type=fbc)",
+	},
+	{
+		goCall: "GetNvLinkState",
+		anyOf:  []string{"nvmlDeviceGetNvLinkState"},
+		serves: "nvlink_*",
+	},
+	{
+		goCall: "GetNvLinkVersion",
+		anyOf:  []string{"nvmlDeviceGetNvLinkVersion"},
+		serves: "nvlink_version",
+	},
+	{
+		goCall: "GetNvLinkRemotePciInfo",
+		anyOf:  []string{"nvmlDeviceGetNvLinkRemotePciInfo", "nvmlDeviceGetNvLinkRemotePciInfo_v2"},
+		serves: "nvlink_remote_info",
+	},
+	{
+		goCall: "GetNvLinkRemoteDeviceType",
+		anyOf:  []string{"nvmlDeviceGetNvLinkRemoteDeviceType"},
+		serves: "nvlink_remote_info",
+	},
 }
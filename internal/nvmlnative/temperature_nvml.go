@@ -0,0 +1,56 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+// temperatureThresholdKinds pairs every driver threshold with its kind, in
+// the driver's order.
+//
+//nolint:gochecknoglobals // lookup table
+var temperatureThresholdKinds = []struct {
+	threshold nvml.TemperatureThresholds
+	kind      string
+}{
+	{nvml.TEMPERATURE_THRESHOLD_SHUTDOWN, nvidiasmi.TemperatureThresholdShutdown},
+	{nvml.TEMPERATURE_THRESHOLD_SLOWDOWN, nvidiasmi.TemperatureThresholdSlowdown},
+	{nvml.TEMPERATURE_THRESHOLD_MEM_MAX, nvidiasmi.TemperatureThresholdMemoryMax},
+	{nvml.TEMPERATURE_THRESHOLD_GPU_MAX, nvidiasmi.TemperatureThresholdGPUMax},
+	{nvml.TEMPERATURE_THRESHOLD_ACOUSTIC_MIN, nvidiasmi.TemperatureThresholdAcousticMin},
+	{nvml.TEMPERATURE_THRESHOLD_ACOUSTIC_CURR, nvidiasmi.TemperatureThresholdAcousticCurrent},
+	{nvml.TEMPERATURE_THRESHOLD_ACOUSTIC_MAX, nvidiasmi.TemperatureThresholdAcousticMax},
+}
+
+// collectTemperatureThresholds appends one device's temperature thresholds.
+// The driver reports them as absolute temperatures, unlike nvidia-smi on
+// datacenter GPUs. A threshold the GPU does not define is skipped silently:
+// besides NOT_SUPPORTED, drivers predating a threshold kind reject it as an
+// invalid argument, and those predating the getter lack its export. Reports
+// whether extras collection may continue.
+func (b *Backend) collectTemperatureThresholds(dev device, uuid string, extras *collect.Extras) bool {
+	for _, entry := range temperatureThresholdKinds {
+		celsius, ret := dev.GetTemperatureThreshold(entry.threshold)
+
+		//nolint:exhaustive // every other return is a plain failure
+		switch ret {
+		case nvml.SUCCESS:
+			extras.TemperatureThresholds = append(extras.TemperatureThresholds, collect.TemperatureThreshold{
+				UUID:    uuid,
+				Kind:    entry.kind,
+				Celsius: float64(celsius),
+			})
+		case nvml.ERROR_NOT_SUPPORTED, nvml.ERROR_INVALID_ARGUMENT, nvml.ERROR_FUNCTION_NOT_FOUND:
+		default:
+			if !b.extrasFailure("temperature-thresholds", "cannot read a temperature threshold", ret) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
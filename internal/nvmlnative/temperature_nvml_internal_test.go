@@ -0,0 +1,88 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+func TestExtrasTemperatureThresholds(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetTemperatureThresholdFunc = func(threshold nvml.TemperatureThresholds) (uint32, nvml.Return) {
+		switch threshold {
+		case nvml.TEMPERATURE_THRESHOLD_SHUTDOWN:
+			return 100, nvml.SUCCESS
+		case nvml.TEMPERATURE_THRESHOLD_SLOWDOWN:
+			return 97, nvml.SUCCESS
+		case nvml.TEMPERATURE_THRESHOLD_GPU_MAX:
+			return 89, nvml.SUCCESS
+		case nvml.TEMPERATURE_THRESHOLD_MEM_MAX:
+			return 0, nvml.ERROR_NOT_SUPPORTED
+		case nvml.TEMPERATURE_THRESHOLD_ACOUSTIC_CURR:
+			return 84, nvml.SUCCESS
+		default:
+			// a driver predating the kind
+			return 0, nvml.ERROR_INVALID_ARGUMENT
+		}
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{TemperatureThresholds: true})(t.Context())
+	require.NoError(t, err)
+
+	uuid := "11111111-2222-3333-4444-555555555555"
+	assert.Equal(t, []collect.TemperatureThreshold{
+		{UUID: uuid, Kind: nvidiasmi.TemperatureThresholdShutdown, Celsius: 100},
+		{UUID: uuid, Kind: nvidiasmi.TemperatureThresholdSlowdown, Celsius: 97},
+		{UUID: uuid, Kind: nvidiasmi.TemperatureThresholdGPUMax, Celsius: 89},
+		{UUID: uuid, Kind: nvidiasmi.TemperatureThresholdAcousticCurrent, Celsius: 84},
+	}, reading.Extras.TemperatureThresholds)
+}
+
+func TestExtrasTemperatureThresholdsOnADriverWithoutTheGetter(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackendWithoutExports(t, fake, "nvmlDeviceGetTemperatureThreshold")
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{TemperatureThresholds: true})(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, reading.Extras.TemperatureThresholds)
+	assert.False(t, backend.extrasWarned["temperature-thresholds"], "a missing export is absence, not a failure")
+}
+
+func TestExtrasTemperatureThresholdsLifecycleAborts(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetTemperatureThresholdFunc = func(nvml.TemperatureThresholds) (uint32, nvml.Return) {
+		return 0, nvml.ERROR_GPU_IS_LOST
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(
+		resolveFields(t, "power.draw"), CollectOptions{TemperatureThresholds: true})(t.Context())
+	require.NoError(t, err, "extras must never fail the collection")
+	assert.Empty(t, reading.Extras.TemperatureThresholds)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "the lifecycle error must mark the backend for re-init")
+}
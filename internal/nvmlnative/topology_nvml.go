@@ -0,0 +1,195 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+// The sizes of the CPU and NUMA node masks handed to the driver, the largest
+// the Linux kernel can be configured for.
+const (
+	topologyMaxCPUs  = 8192
+	topologyMaxNodes = 1024
+)
+
+// topologyLevels maps the closest common ancestor of two GPUs to its topo -m
+// link kind. TOPOLOGY_INTERNAL, two GPUs on one board, has no legend entry
+// and is left out.
+//
+//nolint:gochecknoglobals // lookup table
+var topologyLevels = map[nvml.GpuTopologyLevel]string{
+	nvml.TOPOLOGY_SINGLE:     nvidiasmi.TopologyLinkPIX,
+	nvml.TOPOLOGY_MULTIPLE:   nvidiasmi.TopologyLinkPXB,
+	nvml.TOPOLOGY_HOSTBRIDGE: nvidiasmi.TopologyLinkPHB,
+	nvml.TOPOLOGY_NODE:       nvidiasmi.TopologyLinkNode,
+	nvml.TOPOLOGY_SYSTEM:     nvidiasmi.TopologyLinkSystem,
+}
+
+// topologyPass gathers the devices one extras pass visited, in index order,
+// for the pairwise topology read once the pass ends.
+type topologyPass struct {
+	devices []device
+	uuids   []string
+}
+
+// collectTopology fills the topology of the devices the pass visited. The
+// topology only changes with the device set, so it is read again only when
+// the set or the NVML generation changes; a read stopped by a lifecycle error
+// is not kept, so the next cycle reads it again.
+func (b *Backend) collectTopology(pass *topologyPass, extras *collect.Extras) {
+	key := fmt.Sprintf("%d/%s", b.generation.Load(), strings.Join(pass.uuids, ","))
+	if key != b.topologyKey {
+		topology, ok := b.readTopology(pass)
+		if !ok {
+			return
+		}
+
+		b.topology = topology
+		b.topologyKey = key
+	}
+
+	// the cached slices are never written again, so the snapshots may share
+	// them
+	extras.Topology = b.topology
+}
+
+// readTopology reads the NUMA affinity of every device and the link between
+// every pair of them. A reading the GPU does not support is left out
+// silently. Reports false when a lifecycle error stops the read.
+func (b *Backend) readTopology(pass *topologyPass) (collect.Topology, bool) {
+	var topology collect.Topology
+
+	for i, dev := range pass.devices {
+		affinity, ok := b.readNUMAAffinity(dev, pass.uuids[i])
+		if !ok {
+			return topology, false
+		}
+
+		if affinity != nil {
+			topology.NUMA = append(topology.NUMA, *affinity)
+		}
+	}
+
+	for i, dev := range pass.devices {
+		for j, peer := range pass.devices {
+			if i == j {
+				continue
+			}
+
+			link, ok := b.readTopologyLink(dev, peer)
+			if !ok {
+				return topology, false
+			}
+
+			if link != "" {
+				topology.Links = append(topology.Links, collect.TopologyLink{
+					UUID:     pass.uuids[i],
+					PeerUUID: pass.uuids[j],
+					Link:     link,
+				})
+			}
+		}
+	}
+
+	return topology, true
+}
+
+// readNUMAAffinity reads the NUMA node a device is local to and that node's
+// CPUs. A device local to no single node (a host without NUMA, or one that
+// spreads the GPU over several nodes) has no affinity.
+func (b *Backend) readNUMAAffinity(dev device, uuid string) (*collect.NUMAAffinity, bool) {
+	nodes, ret := dev.GetMemoryAffinity(topologyMaxNodes, nvml.AFFINITY_SCOPE_NODE)
+	if ret != nvml.SUCCESS {
+		return nil, b.topologyRead(ret)
+	}
+
+	members := maskMembers(nodes)
+	if len(members) != 1 {
+		return nil, true
+	}
+
+	affinity := &collect.NUMAAffinity{UUID: uuid, Node: members[0]}
+
+	cpus, ret := dev.GetCpuAffinity(topologyMaxCPUs)
+	if ret != nvml.SUCCESS {
+		return affinity, b.topologyRead(ret)
+	}
+
+	affinity.CPUAffinity = cpuList(maskMembers(cpus))
+
+	return affinity, true
+}
+
+// readTopologyLink reads the link between two devices: NVLink when the pair
+// can reach each other over it, otherwise the closest common ancestor of
+// their PCIe paths. Empty when neither is known.
+func (b *Backend) readTopologyLink(dev, peer device) (string, bool) {
+	status, ret := dev.GetP2PStatus(peer, nvml.P2P_CAPS_INDEX_NVLINK)
+	if ret == nvml.SUCCESS && status == nvml.P2P_STATUS_OK {
+		return nvidiasmi.TopologyLinkNVLink, true
+	}
+
+	if ret != nvml.SUCCESS && !b.topologyRead(ret) {
+		return "", false
+	}
+
+	level, ret := dev.GetTopologyCommonAncestor(peer)
+	if ret != nvml.SUCCESS {
+		return "", b.topologyRead(ret)
+	}
+
+	return topologyLevels[level], true
+}
+
+// topologyRead folds one topology getter's failed return through softRead.
+// Reports whether extras collection may continue.
+func (b *Backend) topologyRead(ret nvml.Return) bool {
+	return b.softRead("topology", "cannot read the GPU topology", ret)
+}
+
+// maskMembers lists the set bits of a driver affinity mask in ascending order.
+func maskMembers(mask []uint) []int {
+	var members []int
+
+	for word, value := range mask {
+		for value != 0 {
+			bit := bits.TrailingZeros(value)
+			members = append(members, word*bits.UintSize+bit)
+			value &^= 1 << bit
+		}
+	}
+
+	return members
+}
+
+// cpuList spells ascending CPU numbers the way nvidia-smi topo -m and the
+// kernel's cpulist files do, runs collapsed to ranges: "0-23,48-71".
+func cpuList(cpus []int) string {
+	var parts []string
+
+	for start := 0; start < len(cpus); {
+		end := start
+		for end+1 < len(cpus) && cpus[end+1] == cpus[end]+1 {
+			end++
+		}
+
+		part := strconv.Itoa(cpus[start])
+		if end > start {
+			part += "-" + strconv.Itoa(cpus[end])
+		}
+
+		parts = append(parts, part)
+		start = end + 1
+	}
+
+	return strings.Join(parts, ",")
+}
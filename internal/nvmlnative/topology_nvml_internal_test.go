@@ -0,0 +1,157 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+// topologyDevices builds three GPUs: an NVLink-bridged pair on NUMA node 1
+// and a third GPU across the SMP interconnect, spread over two nodes.
+func topologyDevices() []*mock.Device {
+	devs := make([]*mock.Device, 3)
+
+	for i := range devs {
+		uuid := []string{
+			"GPU-00000000-2222-3333-4444-555555555555",
+			"GPU-11111111-2222-3333-4444-555555555555",
+			"GPU-22222222-2222-3333-4444-555555555555",
+		}[i]
+
+		dev := identityDevice()
+		dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+		dev.GetUUIDFunc = func() (string, nvml.Return) { return uuid, nvml.SUCCESS }
+		dev.GetMemoryAffinityFunc = func(int, nvml.AffinityScope) ([]uint, nvml.Return) {
+			if i == 2 {
+				return []uint{0b11}, nvml.SUCCESS
+			}
+
+			return []uint{0b10}, nvml.SUCCESS
+		}
+		// CPUs 0-3, 8, 9 and 64
+		dev.GetCpuAffinityFunc = func(int) ([]uint, nvml.Return) { return []uint{0x30f, 1}, nvml.SUCCESS }
+		devs[i] = dev
+	}
+
+	for i, dev := range devs {
+		dev.GetP2PStatusFunc = func(peer nvml.Device, _ nvml.GpuP2PCapsIndex) (nvml.GpuP2PStatus, nvml.Return) {
+			if i < 2 && (peer == devs[0] || peer == devs[1]) {
+				return nvml.P2P_STATUS_OK, nvml.SUCCESS
+			}
+
+			return nvml.P2P_STATUS_NOT_SUPPORTED, nvml.SUCCESS
+		}
+		dev.GetTopologyCommonAncestorFunc = func(peer nvml.Device) (nvml.GpuTopologyLevel, nvml.Return) {
+			if i < 2 && (peer == devs[0] || peer == devs[1]) {
+				return nvml.TOPOLOGY_HOSTBRIDGE, nvml.SUCCESS
+			}
+
+			return nvml.TOPOLOGY_SYSTEM, nvml.SUCCESS
+		}
+	}
+
+	return devs
+}
+
+func topologyAPI(devs []*mock.Device) *fakeAPI {
+	fake := &fakeAPI{}
+	for _, dev := range devs {
+		fake.devices = append(fake.devices, dev)
+	}
+
+	return fake
+}
+
+func TestExtrasTopology(t *testing.T) {
+	t.Parallel()
+
+	backend := newTestBackend(t, topologyAPI(topologyDevices()))
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Topology: true})(t.Context())
+	require.NoError(t, err)
+
+	gpu0 := "00000000-2222-3333-4444-555555555555"
+	gpu1 := "11111111-2222-3333-4444-555555555555"
+	gpu2 := "22222222-2222-3333-4444-555555555555"
+
+	assert.Equal(t, []collect.TopologyLink{
+		{UUID: gpu0, PeerUUID: gpu1, Link: nvidiasmi.TopologyLinkNVLink},
+		{UUID: gpu0, PeerUUID: gpu2, Link: nvidiasmi.TopologyLinkSystem},
+		{UUID: gpu1, PeerUUID: gpu0, Link: nvidiasmi.TopologyLinkNVLink},
+		{UUID: gpu1, PeerUUID: gpu2, Link: nvidiasmi.TopologyLinkSystem},
+		{UUID: gpu2, PeerUUID: gpu0, Link: nvidiasmi.TopologyLinkSystem},
+		{UUID: gpu2, PeerUUID: gpu1, Link: nvidiasmi.TopologyLinkSystem},
+	}, reading.Extras.Topology.Links)
+	assert.Equal(t, []collect.NUMAAffinity{
+		{UUID: gpu0, Node: 1, CPUAffinity: "0-3,8-9,64"},
+		{UUID: gpu1, Node: 1, CPUAffinity: "0-3,8-9,64"},
+	}, reading.Extras.Topology.NUMA, "a GPU spread over two nodes has no affinity")
+}
+
+func TestExtrasTopologyReadOncePerDeviceSet(t *testing.T) {
+	t.Parallel()
+
+	devs := topologyDevices()
+	fake := topologyAPI(devs)
+	backend := newTestBackend(t, fake)
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Topology: true})
+
+	for range 3 {
+		reading, _, err := query(t.Context())
+		require.NoError(t, err)
+		require.Len(t, reading.Extras.Topology.Links, 6, "a cached topology is still reported")
+	}
+
+	assert.Len(t, devs[0].GetMemoryAffinityCalls(), 1)
+	assert.Len(t, devs[0].GetTopologyCommonAncestorCalls(), 1, "an NVLink peer needs no PCIe path")
+
+	// a GPU leaving changes the device set
+	fake.devices = fake.devices[:2]
+
+	reading, _, err := query(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, reading.Extras.Topology.Links, 2)
+	assert.Len(t, devs[0].GetMemoryAffinityCalls(), 2)
+
+	// so does a re-initialization, which may come with a replaced driver
+	backend.generation.Add(1)
+
+	_, _, err = query(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, devs[0].GetMemoryAffinityCalls(), 3)
+}
+
+func TestExtrasTopologyLifecycleDiscardsThePartialRead(t *testing.T) {
+	t.Parallel()
+
+	ancestorRet := nvml.ERROR_GPU_IS_LOST
+
+	devs := topologyDevices()
+	devs[2].GetTopologyCommonAncestorFunc = func(nvml.Device) (nvml.GpuTopologyLevel, nvml.Return) {
+		return nvml.TOPOLOGY_SYSTEM, ancestorRet
+	}
+
+	fake := topologyAPI(devs)
+	backend := newTestBackend(t, fake)
+	query := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{Topology: true})
+
+	reading, _, err := query(t.Context())
+	require.NoError(t, err, "a failed topology read must not fail the collection")
+	assert.Empty(t, reading.Extras.Topology.Links)
+	assert.Empty(t, reading.Extras.Topology.NUMA)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "a lifecycle error must mark the backend for re-init")
+
+	ancestorRet = nvml.SUCCESS
+
+	reading, _, err = query(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, reading.Extras.Topology.Links, 6)
+}
@@ -0,0 +1,272 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"reflect"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+)
+
+// vgpuFamily names the vGPU family in the extras warnings.
+const vgpuFamily = "vgpu"
+
+// vgpuUtilSum accumulates one vGPU instance's samples within a window.
+type vgpuUtilSum struct {
+	sm, memory, enc, dec utilAverage
+}
+
+// utilAverage averages the samples of one engine; a sample whose value
+// cannot be decoded does not count.
+type utilAverage struct {
+	samples int
+	total   float64
+}
+
+func (a *utilAverage) add(valueType nvml.ValueType, raw [8]byte) {
+	value, ok := decodeFieldValue(nvml.FieldValue{
+		ValueType: uint32(valueType), //nolint:gosec // G115: a small enum
+		Value:     raw,
+	})
+	if !ok {
+		return
+	}
+
+	a.samples++
+	a.total += value
+}
+
+// ratio is the average as a fraction of the sampling period, nil without a
+// sample.
+func (a *utilAverage) ratio() *float64 {
+	if a.samples == 0 {
+		return nil
+	}
+
+	ratio := a.total / float64(a.samples) / 100
+
+	return &ratio
+}
+
+// collectVGPUs appends one device's active vGPU instances, with each one's
+// engine utilization averaged over the driver samples newer than the
+// previous collection; the first cycle that sees a host only opens its
+// window, like the per-process utilization. A device that is no vGPU host
+// is skipped silently. Reports whether extras collection may continue.
+func (b *Backend) collectVGPUs(dev device, uuid string, extras *collect.Extras) bool {
+	instances, ret := dev.GetActiveVgpus()
+
+	//nolint:exhaustive // every other return is a plain failure
+	switch ret {
+	case nvml.SUCCESS:
+	case nvml.ERROR_NOT_SUPPORTED, nvml.ERROR_FUNCTION_NOT_FOUND:
+		// bare metal, or a guest: no vGPU host
+		return true
+	default:
+		return b.extrasFailure(vgpuFamily, "cannot list the active vGPU instances", ret)
+	}
+
+	host := collect.VGPUHost{UUID: uuid, Instances: []collect.VGPUInstance{}}
+	byHandle := map[uint32]int{}
+
+	for _, inst := range instances {
+		entry, ok, proceed := b.readVGPUInstance(inst)
+		if !proceed {
+			return false
+		}
+
+		if !ok {
+			continue
+		}
+
+		if handle, known := b.vgpuHandle(inst.raw()); known {
+			byHandle[handle] = len(host.Instances)
+		}
+
+		host.Instances = append(host.Instances, entry)
+	}
+
+	if !b.vgpuUtilization(dev, &host, byHandle) {
+		return false
+	}
+
+	extras.VGPUs = append(extras.VGPUs, host)
+
+	return true
+}
+
+// readVGPUInstance reads one vGPU instance. ok is false for an instance whose
+// uuid cannot be read, which has no identity to label its series by, and
+// proceed false when extras collection must abort.
+func (b *Backend) readVGPUInstance(inst vgpuInstance) (entry collect.VGPUInstance, ok, proceed bool) {
+	vgpuUUID, ret := inst.GetUUID()
+	if ret != nvml.SUCCESS {
+		return entry, false, b.extrasFailure(vgpuFamily, "cannot read a vGPU instance uuid", ret)
+	}
+
+	entry.UUID = nvidiasmi.NormalizeUUID(vgpuUUID)
+
+	vmID, _, ret := inst.GetVmID()
+	if !b.softRead(vgpuFamily, "cannot read a vGPU instance's VM id", ret) {
+		return entry, false, false
+	}
+
+	if ret == nvml.SUCCESS {
+		entry.VMID = vmID
+	}
+
+	if !b.vgpuTypeOf(inst, &entry) {
+		return entry, false, false
+	}
+
+	framebuffer, ret := inst.GetFbUsage()
+	if !b.softRead(vgpuFamily, "cannot read a vGPU instance's framebuffer usage", ret) {
+		return entry, false, false
+	}
+
+	if ret == nvml.SUCCESS {
+		used := float64(framebuffer)
+		entry.FramebufferUsedBytes = &used
+	}
+
+	sessions, _, _, ret := inst.GetEncoderStats()
+	if !b.softRead(vgpuFamily, "cannot read a vGPU instance's encoder stats", ret) {
+		return entry, false, false
+	}
+
+	if ret == nvml.SUCCESS {
+		count := float64(sessions)
+		entry.EncoderSessions = &count
+	}
+
+	return entry, true, true
+}
+
+// vgpuTypeOf fills the instance's type name and class. Reports whether
+// extras collection may continue.
+func (b *Backend) vgpuTypeOf(inst vgpuInstance, entry *collect.VGPUInstance) bool {
+	typeID, ret := inst.GetType()
+	if !b.softRead(vgpuFamily, "cannot read a vGPU instance's type", ret) {
+		return false
+	}
+
+	if ret != nvml.SUCCESS {
+		return true
+	}
+
+	name, ret := typeID.GetName()
+	if !b.softRead(vgpuFamily, "cannot read a vGPU type name", ret) {
+		return false
+	}
+
+	if ret == nvml.SUCCESS {
+		entry.Type = name
+	}
+
+	class, ret := typeID.GetClass()
+	if !b.softRead(vgpuFamily, "cannot read a vGPU type class", ret) {
+		return false
+	}
+
+	if ret == nvml.SUCCESS {
+		entry.Class = class
+	}
+
+	return true
+}
+
+// vgpuUtilization fills the host's instances with their engine utilization
+// over the window since the previous collection. byHandle maps the driver's
+// instance handles, which the samples are keyed by, to the host's
+// instances. Reports whether extras collection may continue.
+func (b *Backend) vgpuUtilization(dev device, host *collect.VGPUHost, byHandle map[uint32]int) bool {
+	if b.vgpuUtilSeen == nil {
+		b.vgpuUtilSeen = map[string]uint64{}
+	}
+
+	lastSeen, known := b.vgpuUtilSeen[host.UUID]
+	if !known {
+		// sample timestamps are CPU time in microseconds since the epoch
+		b.vgpuUtilSeen[host.UUID] = uint64(b.now().UnixMicro())
+
+		return true
+	}
+
+	valueType, samples, ret := dev.GetVgpuUtilization(lastSeen)
+
+	//nolint:exhaustive // every other return is a plain failure
+	switch ret {
+	case nvml.SUCCESS:
+	case nvml.ERROR_NOT_FOUND:
+		// no sample newer than the window start
+		return true
+	case nvml.ERROR_NOT_SUPPORTED, nvml.ERROR_FUNCTION_NOT_FOUND:
+		return true
+	default:
+		return b.extrasFailure(vgpuFamily, "cannot read the vGPU utilization", ret)
+	}
+
+	sums := map[int]*vgpuUtilSum{}
+
+	for _, sample := range samples {
+		if sample.TimeStamp <= lastSeen {
+			continue
+		}
+
+		b.vgpuUtilSeen[host.UUID] = max(b.vgpuUtilSeen[host.UUID], sample.TimeStamp)
+
+		idx, listed := byHandle[sample.VgpuInstance]
+		if !listed {
+			// an instance that stopped since it was listed
+			continue
+		}
+
+		sum := sums[idx]
+		if sum == nil {
+			sum = &vgpuUtilSum{}
+			sums[idx] = sum
+		}
+
+		sum.sm.add(valueType, sample.SmUtil)
+		sum.memory.add(valueType, sample.MemUtil)
+		sum.enc.add(valueType, sample.EncUtil)
+		sum.dec.add(valueType, sample.DecUtil)
+	}
+
+	for idx, sum := range sums {
+		entry := &host.Instances[idx]
+		entry.SMRatio = sum.sm.ratio()
+		entry.MemoryRatio = sum.memory.ratio()
+		entry.EncoderRatio = sum.enc.ratio()
+		entry.DecoderRatio = sum.dec.ratio()
+	}
+
+	return true
+}
+
+// dropOrphanVGPUUtilWindows forgets the utilization windows of GPUs that
+// disappeared, so one that returns starts over instead of averaging across
+// its absence.
+func (b *Backend) dropOrphanVGPUUtilWindows(seenGPUs map[string]bool) {
+	for uuid := range b.vgpuUtilSeen {
+		if !seenGPUs[uuid] {
+			delete(b.vgpuUtilSeen, uuid)
+		}
+	}
+}
+
+// rawVgpuHandle reads the driver's handle out of a go-nvml vGPU instance.
+// The utilization samples name their instance by that handle, a plain
+// integer, but go-nvml keeps the type holding it unexported, so reflection
+// is the only way to match the two.
+func rawVgpuHandle(inst nvml.VgpuInstance) (uint32, bool) {
+	value := reflect.ValueOf(inst)
+	if value.Kind() != reflect.Uint32 {
+		return 0, false
+	}
+
+	return uint32(value.Uint()), true //nolint:gosec // G115: the kind is uint32
+}
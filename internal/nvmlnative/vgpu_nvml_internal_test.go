@@ -0,0 +1,156 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+func vgpuSample(handle uint32, timestamp uint64, sm, memory uint32) nvml.VgpuInstanceUtilizationSample {
+	sample := nvml.VgpuInstanceUtilizationSample{VgpuInstance: handle, TimeStamp: timestamp}
+	binary.LittleEndian.PutUint32(sample.SmUtil[:], sm)
+	binary.LittleEndian.PutUint32(sample.MemUtil[:], memory)
+
+	return sample
+}
+
+// mockVGPU serves one vGPU instance of the given uuid running in VM vm.
+func mockVGPU(uuid, vm string) *mock.VgpuInstance {
+	return &mock.VgpuInstance{
+		GetUUIDFunc: func() (string, nvml.Return) { return uuid, nvml.SUCCESS },
+		GetVmIDFunc: func() (string, nvml.VgpuVmIdType, nvml.Return) {
+			return vm, nvml.VGPU_VM_ID_UUID, nvml.SUCCESS
+		},
+		GetTypeFunc: func() (nvml.VgpuTypeId, nvml.Return) {
+			return &mock.VgpuTypeId{
+				GetNameFunc:  func() (string, nvml.Return) { return "NVIDIA H100-40C", nvml.SUCCESS },
+				GetClassFunc: func() (string, nvml.Return) { return "Compute", nvml.SUCCESS },
+			}, nvml.SUCCESS
+		},
+		GetFbUsageFunc:      func() (uint64, nvml.Return) { return 8 << 30, nvml.SUCCESS },
+		GetEncoderStatsFunc: func() (int, uint32, uint32, nvml.Return) { return 2, 60, 900, nvml.SUCCESS },
+	}
+}
+
+func TestExtrasVGPUs(t *testing.T) {
+	t.Parallel()
+
+	first := mockVGPU("00000000-aaaa-bbbb-cccc-000000000001", "vm-1")
+	second := mockVGPU("00000000-aaaa-bbbb-cccc-000000000002", "vm-2")
+	second.GetEncoderStatsFunc = func() (int, uint32, uint32, nvml.Return) {
+		return 0, 0, 0, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetActiveVgpusFunc = func() ([]nvml.VgpuInstance, nvml.Return) {
+		return []nvml.VgpuInstance{first, second}, nvml.SUCCESS
+	}
+	dev.GetVgpuUtilizationFunc = func(lastSeen uint64) (nvml.ValueType, []nvml.VgpuInstanceUtilizationSample, nvml.Return) {
+		return nvml.VALUE_TYPE_UNSIGNED_INT, []nvml.VgpuInstanceUtilizationSample{
+			vgpuSample(7, lastSeen-1, 100, 100), // before the window
+			vgpuSample(7, lastSeen+1, 40, 10),
+			vgpuSample(7, lastSeen+2, 60, 30),
+			vgpuSample(9, lastSeen+2, 50, 50), // an instance that stopped since
+		}, nvml.SUCCESS
+	}
+
+	backend := newTestBackend(t, &fakeAPI{devices: []nvml.Device{dev}})
+	backend.now = func() time.Time { return time.UnixMicro(1_000_000) }
+	backend.vgpuHandle = func(inst nvml.VgpuInstance) (uint32, bool) {
+		// the samples key only the first instance
+		return 7, inst == first
+	}
+
+	collectOnce := func() []collect.VGPUHost {
+		reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{VGPU: true})(t.Context())
+		require.NoError(t, err)
+
+		return reading.Extras.VGPUs
+	}
+
+	value := func(v float64) *float64 { return &v }
+
+	hosts := collectOnce()
+	require.Len(t, hosts, 1)
+	assert.Equal(t, collect.VGPUInstance{
+		UUID:                 "00000000-aaaa-bbbb-cccc-000000000001",
+		VMID:                 "vm-1",
+		Type:                 "NVIDIA H100-40C",
+		Class:                "Compute",
+		FramebufferUsedBytes: value(8 << 30),
+		EncoderSessions:      value(2),
+	}, hosts[0].Instances[0], "the first cycle only opens the utilization window")
+	assert.Nil(t, hosts[0].Instances[1].EncoderSessions)
+	assert.Empty(t, dev.GetVgpuUtilizationCalls())
+
+	hosts = collectOnce()
+	require.Len(t, hosts, 1)
+	assert.Equal(t, "11111111-2222-3333-4444-555555555555", hosts[0].UUID)
+	require.Len(t, hosts[0].Instances, 2)
+
+	instance := hosts[0].Instances[0]
+	assert.InDelta(t, 0.5, *instance.SMRatio, 1e-9)
+	assert.InDelta(t, 0.2, *instance.MemoryRatio, 1e-9)
+	assert.InDelta(t, 0, *instance.EncoderRatio, 1e-9)
+	assert.Nil(t, hosts[0].Instances[1].SMRatio, "an unsampled instance has no utilization")
+
+	calls := dev.GetVgpuUtilizationCalls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, uint64(1_000_000), calls[0].V, "the window starts when it was opened")
+}
+
+func TestExtrasVGPUsNoHost(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetActiveVgpusFunc = func() ([]nvml.VgpuInstance, nvml.Return) { return nil, nvml.ERROR_NOT_SUPPORTED }
+
+	backend := newTestBackend(t, &fakeAPI{devices: []nvml.Device{dev}})
+
+	for range 2 {
+		reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{VGPU: true})(t.Context())
+		require.NoError(t, err)
+		assert.Empty(t, reading.Extras.VGPUs, "bare metal GPUs have no vGPU entry")
+	}
+
+	assert.Empty(t, dev.GetVgpuUtilizationCalls(), "a GPU that is no host is never sampled")
+}
+
+func TestExtrasVGPUsLifecycleAborts(t *testing.T) {
+	t.Parallel()
+
+	instance := mockVGPU("00000000-aaaa-bbbb-cccc-000000000001", "vm-1")
+	instance.GetFbUsageFunc = func() (uint64, nvml.Return) { return 0, nvml.ERROR_GPU_IS_LOST }
+
+	dev := identityDevice()
+	dev.GetPowerUsageFunc = func() (uint32, nvml.Return) { return 12340, nvml.SUCCESS }
+	dev.GetActiveVgpusFunc = func() ([]nvml.VgpuInstance, nvml.Return) {
+		return []nvml.VgpuInstance{instance}, nvml.SUCCESS
+	}
+
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	backend := newTestBackend(t, fake)
+
+	reading, _, err := backend.QueryFunc(resolveFields(t, "power.draw"), CollectOptions{VGPU: true})(t.Context())
+	require.NoError(t, err, "extras must never fail the collection")
+	assert.Empty(t, reading.Extras.VGPUs)
+	assert.Equal(t, int64(1), fake.shutdowns.Load(), "the lifecycle error must mark the backend for re-init")
+}
+
+func TestRawVgpuHandleRejectsForeignTypes(t *testing.T) {
+	t.Parallel()
+
+	_, ok := rawVgpuHandle(&mock.VgpuInstance{})
+	assert.False(t, ok, "only go-nvml's own handle carries the driver's id")
+}
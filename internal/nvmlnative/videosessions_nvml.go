@@ -0,0 +1,83 @@
+//go:build linux && cgo
+
+package nvmlnative
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// encoderCodecs names the codecs an encoder session can report.
+//
+//nolint:gochecknoglobals // lookup table
+var encoderCodecs = map[uint32]string{
+	uint32(nvml.ENCODER_QUERY_H264): "h264",
+	uint32(nvml.ENCODER_QUERY_HEVC): "hevc",
+	uint32(nvml.ENCODER_QUERY_AV1):  "av1",
+}
+
+// collectVideoSessions appends one device's active encoder and NvFBC
+// sessions. Each kind is read on its own: a GPU without NVENC, or a driver
+// without NvFBC support or without the session getter, skips that kind
+// silently. Reports whether extras collection may continue.
+func (b *Backend) collectVideoSessions(dev device, uuid string, extras *collect.Extras) bool {
+	encoder, ret := dev.GetEncoderSessions()
+	if !b.softRead("video-sessions", "cannot read the encoder sessions", ret) {
+		return false
+	}
+
+	if ret == nvml.SUCCESS {
+		sessions := make([]collect.VideoSession, 0, len(encoder))
+		for _, info := range encoder {
+			codec, known := encoderCodecs[info.CodecType]
+			if !known {
+				codec = "unknown"
+			}
+
+			sessions = append(sessions, videoSession(
+				info.SessionId, info.Pid, codec, info.HResolution, info.VResolution,
+				info.AverageFps, info.AverageLatency))
+		}
+
+		extras.VideoSessions = append(extras.VideoSessions, collect.VideoSessions{
+			UUID: uuid, Type: collect.VideoSessionEncoder, Sessions: sessions,
+		})
+	}
+
+	fbc, ret := dev.GetFBCSessions()
+	if !b.softRead("video-sessions", "cannot read the NvFBC sessions", ret) {
+		return false
+	}
+
+	if ret == nvml.SUCCESS {
+		sessions := make([]collect.VideoSession, 0, len(fbc))
+		for _, info := range fbc {
+			sessions = append(sessions, videoSession(
+				info.SessionId, info.Pid, "", info.HResolution, info.VResolution,
+				info.AverageFPS, info.AverageLatency))
+		}
+
+		extras.VideoSessions = append(extras.VideoSessions, collect.VideoSessions{
+			UUID: uuid, Type: collect.VideoSessionFBC, Sessions: sessions,
+		})
+	}
+
+	return true
+}
+
+// videoSession converts the fields both session kinds share. The driver
+// reports latency in microseconds.
+func videoSession(id, pid uint32, codec string, width, height, fps, latencyUs uint32) collect.VideoSession {
+	return collect.VideoSession{
+		ID:                    strconv.FormatUint(uint64(id), 10),
+		PID:                   strconv.FormatUint(uint64(pid), 10),
+		Codec:                 codec,
+		Resolution:            fmt.Sprintf("%dx%d", width, height),
+		AverageFPS:            float64(fps),
+		AverageLatencySeconds: float64(latencyUs) / 1e6,
+	}
+}
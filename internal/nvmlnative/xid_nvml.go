@@ -11,27 +11,46 @@ import (
 	"github.com/NVIDIA/go-nvml/pkg/nvml"
 
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/eventlog"
 	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/xidcatalog"
 )
 
-// xidWaitTimeoutMs bounds each event wait, so the watcher regularly
+// eventWaitTimeoutMs bounds each event wait, so the watcher regularly
 // re-checks its generation and the context without busy-looping.
-const xidWaitTimeoutMs = 1000
+const eventWaitTimeoutMs = 1000
 
-// xidBackoffStart and xidBackoffMax pace the registration retries when NVML
-// is down or events are unavailable. The backoff resets after a successful
-// registration and is context-aware, so shutdown never waits it out.
+// eventBackoffStart and eventBackoffMax pace the registration retries when
+// NVML is down or events are unavailable. The backoff resets after a
+// successful registration and is context-aware, so shutdown never waits it
+// out.
 const (
-	xidBackoffStart = time.Second
-	xidBackoffMax   = 30 * time.Second
+	eventBackoffStart = time.Second
+	eventBackoffMax   = 30 * time.Second
 )
 
-// xidAccumulator is the cross-cycle XID state: written by the watcher
-// goroutine, read at scrape time. It has its own lock because it is the only
-// state shared between the watcher and the scrape path.
-type xidAccumulator struct {
-	mu    sync.Mutex
-	stats map[string]map[uint64]*xidStat
+// driverEventTypes maps the NVML event types the watcher registers for to
+// their type label. P-state changes are left out: they fire on every
+// power-management transition and say nothing the pstate gauge does not.
+//
+//nolint:gochecknoglobals // lookup table
+var driverEventTypes = map[uint64]string{
+	nvml.EventTypeXidCriticalError:  collect.DriverEventXID,
+	nvml.EventTypeSingleBitEccError: collect.DriverEventECCSingleBit,
+	nvml.EventTypeDoubleBitEccError: collect.DriverEventECCDoubleBit,
+	nvml.EventTypeClock:             collect.DriverEventClockChange,
+	nvml.EventTypePowerSourceChange: collect.DriverEventPowerSourceChange,
+	nvml.EventMigConfigChange:       collect.DriverEventMIGConfigChange,
+}
+
+// eventAccumulator is the cross-cycle driver event state: written by the
+// watcher goroutine, read at scrape time. It has its own lock because it is
+// the only state shared between the watcher and the scrape path.
+type eventAccumulator struct {
+	mu   sync.Mutex
+	xids map[string]map[uint64]*xidStat
+	// events counts every event by GPU and type label, XIDs included.
+	events map[string]map[string]uint64
 	// waitWarned makes a persistent event-wait failure visible exactly
 	// once, alongside the accumulator because it shares the same lock.
 	waitWarned bool
@@ -43,19 +62,44 @@ type xidStat struct {
 	last  time.Time
 }
 
-// bump records one observed event.
-func (a *xidAccumulator) bump(uuid string, xid uint64, at time.Time) {
+// bump records one observed event of the given type label.
+func (a *eventAccumulator) bump(uuid, eventType string) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	if a.stats == nil {
-		a.stats = map[string]map[uint64]*xidStat{}
+	a.count(uuid, eventType)
+}
+
+// count adds one event to the per-type counts. The caller holds mu.
+func (a *eventAccumulator) count(uuid, eventType string) {
+	if a.events == nil {
+		a.events = map[string]map[string]uint64{}
 	}
 
-	perGPU := a.stats[uuid]
+	perGPU := a.events[uuid]
+	if perGPU == nil {
+		perGPU = map[string]uint64{}
+		a.events[uuid] = perGPU
+	}
+
+	perGPU[eventType]++
+}
+
+// bumpXID records one observed XID error beside its event count.
+func (a *eventAccumulator) bumpXID(uuid string, xid uint64, at time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.count(uuid, collect.DriverEventXID)
+
+	if a.xids == nil {
+		a.xids = map[string]map[uint64]*xidStat{}
+	}
+
+	perGPU := a.xids[uuid]
 	if perGPU == nil {
 		perGPU = map[uint64]*xidStat{}
-		a.stats[uuid] = perGPU
+		a.xids[uuid] = perGPU
 	}
 
 	stat := perGPU[xid]
@@ -68,14 +112,15 @@ func (a *xidAccumulator) bump(uuid string, xid uint64, at time.Time) {
 	stat.last = at
 }
 
-// counts snapshots the accumulated state, sorted for deterministic output.
-func (a *xidAccumulator) counts() []collect.XIDCounter {
+// xidCounts snapshots the accumulated XID state, sorted for deterministic
+// output.
+func (a *eventAccumulator) xidCounts() []collect.XIDCounter {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
 	var counters []collect.XIDCounter
 
-	for uuid, perGPU := range a.stats {
+	for uuid, perGPU := range a.xids {
 		for xid, stat := range perGPU {
 			counters = append(counters, collect.XIDCounter{
 				UUID:     uuid,
@@ -97,35 +142,74 @@ func (a *xidAccumulator) counts() []collect.XIDCounter {
 	return counters
 }
 
+// eventCounts snapshots the accumulated event counts, sorted for
+// deterministic output.
+func (a *eventAccumulator) eventCounts() []collect.DriverEventCounter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var counters []collect.DriverEventCounter
+
+	for uuid, perGPU := range a.events {
+		for eventType, count := range perGPU {
+			counters = append(counters, collect.DriverEventCounter{UUID: uuid, Type: eventType, Count: count})
+		}
+	}
+
+	sort.Slice(counters, func(i, j int) bool {
+		if counters[i].UUID != counters[j].UUID {
+			return counters[i].UUID < counters[j].UUID
+		}
+
+		return counters[i].Type < counters[j].Type
+	})
+
+	return counters
+}
+
 // XIDCounts serves the accumulated XID error counts to the exporter. Safe
 // for concurrent use; independent of the collection pipeline by design, so
 // the counters stay visible while collections fail.
 func (b *Backend) XIDCounts() []collect.XIDCounter {
-	return b.xids.counts()
+	return b.events.xidCounts()
+}
+
+// DriverEventCounts serves the accumulated driver event counts to the
+// exporter, with the same guarantees as XIDCounts.
+func (b *Backend) DriverEventCounts() []collect.DriverEventCounter {
+	return b.events.eventCounts()
+}
+
+// RecentEvents serves the latest driver events the watcher observed, oldest
+// first, with the same guarantees as XIDCounts.
+func (b *Backend) RecentEvents(filter eventlog.Filter) []eventlog.Event {
+	return b.recent.RecentEvents(filter)
 }
 
-// xidWatcherLog makes each watcher failure mode visible exactly once, so a
+// eventWatcherLog makes each watcher failure mode visible exactly once, so a
 // setup without event support does not flood the log every backoff round.
-type xidWatcherLog struct {
+type eventWatcherLog struct {
 	createWarned   bool
 	registerWarned bool
 	noneWarned     bool
 }
 
-// RunXIDWatcher registers for XID error events on every device and
-// accumulates them until ctx ends. It runs beside the collection cycles, not
-// inside them: XIDs must be caught as they happen, not when a scrape
-// arrives. It never returns an error: on a machine without event support the
-// watcher idles (retrying periodically, in case a driver reload changes the
-// answer) and the XID families simply stay empty. When the driver generation
-// dies underneath it, the watcher drives the re-initialization itself, so
-// event collection recovers even when nothing is scraping.
-func (b *Backend) RunXIDWatcher(ctx context.Context) error {
-	warnings := &xidWatcherLog{}
-	backoff := xidBackoffStart
+// RunXIDWatcher registers for XID errors and the other driver events on
+// every device and accumulates them until ctx ends. It runs beside the
+// collection cycles, not inside them: XIDs must be caught as they happen, not
+// when a scrape arrives. It never returns an error: on a machine without
+// event support the watcher idles (retrying periodically, in case a driver
+// reload changes the answer) and the event families simply stay empty. When
+// the driver generation dies underneath it, the watcher drives the
+// re-initialization itself, so event collection recovers even when nothing
+// is scraping. The catalog describes the observed XIDs in the watcher's log;
+// a nil catalog leaves them bare.
+func (b *Backend) RunXIDWatcher(ctx context.Context, catalog *xidcatalog.Catalog) error {
+	warnings := &eventWatcherLog{}
+	backoff := eventBackoffStart
 
 	for ctx.Err() == nil {
-		set, uuids, generation, ok := b.xidRegister(warnings)
+		set, uuids, generation, ok := b.eventRegister(warnings)
 		if !ok {
 			// registration failing usually means the driver generation is
 			// dead: recover it here rather than waiting for a scrape
@@ -135,18 +219,18 @@ func (b *Backend) RunXIDWatcher(ctx context.Context) error {
 				return nil
 			}
 
-			backoff = min(backoff*2, xidBackoffMax)
+			backoff = min(backoff*2, eventBackoffMax)
 
 			continue
 		}
 
 		// a fresh registration round may fail for fresh reasons: make them
 		// visible again instead of staying silenced forever
-		*warnings = xidWatcherLog{}
+		*warnings = eventWatcherLog{}
 
-		healthy := b.xidWait(ctx, set, uuids, generation)
+		healthy := b.eventWait(ctx, set, uuids, generation, catalog)
 		if healthy {
-			backoff = xidBackoffStart
+			backoff = eventBackoffStart
 
 			continue
 		}
@@ -157,20 +241,20 @@ func (b *Backend) RunXIDWatcher(ctx context.Context) error {
 			return nil
 		}
 
-		backoff = min(backoff*2, xidBackoffMax)
+		backoff = min(backoff*2, eventBackoffMax)
 	}
 
 	return nil
 }
 
-// xidRegister builds an event set and registers every device for XID
-// events, under the shared lifecycle barrier. It reports the set, the
-// registration-time uuid cache (a GPU may be unreadable by the time it
-// errors), the NVML generation the set belongs to, and whether anything was
-// registered at all.
+// eventRegister builds an event set and registers every device for the
+// driver events it supports, under the shared lifecycle barrier. It reports
+// the set, the registration-time uuid cache (a GPU may be unreadable by the
+// time it errors), the NVML generation the set belongs to, and whether
+// anything was registered at all.
 //
 //nolint:cyclop,funlen,ireturn // one linear pass; the event set is go-nvml.s own interface type
-func (b *Backend) xidRegister(warnings *xidWatcherLog) (nvml.EventSet, map[nvml.Device]string, uint64, bool) {
+func (b *Backend) eventRegister(warnings *eventWatcherLog) (nvml.EventSet, map[nvml.Device]string, uint64, bool) {
 	b.lifecycleMu.RLock()
 	defer b.lifecycleMu.RUnlock()
 
@@ -221,11 +305,11 @@ func (b *Backend) xidRegister(warnings *xidWatcherLog) (nvml.EventSet, map[nvml.
 			continue
 		}
 
-		if ret := dev.RegisterEvents(nvml.EventTypeXidCriticalError, set); ret != nvml.SUCCESS {
+		if ret := dev.RegisterEvents(eventMask(dev), set); ret != nvml.SUCCESS {
 			if !warnings.registerWarned {
 				warnings.registerWarned = true
 
-				b.logger.Warn("cannot register for XID error events on a GPU",
+				b.logger.Warn("cannot register for driver events on a GPU",
 					"uuid", nvidiasmi.NormalizeUUID(uuid), "nvml_return", retString(ret))
 			}
 
@@ -241,7 +325,7 @@ func (b *Backend) xidRegister(warnings *xidWatcherLog) (nvml.EventSet, map[nvml.
 		if !warnings.noneWarned {
 			warnings.noneWarned = true
 
-			b.logger.Warn("registered no GPU for XID error events, the XID counters stay empty")
+			b.logger.Warn("registered no GPU for driver events, the XID and event counters stay empty")
 		}
 
 		return nil, nil, 0, false
@@ -255,7 +339,27 @@ func (b *Backend) xidRegister(warnings *xidWatcherLog) (nvml.EventSet, map[nvml.
 	return set, uuids, generation, true
 }
 
-// xidWait drains the event set until the context ends, the NVML generation
+// eventMask picks the event types to register a device for: every type the
+// watcher counts that the device supports. A driver that cannot list the
+// supported types gets the XID registration alone, which every GPU the
+// watcher can serve supports; asking for an unsupported type would fail the
+// whole registration.
+func eventMask(dev device) uint64 {
+	supported, ret := dev.GetSupportedEventTypes()
+	if ret != nvml.SUCCESS {
+		return nvml.EventTypeXidCriticalError
+	}
+
+	var mask uint64
+
+	for eventType := range driverEventTypes {
+		mask |= eventType
+	}
+
+	return mask & supported
+}
+
+// eventWait drains the event set until the context ends, the NVML generation
 // dies, or the set fails. Every driver call happens under the shared
 // lifecycle barrier. The set is freed under that same barrier, and ONLY
 // while its generation is still alive: once the generation died, the
@@ -264,11 +368,12 @@ func (b *Backend) xidRegister(warnings *xidWatcherLog) (nvml.EventSet, map[nvml.
 // the unfreeable set is the lesser evil, and the next real shutdown
 // reclaims it). Reports whether the wait path stayed healthy: false means
 // the driver failed the wait itself and the rebuild must be paced.
-func (b *Backend) xidWait(
+func (b *Backend) eventWait(
 	ctx context.Context,
 	set nvml.EventSet,
 	uuids map[nvml.Device]string,
 	generation uint64,
+	catalog *xidcatalog.Catalog,
 ) bool {
 	generationAlive := func() bool {
 		return b.genLive.Load() && b.generation.Load() == generation
@@ -287,13 +392,13 @@ func (b *Backend) xidWait(
 			return true
 		}
 
-		data, ret := set.Wait(xidWaitTimeoutMs)
+		data, ret := set.Wait(eventWaitTimeoutMs)
 
 		//nolint:exhaustive // every other return is a failed wait
 		switch ret {
 		case nvml.SUCCESS:
 			b.lifecycleMu.RUnlock()
-			b.recordXID(uuids, data)
+			b.recordEvent(uuids, data, catalog)
 		case nvml.ERROR_TIMEOUT:
 			b.lifecycleMu.RUnlock()
 		default:
@@ -306,31 +411,37 @@ func (b *Backend) xidWait(
 
 			b.lifecycleMu.RUnlock()
 
-			b.warnOnceXID("GPU XID event wait failed, rebuilding the event registration", ret)
+			b.warnOnceEvent("GPU driver event wait failed, rebuilding the event registration", ret)
 
 			return false
 		}
 	}
 }
 
-// warnOnceXID logs a wait failure without flooding: the accumulator mutex
+// warnOnceEvent logs a wait failure without flooding: the accumulator mutex
 // guards the flag because the watcher is the only writer but tests may run
 // concurrent watchers.
-func (b *Backend) warnOnceXID(msg string, ret nvml.Return) {
-	b.xids.mu.Lock()
-	defer b.xids.mu.Unlock()
+func (b *Backend) warnOnceEvent(msg string, ret nvml.Return) {
+	b.events.mu.Lock()
+	defer b.events.mu.Unlock()
 
-	if b.xids.waitWarned {
+	if b.events.waitWarned {
 		return
 	}
 
-	b.xids.waitWarned = true
+	b.events.waitWarned = true
 
 	b.logger.Warn(msg, "nvml_return", retString(ret))
 }
 
-// recordXID folds one received event into the accumulator.
-func (b *Backend) recordXID(uuids map[nvml.Device]string, data nvml.EventData) {
+// recordEvent folds one received event into the accumulator. An event type
+// the watcher did not ask for is dropped.
+func (b *Backend) recordEvent(uuids map[nvml.Device]string, data nvml.EventData, catalog *xidcatalog.Catalog) {
+	eventType, ok := driverEventTypes[data.EventType]
+	if !ok {
+		return
+	}
+
 	uuid := uuids[data.Device]
 	if uuid == "" {
 		// an event from a device that was not in the registration cache:
@@ -338,9 +449,36 @@ func (b *Backend) recordXID(uuids map[nvml.Device]string, data nvml.EventData) {
 		uuid = "unknown"
 	}
 
-	b.xids.bump(uuid, data.EventData, b.now())
+	now := b.now()
+
+	event := eventlog.Event{Time: now, UUID: uuid, Type: eventType, Data: data.EventData}
+	if eventType == collect.DriverEventXID {
+		event.XID = data.EventData
+	}
+
+	b.recent.Add(event)
+
+	//nolint:exhaustive // the remaining types are routine
+	switch eventType {
+	case collect.DriverEventXID:
+		b.events.bumpXID(uuid, data.EventData, now)
+
+		b.logger.Warn("observed a GPU XID error", xidLogAttrs(catalog, uuid, data.EventData)...)
+	case collect.DriverEventECCDoubleBit:
+		b.events.bump(uuid, eventType)
+
+		b.logger.Warn("observed a GPU double-bit ECC error", "uuid", uuid)
+	default:
+		b.events.bump(uuid, eventType)
+
+		b.logger.Debug("observed a GPU driver event", "uuid", uuid, "type", eventType)
+	}
+}
 
-	b.logger.Warn("observed a GPU XID error", "uuid", uuid, "xid", data.EventData)
+// xidLogAttrs labels an observed XID for the log, with its catalog entry
+// when the catalog describes the code.
+func xidLogAttrs(catalog *xidcatalog.Catalog, uuid string, xid uint64) []any {
+	return append([]any{"uuid", uuid, "xid", xid}, catalog.LogAttrs(xid)...)
 }
 
 // sleepContext sleeps for the given duration, reporting false when the
@@ -14,6 +14,10 @@ import (
 	"github.com/neilotoole/slogt/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/eventlog"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/xidcatalog"
 )
 
 // xidFake drives the watcher: a queue of events served by Wait, plus
@@ -31,6 +35,10 @@ type xidFake struct {
 	wedge    chan struct{}
 	regRet   nvml.Return
 	waitRet  nvml.Return
+	// supported is the device's supported event mask, zero for a driver
+	// that cannot list it; mask records the last registered one.
+	supported uint64
+	mask      atomic.Uint64
 }
 
 func (x *xidFake) push(data nvml.EventData) {
@@ -90,11 +98,19 @@ func (x *xidFake) install(api *nvmlAPI, dev *mock.Device) {
 		}, nvml.SUCCESS
 	}
 
-	dev.RegisterEventsFunc = func(uint64, nvml.EventSet) nvml.Return {
+	dev.GetSupportedEventTypesFunc = func() (uint64, nvml.Return) {
+		if x.supported == 0 {
+			return 0, nvml.ERROR_NOT_SUPPORTED
+		}
+
+		return x.supported, nvml.SUCCESS
+	}
+	dev.RegisterEventsFunc = func(mask uint64, _ nvml.EventSet) nvml.Return {
 		if x.regRet != nvml.SUCCESS {
 			return x.regRet
 		}
 
+		x.mask.Store(mask)
 		x.regs.Add(1)
 
 		return nvml.SUCCESS
@@ -111,7 +127,7 @@ func startWatcher(t *testing.T, backend *Backend) (context.CancelFunc, *sync.Wai
 	var wg sync.WaitGroup
 
 	wg.Go(func() {
-		assert.NoError(t, backend.RunXIDWatcher(ctx))
+		assert.NoError(t, backend.RunXIDWatcher(ctx, xidcatalog.Builtin()))
 	})
 
 	return cancel, &wg
@@ -155,6 +171,95 @@ func TestXIDWatcherCountsEvents(t *testing.T) {
 	assert.Equal(t, xid.creates.Load(), xid.frees.Load(), "every event set must be freed")
 }
 
+func TestXIDWatcherCountsDriverEvents(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	xid := &xidFake{supported: nvml.EventTypeXidCriticalError | nvml.EventTypeDoubleBitEccError |
+		nvml.EventTypeClock | nvml.EventTypePState}
+
+	api := fake.api()
+	xid.install(&api, dev)
+
+	backend, err := newWithAPI(api, slogt.New(t))
+	require.NoError(t, err)
+
+	xid.push(nvml.EventData{Device: dev, EventType: nvml.EventTypeClock})
+	xid.push(nvml.EventData{Device: dev, EventType: nvml.EventTypePState})
+	xid.push(nvml.EventData{Device: dev, EventType: nvml.EventTypeDoubleBitEccError})
+	xid.push(nvml.EventData{Device: dev, EventType: nvml.EventTypeClock})
+	xid.push(nvml.EventData{Device: dev, EventType: nvml.EventTypeXidCriticalError, EventData: 48})
+
+	cancel, wg := startWatcher(t, backend)
+
+	require.Eventually(t, func() bool {
+		return len(backend.XIDCounts()) == 1
+	}, 5*time.Second, 5*time.Millisecond, "the last queued event must be counted")
+
+	cancel()
+	wg.Wait()
+
+	assert.Equal(t,
+		uint64(nvml.EventTypeXidCriticalError|nvml.EventTypeDoubleBitEccError|nvml.EventTypeClock),
+		xid.mask.Load(), "only the supported types the watcher counts may be registered")
+
+	uuid := "11111111-2222-3333-4444-555555555555"
+
+	assert.Equal(t, []collect.DriverEventCounter{
+		{UUID: uuid, Type: collect.DriverEventClockChange, Count: 2},
+		{UUID: uuid, Type: collect.DriverEventECCDoubleBit, Count: 1},
+		{UUID: uuid, Type: collect.DriverEventXID, Count: 1},
+	}, backend.DriverEventCounts(), "a P-state change is not counted")
+
+	type logged struct {
+		eventType string
+		xid       uint64
+	}
+
+	var got []logged
+
+	for _, event := range backend.RecentEvents(eventlog.Filter{}) {
+		assert.Equal(t, uuid, event.UUID)
+		assert.False(t, event.Time.IsZero())
+
+		got = append(got, logged{event.Type, event.XID})
+	}
+
+	assert.Equal(t, []logged{
+		{collect.DriverEventClockChange, 0},
+		{collect.DriverEventECCDoubleBit, 0},
+		{collect.DriverEventClockChange, 0},
+		{collect.DriverEventXID, 48},
+	}, got, "the log keeps the arrival order")
+	assert.Len(t, backend.RecentEvents(eventlog.Filter{XIDs: []uint64{48}}), 1)
+}
+
+func TestXIDWatcherRegistersXIDAloneWithoutSupportedTypes(t *testing.T) {
+	t.Parallel()
+
+	dev := identityDevice()
+	fake := &fakeAPI{devices: []nvml.Device{dev}}
+	xid := &xidFake{}
+
+	api := fake.api()
+	xid.install(&api, dev)
+
+	backend, err := newWithAPI(api, slogt.New(t))
+	require.NoError(t, err)
+
+	cancel, wg := startWatcher(t, backend)
+
+	require.Eventually(t, func() bool {
+		return xid.regs.Load() >= 1
+	}, 5*time.Second, 5*time.Millisecond)
+
+	cancel()
+	wg.Wait()
+
+	assert.Equal(t, uint64(nvml.EventTypeXidCriticalError), xid.mask.Load())
+}
+
 func TestXIDWatcherUnsupportedIdles(t *testing.T) {
 	t.Parallel()
 
@@ -359,3 +464,22 @@ func TestMarkLostSkipsShutdownBehindWedgedWait(t *testing.T) {
 	cancel()
 	wg.Wait()
 }
+
+func TestXIDLogAttrs(t *testing.T) {
+	t.Parallel()
+
+	catalog := xidcatalog.Builtin()
+	entry, ok := catalog.Lookup(79)
+	require.True(t, ok)
+
+	assert.Equal(t, []any{
+		"uuid", "gpu-0", "xid", uint64(79),
+		"description", entry.Description,
+		"severity", xidcatalog.SeverityCritical,
+		"action", entry.Action,
+		"reset_required", true,
+	}, xidLogAttrs(catalog, "gpu-0", 79))
+	assert.Equal(t, []any{"uuid", "gpu-0", "xid", uint64(1)}, xidLogAttrs(catalog, "gpu-0", 1),
+		"a code the catalog does not describe is logged bare")
+	assert.Equal(t, []any{"uuid", "gpu-0", "xid", uint64(79)}, xidLogAttrs(nil, "gpu-0", 79))
+}
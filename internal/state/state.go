@@ -0,0 +1,563 @@
+// Package state carries the exporter's cumulative counters across restarts.
+// The XID and driver event counts, the demo backend's integrated GPU and
+// module energy and the collection failure count all start from zero in a
+// new process, so an upgrade during an incident would wipe the evidence and
+// reset series that increase() then has to guess about. A Store checkpoints
+// the served values to a directory and, on the next start, adds them to the
+// live counters as a baseline.
+//
+// The checkpoint is keyed by GPU uuid and by driver generation: the backend,
+// the driver version and the host's boot id. State written under another
+// generation describes counters that have started over (a reboot or a driver
+// upgrade), so it is discarded rather than added.
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+)
+
+// fileName is the checkpoint's name inside the state directory.
+const fileName = "state.json"
+
+// formatVersion is bumped on any incompatible change of the checkpoint
+// format; a checkpoint of another version is discarded.
+const formatVersion = 1
+
+// checkpointInterval is how often the served counters are written out,
+// besides the final checkpoint at shutdown, which bounds what a crash loses.
+const checkpointInterval = 30 * time.Second
+
+// bootIDPath holds the kernel's random id of the current boot.
+const bootIDPath = "/proc/sys/kernel/random/boot_id"
+
+// Generation identifies the driver generation the counters belong to.
+type Generation struct {
+	// Backend is the collection backend; the backends count differently.
+	Backend string `json:"backend"`
+	// DriverVersion is the driver version at startup.
+	DriverVersion string `json:"driverVersion"`
+	// BootID is the kernel's boot id, empty where the kernel exposes none
+	// (outside Linux): a reboot with the same driver then goes unnoticed.
+	BootID string `json:"bootId"`
+}
+
+// CurrentGeneration returns the running generation of the given backend and
+// driver version.
+func CurrentGeneration(backend, driverVersion string) Generation {
+	bootID, err := os.ReadFile(bootIDPath)
+	if err != nil {
+		bootID = nil
+	}
+
+	return Generation{
+		Backend:       backend,
+		DriverVersion: driverVersion,
+		BootID:        strings.TrimSpace(string(bootID)),
+	}
+}
+
+// EventSource serves the live XID and driver event counters, the counters a
+// new process starts from zero.
+type EventSource interface {
+	XIDCounts() []collect.XIDCounter
+	DriverEventCounts() []collect.DriverEventCounter
+}
+
+// document is the checkpoint's on-disk form.
+type document struct {
+	Version    int                  `json:"version"`
+	Generation Generation           `json:"generation"`
+	SavedAt    time.Time            `json:"savedAt"`
+	GPUs       map[string]*gpuState `json:"gpus,omitempty"`
+	Collection collectionState      `json:"collection"`
+}
+
+// gpuState is one GPU's counters, keyed by its uuid in the document.
+type gpuState struct {
+	XIDs               []xidState        `json:"xids,omitempty"`
+	Events             map[string]uint64 `json:"events,omitempty"`
+	EnergyJoules       float64           `json:"energyJoules,omitempty"`
+	ModuleEnergyJoules float64           `json:"moduleEnergyJoules,omitempty"`
+}
+
+// xidState is one XID code's counter on a GPU.
+type xidState struct {
+	XID      uint64    `json:"xid"`
+	Count    uint64    `json:"count"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// collectionState is the collection health the exporter accumulates.
+type collectionState struct {
+	Failures    uint64    `json:"failures"`
+	LastSuccess time.Time `json:"lastSuccess,omitzero"`
+}
+
+// Store restores the counters checkpointed by a previous process and
+// checkpoints the current ones. It serves the restored baseline through
+// wrappers around the live sources: Events, Source and WrapQueryFunc.
+type Store struct {
+	dir        string
+	generation Generation
+	logger     *slog.Logger
+	interval   time.Duration
+
+	// restored is the previous process's state, never modified after Open.
+	restored document
+
+	mu sync.Mutex
+	// events is the live event source, nil until Events is called.
+	events EventSource
+	// energy, moduleEnergy and collection hold the values last served,
+	// which the checkpoints write out.
+	energy       map[string]float64
+	moduleEnergy map[string]float64
+	collection   collectionState
+	// saveWarned makes a persistent checkpoint failure visible exactly once.
+	saveWarned bool
+}
+
+// Open creates the state directory if needed, restores the checkpoint of the
+// same generation from it, and writes a first checkpoint, so a directory the
+// exporter cannot write to fails the startup instead of every checkpoint. An
+// unreadable checkpoint, or one of another generation, is discarded with a
+// log line: the counters then start from zero, as without a state directory.
+func Open(dir string, generation Generation, logger *slog.Logger) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create the state directory: %w", err)
+	}
+
+	store := &Store{
+		dir:        dir,
+		generation: generation,
+		logger:     logger,
+		interval:   checkpointInterval,
+	}
+
+	restored, err := store.load()
+	if err != nil {
+		return nil, err
+	}
+
+	store.restored = restored
+	store.energy = map[string]float64{}
+	store.moduleEnergy = map[string]float64{}
+	store.collection = restored.Collection
+
+	if err = store.save(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// load reads the checkpoint, returning an empty document when there is none
+// to restore.
+func (s *Store) load() (document, error) {
+	empty := document{Version: formatVersion, Generation: s.generation}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, fileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return empty, nil
+	}
+
+	if err != nil {
+		return document{}, fmt.Errorf("failed to read the state: %w", err)
+	}
+
+	var doc document
+
+	switch err = json.Unmarshal(data, &doc); {
+	case err != nil:
+		s.logger.Warn("discarding an unreadable state checkpoint", "dir", s.dir, "err", err)
+
+		return empty, nil
+	case doc.Version != formatVersion:
+		s.logger.Warn("discarding a state checkpoint of another format",
+			"dir", s.dir, "version", doc.Version)
+
+		return empty, nil
+	case s.generation.DriverVersion == "":
+		s.logger.Warn("discarding the state checkpoint: the driver version is unknown, "+
+			"so the checkpoint cannot be matched to the driver generation", "dir", s.dir)
+
+		return empty, nil
+	case doc.Generation != s.generation:
+		s.logger.Info("discarding the state checkpoint of another driver generation",
+			"dir", s.dir,
+			"saved_backend", doc.Generation.Backend,
+			"saved_driver_version", doc.Generation.DriverVersion,
+			"saved_boot_id", doc.Generation.BootID)
+
+		return empty, nil
+	}
+
+	s.logger.Info("restored the state checkpoint", "dir", s.dir, "saved_at", doc.SavedAt)
+
+	return doc, nil
+}
+
+// Run writes a checkpoint every interval until ctx ends, then a final one.
+// It never returns an error: a failed checkpoint is logged once, and the
+// next one retries.
+func (s *Store) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.checkpoint()
+
+			return nil
+		case <-ticker.C:
+			s.checkpoint()
+		}
+	}
+}
+
+// checkpoint saves the state, logging the first of consecutive failures.
+func (s *Store) checkpoint() {
+	err := s.save()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case err == nil:
+		s.saveWarned = false
+	case !s.saveWarned:
+		s.saveWarned = true
+
+		s.logger.Warn("failed to checkpoint the state", "dir", s.dir, "err", err)
+	}
+}
+
+// save writes the current state atomically: to a temporary file in the
+// directory, synced, then renamed over the checkpoint, so a crash leaves
+// either the previous checkpoint or the new one, never a torn file.
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.current(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the state: %w", err)
+	}
+
+	file, err := os.CreateTemp(s.dir, "."+fileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write the state: %w", err)
+	}
+
+	defer func() {
+		_ = os.Remove(file.Name())
+	}()
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), filepath.Join(s.dir, fileName))
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write the state: %w", err)
+	}
+
+	// persist the rename itself; best-effort, some platforms cannot sync
+	// a directory
+	if dir, err := os.Open(s.dir); err == nil {
+		_ = dir.Sync()
+		_ = dir.Close()
+	}
+
+	return nil
+}
+
+// current builds the document of the counters as served right now.
+func (s *Store) current() document {
+	doc := document{
+		Version:    formatVersion,
+		Generation: s.generation,
+		SavedAt:    time.Now(),
+		GPUs:       map[string]*gpuState{},
+	}
+
+	gpu := func(uuid string) *gpuState {
+		state := doc.GPUs[uuid]
+		if state == nil {
+			state = &gpuState{}
+			doc.GPUs[uuid] = state
+		}
+
+		return state
+	}
+
+	for _, counter := range s.xidCounts() {
+		state := gpu(counter.UUID)
+		state.XIDs = append(state.XIDs, xidState{XID: counter.XID, Count: counter.Count, LastSeen: counter.LastSeen})
+	}
+
+	for _, counter := range s.driverEventCounts() {
+		state := gpu(counter.UUID)
+		if state.Events == nil {
+			state.Events = map[string]uint64{}
+		}
+
+		state.Events[counter.Type] = counter.Count
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// a GPU no collection has listed since the start keeps its restored
+	// energy
+	for uuid, restored := range s.restored.GPUs {
+		if restored.EnergyJoules > 0 {
+			gpu(uuid).EnergyJoules = restored.EnergyJoules
+		}
+
+		if restored.ModuleEnergyJoules > 0 {
+			gpu(uuid).ModuleEnergyJoules = restored.ModuleEnergyJoules
+		}
+	}
+
+	for uuid, joules := range s.energy {
+		gpu(uuid).EnergyJoules = joules
+	}
+
+	for uuid, joules := range s.moduleEnergy {
+		gpu(uuid).ModuleEnergyJoules = joules
+	}
+
+	doc.Collection = s.collection
+
+	return doc
+}
+
+// Events wraps the live event source: the returned one serves its counters
+// plus the restored ones, and the checkpoints read it.
+func (s *Store) Events(live EventSource) *Events {
+	s.mu.Lock()
+	s.events = live
+	s.mu.Unlock()
+
+	return &Events{store: s}
+}
+
+// Events serves the live XID and driver event counters with the restored
+// ones added.
+type Events struct {
+	store *Store
+}
+
+// XIDCounts serves the XID counters, adding the restored count of each
+// (uuid, code) pair to the live one.
+func (e *Events) XIDCounts() []collect.XIDCounter {
+	return e.store.xidCounts()
+}
+
+// DriverEventCounts serves the driver event counters, adding the restored
+// count of each (uuid, type) pair to the live one.
+func (e *Events) DriverEventCounts() []collect.DriverEventCounter {
+	return e.store.driverEventCounts()
+}
+
+// xidCounts merges the restored and live XID counters; the most recent of the
+// two timestamps is the last seen one.
+func (s *Store) xidCounts() []collect.XIDCounter {
+	type key struct {
+		uuid string
+		xid  uint64
+	}
+
+	merged := map[key]*collect.XIDCounter{}
+
+	for uuid, restored := range s.restored.GPUs {
+		for _, xid := range restored.XIDs {
+			merged[key{uuid, xid.XID}] = &collect.XIDCounter{
+				UUID:     uuid,
+				XID:      xid.XID,
+				Count:    xid.Count,
+				LastSeen: xid.LastSeen,
+			}
+		}
+	}
+
+	for _, live := range s.liveEvents().XIDCounts() {
+		counter := merged[key{live.UUID, live.XID}]
+		if counter == nil {
+			merged[key{live.UUID, live.XID}] = &live
+
+			continue
+		}
+
+		counter.Count += live.Count
+		if live.LastSeen.After(counter.LastSeen) {
+			counter.LastSeen = live.LastSeen
+		}
+	}
+
+	counters := make([]collect.XIDCounter, 0, len(merged))
+	for _, counter := range merged {
+		counters = append(counters, *counter)
+	}
+
+	sort.Slice(counters, func(i, j int) bool {
+		if counters[i].UUID != counters[j].UUID {
+			return counters[i].UUID < counters[j].UUID
+		}
+
+		return counters[i].XID < counters[j].XID
+	})
+
+	return counters
+}
+
+// driverEventCounts merges the restored and live driver event counters.
+func (s *Store) driverEventCounts() []collect.DriverEventCounter {
+	type key struct {
+		uuid, eventType string
+	}
+
+	merged := map[key]uint64{}
+
+	for uuid, restored := range s.restored.GPUs {
+		for eventType, count := range restored.Events {
+			merged[key{uuid, eventType}] = count
+		}
+	}
+
+	for _, live := range s.liveEvents().DriverEventCounts() {
+		merged[key{live.UUID, live.Type}] += live.Count
+	}
+
+	counters := make([]collect.DriverEventCounter, 0, len(merged))
+	for key, count := range merged {
+		counters = append(counters, collect.DriverEventCounter{UUID: key.uuid, Type: key.eventType, Count: count})
+	}
+
+	sort.Slice(counters, func(i, j int) bool {
+		if counters[i].UUID != counters[j].UUID {
+			return counters[i].UUID < counters[j].UUID
+		}
+
+		return counters[i].Type < counters[j].Type
+	})
+
+	return counters
+}
+
+// liveEvents returns the live event source, an empty one before Events.
+//
+//nolint:ireturn // either the wrapped source or the empty stand-in
+func (s *Store) liveEvents() EventSource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.events == nil {
+		return noEvents{}
+	}
+
+	return s.events
+}
+
+// noEvents stands in for a backend without an event source, whose restored
+// counters are carried over unchanged.
+type noEvents struct{}
+
+func (noEvents) XIDCounts() []collect.XIDCounter { return nil }
+
+func (noEvents) DriverEventCounts() []collect.DriverEventCounter { return nil }
+
+// Source wraps a collection source: the returned one serves its snapshots
+// with the restored failure count added.
+func (s *Store) Source(live collect.Source) *Source {
+	return &Source{store: s, live: live}
+}
+
+// Source serves a collection source's snapshots with the restored collection
+// health folded in.
+type Source struct {
+	store *Store
+	live  collect.Source
+}
+
+// Latest serves the live snapshot, its failure count raised by the restored
+// one and its last success no older than the restored one.
+func (s *Source) Latest(ctx context.Context) collect.Snapshot {
+	snapshot := s.live.Latest(ctx)
+
+	restored := s.store.restored.Collection
+
+	snapshot.Failures += restored.Failures
+	if restored.LastSuccess.After(snapshot.LastSuccess) {
+		snapshot.LastSuccess = restored.LastSuccess
+	}
+
+	s.store.mu.Lock()
+	s.store.collection = collectionState{Failures: snapshot.Failures, LastSuccess: snapshot.LastSuccess}
+	s.store.mu.Unlock()
+
+	return snapshot
+}
+
+// WrapQueryFunc adds each GPU's restored energy to the energy counters of
+// the collections, and its restored module energy to the superchip module
+// energy counters. It is for counters the exporter integrates itself: the
+// nvml backend's energy counters are the driver's own and survive a restart.
+func (s *Store) WrapQueryFunc(query collect.QueryFunc) collect.QueryFunc {
+	return func(ctx context.Context) (collect.Reading, int, error) {
+		reading, exitCode, err := query(ctx)
+		if err != nil {
+			return reading, exitCode, err
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for i := range reading.Extras.Energy {
+			counter := &reading.Extras.Energy[i]
+
+			if restored := s.restored.GPUs[counter.UUID]; restored != nil {
+				counter.Joules += restored.EnergyJoules
+			}
+
+			s.energy[counter.UUID] = counter.Joules
+		}
+
+		for i := range reading.Extras.Superchips {
+			superchip := &reading.Extras.Superchips[i]
+			if superchip.ModuleEnergyJoules == nil {
+				continue
+			}
+
+			joules := *superchip.ModuleEnergyJoules
+			if restored := s.restored.GPUs[superchip.UUID]; restored != nil {
+				joules += restored.ModuleEnergyJoules
+			}
+
+			superchip.ModuleEnergyJoules = &joules
+			s.moduleEnergy[superchip.UUID] = joules
+		}
+
+		return reading, exitCode, nil
+	}
+}
@@ -0,0 +1,215 @@
+package state_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/neilotoole/slogt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/state"
+)
+
+// fakeEvents serves fixed live event counters.
+type fakeEvents struct {
+	xids   []collect.XIDCounter
+	events []collect.DriverEventCounter
+}
+
+func (f fakeEvents) XIDCounts() []collect.XIDCounter { return f.xids }
+
+func (f fakeEvents) DriverEventCounts() []collect.DriverEventCounter { return f.events }
+
+// fakeSource serves a fixed snapshot.
+type fakeSource collect.Snapshot
+
+func (f fakeSource) Latest(context.Context) collect.Snapshot { return collect.Snapshot(f) }
+
+// energyQuery is a collection reporting the given energy counters.
+func energyQuery(counters ...collect.EnergyCounter) collect.QueryFunc {
+	return func(context.Context) (collect.Reading, int, error) {
+		return collect.Reading{Extras: collect.Extras{Energy: counters}}, 0, nil
+	}
+}
+
+// runUntilShutdown runs the store's checkpoints and returns the shutdown,
+// which writes the final checkpoint.
+func runUntilShutdown(t *testing.T, store *state.Store) func() {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	var wg sync.WaitGroup
+
+	wg.Go(func() {
+		assert.NoError(t, store.Run(ctx))
+	})
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+var generation = state.Generation{Backend: "nvml", DriverVersion: "590.48.01", BootID: "boot-1"}
+
+func TestStoreRestoresTheCountersOfTheSameGeneration(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	first := time.Unix(1700000000, 0).UTC()
+	second := first.Add(time.Hour)
+
+	store, err := state.Open(dir, generation, slogt.New(t))
+	require.NoError(t, err)
+
+	shutdown := runUntilShutdown(t, store)
+
+	store.Events(fakeEvents{
+		xids: []collect.XIDCounter{{UUID: "gpu-a", XID: 79, Count: 2, LastSeen: first}},
+		events: []collect.DriverEventCounter{
+			{UUID: "gpu-a", Type: collect.DriverEventXID, Count: 2},
+			{UUID: "gpu-a", Type: collect.DriverEventECCSingleBit, Count: 5},
+		},
+	})
+	store.Source(fakeSource{Failures: 3, LastSuccess: first}).Latest(t.Context())
+	_, _, err = store.WrapQueryFunc(energyQuery(collect.EnergyCounter{UUID: "gpu-a", Joules: 100}))(t.Context())
+	require.NoError(t, err)
+
+	shutdown()
+
+	// the next process starts its live counters from zero
+	store, err = state.Open(dir, generation, slogt.New(t))
+	require.NoError(t, err)
+
+	events := store.Events(fakeEvents{
+		xids: []collect.XIDCounter{
+			{UUID: "gpu-a", XID: 79, Count: 1, LastSeen: second},
+			{UUID: "gpu-b", XID: 13, Count: 1, LastSeen: second},
+		},
+		events: []collect.DriverEventCounter{{UUID: "gpu-a", Type: collect.DriverEventXID, Count: 1}},
+	})
+
+	assert.Equal(t, []collect.XIDCounter{
+		{UUID: "gpu-a", XID: 79, Count: 3, LastSeen: second},
+		{UUID: "gpu-b", XID: 13, Count: 1, LastSeen: second},
+	}, events.XIDCounts())
+	assert.Equal(t, []collect.DriverEventCounter{
+		{UUID: "gpu-a", Type: collect.DriverEventECCSingleBit, Count: 5},
+		{UUID: "gpu-a", Type: collect.DriverEventXID, Count: 3},
+	}, events.DriverEventCounts())
+
+	snapshot := store.Source(fakeSource{Failures: 1}).Latest(t.Context())
+	assert.Equal(t, uint64(4), snapshot.Failures)
+	assert.True(t, snapshot.LastSuccess.Equal(first), "the restored last success stands until a newer one")
+
+	reading, _, err := store.WrapQueryFunc(energyQuery(
+		collect.EnergyCounter{UUID: "gpu-a", Joules: 10},
+		collect.EnergyCounter{UUID: "gpu-b", Joules: 20},
+	))(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []collect.EnergyCounter{
+		{UUID: "gpu-a", Joules: 110},
+		{UUID: "gpu-b", Joules: 20},
+	}, reading.Extras.Energy)
+}
+
+func TestStoreRestoresTheModuleEnergy(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	value := func(v float64) *float64 { return &v }
+	superchipQuery := func(superchips ...collect.Superchip) collect.QueryFunc {
+		return func(context.Context) (collect.Reading, int, error) {
+			return collect.Reading{Extras: collect.Extras{Superchips: superchips}}, 0, nil
+		}
+	}
+
+	store, err := state.Open(dir, generation, slogt.New(t))
+	require.NoError(t, err)
+
+	shutdown := runUntilShutdown(t, store)
+
+	_, _, err = store.WrapQueryFunc(superchipQuery(
+		collect.Superchip{UUID: "gpu-a", ModuleEnergyJoules: value(140)},
+	))(t.Context())
+	require.NoError(t, err)
+
+	shutdown()
+
+	store, err = state.Open(dir, generation, slogt.New(t))
+	require.NoError(t, err)
+
+	reading, _, err := store.WrapQueryFunc(superchipQuery(
+		collect.Superchip{UUID: "gpu-a", ModuleEnergyJoules: value(14)},
+		collect.Superchip{UUID: "gpu-b"},
+	))(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []collect.Superchip{
+		{UUID: "gpu-a", ModuleEnergyJoules: value(154)},
+		{UUID: "gpu-b"},
+	}, reading.Extras.Superchips, "a superchip without a module energy reading stays without one")
+}
+
+func TestStoreDiscardsAnotherGeneration(t *testing.T) {
+	t.Parallel()
+
+	for name, next := range map[string]state.Generation{
+		"driver upgrade": {Backend: "nvml", DriverVersion: "595.71.05", BootID: "boot-1"},
+		"reboot":         {Backend: "nvml", DriverVersion: "590.48.01", BootID: "boot-2"},
+		"other backend":  {Backend: "exec", DriverVersion: "590.48.01", BootID: "boot-1"},
+		"unknown driver": {Backend: "nvml", BootID: "boot-1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+
+			store, err := state.Open(dir, generation, slogt.New(t))
+			require.NoError(t, err)
+
+			shutdown := runUntilShutdown(t, store)
+
+			store.Events(fakeEvents{xids: []collect.XIDCounter{{UUID: "gpu-a", XID: 79, Count: 2}}})
+
+			shutdown()
+
+			store, err = state.Open(dir, next, slogt.New(t))
+			require.NoError(t, err)
+
+			assert.Empty(t, store.Events(fakeEvents{}).XIDCounts())
+		})
+	}
+}
+
+func TestStoreDiscardsAnUnreadableCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "state.json"), []byte("{\"version\": 1, \"gpus\""), 0o600))
+
+	store, err := state.Open(dir, generation, slogt.New(t))
+	require.NoError(t, err)
+
+	assert.Empty(t, store.Events(fakeEvents{}).XIDCounts())
+
+	data, err := os.ReadFile(filepath.Join(dir, "state.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"driverVersion": "590.48.01"`, "the first checkpoint replaces it")
+}
+
+func TestOpenFailsOnAnUnusableDirectory(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	_, err := state.Open(path, generation, slogt.New(t))
+	assert.Error(t, err)
+}
@@ -0,0 +1,182 @@
+// Package xidcatalog describes the XID codes the NVIDIA driver reports: what
+// each one means, how serious it is and what an operator should do about it.
+// It is backend-neutral, so whatever observes the XIDs (the NVML event
+// watcher, a kernel log reader) can label them from the same table.
+package xidcatalog
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The severities an entry may carry.
+const (
+	// SeverityInfo marks codes that need no operator action, usually
+	// application faults.
+	SeverityInfo = "info"
+	// SeverityWarning marks codes worth investigating.
+	SeverityWarning = "warning"
+	// SeverityCritical marks codes that leave the GPU unusable until the
+	// recommended action is taken.
+	SeverityCritical = "critical"
+)
+
+//go:embed catalog.yaml
+var builtin []byte
+
+// Entry describes one XID code.
+type Entry struct {
+	XID         uint64 `yaml:"xid"`
+	Description string `yaml:"description"`
+	// Severity is one of the Severity* constants.
+	Severity string `yaml:"severity"`
+	// Action is the recommended operator action.
+	Action string `yaml:"action"`
+	// ResetRequired reports that the GPU needs a reset or the node a reboot
+	// to recover.
+	ResetRequired bool `yaml:"reset-required"` //nolint:tagliatelle // kebab-case config keys
+}
+
+// fileCatalog is the document both the built-in catalog and an override
+// file are written in.
+type fileCatalog struct {
+	XIDs []Entry `yaml:"xids"`
+}
+
+// Catalog is an immutable set of entries keyed by XID code.
+type Catalog struct {
+	entries map[uint64]Entry
+}
+
+// Builtin returns the catalog the exporter ships with.
+func Builtin() *Catalog {
+	entries, err := parse(builtin, "<builtin>")
+	if err != nil {
+		// the embedded document is covered by the package tests
+		panic(err)
+	}
+
+	catalog := &Catalog{entries: make(map[uint64]Entry, len(entries))}
+	for _, entry := range entries {
+		catalog.entries[entry.XID] = entry
+	}
+
+	return catalog
+}
+
+// Load returns the built-in catalog overridden by the file at path: an entry
+// in the file replaces the built-in one with the same code as a whole, and
+// codes the built-in catalog lacks are added.
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XID catalog %q: %w", path, err)
+	}
+
+	entries, err := parse(data, path)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := Builtin()
+	for _, entry := range entries {
+		catalog.entries[entry.XID] = entry
+	}
+
+	return catalog, nil
+}
+
+// Lookup returns the entry of an XID code, reporting false for a code the
+// catalog does not describe.
+func (c *Catalog) Lookup(xid uint64) (Entry, bool) {
+	entry, ok := c.entries[xid]
+
+	return entry, ok
+}
+
+// LogAttrs returns the slog attributes describing an observed XID: its
+// catalog entry, or nothing for a code the catalog does not describe. A nil
+// catalog describes nothing.
+func (c *Catalog) LogAttrs(xid uint64) []any {
+	if c == nil {
+		return nil
+	}
+
+	entry, ok := c.entries[xid]
+	if !ok {
+		return nil
+	}
+
+	return []any{
+		"description", entry.Description,
+		"severity", entry.Severity,
+		"action", entry.Action,
+		"reset_required", entry.ResetRequired,
+	}
+}
+
+// Entries lists every entry, ordered by code.
+func (c *Catalog) Entries() []Entry {
+	entries := make([]Entry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].XID < entries[j].XID })
+
+	return entries
+}
+
+// parse strictly decodes and validates a catalog document.
+func parse(data []byte, name string) ([]Entry, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var doc fileCatalog
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse XID catalog %q: %w", name, err)
+	}
+
+	seen := make(map[uint64]bool, len(doc.XIDs))
+
+	for _, entry := range doc.XIDs {
+		if err := entry.validate(); err != nil {
+			return nil, fmt.Errorf("invalid XID catalog %q: %w", name, err)
+		}
+
+		if seen[entry.XID] {
+			return nil, fmt.Errorf("invalid XID catalog %q: xid %d is listed twice", name, entry.XID)
+		}
+
+		seen[entry.XID] = true
+	}
+
+	return doc.XIDs, nil
+}
+
+// validate checks one entry. The driver numbers XIDs from 1, so a zero code
+// is an entry missing its xid key.
+func (e Entry) validate() error {
+	if e.XID == 0 {
+		return errors.New("an entry has no xid")
+	}
+
+	if e.Description == "" {
+		return fmt.Errorf("xid %d has no description", e.XID)
+	}
+
+	switch e.Severity {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+	default:
+		return fmt.Errorf("xid %d has severity %q, want %s, %s or %s",
+			e.XID, e.Severity, SeverityInfo, SeverityWarning, SeverityCritical)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package xidcatalog_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/xidcatalog"
+)
+
+func TestBuiltin(t *testing.T) {
+	t.Parallel()
+
+	catalog := xidcatalog.Builtin()
+
+	entry, ok := catalog.Lookup(79)
+	require.True(t, ok)
+	assert.Equal(t, "GPU has fallen off the bus", entry.Description)
+	assert.Equal(t, xidcatalog.SeverityCritical, entry.Severity)
+	assert.True(t, entry.ResetRequired)
+
+	_, ok = catalog.Lookup(1)
+	assert.False(t, ok)
+
+	entries := catalog.Entries()
+	require.NotEmpty(t, entries)
+
+	for i, entry := range entries {
+		assert.NotEmpty(t, entry.Action, "xid %d has no recommended action", entry.XID)
+
+		if i > 0 {
+			assert.Less(t, entries[i-1].XID, entry.XID, "entries must be ordered by code")
+		}
+	}
+
+	// the codes the alerting guidance calls reset/reboot-class
+	for _, xid := range []uint64{48, 62, 64, 74, 79, 95, 119, 120} {
+		entry, ok := catalog.Lookup(xid)
+		require.True(t, ok, "xid %d", xid)
+		assert.Equal(t, xidcatalog.SeverityCritical, entry.Severity, "xid %d", xid)
+		assert.True(t, entry.ResetRequired, "xid %d", xid)
+	}
+}
+
+func TestLoadOverridesTheBuiltinCatalog(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "xids.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`xids:
+  - xid: 13
+    description: Graphics engine exception
+    severity: warning
+    action: Page the ML platform team
+  - xid: 154
+    description: GPU recovery action changed
+    severity: critical
+    action: Follow the recovery action the driver reports
+    reset-required: true
+`), 0o600))
+
+	catalog, err := xidcatalog.Load(path)
+	require.NoError(t, err)
+
+	entry, ok := catalog.Lookup(13)
+	require.True(t, ok)
+	assert.Equal(t, xidcatalog.Entry{
+		XID:         13,
+		Description: "Graphics engine exception",
+		Severity:    xidcatalog.SeverityWarning,
+		Action:      "Page the ML platform team",
+	}, entry)
+
+	entry, ok = catalog.Lookup(154)
+	require.True(t, ok, "a code the built-in catalog lacks is added")
+	assert.True(t, entry.ResetRequired)
+
+	_, ok = catalog.Lookup(79)
+	assert.True(t, ok, "the entries the file does not mention are kept")
+	assert.Len(t, catalog.Entries(), len(xidcatalog.Builtin().Entries())+1)
+
+	_, ok = xidcatalog.Builtin().Lookup(154)
+	assert.False(t, ok, "an override must not leak into the built-in catalog")
+}
+
+func TestLoadRejectsInvalidCatalogs(t *testing.T) {
+	t.Parallel()
+
+	for name, doc := range map[string]string{
+		"unknown key":      "xids:\n  - xid: 13\n    description: x\n    severity: info\n    reset: true\n",
+		"missing xid":      "xids:\n  - description: x\n    severity: info\n",
+		"no description":   "xids:\n  - xid: 13\n    severity: info\n",
+		"unknown severity": "xids:\n  - xid: 13\n    description: x\n    severity: fatal\n",
+		"duplicate":        "xids:\n  - {xid: 13, description: x, severity: info}\n  - {xid: 13, description: y, severity: info}\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "xids.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(doc), 0o600))
+
+			_, err := xidcatalog.Load(path)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := xidcatalog.Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestLogAttrs(t *testing.T) {
+	t.Parallel()
+
+	catalog := xidcatalog.Builtin()
+
+	assert.Equal(t, []any{
+		"description", "GPU has fallen off the bus",
+		"severity", xidcatalog.SeverityCritical,
+		"action", "Drain and reboot the node; check power, cooling and PCIe seating if it recurs",
+		"reset_required", true,
+	}, catalog.LogAttrs(79))
+	assert.Nil(t, catalog.LogAttrs(1), "a code the catalog does not describe has no attributes")
+
+	var none *xidcatalog.Catalog
+	assert.Nil(t, none.LogAttrs(79))
+}
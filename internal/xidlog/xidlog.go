@@ -0,0 +1,456 @@
+// Package xidlog counts XID errors from the kernel log, for the exec backend,
+// which has no driver event watcher. The driver logs every XID it raises as
+//
+//	NVRM: Xid (PCI:0000:3b:00): 79, pid=1234, name=python, GPU has fallen off the bus.
+//
+// so following /dev/kmsg, or a file a journal export appends to, yields the
+// same counters the NVML backend's watcher serves. The lines name the GPU by
+// PCI bus id only; the uuid comes from the collections' tables.
+package xidlog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/eventlog"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/xidcatalog"
+)
+
+// DefaultPath is the kernel log device.
+const DefaultPath = "/dev/kmsg"
+
+// unknownUUID labels the XIDs of a bus id no collection has listed yet, the
+// same fallback the NVML watcher uses for an unregistered device.
+const unknownUUID = "unknown"
+
+// The pacing of the follower: how often a plain file is checked for new
+// lines, and the backoff between reopen attempts after a read failure.
+const (
+	pollInterval = time.Second
+	backoffStart = time.Second
+	backoffMax   = 30 * time.Second
+)
+
+// xidLine matches the driver's XID log line. The bus id is
+// domain:bus:device, sometimes with the function appended.
+var xidLine = regexp.MustCompile(
+	`NVRM: Xid \(PCI:([0-9A-Fa-f]+:[0-9A-Fa-f]+:[0-9A-Fa-f]+)(?:\.[0-9A-Fa-f]+)?\): (\d+),`)
+
+// ParseLine extracts the bus id and the XID code from one kernel log line,
+// reporting false for a line that is not an XID. The bus id is normalized
+// like BusKey's.
+func ParseLine(line string) (string, uint64, bool) {
+	match := xidLine.FindStringSubmatch(line)
+	if match == nil {
+		return "", 0, false
+	}
+
+	busID, ok := BusKey(match[1])
+	if !ok {
+		return "", 0, false
+	}
+
+	xid, err := strconv.ParseUint(match[2], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+
+	return busID, xid, true
+}
+
+// BusKey normalizes a PCI bus id to domain:bus:device in lower-case hex of
+// fixed width, the identity the kernel log and nvidia-smi share:
+// nvidia-smi's "00000000:3B:00.0" and the driver's "0000:3b:00" both become
+// "0000:3b:00".
+func BusKey(busID string) (string, bool) {
+	busID, _, _ = strings.Cut(strings.TrimSpace(busID), ".")
+
+	parts := strings.Split(busID, ":")
+	if len(parts) != 3 { //nolint:mnd // domain, bus, device
+		return "", false
+	}
+
+	values := make([]uint64, len(parts))
+
+	for i, part := range parts {
+		value, err := strconv.ParseUint(part, 16, 32)
+		if err != nil {
+			return "", false
+		}
+
+		values[i] = value
+	}
+
+	return fmt.Sprintf("%04x:%02x:%02x", values[0], values[1], values[2]), true
+}
+
+// xidStat is one (bus, XID code) pair's running state.
+type xidStat struct {
+	count uint64
+	last  time.Time
+}
+
+// Source follows a kernel log and counts the XIDs it reports. It serves the
+// exporter's XID and driver event sources; the counts are keyed by bus id
+// and attributed to uuids at read time, so an XID logged before the first
+// collection still lands on its GPU.
+type Source struct {
+	path    string
+	catalog *xidcatalog.Catalog
+	logger  *slog.Logger
+	now     func() time.Time
+	poll    time.Duration
+
+	file *os.File
+
+	mu   sync.Mutex
+	xids map[string]map[uint64]*xidStat
+	// uuids maps every bus id a collection ever listed to its uuid. A bus
+	// is never forgotten: a GPU that fell off the bus leaves the tables,
+	// and its XIDs must keep their uuid.
+	uuids map[string]string
+	// recent logs the XIDs in order, each with the line it was parsed from.
+	recent eventlog.Ring
+}
+
+// errRotated reports that the followed path names another file now.
+var errRotated = errors.New("the log was rotated")
+
+// Open opens the kernel log at path and positions it at its end: only the
+// lines written after startup are counted, like the NVML watcher's events.
+// Failing to open it (a missing file, or /dev/kmsg without CAP_SYSLOG under
+// kernel.dmesg_restrict) is a startup error rather than silently empty
+// counters.
+func Open(path string, catalog *xidcatalog.Catalog, logger *slog.Logger) (*Source, error) {
+	file, err := openLog(path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{
+		path:    path,
+		catalog: catalog,
+		logger:  logger,
+		now:     time.Now,
+		poll:    pollInterval,
+		file:    file,
+	}, nil
+}
+
+// openLog opens a log, past its current content when atEnd is set.
+func openLog(path string, atEnd bool) (*os.File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the kernel log: %w", err)
+	}
+
+	if !atEnd {
+		return file, nil
+	}
+
+	if _, err = file.Seek(0, io.SeekEnd); err != nil {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("failed to seek to the end of the kernel log %q: %w", path, err)
+	}
+
+	return file, nil
+}
+
+// Run follows the log until ctx ends. It never returns an error: a failed
+// read is logged once and the log reopened with a backoff, counting from
+// its end again; a rotated file is reopened from its start, since every
+// line in it is new.
+func (s *Source) Run(ctx context.Context) error {
+	backoff := backoffStart
+	warned := false
+	file := s.file
+
+	for {
+		err := s.follow(ctx, file)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		rotated := errors.Is(err, errRotated)
+		if !rotated && !warned {
+			warned = true
+
+			s.logger.Warn("failed to read the kernel log, reopening it", "path", s.path, "err", err)
+		}
+
+		delay := s.poll
+		if !rotated {
+			delay = backoff
+		}
+
+		for file = nil; file == nil; {
+			if !sleepContext(ctx, delay) {
+				return nil
+			}
+
+			file, err = openLog(s.path, !rotated)
+			if err != nil {
+				delay = min(backoff*2, backoffMax)
+				backoff = delay
+			}
+		}
+
+		backoff = backoffStart
+	}
+}
+
+// follow reads an open log until ctx ends or the read fails, closing the
+// file either way. /dev/kmsg blocks for the next record, so the file is
+// closed on ctx end to release the read; a plain file reports EOF at its
+// end and is polled, left when it was rotated and rewound when it was
+// truncated.
+func (s *Source) follow(ctx context.Context, file *os.File) error {
+	stop := context.AfterFunc(ctx, func() { _ = file.Close() })
+
+	defer func() {
+		if stop() {
+			_ = file.Close()
+		}
+	}()
+
+	reader := bufio.NewReader(file)
+
+	var partial string
+
+	for {
+		line, err := reader.ReadString('\n')
+		partial += line
+
+		switch {
+		case err == nil:
+			s.record(partial)
+			partial = ""
+		case errors.Is(err, syscall.EPIPE):
+			// /dev/kmsg overwrote records before they were read: the
+			// reader resumes at the oldest one still buffered
+			continue
+		case errors.Is(err, io.EOF):
+			if !sleepContext(ctx, s.poll) {
+				return ctx.Err()
+			}
+
+			truncated, err := s.rewind(file)
+			if err != nil {
+				return err
+			}
+
+			if truncated {
+				partial = ""
+			}
+		default:
+			return err
+		}
+	}
+}
+
+// rewind checks a plain file at EOF: it fails with errRotated when the path
+// names another file now, and seeks back to the start of a file truncated
+// below the read position, reporting true.
+func (s *Source) rewind(file *os.File) (bool, error) {
+	current, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	latest, err := os.Stat(s.path)
+	if err != nil || !os.SameFile(current, latest) {
+		// a vanished path is a rotation in progress
+		return false, errRotated
+	}
+
+	offset, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, err
+	}
+
+	if current.Size() >= offset {
+		return false, nil
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// record counts the XID one log line reports, if any.
+func (s *Source) record(line string) {
+	busID, xid, ok := ParseLine(line)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+
+	if s.xids == nil {
+		s.xids = map[string]map[uint64]*xidStat{}
+	}
+
+	perBus := s.xids[busID]
+	if perBus == nil {
+		perBus = map[uint64]*xidStat{}
+		s.xids[busID] = perBus
+	}
+
+	stat := perBus[xid]
+	if stat == nil {
+		stat = &xidStat{}
+		perBus[xid] = stat
+	}
+
+	now := s.now()
+
+	stat.count++
+	stat.last = now
+
+	uuid := s.uuids[busID]
+	if uuid == "" {
+		uuid = unknownUUID
+	}
+
+	s.mu.Unlock()
+
+	s.recent.Add(eventlog.Event{
+		Time:     now,
+		UUID:     uuid,
+		Type:     collect.DriverEventXID,
+		XID:      xid,
+		PCIBusID: busID,
+		Message:  strings.TrimSpace(line),
+	})
+
+	s.logger.Warn("observed a GPU XID error",
+		append([]any{"pci_bus_id", busID, "xid", xid}, s.catalog.LogAttrs(xid)...)...)
+}
+
+// ObserveTable learns the bus ids and uuids of a collection's GPUs.
+func (s *Source) ObserveTable(table *nvidiasmi.Table) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.uuids == nil {
+		s.uuids = map[string]string{}
+	}
+
+	for _, row := range table.Rows {
+		busID, ok := BusKey(row.QFieldToCells[nvidiasmi.PCIBusIDQField].RawValue)
+		if !ok {
+			continue
+		}
+
+		s.uuids[busID] = nvidiasmi.NormalizeUUID(row.QFieldToCells[nvidiasmi.UUIDQField].RawValue)
+	}
+}
+
+// XIDCounts serves the accumulated XID counts, sorted for deterministic
+// output. The bus ids no collection has listed are labeled "unknown"; the
+// counts of buses sharing a label are merged, so the series stay unique.
+func (s *Source) XIDCounts() []collect.XIDCounter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type key struct {
+		uuid string
+		xid  uint64
+	}
+
+	merged := map[key]*collect.XIDCounter{}
+
+	for busID, perBus := range s.xids {
+		uuid := s.uuids[busID]
+		if uuid == "" {
+			uuid = unknownUUID
+		}
+
+		for xid, stat := range perBus {
+			counter := merged[key{uuid, xid}]
+			if counter == nil {
+				counter = &collect.XIDCounter{UUID: uuid, XID: xid}
+				merged[key{uuid, xid}] = counter
+			}
+
+			counter.Count += stat.count
+			if stat.last.After(counter.LastSeen) {
+				counter.LastSeen = stat.last
+			}
+		}
+	}
+
+	counters := make([]collect.XIDCounter, 0, len(merged))
+	for _, counter := range merged {
+		counters = append(counters, *counter)
+	}
+
+	sort.Slice(counters, func(i, j int) bool {
+		if counters[i].UUID != counters[j].UUID {
+			return counters[i].UUID < counters[j].UUID
+		}
+
+		return counters[i].XID < counters[j].XID
+	})
+
+	return counters
+}
+
+// DriverEventCounts serves the XID totals per GPU as the xid driver event
+// type: the kernel log carries the XIDs alone, none of the other event types.
+func (s *Source) DriverEventCounts() []collect.DriverEventCounter {
+	var events []collect.DriverEventCounter
+
+	for _, counter := range s.XIDCounts() {
+		if n := len(events); n > 0 && events[n-1].UUID == counter.UUID {
+			events[n-1].Count += counter.Count
+
+			continue
+		}
+
+		events = append(events, collect.DriverEventCounter{
+			UUID:  counter.UUID,
+			Type:  collect.DriverEventXID,
+			Count: counter.Count,
+		})
+	}
+
+	return events
+}
+
+// RecentEvents serves the latest XIDs from the log, oldest first. An event
+// keeps the uuid its bus had when it was logged.
+func (s *Source) RecentEvents(filter eventlog.Filter) []eventlog.Event {
+	return s.recent.RecentEvents(filter)
+}
+
+// sleepContext sleeps for the given duration, reporting false when the
+// context ended first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
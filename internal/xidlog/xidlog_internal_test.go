@@ -0,0 +1,228 @@
+package xidlog
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/neilotoole/slogt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/collect"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/eventlog"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/nvidiasmi"
+	"github.com/utkuozdemir/nvidia_gpu_exporter/internal/xidcatalog"
+)
+
+// xidFixture holds kernel log lines in the three shapes the source may
+// follow: /dev/kmsg records (with their continuation lines), dmesg output and
+// a journal export.
+const xidFixture = "testdata/kernel.log"
+
+func TestParseLine(t *testing.T) {
+	t.Parallel()
+
+	type xid struct {
+		busID string
+		xid   uint64
+	}
+
+	file, err := os.Open(xidFixture)
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	var got []xid
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if busID, code, ok := ParseLine(scanner.Text()); ok {
+			got = append(got, xid{busID, code})
+		}
+	}
+
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []xid{
+		{"0000:3b:00", 13},
+		{"0000:3b:00", 13},
+		{"0000:86:00", 79},
+		{"0000:af:00", 48},
+	}, got)
+
+	busID, code, ok := ParseLine("NVRM: Xid (PCI:0001:3B:00.1): 119, pid=1, Timeout after 6s of waiting for RPC response")
+	assert.True(t, ok, "a bus id with its function and upper-case digits")
+	assert.Equal(t, "0001:3b:00", busID)
+	assert.Equal(t, uint64(119), code)
+}
+
+func TestBusKey(t *testing.T) {
+	t.Parallel()
+
+	for raw, want := range map[string]string{
+		"00000000:3B:00.0": "0000:3b:00",
+		"0000:3b:00":       "0000:3b:00",
+		" 00000001:AF:1F ": "0001:af:1f",
+	} {
+		got, ok := BusKey(raw)
+		assert.True(t, ok, raw)
+		assert.Equal(t, want, got, raw)
+	}
+
+	for _, raw := range []string{"", "N/A", "3b:00.0", "0000:zz:00.0"} {
+		_, ok := BusKey(raw)
+		assert.False(t, ok, raw)
+	}
+}
+
+// busTable builds a collection table listing the given bus ids and uuids.
+func busTable(pairs ...string) *nvidiasmi.Table {
+	table := &nvidiasmi.Table{}
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		table.Rows = append(table.Rows, nvidiasmi.Row{QFieldToCells: map[nvidiasmi.QField]nvidiasmi.Cell{
+			nvidiasmi.PCIBusIDQField: {RawValue: pairs[i]},
+			nvidiasmi.UUIDQField:     {RawValue: pairs[i+1]},
+		}})
+	}
+
+	return table
+}
+
+// followFile runs a source on a log file until the test ends; teardown
+// waits for the follower to exit.
+func followFile(t *testing.T, path string, now time.Time) *Source {
+	t.Helper()
+
+	source, err := Open(path, xidcatalog.Builtin(), slogt.New(t))
+	require.NoError(t, err)
+
+	source.poll = 5 * time.Millisecond
+	source.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	var wg sync.WaitGroup
+
+	wg.Go(func() {
+		assert.NoError(t, source.Run(ctx))
+	})
+
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	return source
+}
+
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+
+	_, err = file.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
+
+func TestSourceCountsAppendedXIDs(t *testing.T) {
+	t.Parallel()
+
+	fixture, err := os.ReadFile(xidFixture)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "kernel.log")
+	// an XID already in the log predates the exporter and is not counted
+	require.NoError(t, os.WriteFile(path, []byte("NVRM: Xid (PCI:0000:3b:00): 31, pid=1, MMU Fault\n"), 0o600))
+
+	now := time.Unix(1700000000, 0)
+	source := followFile(t, path, now)
+
+	source.ObserveTable(busTable(
+		"00000000:3B:00.0", "GPU-3b3b3b3b-0000-0000-0000-000000000000",
+		"00000000:86:00.0", "GPU-86868686-0000-0000-0000-000000000000"))
+
+	// the last line arrives in two writes, like a logger flushing mid-line
+	lines := string(fixture)
+	cut := len(lines) - 40
+	appendFile(t, path, lines[:cut])
+	appendFile(t, path, lines[cut:])
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, []collect.XIDCounter{
+			{UUID: "3b3b3b3b-0000-0000-0000-000000000000", XID: 13, Count: 2, LastSeen: now},
+			{UUID: "86868686-0000-0000-0000-000000000000", XID: 79, Count: 1, LastSeen: now},
+			{UUID: unknownUUID, XID: 48, Count: 1, LastSeen: now},
+		}, source.XIDCounts())
+	}, 5*time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, []collect.DriverEventCounter{
+		{UUID: "3b3b3b3b-0000-0000-0000-000000000000", Type: collect.DriverEventXID, Count: 2},
+		{UUID: "86868686-0000-0000-0000-000000000000", Type: collect.DriverEventXID, Count: 1},
+		{UUID: unknownUUID, Type: collect.DriverEventXID, Count: 1},
+	}, source.DriverEventCounts())
+
+	events := source.RecentEvents(eventlog.Filter{XIDs: []uint64{79}})
+	require.Len(t, events, 1)
+	assert.Equal(t, "86868686-0000-0000-0000-000000000000", events[0].UUID)
+	assert.Equal(t, "0000:86:00", events[0].PCIBusID)
+	assert.Contains(t, events[0].Message, "NVRM: Xid (PCI:0000:86:00): 79")
+	assert.Len(t, source.RecentEvents(eventlog.Filter{}), 4)
+
+	// a GPU that fell off the bus leaves the tables but keeps its uuid,
+	// and a bus listed late claims the XIDs logged before it
+	source.ObserveTable(busTable(
+		"00000000:3B:00.0", "GPU-3b3b3b3b-0000-0000-0000-000000000000",
+		"00000000:AF:00.0", "GPU-afafafaf-0000-0000-0000-000000000000"))
+
+	counts := source.XIDCounts()
+	require.Len(t, counts, 3)
+	assert.Equal(t, "86868686-0000-0000-0000-000000000000", counts[1].UUID)
+	assert.Equal(t, "afafafaf-0000-0000-0000-000000000000", counts[2].UUID)
+}
+
+func TestSourceFollowsRotationAndTruncation(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "kernel.log")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	source := followFile(t, path, time.Unix(1700000000, 0))
+
+	xidCount := func() uint64 {
+		var total uint64
+		for _, counter := range source.XIDCounts() {
+			total += counter.Count
+		}
+
+		return total
+	}
+
+	xid := "NVRM: Xid (PCI:0000:3b:00): 79, pid=1, GPU has fallen off the bus.\n"
+
+	appendFile(t, path, strings.Repeat(xid, 3))
+	require.Eventually(t, func() bool { return xidCount() == 3 }, 5*time.Second, 5*time.Millisecond)
+
+	// a truncated file is read again from its start
+	require.NoError(t, os.WriteFile(path, []byte(xid), 0o600))
+	require.Eventually(t, func() bool { return xidCount() == 4 }, 5*time.Second, 5*time.Millisecond)
+
+	// a rotated file is replaced by a new one, read whole
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, os.WriteFile(path, []byte(xid+xid), 0o600))
+	require.Eventually(t, func() bool { return xidCount() == 6 }, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestOpenFailsOnAMissingLog(t *testing.T) {
+	t.Parallel()
+
+	_, err := Open(filepath.Join(t.TempDir(), "missing"), nil, slogt.New(t))
+	assert.Error(t, err)
+}